@@ -29,6 +29,9 @@ Examples:
   # Check specific containers
   zockimate check wireguard plex
 
+  # Check a container on another Docker host (see --hosts)
+  zockimate check web1/nginx
+
   # Check all containers including stopped ones
   zockimate -A check
 
@@ -72,8 +75,15 @@ Examples:
 					needsUpdate++
 					updateMsg := fmt.Sprintf("%s: %s → %s",
 						name, result.CurrentImage.String(), result.UpdateImage.String())
+					if result.InCooldown {
+						updateMsg += fmt.Sprintf(" (update available, waiting for cooldown until %s)",
+							result.CooldownUntil.Local().Format("2006-01-02 15:04"))
+						updates = append(updates, fmt.Sprintf("%s (cooldown until %s)",
+							name, result.CooldownUntil.Local().Format("2006-01-02 15:04")))
+					} else {
+						updates = append(updates, name)
+					}
 					cfg.Logger.Infof("✓ %s", updateMsg)
-					updates = append(updates, name)
 					updateDetails = append(updateDetails, updateMsg)
 				} else {
 					upToDate++
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"zockimate/internal/config"
+	"zockimate/internal/manager"
+	"zockimate/internal/types/options"
+)
+
+func newCloneCmd(cfg *config.Config) *cobra.Command {
+	var opts = options.CloneOptions{
+		Timeout: options.DefaultRollbackTimeout,
+	}
+	var destroy bool
+
+	cmd := &cobra.Command{
+		Use:   "clone container-name [snapshot-id] --name new-name | clone --destroy new-name",
+		Short: "Run a copy of a container from a snapshot",
+		Long: `Create a new container from the configuration of a snapshot, next to the
+original, for example to test an upgrade. If no snapshot ID is specified, uses
+the most recent snapshot; --now takes a snapshot of the current state first.
+
+The clone gets its own name, no published host ports (or ports shifted by
+--port-offset), no docker compose or traefik labels, no network aliases or
+fixed addresses, and zockimate.enable=false unless --enable is given. Its ZFS
+data is a zfs clone of each dataset of the snapshot, mounted next to the
+original dataset. Named volumes and bind mounts outside those datasets are
+shared with the original, and reported.
+
+clone --destroy removes the clone container and destroys its ZFS clones.
+
+Examples:
+  # Run the last snapshot of nextcloud as nextcloud-test
+  zockimate clone nextcloud --name nextcloud-test
+
+  # Clone the current state, publishing ports shifted by 10000
+  zockimate clone nextcloud --now --name nextcloud-test --port-offset 10000
+
+  # Tear it down
+  zockimate clone --destroy nextcloud-test`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if destroy {
+				if len(args) != 1 {
+					return fmt.Errorf("--destroy takes the name of the clone")
+				}
+			} else if opts.Name == "" {
+				return fmt.Errorf("--name is required")
+			}
+
+			m, err := manager.NewContainerManager(cfg)
+			if err != nil {
+				return err
+			}
+			defer m.Close()
+
+			ctx := context.Background()
+			if destroy {
+				result, err := m.DestroyClone(ctx, args[0])
+				if err != nil {
+					return err
+				}
+				if !result.Success {
+					return fmt.Errorf("destroy failed: %v", result.Error)
+				}
+				cfg.Logger.Infof("✓ Clone %s destroyed", result.NewName)
+				for _, d := range result.Datasets {
+					cfg.Logger.Infof("  dataset %s destroyed", d)
+				}
+				return nil
+			}
+
+			if len(args) > 1 {
+				if id, err := strconv.ParseInt(args[1], 10, 64); err == nil {
+					opts.SnapshotID = id
+				} else {
+					return fmt.Errorf("invalid snapshot ID: %v", err)
+				}
+			}
+
+			result, err := m.CloneContainer(ctx, args[0], opts)
+			if err != nil {
+				return err
+			}
+			if !result.Success {
+				return fmt.Errorf("clone failed: %v", result.Error)
+			}
+
+			cfg.Logger.Infof("✓ %s cloned from snapshot %d of %s (image %s)",
+				result.NewName, result.SnapshotID, result.ContainerName, result.Image)
+			for _, d := range result.Datasets {
+				cfg.Logger.Infof("  dataset %s", d)
+			}
+			if len(result.Ports) > 0 {
+				cfg.Logger.Infof("  ports: %s", strings.Join(result.Ports, ", "))
+			}
+			for _, s := range result.SharedMounts {
+				cfg.Logger.Warnf("  %s is shared with %s", s, result.ContainerName)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Name, "name", "", "Name of the clone container")
+	cmd.Flags().BoolVar(&opts.Now, "now", false, "Snapshot the current state first and clone it")
+	cmd.Flags().IntVar(&opts.PortOffset, "port-offset", 0,
+		"Publish host ports shifted by this offset (default: no published ports)")
+	cmd.Flags().BoolVar(&opts.Enable, "enable", false, "Keep the clone managed by zockimate")
+	cmd.Flags().BoolVar(&opts.NoData, "no-data", false,
+		"Don't clone ZFS datasets (the clone uses the original data)")
+	cmd.Flags().BoolVarP(&opts.Force, "force", "f", false,
+		"Force clone even if exact image version cannot be guaranteed")
+	cmd.Flags().BoolVar(&destroy, "destroy", false, "Remove a clone and destroy its ZFS clones")
+
+	return cmd
+}
@@ -0,0 +1,246 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/docker/go-units"
+	"github.com/spf13/cobra"
+
+	"zockimate/internal/config"
+	"zockimate/internal/manager"
+)
+
+func newDbCmd(cfg *config.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "db",
+		Short: "Maintain the snapshot database",
+	}
+
+	cmd.AddCommand(
+		newDbRebuildCmd(cfg),
+		newDbVersionCmd(cfg),
+		newDbMigrateCmd(cfg),
+		newDbVacuumCmd(cfg),
+	)
+
+	return cmd
+}
+
+func newDbRebuildCmd(cfg *config.Config) *cobra.Command {
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "rebuild",
+		Short: "Rebuild lost entries from ZFS snapshot properties",
+		Long: `Recreate the database entries of ZFS snapshots from the zockimate:* user
+properties set on each snapshot (container, image, configuration, reason).
+Use it after losing the database: existing entries are kept.
+Mount archives are not recovered.
+
+Examples:
+  # Show what would be imported
+  zockimate db rebuild --dry-run
+
+  # Rebuild into a new database
+  zockimate db rebuild --db /data/zockimate.db`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			m, err := manager.NewContainerManager(cfg)
+			if err != nil {
+				return err
+			}
+			defer m.Close()
+
+			results, err := m.RebuildDatabase(context.Background(), dryRun)
+			if err != nil {
+				return err
+			}
+			if len(results) == 0 {
+				cfg.Logger.Info("No host with ZFS access")
+				return nil
+			}
+
+			var failed int
+			for _, r := range results {
+				if r.Error != "" {
+					failed++
+					cfg.Logger.Errorf("✗ %s: %s", r.Host, r.Error)
+					continue
+				}
+				if dryRun {
+					cfg.Logger.Infof("%s: %d snapshot(s) found", r.Host, r.Found)
+				} else {
+					cfg.Logger.Infof("✓ %s: %d snapshot(s) found, %d imported, %d already present",
+						r.Host, r.Found, r.Imported, r.Existing)
+				}
+				if r.Renumbered > 0 {
+					cfg.Logger.Warnf("  %d entry(ies) imported under a new ID (their ID was reused)", r.Renumbered)
+				}
+				for _, name := range r.Invalid {
+					cfg.Logger.Warnf("  %s: incomplete zockimate properties, skipped", name)
+				}
+			}
+
+			if failed > 0 {
+				return fmt.Errorf("rebuild failed on %d host(s)", failed)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVarP(&dryRun, "dry-run", "n", false,
+		"Show what would be imported without taking action")
+
+	return cmd
+}
+
+func newDbVersionCmd(cfg *config.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "version",
+		Short: "Show the database schema version",
+		Long: `Show the schema version of the database, the migrations already applied
+and those that the next run (or "db migrate") will apply.
+The database is not modified.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			status, err := manager.DatabaseVersion(cfg)
+			if status == nil {
+				return err
+			}
+
+			if cfg.JSON {
+				if err := json.NewEncoder(os.Stdout).Encode(status); err != nil {
+					return fmt.Errorf("failed to encode JSON: %v", err)
+				}
+				return err
+			}
+
+			cfg.Logger.Infof("Schema version: %d (latest: %d)", status.Current, status.Latest)
+			for _, m := range status.Applied {
+				if m.AppliedAt != nil {
+					cfg.Logger.Infof("  ✓ %04d %s (%s)", m.Version, m.Name, m.AppliedAt.Local().Format("2006-01-02 15:04:05"))
+				} else {
+					cfg.Logger.Infof("  ✓ %04d %s", m.Version, m.Name)
+				}
+			}
+			for _, m := range status.Pending {
+				cfg.Logger.Infof("  · %04d %s (pending)", m.Version, m.Name)
+			}
+			return err
+		},
+	}
+
+	cmd.Flags().BoolVarP(&cfg.JSON, "json", "j", false,
+		"Output in JSON format")
+
+	return cmd
+}
+
+func newDbMigrateCmd(cfg *config.Config) *cobra.Command {
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Apply pending database schema migrations",
+		Long: `Apply the pending schema migrations embedded in the binary.
+The database file is copied to <db>.v<version>-<date>.bak first.
+Migrations are also applied automatically by every other command.
+
+Examples:
+  # List the migrations that would be applied
+  zockimate db migrate --dry-run
+
+  # Migrate
+  zockimate db migrate`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			result, err := manager.MigrateDatabase(cfg, dryRun)
+			if result == nil {
+				return err
+			}
+
+			if dryRun {
+				if len(result.Pending) == 0 {
+					cfg.Logger.Infof("Schema is up to date (version %d)", result.From)
+					return nil
+				}
+				for _, m := range result.Pending {
+					cfg.Logger.Infof("Would apply %04d %s", m.Version, m.Name)
+				}
+				return nil
+			}
+
+			if result.Backup != "" {
+				cfg.Logger.Infof("Backup: %s", result.Backup)
+			}
+			for _, m := range result.Applied {
+				cfg.Logger.Infof("✓ Applied %04d %s", m.Version, m.Name)
+			}
+			if err != nil {
+				return err
+			}
+			if len(result.Applied) == 0 {
+				cfg.Logger.Infof("Schema is up to date (version %d)", result.To)
+			} else {
+				cfg.Logger.Infof("Schema migrated from version %d to %d", result.From, result.To)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVarP(&dryRun, "dry-run", "n", false,
+		"List pending migrations without applying them")
+
+	return cmd
+}
+
+func newDbVacuumCmd(cfg *config.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "vacuum",
+		Short: "Compact the database and report space saved",
+		Long: `Move the configurations still stored inline in older entries to the
+deduplicated, zstd-compressed blob store, delete blobs no entry references
+anymore and compact the database (SQLite VACUUM, PostgreSQL VACUUM FULL).
+
+Examples:
+  zockimate db vacuum`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			result, err := manager.VacuumDatabase(cfg)
+			if result == nil {
+				return err
+			}
+
+			if cfg.JSON {
+				if err := json.NewEncoder(os.Stdout).Encode(result); err != nil {
+					return fmt.Errorf("failed to encode JSON: %v", err)
+				}
+				return err
+			}
+
+			if result.Converted > 0 {
+				cfg.Logger.Infof("Moved configurations of %d snapshot(s) to the blob store", result.Converted)
+			}
+			if result.OrphanBlobs > 0 {
+				cfg.Logger.Infof("Deleted %d unreferenced blob(s)", result.OrphanBlobs)
+			}
+			if err != nil {
+				return err
+			}
+			cfg.Logger.Infof("Blobs: %d (%s, %s compressed)", result.Blobs,
+				units.BytesSize(float64(result.BlobSize)), units.BytesSize(float64(result.BlobStored)))
+			cfg.Logger.Infof("Database size: %s -> %s (saved %s)",
+				units.BytesSize(float64(result.SizeBefore)), units.BytesSize(float64(result.SizeAfter)),
+				units.BytesSize(float64(result.SizeBefore-result.SizeAfter)))
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVarP(&cfg.JSON, "json", "j", false,
+		"Output in JSON format")
+
+	return cmd
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/moby/term"
+	"github.com/spf13/cobra"
+
+	"zockimate/internal/config"
+	"zockimate/internal/manager"
+	"zockimate/internal/types/options"
+)
+
+func newExportCmd(cfg *config.Config) *cobra.Command {
+	var opts options.ExportOptions
+	var output string
+
+	cmd := &cobra.Command{
+		Use:   "export [container...]",
+		Short: "Export snapshot history to an archive",
+		Long: `Write the history of the given containers (all by default) to a versioned
+tar archive: snapshot entries with their configurations, update and rollback
+operations and, with --images, the images they reference.
+
+The archive can be merged into another database with "zockimate import".
+Mount archives are not included (copy the archive directory), and ZFS snapshot
+references only stay valid where the pools are.
+
+Examples:
+  # Export all history
+  zockimate export > history.tar
+
+  # Export two containers with their images
+  zockimate export --images -o nginx.tar nginx remote/redis`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var w io.Writer = os.Stdout
+			var file *os.File
+			if output == "" || output == "-" {
+				if term.IsTerminal(os.Stdout.Fd()) {
+					return fmt.Errorf("refusing to write the archive to a terminal, use -o or redirect the output")
+				}
+			} else {
+				f, err := os.Create(output)
+				if err != nil {
+					return fmt.Errorf("failed to create %s: %w", output, err)
+				}
+				defer f.Close()
+				file, w = f, f
+			}
+
+			m, err := manager.NewContainerManager(cfg)
+			if err != nil {
+				return err
+			}
+			defer m.Close()
+
+			opts.Containers = args
+			result, err := m.ExportHistory(context.Background(), w, opts)
+			if err != nil {
+				if file != nil {
+					os.Remove(output)
+				}
+				return err
+			}
+			if file != nil {
+				if err := file.Close(); err != nil {
+					return fmt.Errorf("failed to write %s: %w", output, err)
+				}
+			}
+
+			if cfg.JSON {
+				if err := json.NewEncoder(os.Stderr).Encode(result); err != nil {
+					return fmt.Errorf("failed to encode JSON: %v", err)
+				}
+				return nil
+			}
+			cfg.Logger.Infof("✓ Exported %d snapshot(s), %d operation(s), %d configuration(s), %d image(s)",
+				result.Snapshots, result.Operations, result.Blobs, result.Images)
+			for _, id := range result.MissingImages {
+				cfg.Logger.Warnf("  image %s not found on its host, not included", id)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Archive file (default: standard output)")
+	cmd.Flags().BoolVar(&opts.Images, "images", false, "Include the referenced images (docker save)")
+	cmd.Flags().BoolVarP(&cfg.JSON, "json", "j", false,
+		"Print the result as JSON on standard error")
+
+	return cmd
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"zockimate/internal/config"
+	"zockimate/internal/hosts"
+	"zockimate/internal/manager"
+	"zockimate/internal/types/options"
+	"zockimate/pkg/utils"
+)
+
+func newFsckCmd(cfg *config.Config) *cobra.Command {
+	var opts options.FsckOptions
+	var jsonOutput bool
+
+	cmd := &cobra.Command{
+		Use:   "fsck",
+		Short: "Check the database against ZFS snapshots",
+		Long: `Cross-check the snapshot_* ZFS snapshots of the managed datasets against
+the database, on every host with ZFS access.
+
+Reports orphan ZFS snapshots (no database entry) and database entries whose
+ZFS snapshots no longer exist. With --fix, orphans are destroyed and broken
+entries are marked as data-less (image and config can still be restored).
+Run it while no update or snapshot is in progress.
+
+Examples:
+  # Report inconsistencies
+  zockimate fsck
+
+  # Show what --fix would do
+  zockimate fsck --fix --dry-run
+
+  # Repair
+  zockimate fsck --fix`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			m, err := manager.NewContainerManager(cfg)
+			if err != nil {
+				return err
+			}
+			defer m.Close()
+
+			results, err := m.Fsck(context.Background(), opts)
+			if err != nil {
+				return err
+			}
+
+			if jsonOutput {
+				if err := json.NewEncoder(os.Stdout).Encode(results); err != nil {
+					return fmt.Errorf("failed to encode JSON: %v", err)
+				}
+				return nil
+			}
+
+			if len(results) == 0 {
+				cfg.Logger.Info("No host with ZFS access")
+				return nil
+			}
+
+			var failed, issues int
+			for _, r := range results {
+				cfg.Logger.Infof("Host %s: %d dataset(s) checked", r.Host, len(r.Datasets))
+				if r.Error != "" {
+					failed++
+					cfg.Logger.Errorf("✗ %s: %s", r.Host, r.Error)
+				}
+
+				for _, e := range r.BrokenEntries {
+					issues++
+					action := "missing"
+					if opts.Fix {
+						action = "would mark as data-less"
+						if !opts.DryRun {
+							action = "marked as data-less"
+						}
+					}
+					cfg.Logger.Warnf("  Entry %d (%s): ZFS snapshot %s %s", e.SnapshotID,
+						utils.ContainerID(r.Host, e.ContainerName, hosts.DefaultHost),
+						strings.Join(e.Missing, ", "), action)
+				}
+				for _, e := range r.ReplicaEntries {
+					cfg.Logger.Infof("  Entry %d (%s): ZFS snapshot %s missing, kept: available on the replica",
+						e.SnapshotID, utils.ContainerID(r.Host, e.ContainerName, hosts.DefaultHost),
+						strings.Join(e.Missing, ", "))
+				}
+				for _, s := range r.OrphanSnapshots {
+					issues++
+					action := "orphan"
+					if opts.Fix {
+						action = "orphan, would delete"
+						if !opts.DryRun {
+							action = "orphan, deleted"
+						}
+					}
+					cfg.Logger.Warnf("  %s: %s", s, action)
+				}
+			}
+
+			if issues == 0 && failed == 0 {
+				cfg.Logger.Info("✓ Database and ZFS snapshots are consistent")
+			} else if issues > 0 && !opts.Fix {
+				cfg.Logger.Infof("%d issue(s) found, run with --fix to repair", issues)
+			}
+
+			if failed > 0 {
+				return fmt.Errorf("fsck failed on %d host(s)", failed)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.Fix, "fix", false,
+		"Delete orphan snapshots and mark broken entries as data-less")
+	cmd.Flags().BoolVarP(&opts.DryRun, "dry-run", "n", false,
+		"Show what --fix would do without taking action")
+	cmd.Flags().BoolVarP(&jsonOutput, "json", "j", false, "Output in JSON format")
+
+	return cmd
+}
@@ -9,11 +9,16 @@ import (
 	"github.com/spf13/cobra"
 
 	"zockimate/internal/config"
+	"zockimate/internal/hosts"
 	"zockimate/internal/manager"
+	"zockimate/internal/types"
 	"zockimate/internal/types/options"
+	"zockimate/pkg/utils"
 )
 
 func newHistoryCmd(cfg *config.Config) *cobra.Command {
+	var timeline bool
+
 	cmd := &cobra.Command{
 		Use:   "history [container...]",
 		Short: "Show container history",
@@ -33,6 +38,9 @@ Examples:
   # Show only last entry for each container
   zockimate history -L
 
+  # Show updates and rollbacks along with snapshots
+  zockimate history --timeline wireguard
+
   # Show as JSON
   zockimate history -j`,
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -49,6 +57,7 @@ Examples:
 				SortBy:    cfg.SortBy,
 				JSON:      cfg.JSON,
 				Search:    cfg.Search,
+				Timeline:  timeline,
 			}
 
 			if cfg.Since != "" {
@@ -67,6 +76,10 @@ Examples:
 				}
 			}
 
+			if opts.Timeline {
+				return printTimeline(cfg, m, opts)
+			}
+
 			history, err := m.GetHistory(opts)
 			if err != nil {
 				return err
@@ -85,24 +98,8 @@ Examples:
 			}
 
 			// Affichage formaté
-			for _, entry := range history {
-				cfg.Logger.Infof("[%s] %s (ID: %d)",
-					entry.CreatedAt.Format("2006-01-02 15:04:05"),
-					entry.ContainerName,
-					entry.ID,
-				)
-				cfg.Logger.Infof("  Status: %s", entry.Status)
-				if entry.RepoDigest != "" {
-					cfg.Logger.Infof("  Image: %s", entry.RepoDigest)
-				} else {
-					cfg.Logger.Infof("  Image: %s (%s)",
-						entry.ImageTag,
-						entry.ImageID[:12],
-					)
-				}
-				if entry.Message != "" {
-					cfg.Logger.Infof("  Message: %s", entry.Message)
-				}
+			for i := range history {
+				printSnapshot(cfg, &history[i])
 				cfg.Logger.Info("")
 			}
 
@@ -119,11 +116,96 @@ Examples:
 	cmd.Flags().BoolVarP(&cfg.JSON, "json", "j", false,
 		"Output in JSON format")
 	cmd.Flags().StringVarP(&cfg.Search, "search", "q", "",
-		"Search in messages and status")
+		"Search in messages (and operation errors with --timeline)")
 	cmd.Flags().StringVarP(&cfg.Since, "since", "S", "",
 		"Show entries since date (YYYY-MM-DD)")
 	cmd.Flags().StringVarP(&cfg.Before, "before", "b", "",
 		"Show entries before date (YYYY-MM-DD)")
+	cmd.Flags().BoolVarP(&timeline, "timeline", "T", false,
+		"Show updates and rollbacks interleaved with snapshots")
 
 	return cmd
 }
+
+// printTimeline affiche la chronologie des snapshots et des opérations
+func printTimeline(cfg *config.Config, m *manager.ContainerManager, opts options.HistoryOptions) error {
+	entries, err := m.GetTimeline(opts)
+	if err != nil {
+		return err
+	}
+
+	if len(entries) == 0 {
+		cfg.Logger.Info("No history found")
+		return nil
+	}
+
+	if cfg.JSON {
+		if err := json.NewEncoder(os.Stdout).Encode(entries); err != nil {
+			return fmt.Errorf("failed to encode JSON: %v", err)
+		}
+		return nil
+	}
+
+	for _, entry := range entries {
+		if entry.Operation != nil {
+			printOperation(cfg, entry.Operation)
+		} else {
+			printSnapshot(cfg, entry.Snapshot)
+		}
+		cfg.Logger.Info("")
+	}
+	return nil
+}
+
+// printSnapshot affiche une entrée de l'historique
+func printSnapshot(cfg *config.Config, entry *types.SnapshotMetadata) {
+	cfg.Logger.Infof("[%s] %s (ID: %d)",
+		entry.CreatedAt.Format("2006-01-02 15:04:05"),
+		utils.ContainerID(entry.Host, entry.ContainerName, hosts.DefaultHost),
+		entry.ID,
+	)
+	if entry.RepoDigest != "" {
+		cfg.Logger.Infof("  Image: %s", entry.RepoDigest)
+	} else {
+		cfg.Logger.Infof("  Image: %s (%s)",
+			entry.ImageTag,
+			utils.ShortenID(entry.ImageID),
+		)
+	}
+	if entry.Message != "" {
+		cfg.Logger.Infof("  Message: %s", entry.Message)
+	}
+	if entry.SnapshotMode != "" && entry.SnapshotMode != "live" {
+		cfg.Logger.Infof("  Consistency: %s (frozen %s)",
+			entry.SnapshotMode, entry.FreezeDuration.Round(time.Millisecond))
+	}
+	if entry.VulnDelta != nil {
+		cfg.Logger.Infof("  Vulnerabilities: %s", entry.VulnDelta.String())
+	}
+}
+
+// printOperation affiche une mise à jour ou un rollback de la chronologie
+func printOperation(cfg *config.Config, op *types.Operation) {
+	cfg.Logger.Infof("[%s] %s %s (%s, %s)",
+		op.StartedAt.Format("2006-01-02 15:04:05"),
+		utils.ContainerID(op.Host, op.ContainerName, hosts.DefaultHost),
+		op.Type,
+		op.Outcome,
+		op.TriggeredBy,
+	)
+	if op.FromImage != "" || op.ToImage != "" {
+		cfg.Logger.Infof("  Image: %s -> %s", utils.ShortenID(op.FromImage), utils.ShortenID(op.ToImage))
+	}
+	if op.TargetSnapshotID > 0 {
+		cfg.Logger.Infof("  Restored snapshot: %d", op.TargetSnapshotID)
+	}
+	if op.SnapshotID > 0 {
+		cfg.Logger.Infof("  Safety snapshot: %d", op.SnapshotID)
+	}
+	if op.FinishedAt != nil {
+		cfg.Logger.Infof("  Duration: %s", op.Duration().Round(time.Second))
+	}
+	if op.Error != "" {
+		cfg.Logger.Infof("  Error: %s", op.Error)
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"zockimate/internal/config"
+	"zockimate/internal/manager"
+	"zockimate/internal/types/options"
+)
+
+func newImportCmd(cfg *config.Config) *cobra.Command {
+	var opts options.ImportOptions
+
+	cmd := &cobra.Command{
+		Use:   "import [file|-]",
+		Short: "Import snapshot history from an export archive",
+		Long: `Merge the history of an archive written by "zockimate export" into the
+database. Entries get new identifiers; operations and vulnerability deltas
+follow them. An entry of the same container and date, or an operation of the
+same container, type and start, is already present and skipped, so importing
+an archive twice is harmless.
+
+Images included in the archive are loaded on the host of their entries when
+that host is configured. The archive is read from standard input by default.
+
+Examples:
+  # Preview the import
+  zockimate import --dry-run history.tar
+
+  # Move a host's history to a new machine
+  ssh old zockimate export | zockimate import --host local`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var r io.Reader = os.Stdin
+			if len(args) == 1 && args[0] != "-" {
+				f, err := os.Open(args[0])
+				if err != nil {
+					return fmt.Errorf("failed to open %s: %w", args[0], err)
+				}
+				defer f.Close()
+				r = f
+			}
+
+			m, err := manager.NewContainerManager(cfg)
+			if err != nil {
+				return err
+			}
+			defer m.Close()
+
+			result, err := m.ImportHistory(context.Background(), r, opts)
+			if err != nil {
+				return err
+			}
+
+			if cfg.JSON {
+				if err := json.NewEncoder(os.Stdout).Encode(result); err != nil {
+					return fmt.Errorf("failed to encode JSON: %v", err)
+				}
+				return nil
+			}
+			if opts.DryRun {
+				cfg.Logger.Infof("%d snapshot(s) to import, %d already present; %d of %d operation(s) to import",
+					result.Imported, result.Existing, result.OperationsImported, result.Operations)
+				return nil
+			}
+			cfg.Logger.Infof("✓ %d snapshot(s) imported, %d already present; %d of %d operation(s) imported; %d image(s) loaded",
+				result.Imported, result.Existing, result.OperationsImported, result.Operations, result.Images)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Host, "host", "", "Attach the imported entries to this host")
+	cmd.Flags().BoolVar(&opts.SkipImages, "skip-images", false, "Don't load the images of the archive")
+	cmd.Flags().BoolVarP(&opts.DryRun, "dry-run", "n", false,
+		"Show what would be imported without taking action")
+	cmd.Flags().BoolVarP(&cfg.JSON, "json", "j", false, "Output in JSON format")
+
+	return cmd
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"zockimate/internal/config"
+	"zockimate/internal/manager"
+)
+
+func newInspectCmd(cfg *config.Config) *cobra.Command {
+	var jsonOutput bool
+
+	cmd := &cobra.Command{
+		Use:   "inspect container",
+		Short: "Show how a container's data is snapshotted",
+		Long: `Show the mounts of a container, the ZFS dataset behind each of them
+and the datasets included in its snapshots.
+Use it to find the value of the zockimate.zfs_dataset label, or set the
+label to "auto" to snapshot the discovered datasets.
+
+Examples:
+  # Show the discovered datasets
+  zockimate inspect nextcloud
+
+  # Show as JSON
+  zockimate inspect -j nextcloud`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			m, err := manager.NewContainerManager(cfg)
+			if err != nil {
+				return err
+			}
+			defer m.Close()
+
+			result, err := m.InspectContainer(context.Background(), args[0])
+			if err != nil {
+				return err
+			}
+
+			if jsonOutput {
+				if err := json.NewEncoder(os.Stdout).Encode(result); err != nil {
+					return fmt.Errorf("failed to encode JSON: %v", err)
+				}
+				return nil
+			}
+
+			state := "stopped"
+			if result.Running {
+				state = "running"
+			}
+			cfg.Logger.Infof("%s (%s, %s)", result.ContainerName, result.Image, state)
+			if !result.Enabled {
+				cfg.Logger.Info("  Not enabled for management (zockimate.enable)")
+			}
+
+			cfg.Logger.Info("  Mounts:")
+			if len(result.Mounts) == 0 {
+				cfg.Logger.Info("    none")
+			}
+			for _, mount := range result.Mounts {
+				dataset := mount.Dataset
+				if dataset == "" {
+					dataset = "not on ZFS"
+				}
+				var flags []string
+				if !mount.RW {
+					flags = append(flags, "ro")
+				}
+				if mount.Backup {
+					flags = append(flags, "archived")
+				}
+				line := fmt.Sprintf("    %s %s -> %s: %s", mount.Type, mount.Source, mount.Destination, dataset)
+				if len(flags) > 0 {
+					line += " (" + strings.Join(flags, ", ") + ")"
+				}
+				cfg.Logger.Info(line)
+			}
+
+			if result.ZFSError != "" {
+				cfg.Logger.Warnf("  ZFS: %s", result.ZFSError)
+				return nil
+			}
+
+			datasets := strings.Join(result.Datasets, ", ")
+			if datasets == "" {
+				datasets = "none"
+			}
+			recursive := ""
+			if result.Recursive {
+				recursive = " (recursive)"
+			}
+			if result.DatasetLabel == "" {
+				cfg.Logger.Infof("  No zockimate.zfs_dataset label, discovered datasets: %s", datasets)
+				if len(result.Datasets) > 0 {
+					cfg.Logger.Info("  Set zockimate.zfs_dataset=auto to snapshot them")
+				}
+			} else {
+				cfg.Logger.Infof("  Snapshotted datasets%s: %s", recursive, datasets)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVarP(&jsonOutput, "json", "j", false, "Output in JSON format")
+
+	return cmd
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"os"
+	_ "time/tzdata" // Fuseaux horaires des fenêtres de maintenance (image sans tzdata)
 
 	"github.com/spf13/cobra"
 
@@ -20,10 +21,22 @@ Enables automatic updates, rollbacks, and monitoring of your Docker containers.
 
 Environment variables:
   ZOCKIMATE_LOG_LEVEL   : Logging level (debug, info, warn, error)
-  ZOCKIMATE_DB         : Database path
+  ZOCKIMATE_DB         : Database path (SQLite) or postgres:// URL
   ZOCKIMATE_APPRISE_URL: Apprise URL for notifications
   ZOCKIMATE_RETENTION  : Number of snapshots to retain
-  ZOCKIMATE_TIMEOUT    : Default operation timeout in seconds`,
+  ZOCKIMATE_TIMEOUT    : Default operation timeout in seconds
+  ZOCKIMATE_WINDOW     : Default maintenance window (e.g. "Sat 02:00-05:00 Europe/Paris")
+  ZOCKIMATE_BLACKOUT   : Blackout dates (e.g. "2024-12-24,2024-12-30..2025-01-02")
+  ZOCKIMATE_DOCKER_CONFIG   : Docker client config for registry credentials
+  ZOCKIMATE_REGISTRY_SECRETS: Per-registry credentials file (JSON)
+  ZOCKIMATE_PULL_RETRIES    : Retries on transient image pull errors
+  ZOCKIMATE_HOSTS           : Hosts file (JSON) to manage several Docker hosts
+  ZOCKIMATE_ARCHIVE_DIR     : Directory of volume backup archives
+  ZOCKIMATE_HELPER_IMAGE    : Image of the volume backup helper containers
+  ZOCKIMATE_ZFS_REPLICA     : Parent dataset receiving replicated ZFS snapshots
+  ZOCKIMATE_ZFS_REPLICA_SSH : SSH target of the replica pool (user@host[:port])
+  ZOCKIMATE_ZFS_BACKEND     : ZFS access: auto, native (/dev/zfs ioctls) or cli
+  ZOCKIMATE_ZFS_TIMEOUT     : Timeout of zfs commands in seconds`,
 		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 			if err := cfg.LoadFromEnv(); err != nil {
 				return err
@@ -36,7 +49,7 @@ Environment variables:
 	rootCmd.PersistentFlags().StringVarP(&cfg.LogLevel, "log-level", "l",
 		config.DefaultLogLevel, "Log level")
 	rootCmd.PersistentFlags().StringVarP(&cfg.DbPath, "db", "D",
-		config.DefaultDbPath, "Database path")
+		config.DefaultDbPath, "Database path (SQLite) or postgres:// URL")
 	rootCmd.PersistentFlags().StringVarP(&cfg.AppriseURL, "apprise-url", "a",
 		"", "Apprise URL for notifications")
 	rootCmd.PersistentFlags().BoolVarP(&cfg.All, "all", "A",
@@ -47,17 +60,46 @@ Environment variables:
 		config.DefaultRetention, "Number of snapshots to retain")
 	rootCmd.PersistentFlags().IntVar(&cfg.Timeout, "timeout",
 		config.DefaultTimeout, "Operation timeout in seconds")
+	rootCmd.PersistentFlags().StringVar(&cfg.HostsFile, "hosts",
+		"", "Hosts file (JSON) listing the Docker hosts to manage")
+	rootCmd.PersistentFlags().IntVar(&cfg.PullRetries, "pull-retries",
+		config.DefaultPullRetries, "Retries on transient image pull errors")
+	rootCmd.PersistentFlags().StringVar(&cfg.PullBandwidth, "pull-bandwidth",
+		"", "Maximum image download rate per host, per second (e.g. 10MB; default: unlimited)")
+	rootCmd.PersistentFlags().StringVar(&cfg.ArchiveDir, "archive-dir",
+		"", "Directory of volume backup archives (default: next to the database)")
+	rootCmd.PersistentFlags().StringVar(&cfg.HelperImage, "helper-image",
+		config.DefaultHelperImage, "Image of the volume backup helper containers")
+	rootCmd.PersistentFlags().StringVar(&cfg.ZFSReplica, "zfs-replica",
+		"", "Parent dataset receiving replicated ZFS snapshots of the local host")
+	rootCmd.PersistentFlags().StringVar(&cfg.ZFSReplicaSSH, "zfs-replica-ssh",
+		"", "SSH target of the replica pool (default: same machine as zfs)")
+	rootCmd.PersistentFlags().StringVar(&cfg.ZFSBackend, "zfs-backend",
+		config.DefaultZFSBackend, "ZFS access: auto, native (/dev/zfs ioctls, local host) or cli (zfs command)")
+	rootCmd.PersistentFlags().IntVar(&cfg.ZFSTimeout, "zfs-timeout",
+		config.DefaultZFSTimeout, "Timeout of zfs commands in seconds")
+	rootCmd.PersistentFlags().StringVar(&cfg.Window, "window",
+		"", "Default maintenance window for containers without zockimate.window label")
+	rootCmd.PersistentFlags().StringVar(&cfg.Blackout, "blackout",
+		"", "Blackout dates during which no update is applied (YYYY-MM-DD[..YYYY-MM-DD],...)")
 
 	// Sous-commandes
 	rootCmd.AddCommand(
 		newUpdateCmd(cfg),
 		newCheckCmd(cfg),
 		newRollbackCmd(cfg),
+		newRestoreCmd(cfg),
+		newCloneCmd(cfg),
 		newHistoryCmd(cfg),
 		newScheduleCmd(cfg),
 		newSaveCmd(cfg),
 		newRenameCmd(cfg),
+		newInspectCmd(cfg),
 		newRemoveCmd(cfg),
+		newFsckCmd(cfg),
+		newDbCmd(cfg),
+		newExportCmd(cfg),
+		newImportCmd(cfg),
 	)
 
 	if err := rootCmd.Execute(); err != nil {
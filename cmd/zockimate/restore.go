@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"zockimate/internal/config"
+	"zockimate/internal/manager"
+	"zockimate/internal/types"
+	"zockimate/internal/types/options"
+)
+
+func newRestoreCmd(cfg *config.Config) *cobra.Command {
+	var opts = options.RestoreOptions{
+		Timeout: options.DefaultRollbackTimeout,
+		Trigger: types.TriggerCLI,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "restore container-name [snapshot-id]",
+		Short: "Recreate a deleted container from a snapshot",
+		Long: `Recreate a container that no longer exists from its configuration stored in
+the database. If no snapshot ID is specified, uses the most recent snapshot.
+
+The image is reused if present on the host, otherwise pulled. User-defined
+networks that were removed with the container are recreated as bridge
+networks. With --data, the ZFS snapshots and mount archives of the snapshot
+are restored before the container starts. The container must become ready,
+otherwise it is removed again.
+
+Examples:
+  # Recreate a removed container from its last snapshot
+  zockimate restore nextcloud
+
+  # Recreate it with its data from a given snapshot
+  zockimate restore nextcloud 42 --data
+
+  # Recreate it under another name, next to the original
+  zockimate restore nextcloud --as nextcloud-old`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			m, err := manager.NewContainerManager(cfg)
+			if err != nil {
+				return err
+			}
+			defer m.Close()
+
+			name := args[0]
+			if len(args) > 1 {
+				if id, err := strconv.ParseInt(args[1], 10, 64); err == nil {
+					opts.SnapshotID = id
+				} else {
+					return fmt.Errorf("invalid snapshot ID: %v", err)
+				}
+			}
+
+			result, err := m.RestoreContainer(context.Background(), name, opts)
+			if err != nil {
+				return err
+			}
+			if !result.Success {
+				return fmt.Errorf("restore failed: %v", result.Error)
+			}
+
+			cfg.Logger.Infof("✓ %s restored from snapshot %d (image %s)",
+				result.NewName, result.SnapshotID, result.Image)
+			if len(result.NetworksCreated) > 0 {
+				cfg.Logger.Infof("  networks created: %s", strings.Join(result.NetworksCreated, ", "))
+			}
+			if result.DataRestored {
+				cfg.Logger.Info("  data restored")
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.As, "as", "", "Name of the recreated container (default: original name)")
+	cmd.Flags().BoolVarP(&opts.Data, "data", "d", false, "Restore data (ZFS snapshot and mount archives)")
+	cmd.Flags().BoolVarP(&opts.Force, "force", "f", false,
+		"Force restore even if exact image version cannot be guaranteed")
+	cmd.Flags().BoolVar(&opts.DestroyNewer, "destroy-newer", false,
+		"Restore data with zfs rollback -r, destroying newer ZFS snapshots")
+
+	return cmd
+}
@@ -9,6 +9,7 @@ import (
 
 	"zockimate/internal/config"
 	"zockimate/internal/manager"
+	"zockimate/internal/types"
 	"zockimate/internal/types/options"
 )
 
@@ -20,6 +21,7 @@ func newRollbackCmd(cfg *config.Config) *cobra.Command {
 		Config:  false,
 		Force:   false,
 		Timeout: options.DefaultRollbackTimeout,
+		Trigger: types.TriggerCLI,
 	}
 
 	cmd := &cobra.Command{
@@ -40,7 +42,10 @@ Examples:
   zockimate rollback wireguard -c
 
   # Force rollback when exact image version cannot be guaranteed
-  zockimate rollback wireguard -i -f`,
+  zockimate rollback wireguard -i -f
+
+  # Restore data in place, destroying newer ZFS snapshots
+  zockimate rollback wireguard 123 -d --destroy-newer`,
 		Args: cobra.RangeArgs(1, 2),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if !opts.Image && !opts.Data && !opts.Config {
@@ -74,10 +79,12 @@ Examples:
 	}
 
 	cmd.Flags().BoolVarP(&opts.Image, "image", "i", false, "Rollback image")
-	cmd.Flags().BoolVarP(&opts.Data, "data", "d", false, "Rollback data (ZFS snapshot)")
+	cmd.Flags().BoolVarP(&opts.Data, "data", "d", false, "Rollback data (ZFS snapshot and mount archives)")
 	cmd.Flags().BoolVarP(&opts.Config, "config", "c", false, "Rollback configuration")
 	cmd.Flags().BoolVarP(&opts.Force, "force", "f", false,
 		"Force rollback even if exact image version cannot be guaranteed")
+	cmd.Flags().BoolVar(&opts.DestroyNewer, "destroy-newer", false,
+		"Restore data with zfs rollback -r, destroying newer ZFS snapshots")
 
 	return cmd
 }
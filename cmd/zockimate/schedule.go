@@ -63,6 +63,8 @@ func newScheduleUpdateCmd(cfg *config.Config) *cobra.Command {
 		"Force update even if no new image available")
 	cmd.Flags().BoolVarP(&opts.DryRun, "dry-run", "n", false,
 		"Show what would be updated without making changes")
+	cmd.Flags().BoolVar(&opts.IgnoreWindow, "ignore-window", false,
+		"Apply updates even outside maintenance windows and blackout periods")
 
 	return cmd
 }
@@ -14,7 +14,7 @@ import (
 )
 
 func newUpdateCmd(cfg *config.Config) *cobra.Command {
-	var opts = options.NewUpdateOptions()
+	var opts = options.NewUpdateOptions(options.WithUpdateTrigger(types.TriggerCLI))
 
 	cmd := &cobra.Command{
 		Use:   "update [container...]",
@@ -36,7 +36,10 @@ Examples:
   zockimate update -f wireguard
 
   # Dry run to see what would be updated
-  zockimate update -n`,
+  zockimate update -n
+
+  # Update now, outside of maintenance windows and blackout periods
+  zockimate update --ignore-window wireguard`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			m, err := manager.NewContainerManager(cfg)
 			if err != nil {
@@ -69,7 +72,7 @@ Examples:
 				results = append(results, result)
 			}
 
-			var updated, skipped, failed int
+			var updated, skipped, deferred, failed int
 			var errors []string
 			var updateDetails []string
 			var failureDetails []string
@@ -79,6 +82,9 @@ Examples:
 					updated++
 					updateMsg := fmt.Sprintf("%s: %s → %s",
 						r.ContainerName, r.OldImage.String(), r.NewImage.String())
+					if r.VulnDelta != nil {
+						updateMsg += fmt.Sprintf(" [vulnerabilities: %s]", r.VulnDelta.String())
+					}
 					cfg.Logger.Infof("✓ %s", updateMsg)
 					updateDetails = append(updateDetails, updateMsg)
 				} else if r.Error != nil {
@@ -87,26 +93,34 @@ Examples:
 					cfg.Logger.Errorf("✗ %s", errMsg)
 					errors = append(errors, errMsg)
 					failureDetails = append(failureDetails, errMsg)
+				} else if r.Deferred {
+					deferred++
+					cfg.Logger.Infof("⏸ %s: deferred (%s)", r.ContainerName, r.DeferReason)
 				} else if !r.NeedsUpdate {
 					skipped++
 					cfg.Logger.Infof("- %s: no update needed", r.ContainerName)
 				}
 			}
 
-			summaryMsg := fmt.Sprintf("Summary: %d updated, %d skipped, %d failed",
-				updated, skipped, failed)
+			summaryMsg := fmt.Sprintf("Summary: %d updated, %d skipped, %d deferred, %d failed",
+				updated, skipped, deferred, failed)
 			cfg.Logger.Info(summaryMsg)
 
 			// Pour la commande update
 			if opts.Notify && !opts.DryRun && cfg.AppriseURL != "" {
 				var updatedContainers []string
 				var failedContainers []string
+				var deferredContainers []string
 
 				for _, r := range results {
-					if r.Success {
+					if r.Success && r.Security {
+						updatedContainers = append(updatedContainers, r.ContainerName+" (security)")
+					} else if r.Success {
 						updatedContainers = append(updatedContainers, r.ContainerName)
 					} else if r.Error != nil {
 						failedContainers = append(failedContainers, r.ContainerName)
+					} else if r.Deferred {
+						deferredContainers = append(deferredContainers, r.ContainerName)
 					}
 				}
 
@@ -119,8 +133,11 @@ Examples:
 				if len(failedContainers) > 0 {
 					parts = append(parts, fmt.Sprintf("Failed: %s", strings.Join(failedContainers, ", ")))
 				}
-				notifMsg := fmt.Sprintf("%d updated, %d skipped, %d failed.",
-					updated, skipped, failed) + "\nUpdated: " + strings.Join(parts, "\n")
+				if len(deferredContainers) > 0 {
+					parts = append(parts, fmt.Sprintf("Deferred: %s", strings.Join(deferredContainers, ", ")))
+				}
+				notifMsg := fmt.Sprintf("%d updated, %d skipped, %d deferred, %d failed.",
+					updated, skipped, deferred, failed) + "\nUpdated: " + strings.Join(parts, "\n")
 
 				if err := m.SendNotification(notifTitle, notifMsg); err != nil {
 					cfg.Logger.Warnf("Failed to send notification: %v", err)
@@ -141,6 +158,8 @@ Examples:
 		"Force update even if no new image available")
 	cmd.Flags().BoolVarP(&opts.DryRun, "dry-run", "n", false,
 		"Show what would be updated without making changes")
+	cmd.Flags().BoolVar(&opts.IgnoreWindow, "ignore-window", false,
+		"Apply updates even outside maintenance windows and blackout periods")
 
 	return cmd
 }
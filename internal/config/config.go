@@ -5,9 +5,13 @@ import (
     "fmt"
     "os"
     "strconv"
+    "strings"
     "time"
 
+    "github.com/docker/go-units"
     "github.com/sirupsen/logrus"
+
+    "zockimate/internal/maintenance"
 )
 
 const (
@@ -17,6 +21,10 @@ const (
     DefaultTimeout    = 180
     DefaultRetention  = 10
     DefaultSortBy     = "date"
+    DefaultPullRetries = 3
+    DefaultHelperImage = "busybox:stable"
+    DefaultZFSBackend  = "auto"
+    DefaultZFSTimeout  = 300
 
     // Environment variables
     EnvPrefix         = "ZOCKIMATE_"
@@ -25,13 +33,29 @@ const (
     EnvAppriseURL     = EnvPrefix + "APPRISE_URL"
     EnvRetention      = EnvPrefix + "RETENTION"
     EnvTimeout        = EnvPrefix + "TIMEOUT"
+    EnvWindow         = EnvPrefix + "WINDOW"
+    EnvBlackout       = EnvPrefix + "BLACKOUT"
+    EnvInsecureRegistries = EnvPrefix + "INSECURE_REGISTRIES"
+    EnvDockerConfig   = EnvPrefix + "DOCKER_CONFIG"
+    EnvRegistrySecrets = EnvPrefix + "REGISTRY_SECRETS"
+    EnvPullRetries    = EnvPrefix + "PULL_RETRIES"
+    EnvPullBandwidth  = EnvPrefix + "PULL_BANDWIDTH"
+    EnvHosts          = EnvPrefix + "HOSTS"
+    EnvArchiveDir     = EnvPrefix + "ARCHIVE_DIR"
+    EnvHelperImage    = EnvPrefix + "HELPER_IMAGE"
+    EnvZFSReplica     = EnvPrefix + "ZFS_REPLICA"
+    EnvZFSReplicaSSH  = EnvPrefix + "ZFS_REPLICA_SSH"
+    EnvZFSBackend     = EnvPrefix + "ZFS_BACKEND"
+    EnvZFSTimeout     = EnvPrefix + "ZFS_TIMEOUT"
+    EnvScanner        = EnvPrefix + "SCANNER"
+    EnvScanDir        = EnvPrefix + "SCAN_DIR"
 )
 
 // Config représente la configuration globale de l'application
 type Config struct {
     // Paramètres généraux
     LogLevel    string
-    DbPath      string
+    DbPath      string  // Chemin de la base SQLite ou URL postgres://
     AppriseURL  string
     
     // Filtres et comportement
@@ -53,6 +77,36 @@ type Config struct {
     Retention   int     // Nombre de snapshots à conserver
     Timeout     int     // Timeout global en secondes
 
+    // Maintenance
+    Window      string  // Fenêtre de maintenance par défaut (sans label zockimate.window)
+    Blackout    string  // Dates de gel des mises à jour (YYYY-MM-DD[..YYYY-MM-DD],...)
+
+    // Hôtes Docker
+    HostsFile   string  // Fichier JSON des hôtes gérés (vide : hôte local uniquement)
+
+    // Sauvegarde des volumes
+    ArchiveDir  string  // Répertoire des archives de volumes (défaut : à côté de la base)
+    HelperImage string  // Image des conteneurs auxiliaires de sauvegarde/restauration
+
+    // Réplication ZFS de l'hôte local (les autres hôtes la configurent dans le fichier d'hôtes)
+    ZFSReplica    string // Dataset parent recevant les snapshots répliqués
+    ZFSReplicaSSH string // Cible SSH du pool de réplication (user@host[:port])
+
+    // Accès à ZFS
+    ZFSBackend  string  // auto, native (ioctls de /dev/zfs) ou cli (commande zfs)
+    ZFSTimeout  int     // Durée maximale d'une commande zfs en secondes
+
+    // Registres
+    InsecureRegistries string // Registres joints en HTTP (séparés par des virgules)
+    DockerConfig       string // Fichier config.json du client Docker (identifiants)
+    RegistrySecrets    string // Fichier JSON d'identifiants par registre
+    PullRetries        int    // Tentatives supplémentaires en cas d'erreur transitoire du pull
+    PullBandwidth      string // Débit maximal des pulls par hôte (ex: 10MB, par seconde)
+
+    // Analyse de vulnérabilités
+    Scanner     string  // Binaire du scanner (trivy ou grype)
+    ScanDir     string  // Répertoire de rapports JSON pré-générés
+
     // Logger configuré
     Logger     *logrus.Logger
 }
@@ -65,6 +119,10 @@ func NewConfig() *Config {
         Retention:  DefaultRetention,
         Timeout:    DefaultTimeout,
         SortBy:     DefaultSortBy,
+        PullRetries: DefaultPullRetries,
+        HelperImage: DefaultHelperImage,
+        ZFSBackend:  DefaultZFSBackend,
+        ZFSTimeout:  DefaultZFSTimeout,
         Logger:     newLogger(DefaultLogLevel),
     }
 }
@@ -78,7 +136,7 @@ func (c *Config) LoadFromEnv() error {
         }
     }
 
-    // Database path
+    // Database path or URL
     if path := os.Getenv(EnvDbPath); path != "" {
         c.DbPath = path
     }
@@ -106,6 +164,82 @@ func (c *Config) LoadFromEnv() error {
         c.Timeout = t
     }
 
+    // Tentatives de pull
+    if retries := os.Getenv(EnvPullRetries); retries != "" {
+        r, err := strconv.Atoi(retries)
+        if err != nil {
+            return fmt.Errorf("invalid pull retries value: %w", err)
+        }
+        c.PullRetries = r
+    }
+    if bandwidth := os.Getenv(EnvPullBandwidth); bandwidth != "" {
+        c.PullBandwidth = bandwidth
+    }
+
+    // Fenêtre de maintenance par défaut
+    if window := os.Getenv(EnvWindow); window != "" {
+        c.Window = window
+    }
+
+    // Périodes de gel
+    if blackout := os.Getenv(EnvBlackout); blackout != "" {
+        c.Blackout = blackout
+    }
+
+    // Registres sans TLS
+    if insecure := os.Getenv(EnvInsecureRegistries); insecure != "" {
+        c.InsecureRegistries = insecure
+    }
+
+    // Hôtes Docker gérés
+    if path := os.Getenv(EnvHosts); path != "" {
+        c.HostsFile = path
+    }
+
+    // Sauvegarde des volumes
+    if dir := os.Getenv(EnvArchiveDir); dir != "" {
+        c.ArchiveDir = dir
+    }
+    if image := os.Getenv(EnvHelperImage); image != "" {
+        c.HelperImage = image
+    }
+
+    // Réplication ZFS
+    if replica := os.Getenv(EnvZFSReplica); replica != "" {
+        c.ZFSReplica = replica
+    }
+    if target := os.Getenv(EnvZFSReplicaSSH); target != "" {
+        c.ZFSReplicaSSH = target
+    }
+
+    // Accès à ZFS
+    if backend := os.Getenv(EnvZFSBackend); backend != "" {
+        c.ZFSBackend = backend
+    }
+    if timeout := os.Getenv(EnvZFSTimeout); timeout != "" {
+        t, err := strconv.Atoi(timeout)
+        if err != nil {
+            return fmt.Errorf("invalid ZFS timeout value: %w", err)
+        }
+        c.ZFSTimeout = t
+    }
+
+    // Identifiants des registres
+    if path := os.Getenv(EnvDockerConfig); path != "" {
+        c.DockerConfig = path
+    }
+    if path := os.Getenv(EnvRegistrySecrets); path != "" {
+        c.RegistrySecrets = path
+    }
+
+    // Scanner de vulnérabilités
+    if scanner := os.Getenv(EnvScanner); scanner != "" {
+        c.Scanner = scanner
+    }
+    if dir := os.Getenv(EnvScanDir); dir != "" {
+        c.ScanDir = dir
+    }
+
     return nil
 }
 
@@ -126,11 +260,34 @@ func (c *Config) Validate() error {
         return fmt.Errorf("retention must be at least 1")
     }
 
+    // Vérifier l'image auxiliaire
+    if c.HelperImage == "" {
+        return fmt.Errorf("helper image cannot be empty")
+    }
+
+    // Vérifier le nombre de tentatives de pull
+    if c.PullRetries < 0 {
+        return fmt.Errorf("pull retries cannot be negative")
+    }
+    if _, err := c.PullBandwidthBytes(); err != nil {
+        return err
+    }
+
     // Vérifier le timeout
     if c.Timeout < 1 {
         return fmt.Errorf("timeout must be at least 1 second")
     }
 
+    // Vérifier l'accès à ZFS
+    switch c.ZFSBackend {
+    case "auto", "native", "cli":
+    default:
+        return fmt.Errorf("invalid ZFS backend '%s': must be 'auto', 'native' or 'cli'", c.ZFSBackend)
+    }
+    if c.ZFSTimeout < 1 {
+        return fmt.Errorf("ZFS timeout must be at least 1 second")
+    }
+
     // Vérifier limit
     if c.Limit < 0 {
         return fmt.Errorf("limit cannot be negative")
@@ -156,6 +313,14 @@ func (c *Config) Validate() error {
         return fmt.Errorf("since date must be before (or equal to) before date")
     }
 
+    // Vérifier la fenêtre de maintenance et les périodes de gel
+    if _, err := maintenance.ParseWindows(c.Window); err != nil {
+        return err
+    }
+    if _, err := maintenance.ParseBlackouts(c.Blackout); err != nil {
+        return err
+    }
+
     // Vérifier le critère de tri
     if c.SortBy != "date" && c.SortBy != "container" {
         return fmt.Errorf("invalid sort criteria: must be 'date' or 'container'")
@@ -214,6 +379,35 @@ func (c *Config) Clone() *Config {
         Before:     c.Before,
         Retention:  c.Retention,
         Timeout:    c.Timeout,
+        Window:     c.Window,
+        Blackout:   c.Blackout,
+        HostsFile:  c.HostsFile,
+        ArchiveDir:  c.ArchiveDir,
+        HelperImage: c.HelperImage,
+        ZFSReplica:    c.ZFSReplica,
+        ZFSReplicaSSH: c.ZFSReplicaSSH,
+        ZFSBackend:    c.ZFSBackend,
+        ZFSTimeout:    c.ZFSTimeout,
+        InsecureRegistries: c.InsecureRegistries,
+        DockerConfig:       c.DockerConfig,
+        RegistrySecrets:    c.RegistrySecrets,
+        PullRetries:        c.PullRetries,
+        PullBandwidth:      c.PullBandwidth,
+        Scanner:    c.Scanner,
+        ScanDir:    c.ScanDir,
         Logger:     c.Logger, // Partagé intentionnellement
     }
-}
\ No newline at end of file
+}
+
+// PullBandwidthBytes retourne le débit maximal des pulls en octets par seconde
+// (0 : illimité). Les tailles sont décimales : 10MB = 10 000 000 octets.
+func (c *Config) PullBandwidthBytes() (int64, error) {
+    if c.PullBandwidth == "" || c.PullBandwidth == "0" {
+        return 0, nil
+    }
+    size, err := units.FromHumanSize(strings.TrimSuffix(c.PullBandwidth, "/s"))
+    if err != nil || size <= 0 {
+        return 0, fmt.Errorf("invalid pull bandwidth '%s' (e.g. 10MB)", c.PullBandwidth)
+    }
+    return size, nil
+}
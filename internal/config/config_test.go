@@ -0,0 +1,28 @@
+// internal/config/config_test.go
+package config
+
+import "testing"
+
+func TestPullBandwidthBytes(t *testing.T) {
+    tests := []struct {
+        value   string
+        want    int64
+        wantErr bool
+    }{
+        {"", 0, false},
+        {"0", 0, false},
+        {"10MB", 10_000_000, false},
+        {"500kB/s", 500_000, false},
+        {"1.5M", 1_500_000, false},
+        {"fast", 0, true},
+        {"-1MB", 0, true},
+    }
+    for _, tt := range tests {
+        c := NewConfig()
+        c.PullBandwidth = tt.value
+        got, err := c.PullBandwidthBytes()
+        if (err != nil) != tt.wantErr || got != tt.want {
+            t.Errorf("PullBandwidthBytes(%q) = %d, %v; want %d (error: %v)", tt.value, got, err, tt.want, tt.wantErr)
+        }
+    }
+}
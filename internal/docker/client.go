@@ -4,7 +4,7 @@ package docker
 import (
     "context"
     "fmt"
-    "io"
+    "os"
     "time"
     "encoding/json"
 
@@ -12,23 +12,70 @@ import (
 	"github.com/docker/docker/api/types/image"
     "github.com/docker/docker/api/types/container"
     "github.com/docker/docker/api/types/network"
+    registryTypes "github.com/docker/docker/api/types/registry"
     "github.com/docker/docker/client"
     "github.com/sirupsen/logrus"
+    "golang.org/x/time/rate"
     
+    "zockimate/internal/hosts"
+    "zockimate/internal/registry"
     zTypes "zockimate/internal/types"
 )
 
 // Client encapsule le client Docker avec des fonctionnalités supplémentaires
 type Client struct {
     cli    *client.Client
+    creds  *registry.CredentialStore
     logger *logrus.Logger
+
+    pullRetries int              // Tentatives supplémentaires en cas d'échec transitoire du pull
+    limiter     *rate.Limiter    // Plafond de débit des pulls (nil : illimité)
+    registry    *registry.Client // Téléchargement des couches au débit limité
+    podman      bool             // Démon Podman (API compatible Docker)
+    tls         bool             // Connexion TLS au démon
+}
+
+// PullOptions règle le téléchargement des images d'un hôte
+type PullOptions struct {
+    Retries   int      // Tentatives supplémentaires en cas d'erreur transitoire
+    Bandwidth int64    // Débit maximal en octets par seconde (0 : illimité)
+    Insecure  []string // Registres joints en HTTP lors des téléchargements limités
 }
 
-// NewClient crée une nouvelle instance du client Docker
-func NewClient(logger *logrus.Logger) (*Client, error) {
-    logger.Debug("Creating new Docker client...")
+// NewClient crée une nouvelle instance du client Docker pour un hôte.
+// Un hôte sans endpoint utilise les variables d'environnement DOCKER_*.
+// creds fournit les identifiants des registres privés (peut être nil).
+func NewClient(host hosts.Host, creds *registry.CredentialStore, pull PullOptions, logger *logrus.Logger) (*Client, error) {
+    logger.Debugf("Creating new Docker client for host %s...", host.Name)
+
+    opts := []client.Opt{client.FromEnv}
+    useTLS := host.TLSCA != "" || host.TLSCert != ""
+    switch host.Scheme() {
+    case "":
+        // Sans Docker, utiliser le socket Podman (rootless ou root)
+        if socket := localSocket(); socket != "" {
+            logger.Debugf("Docker socket not found, using %s", socket)
+            opts = append(opts, client.WithHost(socket), client.WithAPIVersionNegotiation())
+        }
+        useTLS = os.Getenv("DOCKER_CERT_PATH") != ""
+    case "ssh":
+        dialer, err := sshDialer(host.Endpoint)
+        if err != nil {
+            return nil, err
+        }
+        opts = append(opts,
+            client.WithHost("http://docker.example.com"),
+            client.WithDialContext(dialer),
+            client.WithAPIVersionNegotiation(),
+        )
+    default:
+        opts = append(opts, client.WithHost(host.Endpoint), client.WithAPIVersionNegotiation())
+        if useTLS {
+            opts = append(opts, client.WithTLSClientConfig(host.TLSCA, host.TLSCert, host.TLSKey))
+        }
+    }
 
-    cli, err := client.NewClientWithOpts(client.FromEnv)
+    cli, err := client.NewClientWithOpts(opts...)
     if err != nil {
         return nil, fmt.Errorf("failed to create Docker client: %w", err)
     }
@@ -40,37 +87,56 @@ func NewClient(logger *logrus.Logger) (*Client, error) {
         return nil, fmt.Errorf("failed to connect to Docker daemon: %w", err)
     }
 
-    logger.Debug("Successfully connected to Docker daemon")
+    logger.Debugf("Successfully connected to Docker daemon on host %s", host.Name)
 
-    return &Client{
+    c := &Client{
         cli:    cli,
+        creds:  creds,
         logger: logger,
-    }, nil
+        pullRetries: pull.Retries,
+        limiter:     newLimiter(pull.Bandwidth),
+        tls:    useTLS,
+    }
+    if c.limiter != nil {
+        c.registry = registry.NewClient(pull.Insecure, creds, logger)
+    }
+
+    // Détecter Podman
+    if version, err := cli.ServerVersion(ctx); err != nil {
+        logger.Warnf("Failed to get daemon version on host %s: %v", host.Name, err)
+    } else if isPodman(version) {
+        c.podman = true
+        logger.Debugf("Podman %s detected on host %s", version.Version, host.Name)
+    }
+
+    return c, nil
 }
+
 // Close ferme le client Docker
 func (c *Client) Close() error {
     return c.cli.Close()
 }
 
-// PullImage télécharge une image avec retry
-
-func (c *Client) PullImage(ctx context.Context, ref string) error {
-    c.logger.Debugf("Starting pull for image: %s", ref)
-
-    reader, err := c.cli.ImagePull(ctx, ref, image.PullOptions{})
+// registryAuth retourne les identifiants encodés pour le registre de l'image,
+// ou une chaîne vide si aucun n'est configuré
+func (c *Client) registryAuth(ref string) (string, error) {
+    repo, err := registry.ParseRepository(ref)
     if err != nil {
-        c.logger.Debugf("Pull failed with error: %v", err)
-        return fmt.Errorf("pull failed: %w", err)
+        return "", err
     }
-    defer reader.Close()
 
-    _, err = io.Copy(io.Discard, reader)
-    if err != nil {
-        return fmt.Errorf("error reading pull response: %w", err)
+    creds, found, err := c.creds.Lookup(repo.Domain)
+    if err != nil || !found {
+        return "", err
     }
 
-    c.logger.Debug("Pull completed successfully")
-    return nil
+    c.logger.Debugf("Using credentials for registry %s", repo.Domain)
+    return registryTypes.EncodeAuthConfig(registryTypes.AuthConfig{
+        Username:      creds.Username,
+        Password:      creds.Password,
+        IdentityToken: creds.IdentityToken,
+        ServerAddress: repo.Domain,
+    })
 }
 
 // GetImageInfo récupère les informations complètes d'une image
@@ -91,6 +157,9 @@ func (c *Client) GetImageInfo(ctx context.Context, ref string) (*zTypes.ImageRef
     if len(inspect.RepoTags) > 0 {
         imgRef.Tag = inspect.RepoTags[0]
     }
+    if inspect.Config != nil {
+        imgRef.Labels = inspect.Config.Labels
+    }
 
     return imgRef, nil
 }
@@ -99,6 +168,19 @@ func (c *Client) GetImageInfo(ctx context.Context, ref string) (*zTypes.ImageRef
 func (c *Client) RecreateContainer(ctx context.Context, name string, config *container.Config, 
     hostConfig *container.HostConfig, networkConfig *network.NetworkingConfig) error {
     
+    // Podman : retrouver le pod du conteneur pour le recréer à l'intérieur
+    var pod string
+    if c.podman {
+        cleanEndpoints(networkConfig)
+        if _, err := c.cli.ContainerInspect(ctx, name); err == nil {
+            p, err := c.podOf(ctx, name)
+            if err != nil {
+                return err
+            }
+            pod = p
+        }
+    }
+
     // Vérifier si le conteneur existe et le stopper/supprimer
     if _, err := c.cli.ContainerInspect(ctx, name); err == nil {
         timeout := 30 // secondes
@@ -116,13 +198,24 @@ func (c *Client) RecreateContainer(ctx context.Context, name string, config *con
     }
 
     // Créer le nouveau conteneur
-    resp, err := c.cli.ContainerCreate(ctx, config, hostConfig, networkConfig, nil, name)
-    if err != nil {
-        return fmt.Errorf("failed to create container: %w", err)
+    var id string
+    if pod != "" {
+        c.logger.Debugf("Recreating container %s in pod %s", name, pod)
+        podID, err := c.createInPod(ctx, pod, name, config, hostConfig)
+        if err != nil {
+            return err
+        }
+        id = podID
+    } else {
+        resp, err := c.cli.ContainerCreate(ctx, config, hostConfig, networkConfig, nil, name)
+        if err != nil {
+            return fmt.Errorf("failed to create container: %w", err)
+        }
+        id = resp.ID
     }
 
     // Démarrer le conteneur
-    if err := c.cli.ContainerStart(ctx, resp.ID, container.StartOptions{}); err != nil {
+    if err := c.cli.ContainerStart(ctx, id, container.StartOptions{}); err != nil {
         return fmt.Errorf("failed to start container: %w", err)
     }
 
@@ -147,17 +240,33 @@ func (c *Client) WaitForContainer(ctx context.Context, name string, timeout time
                 return err
             }
 
-            if container.State.Health != nil {
-                if container.State.Health.Status == "healthy" {
-                    return nil
-                }
-            } else if container.State.Running {
+            if c.isReady(container.State) {
                 return nil
             }
         }
     }
 }
 
+// isReady indique si un conteneur est prêt : sain s'il a un healthcheck, sinon démarré
+func (c *Client) isReady(state *types.ContainerState) bool {
+    if state == nil {
+        return false
+    }
+    if state.Health != nil {
+        switch state.Health.Status {
+        case types.Healthy:
+            return true
+        case "", "none":
+            // Podman renvoie un état de santé vide pour les conteneurs sans healthcheck
+            if c.podman {
+                return state.Running
+            }
+        }
+        return false
+    }
+    return state.Running
+}
+
 // ListContainers liste les conteneurs selon les critères
 func (c *Client) ListContainers(ctx context.Context, all bool) ([]types.Container, error) {
     opts := container.ListOptions{
@@ -178,6 +287,17 @@ func (c *Client) InspectContainer(ctx context.Context, name string) (types.Conta
     return ctn, nil
 }
 
+// ContainerExists indique si un conteneur existe (démarré ou non)
+func (c *Client) ContainerExists(ctx context.Context, name string) (bool, error) {
+    if _, err := c.cli.ContainerInspect(ctx, name); err != nil {
+        if client.IsErrNotFound(err) {
+            return false, nil
+        }
+        return false, fmt.Errorf("failed to inspect container: %w", err)
+    }
+    return true, nil
+}
+
 // GetContainerConfigs extrait et sérialise les configurations d'un conteneur
 func (c *Client) GetContainerConfigs(ctn types.ContainerJSON) ([]byte, []byte, []byte, error) {
     configJSON, err := json.Marshal(ctn.Config)
@@ -0,0 +1,69 @@
+// internal/docker/exec.go
+package docker
+
+import (
+    "bytes"
+    "context"
+    "fmt"
+    "strings"
+
+    "github.com/docker/docker/api/types/container"
+    "github.com/docker/docker/pkg/stdcopy"
+)
+
+// PauseContainer gèle les processus d'un conteneur (freezer cgroup)
+func (c *Client) PauseContainer(ctx context.Context, name string) error {
+    if err := c.cli.ContainerPause(ctx, name); err != nil {
+        return fmt.Errorf("failed to pause container: %w", err)
+    }
+    return nil
+}
+
+// UnpauseContainer dégèle les processus d'un conteneur
+func (c *Client) UnpauseContainer(ctx context.Context, name string) error {
+    if err := c.cli.ContainerUnpause(ctx, name); err != nil {
+        return fmt.Errorf("failed to unpause container: %w", err)
+    }
+    return nil
+}
+
+// StartContainer démarre un conteneur existant
+func (c *Client) StartContainer(ctx context.Context, name string) error {
+    if err := c.cli.ContainerStart(ctx, name, container.StartOptions{}); err != nil {
+        return fmt.Errorf("failed to start container: %w", err)
+    }
+    return nil
+}
+
+// ExecContainer exécute une commande shell dans un conteneur et échoue si
+// elle ne se termine pas avec le code 0
+func (c *Client) ExecContainer(ctx context.Context, name, command string) error {
+    exec, err := c.cli.ContainerExecCreate(ctx, name, container.ExecOptions{
+        Cmd:          []string{"sh", "-c", command},
+        AttachStdout: true,
+        AttachStderr: true,
+    })
+    if err != nil {
+        return fmt.Errorf("failed to create exec: %w", err)
+    }
+
+    resp, err := c.cli.ContainerExecAttach(ctx, exec.ID, container.ExecAttachOptions{})
+    if err != nil {
+        return fmt.Errorf("failed to run %q: %w", command, err)
+    }
+    defer resp.Close()
+
+    var output bytes.Buffer
+    if _, err := stdcopy.StdCopy(&output, &output, resp.Reader); err != nil {
+        return fmt.Errorf("failed to read output of %q: %w", command, err)
+    }
+
+    inspect, err := c.cli.ContainerExecInspect(ctx, exec.ID)
+    if err != nil {
+        return fmt.Errorf("failed to inspect exec: %w", err)
+    }
+    if inspect.ExitCode != 0 {
+        return fmt.Errorf("%q exited with code %d: %s", command, inspect.ExitCode, strings.TrimSpace(output.String()))
+    }
+    return nil
+}
@@ -0,0 +1,590 @@
+// internal/docker/fake.go
+package docker
+
+import (
+    "archive/tar"
+    "bytes"
+    "encoding/base64"
+    "encoding/json"
+    "fmt"
+    "io"
+    "net/http"
+    "path"
+    "regexp"
+    "strconv"
+    "strings"
+    "sync"
+    "time"
+
+    "github.com/distribution/reference"
+    "github.com/docker/docker/api/types"
+    "github.com/docker/docker/api/types/container"
+    "github.com/docker/docker/api/types/network"
+    "github.com/docker/docker/api/types/swarm"
+    "github.com/docker/docker/api/types/system"
+)
+
+// FakeDaemon est un démon d'API Docker (ou Podman) en mémoire pour les tests
+// unitaires, à servir avec httptest : version, info, conteneurs (création,
+// démarrage, arrêt, pause, exec, suppression, inspection), images locales et du
+// registre, pull, docker load, copie de fichiers dans les montages, réseaux et
+// services Swarm. Les autres routes (ou celles à surcharger) sont fournies dans Handlers, sous la forme "MÉTHODE /chemin" sans
+// préfixe de version d'API (les routes natives Podman gardent leur préfixe /libpod).
+type FakeDaemon struct {
+    mu         sync.Mutex
+    Version    types.Version
+    Info       system.Info
+    Containers map[string]*types.ContainerJSON // Par nom
+    Images     map[string]types.ImageInspect   // Images locales, par référence ou ID
+    Registry   map[string]types.ImageInspect   // Images téléchargeables, par référence
+    Networks   map[string]bool
+    Crashing   map[string]bool              // Conteneurs qui s'arrêtent aussitôt démarrés
+    ExitCodes  map[string]int               // Code de sortie des conteneurs attendus (wait), par image
+    Volumes    map[string]map[string]string // Fichiers des volumes et binds, par source
+    Services   map[string]*swarm.Service    // Services Swarm, par nom
+    Failing    map[string]bool              // Images dont les tâches de service ne démarrent pas
+    ExecCodes  map[string]int               // Code de sortie des commandes exec, par commande
+    Handlers   map[string]http.HandlerFunc
+    Loads      []map[string][]byte // Fichiers de chaque archive docker load
+    Pulls      []string            // Images demandées à /images/create
+    Created    []string            // Conteneurs créés, dans l'ordre
+    Execs      []string            // Commandes exec démarrées, dans l'ordre
+    Requests   []string            // "MÉTHODE /chemin" de chaque requête
+
+    execs map[string]string // Commande de chaque exec créé, par ID
+}
+
+var apiVersionPrefix = regexp.MustCompile(`^/v[0-9.]+`)
+
+// NewFakeDaemon crée un démon vide avec les réseaux par défaut
+func NewFakeDaemon() *FakeDaemon {
+    return &FakeDaemon{
+        Version:    types.Version{Version: "27.1.1", APIVersion: "1.46", Os: "linux", Arch: "amd64"},
+        Containers: make(map[string]*types.ContainerJSON),
+        Images:     make(map[string]types.ImageInspect),
+        Registry:   make(map[string]types.ImageInspect),
+        Networks:   map[string]bool{"bridge": true, "host": true, "none": true},
+        Crashing:   make(map[string]bool),
+        ExitCodes:  make(map[string]int),
+        Volumes:    make(map[string]map[string]string),
+        Services:   make(map[string]*swarm.Service),
+        Failing:    make(map[string]bool),
+        ExecCodes:  make(map[string]int),
+        Handlers:   make(map[string]http.HandlerFunc),
+        execs:      make(map[string]string),
+    }
+}
+
+// Handle définit la réponse JSON d'une route
+func (d *FakeDaemon) Handle(route string, status int, body interface{}) {
+    d.mu.Lock()
+    defer d.mu.Unlock()
+    d.Handlers[route] = func(w http.ResponseWriter, r *http.Request) {
+        writeFakeJSON(w, status, body)
+    }
+}
+
+// Called indique si une route a été appelée
+func (d *FakeDaemon) Called(route string) bool {
+    d.mu.Lock()
+    defer d.mu.Unlock()
+    for _, r := range d.Requests {
+        if r == route {
+            return true
+        }
+    }
+    return false
+}
+
+// AddImage ajoute une image locale, accessible par son ID et ses références
+func (d *FakeDaemon) AddImage(id string, refs ...string) {
+    d.mu.Lock()
+    defer d.mu.Unlock()
+    inspect := types.ImageInspect{ID: id, Os: "linux", Architecture: "amd64"}
+    for _, ref := range refs {
+        if strings.Contains(ref, "@") {
+            inspect.RepoDigests = append(inspect.RepoDigests, ref)
+        } else {
+            inspect.RepoTags = append(inspect.RepoTags, ref)
+        }
+    }
+    d.Images[id] = inspect
+    for _, ref := range refs {
+        d.Images[ref] = inspect
+    }
+}
+
+// RunContainer ajoute un conteneur en cours d'exécution sur une image locale
+func (d *FakeDaemon) RunContainer(name, image string, labels map[string]string) {
+    d.mu.Lock()
+    defer d.mu.Unlock()
+    d.Containers[name] = &types.ContainerJSON{
+        ContainerJSONBase: &types.ContainerJSONBase{
+            ID:    "id-" + name,
+            Name:  "/" + name,
+            Image: d.Images[image].ID,
+            State: &types.ContainerState{Running: true, Status: "running"},
+        },
+        Config:          &container.Config{Image: image, Labels: labels},
+        NetworkSettings: &types.NetworkSettings{},
+    }
+}
+
+// Container retourne un conteneur par nom (nil s'il n'existe pas)
+func (d *FakeDaemon) Container(name string) *types.ContainerJSON {
+    d.mu.Lock()
+    defer d.mu.Unlock()
+    return d.Containers[name]
+}
+
+// ServeHTTP répond à une requête de l'API
+func (d *FakeDaemon) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+    path := apiVersionPrefix.ReplaceAllString(r.URL.Path, "")
+    route := r.Method + " " + path
+
+    d.mu.Lock()
+    d.Requests = append(d.Requests, route)
+    handler, ok := d.Handlers[route]
+    d.mu.Unlock()
+    if ok {
+        handler(w, r)
+        return
+    }
+
+    d.mu.Lock()
+    defer d.mu.Unlock()
+
+    switch {
+    case route == "GET /_ping" || route == "HEAD /_ping":
+        w.Header().Set("API-Version", d.Version.APIVersion)
+        w.Write([]byte("OK")) //nolint:errcheck
+    case route == "GET /version":
+        writeFakeJSON(w, http.StatusOK, d.Version)
+    case route == "GET /info":
+        writeFakeJSON(w, http.StatusOK, d.Info)
+
+    case route == "GET /containers/json":
+        d.listContainers(w, r)
+    case route == "POST /containers/create":
+        d.createContainer(w, r)
+    case strings.HasPrefix(path, "/containers/"):
+        d.serveContainer(w, r, strings.TrimPrefix(path, "/containers/"))
+
+    case strings.HasPrefix(path, "/exec/"):
+        d.serveExec(w, r, strings.TrimPrefix(path, "/exec/"))
+
+    case route == "POST /images/create":
+        d.pullImage(w, r)
+    case route == "POST /images/load":
+        d.loadImage(w, r)
+    case r.Method == http.MethodGet && strings.HasPrefix(path, "/images/") && strings.HasSuffix(path, "/json"):
+        ref := strings.TrimSuffix(strings.TrimPrefix(path, "/images/"), "/json")
+        if inspect, ok := d.Images[ref]; ok {
+            writeFakeJSON(w, http.StatusOK, inspect)
+        } else {
+            writeFakeError(w, http.StatusNotFound, "No such image: "+ref)
+        }
+
+    case r.Method == http.MethodGet && strings.HasPrefix(path, "/distribution/") && strings.HasSuffix(path, "/json"):
+        ref := strings.TrimSuffix(strings.TrimPrefix(path, "/distribution/"), "/json")
+        d.inspectDistribution(w, ref)
+
+    case route == "GET /services":
+        services := []swarm.Service{}
+        for _, service := range d.Services {
+            services = append(services, *service)
+        }
+        writeFakeJSON(w, http.StatusOK, services)
+    case strings.HasPrefix(path, "/services/"):
+        d.serveService(w, r, strings.TrimPrefix(path, "/services/"))
+
+    case route == "POST /networks/create":
+        var req network.CreateRequest
+        if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+            writeFakeError(w, http.StatusBadRequest, err.Error())
+            return
+        }
+        d.Networks[req.Name] = true
+        writeFakeJSON(w, http.StatusCreated, network.CreateResponse{ID: "net-" + req.Name})
+    case r.Method == http.MethodGet && strings.HasPrefix(path, "/networks/"):
+        name := strings.TrimPrefix(path, "/networks/")
+        if d.Networks[name] {
+            writeFakeJSON(w, http.StatusOK, network.Inspect{Name: name, ID: "net-" + name})
+        } else {
+            writeFakeError(w, http.StatusNotFound, "network "+name+" not found")
+        }
+
+    default:
+        writeFakeError(w, http.StatusNotFound, "page not found: "+route)
+    }
+}
+
+// listContainers liste les conteneurs, arrêtés compris avec all=1
+func (d *FakeDaemon) listContainers(w http.ResponseWriter, r *http.Request) {
+    all := r.URL.Query().Get("all") == "1"
+    containers := []types.Container{}
+    for name, ctn := range d.Containers {
+        if !all && !ctn.State.Running {
+            continue
+        }
+        containers = append(containers, types.Container{
+            ID:      ctn.ID,
+            Names:   []string{"/" + name},
+            Image:   ctn.Config.Image,
+            ImageID: ctn.Image,
+            Labels:  ctn.Config.Labels,
+            State:   ctn.State.Status,
+        })
+    }
+    writeFakeJSON(w, http.StatusOK, containers)
+}
+
+func (d *FakeDaemon) createContainer(w http.ResponseWriter, r *http.Request) {
+    name := r.URL.Query().Get("name")
+    if name == "" {
+        name = fmt.Sprintf("container_%d", len(d.Created)+1)
+    }
+    if _, ok := d.Containers[name]; ok {
+        writeFakeError(w, http.StatusConflict, "container name "+name+" is already in use")
+        return
+    }
+    var req container.CreateRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        writeFakeError(w, http.StatusBadRequest, err.Error())
+        return
+    }
+    if _, ok := d.Images[req.Config.Image]; !ok {
+        writeFakeError(w, http.StatusNotFound, "No such image: "+req.Config.Image)
+        return
+    }
+    ctn := &types.ContainerJSON{
+        ContainerJSONBase: &types.ContainerJSONBase{
+            ID:         "id-" + name,
+            Name:       "/" + name,
+            Image:      d.Images[req.Config.Image].ID,
+            State:      &types.ContainerState{Status: "created"},
+            HostConfig: req.HostConfig,
+        },
+        Config:          req.Config,
+        NetworkSettings: &types.NetworkSettings{},
+    }
+    if req.NetworkingConfig != nil {
+        ctn.NetworkSettings.Networks = req.NetworkingConfig.EndpointsConfig
+    }
+    d.Containers[name] = ctn
+    d.Created = append(d.Created, name)
+    writeFakeJSON(w, http.StatusCreated, container.CreateResponse{ID: ctn.ID})
+}
+
+func (d *FakeDaemon) serveContainer(w http.ResponseWriter, r *http.Request, rest string) {
+    ref, action, _ := strings.Cut(rest, "/")
+    name := strings.TrimPrefix(ref, "id-")
+    ctn, ok := d.Containers[name]
+    if !ok {
+        writeFakeError(w, http.StatusNotFound, "No such container: "+ref)
+        return
+    }
+
+    switch r.Method + " " + action {
+    case "GET json":
+        writeFakeJSON(w, http.StatusOK, ctn)
+    case "POST start":
+        ctn.State.Running = !d.Crashing[name]
+        ctn.State.Status = "running"
+        if !ctn.State.Running {
+            ctn.State.Status = "exited"
+        }
+        w.WriteHeader(http.StatusNoContent)
+    case "POST stop":
+        ctn.State.Running = false
+        ctn.State.Status = "exited"
+        w.WriteHeader(http.StatusNoContent)
+    case "POST pause", "POST unpause":
+        if !ctn.State.Running || ctn.State.Paused == (action == "pause") {
+            writeFakeError(w, http.StatusConflict, "Container "+name+" is not running or already "+action+"d")
+            return
+        }
+        ctn.State.Paused = action == "pause"
+        ctn.State.Status = "running"
+        if ctn.State.Paused {
+            ctn.State.Status = "paused"
+        }
+        w.WriteHeader(http.StatusNoContent)
+    case "POST exec":
+        if !ctn.State.Running || ctn.State.Paused {
+            writeFakeError(w, http.StatusConflict, "Container "+name+" is not running")
+            return
+        }
+        var req container.ExecOptions
+        if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+            writeFakeError(w, http.StatusBadRequest, err.Error())
+            return
+        }
+        id := fmt.Sprintf("exec-%d", len(d.execs)+1)
+        d.execs[id] = req.Cmd[len(req.Cmd)-1]
+        writeFakeJSON(w, http.StatusCreated, types.IDResponse{ID: id})
+    case "POST wait":
+        ctn.State.Running = false
+        ctn.State.Status = "exited"
+        writeFakeJSON(w, http.StatusOK, container.WaitResponse{StatusCode: int64(d.ExitCodes[ctn.Config.Image])})
+    case "DELETE ":
+        delete(d.Containers, name)
+        w.WriteHeader(http.StatusNoContent)
+    case "GET archive":
+        d.readArchive(w, ctn, r.URL.Query().Get("path"))
+    case "PUT archive":
+        d.writeArchive(w, r, ctn, r.URL.Query().Get("path"))
+    default:
+        writeFakeError(w, http.StatusNotFound, "page not found: "+r.Method+" "+r.URL.Path)
+    }
+}
+
+// serveExec démarre un exec (sans sortie, connexion détournée comme le fait
+// Docker) ou retourne son code de sortie
+func (d *FakeDaemon) serveExec(w http.ResponseWriter, r *http.Request, rest string) {
+    id, action, _ := strings.Cut(rest, "/")
+    command, ok := d.execs[id]
+    if !ok {
+        writeFakeError(w, http.StatusNotFound, "No such exec instance: "+id)
+        return
+    }
+
+    switch r.Method + " " + action {
+    case "POST start":
+        d.Execs = append(d.Execs, command)
+        conn, buf, err := w.(http.Hijacker).Hijack()
+        if err != nil {
+            return
+        }
+        defer conn.Close()
+        buf.WriteString("HTTP/1.1 101 UPGRADED\r\nContent-Type: application/vnd.docker.multiplexed-stream\r\n" +
+            "Connection: Upgrade\r\nUpgrade: tcp\r\n\r\n") //nolint:errcheck
+        buf.Flush() //nolint:errcheck
+    case "GET json":
+        writeFakeJSON(w, http.StatusOK, container.ExecInspect{ExecID: id, ExitCode: d.ExecCodes[command]})
+    default:
+        writeFakeError(w, http.StatusNotFound, "page not found: "+r.Method+" "+r.URL.Path)
+    }
+}
+
+// mountAt retourne la source du montage d'un conteneur contenant le chemin donné
+// et le chemin relatif dans ce montage
+func mountAt(ctn *types.ContainerJSON, p string) (string, string, bool) {
+    if ctn.HostConfig == nil {
+        return "", "", false
+    }
+    for _, m := range ctn.HostConfig.Mounts {
+        if rel, ok := strings.CutPrefix(p, m.Target); ok && (rel == "" || strings.HasPrefix(rel, "/")) {
+            return m.Source, strings.TrimPrefix(rel, "/"), true
+        }
+    }
+    return "", "", false
+}
+
+// readArchive retourne le contenu d'un montage sous forme d'archive tar,
+// préfixé par le nom du répertoire demandé comme le fait Docker
+func (d *FakeDaemon) readArchive(w http.ResponseWriter, ctn *types.ContainerJSON, p string) {
+    source, rel, ok := mountAt(ctn, p)
+    if !ok || rel != "" {
+        writeFakeError(w, http.StatusNotFound, "Could not find the file "+p+" in container")
+        return
+    }
+
+    var buf bytes.Buffer
+    tw := tar.NewWriter(&buf)
+    base := path.Base(p)
+    tw.WriteHeader(&tar.Header{Typeflag: tar.TypeDir, Name: base + "/", Mode: 0o755}) //nolint:errcheck
+    for name, content := range d.Volumes[source] {
+        tw.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: base + "/" + name, Mode: 0o644, Size: int64(len(content))}) //nolint:errcheck
+        tw.Write([]byte(content)) //nolint:errcheck
+    }
+    tw.Close() //nolint:errcheck
+
+    stat, _ := json.Marshal(container.PathStat{Name: base, Mode: 0o755 | 1<<31})
+    w.Header().Set("X-Docker-Container-Path-Stat", base64.StdEncoding.EncodeToString(stat))
+    w.Header().Set("Content-Type", "application/x-tar")
+    w.Write(buf.Bytes()) //nolint:errcheck
+}
+
+// writeArchive extrait une archive tar dans les montages d'un conteneur
+func (d *FakeDaemon) writeArchive(w http.ResponseWriter, r *http.Request, ctn *types.ContainerJSON, p string) {
+    tr := tar.NewReader(r.Body)
+    for {
+        hdr, err := tr.Next()
+        if err == io.EOF {
+            break
+        }
+        if err != nil {
+            writeFakeError(w, http.StatusBadRequest, "invalid archive: "+err.Error())
+            return
+        }
+        if hdr.Typeflag != tar.TypeReg {
+            continue
+        }
+        source, rel, ok := mountAt(ctn, path.Join(p, hdr.Name))
+        if !ok {
+            writeFakeError(w, http.StatusBadRequest, "cannot write "+hdr.Name+" outside a mount")
+            return
+        }
+        content, err := io.ReadAll(tr)
+        if err != nil {
+            writeFakeError(w, http.StatusBadRequest, "invalid archive: "+err.Error())
+            return
+        }
+        if d.Volumes[source] == nil {
+            d.Volumes[source] = make(map[string]string)
+        }
+        d.Volumes[source][rel] = string(content)
+    }
+    w.WriteHeader(http.StatusOK)
+}
+
+// pullImage télécharge une image du registre, avec le flux de progression de l'API.
+// Une image absente du registre mais présente localement est à jour.
+func (d *FakeDaemon) pullImage(w http.ResponseWriter, r *http.Request) {
+    ref := r.URL.Query().Get("fromImage")
+    if named, err := reference.ParseNormalizedNamed(ref); err == nil {
+        ref = reference.FamiliarString(named)
+    }
+    if tag := r.URL.Query().Get("tag"); strings.HasPrefix(tag, "sha256:") {
+        ref += "@" + tag
+    } else if tag != "" {
+        ref += ":" + tag
+    }
+    d.Pulls = append(d.Pulls, ref)
+
+    if inspect, ok := d.Registry[ref]; ok {
+        d.Images[ref] = inspect
+        d.Images[inspect.ID] = inspect
+        writeFakeJSON(w, http.StatusOK, map[string]string{"status": "Downloaded newer image for " + ref})
+        return
+    }
+    if _, ok := d.Images[ref]; ok {
+        writeFakeJSON(w, http.StatusOK, map[string]string{"status": "Status: Image is up to date for " + ref})
+        return
+    }
+    writeFakeJSON(w, http.StatusOK, map[string]string{"error": "manifest for " + ref + " not found"})
+}
+
+// loadImage lit une archive docker load, enregistre ses fichiers et ajoute
+// ses images (ID tiré du nom de la configuration) sous leurs tags
+func (d *FakeDaemon) loadImage(w http.ResponseWriter, r *http.Request) {
+    files := make(map[string][]byte)
+    tr := tar.NewReader(r.Body)
+    for {
+        hdr, err := tr.Next()
+        if err == io.EOF {
+            break
+        }
+        if err != nil {
+            writeFakeError(w, http.StatusInternalServerError, "invalid archive: "+err.Error())
+            return
+        }
+        data, err := io.ReadAll(tr)
+        if err != nil {
+            writeFakeError(w, http.StatusInternalServerError, "invalid archive: "+err.Error())
+            return
+        }
+        files[hdr.Name] = data
+    }
+    d.Loads = append(d.Loads, files)
+
+    var manifest []struct {
+        Config   string
+        RepoTags []string
+    }
+    if err := json.Unmarshal(files["manifest.json"], &manifest); err != nil {
+        writeFakeError(w, http.StatusInternalServerError, "invalid manifest.json: "+err.Error())
+        return
+    }
+    for _, entry := range manifest {
+        inspect := types.ImageInspect{
+            ID:           "sha256:" + strings.TrimSuffix(path.Base(entry.Config), ".json"),
+            RepoTags:     entry.RepoTags,
+            Os:           "linux",
+            Architecture: "amd64",
+        }
+        d.Images[inspect.ID] = inspect
+        for _, tag := range entry.RepoTags {
+            d.Images[tag] = inspect
+        }
+    }
+    writeFakeJSON(w, http.StatusOK, map[string]string{"stream": "Loaded image\n"})
+}
+
+// inspectDistribution retourne le digest d'une image du registre
+func (d *FakeDaemon) inspectDistribution(w http.ResponseWriter, ref string) {
+    if named, err := reference.ParseNormalizedNamed(ref); err == nil {
+        ref = reference.FamiliarString(named)
+    }
+    inspect, ok := d.Registry[ref]
+    if !ok || len(inspect.RepoDigests) == 0 {
+        writeFakeError(w, http.StatusNotFound, "manifest for "+ref+" not found")
+        return
+    }
+    _, digest, _ := strings.Cut(inspect.RepoDigests[0], "@")
+    writeFakeJSON(w, http.StatusOK, map[string]interface{}{
+        "Descriptor": map[string]interface{}{
+            "mediaType": "application/vnd.oci.image.index.v1+json",
+            "digest":    digest,
+            "size":      1024,
+        },
+    })
+}
+
+// serveService inspecte ou met à jour un service (par nom ou ID). Une mise à jour
+// se termine aussitôt, en échec si la nouvelle image fait partie de Failing.
+func (d *FakeDaemon) serveService(w http.ResponseWriter, r *http.Request, rest string) {
+    ref, action, _ := strings.Cut(rest, "/")
+    var service *swarm.Service
+    for name, s := range d.Services {
+        if name == ref || s.ID == ref {
+            service = s
+        }
+    }
+    if service == nil {
+        writeFakeError(w, http.StatusNotFound, "service "+ref+" not found")
+        return
+    }
+
+    switch r.Method + " " + action {
+    case "GET ":
+        writeFakeJSON(w, http.StatusOK, service)
+    case "POST update":
+        if version := r.URL.Query().Get("version"); version != strconv.FormatUint(service.Version.Index, 10) {
+            writeFakeError(w, http.StatusConflict, "update out of sequence")
+            return
+        }
+        var spec swarm.ServiceSpec
+        if err := json.NewDecoder(r.Body).Decode(&spec); err != nil {
+            writeFakeError(w, http.StatusBadRequest, err.Error())
+            return
+        }
+        previous := service.Spec
+        service.PreviousSpec = &previous
+        service.Spec = spec
+        service.Version.Index++
+
+        now := time.Now()
+        service.UpdateStatus = &swarm.UpdateStatus{State: swarm.UpdateStateCompleted, StartedAt: &now, CompletedAt: &now}
+        if d.Failing[spec.TaskTemplate.ContainerSpec.Image] {
+            service.UpdateStatus.State = swarm.UpdateStateRollbackCompleted
+            service.UpdateStatus.Message = "rollback completed: task failed to start"
+        }
+        writeFakeJSON(w, http.StatusOK, swarm.ServiceUpdateResponse{})
+    default:
+        writeFakeError(w, http.StatusNotFound, "page not found: "+r.Method+" "+r.URL.Path)
+    }
+}
+
+func writeFakeJSON(w http.ResponseWriter, status int, v interface{}) {
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(status)
+    if v != nil {
+        json.NewEncoder(w).Encode(v) //nolint:errcheck
+    }
+}
+
+func writeFakeError(w http.ResponseWriter, status int, message string) {
+    writeFakeJSON(w, status, map[string]string{"message": message})
+}
@@ -0,0 +1,32 @@
+// internal/docker/fake_test.go
+package docker
+
+import (
+    "io"
+    "net/http/httptest"
+    "strings"
+    "testing"
+
+    "github.com/docker/docker/client"
+    "github.com/sirupsen/logrus"
+)
+
+// fakeClient sert le démon en mémoire et retourne un client connecté
+func fakeClient(t *testing.T, d *FakeDaemon, podman bool) *Client {
+    t.Helper()
+    srv := httptest.NewServer(d)
+    t.Cleanup(srv.Close)
+
+    cli, err := client.NewClientWithOpts(
+        client.WithHost("tcp://"+strings.TrimPrefix(srv.URL, "http://")),
+        client.WithVersion("1.46"),
+    )
+    if err != nil {
+        t.Fatal(err)
+    }
+    t.Cleanup(func() { cli.Close() })
+
+    logger := logrus.New()
+    logger.SetOutput(io.Discard)
+    return &Client{cli: cli, logger: logger, podman: podman}
+}
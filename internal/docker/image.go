@@ -0,0 +1,68 @@
+// internal/docker/image.go
+package docker
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "io"
+
+    "github.com/docker/docker/pkg/jsonmessage"
+
+    "zockimate/pkg/utils"
+)
+
+// SaveImage écrit l'archive d'une image (docker save). L'image est désignée par
+// les tags qui pointent encore sur elle, pour qu'ils soient recréés au chargement.
+func (c *Client) SaveImage(ctx context.Context, imageID string, w io.Writer) error {
+    inspect, _, err := c.cli.ImageInspectWithRaw(ctx, imageID)
+    if err != nil {
+        return fmt.Errorf("failed to inspect image %s: %w", utils.ShortenID(imageID), err)
+    }
+    names := inspect.RepoTags
+    if len(names) == 0 {
+        names = []string{inspect.ID}
+    }
+
+    reader, err := c.cli.ImageSave(ctx, names)
+    if err != nil {
+        return fmt.Errorf("failed to save image %s: %w", utils.ShortenID(imageID), err)
+    }
+    defer reader.Close()
+
+    if _, err := io.Copy(w, reader); err != nil {
+        return fmt.Errorf("failed to save image %s: %w", utils.ShortenID(imageID), err)
+    }
+    return nil
+}
+
+// LoadImage charge une archive d'image produite par docker save (docker load)
+func (c *Client) LoadImage(ctx context.Context, r io.Reader) error {
+    resp, err := c.cli.ImageLoad(ctx, r, true)
+    if err != nil {
+        return fmt.Errorf("failed to load image: %w", err)
+    }
+    defer resp.Body.Close()
+
+    // Erreur signalée dans le flux (le code HTTP était 200)
+    dec := json.NewDecoder(resp.Body)
+    for {
+        var msg jsonmessage.JSONMessage
+        if err := dec.Decode(&msg); err != nil {
+            if err == io.EOF {
+                return nil
+            }
+            return fmt.Errorf("error reading load response: %w", err)
+        }
+        if msg.Error != nil {
+            return fmt.Errorf("failed to load image: %w", msg.Error)
+        }
+        if msg.ErrorMessage != "" {
+            return fmt.Errorf("failed to load image: %w", errors.New(msg.ErrorMessage))
+        }
+        if msg.Stream != "" {
+            c.logger.Debugf("Image load: %s", msg.Stream)
+        }
+    }
+}
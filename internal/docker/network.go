@@ -0,0 +1,58 @@
+// internal/docker/network.go
+package docker
+
+import (
+    "context"
+    "fmt"
+    "sort"
+
+    "github.com/docker/docker/api/types/container"
+    "github.com/docker/docker/api/types/network"
+    "github.com/docker/docker/client"
+)
+
+// EnsureNetworks crée les réseaux définis par l'utilisateur auxquels un conteneur à
+// recréer est rattaché et qui n'existent plus (pilote bridge par défaut : les options
+// d'origine du réseau ne sont pas connues). Retourne les réseaux créés.
+// Les champs d'exécution des endpoints (identifiants, adresses attribuées) sont
+// retirés : ils désignent un conteneur et des réseaux qui n'existent plus.
+func (c *Client) EnsureNetworks(ctx context.Context, hostConfig *container.HostConfig,
+    networkConfig *network.NetworkingConfig) ([]string, error) {
+
+    names := make(map[string]bool)
+    if hostConfig != nil && hostConfig.NetworkMode.IsUserDefined() {
+        names[hostConfig.NetworkMode.NetworkName()] = true
+    }
+    if networkConfig != nil {
+        for name := range networkConfig.EndpointsConfig {
+            if container.NetworkMode(name).IsUserDefined() {
+                names[name] = true
+            }
+        }
+    }
+    cleanEndpoints(networkConfig)
+
+    sorted := make([]string, 0, len(names))
+    for name := range names {
+        sorted = append(sorted, name)
+    }
+    sort.Strings(sorted)
+
+    var created []string
+    for _, name := range sorted {
+        _, err := c.cli.NetworkInspect(ctx, name, network.InspectOptions{})
+        if err == nil {
+            continue
+        }
+        if !client.IsErrNotFound(err) {
+            return created, fmt.Errorf("failed to inspect network %s: %w", name, err)
+        }
+
+        c.logger.Infof("Creating missing network %s", name)
+        if _, err := c.cli.NetworkCreate(ctx, name, network.CreateOptions{Driver: "bridge"}); err != nil {
+            return created, fmt.Errorf("failed to create network %s: %w", name, err)
+        }
+        created = append(created, name)
+    }
+    return created, nil
+}
@@ -0,0 +1,638 @@
+// internal/docker/podman.go
+package docker
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "fmt"
+    "io"
+    "net/http"
+    "os"
+    "path/filepath"
+    "sort"
+    "strconv"
+    "strings"
+
+    "github.com/docker/docker/api/types"
+    "github.com/docker/docker/api/types/container"
+    "github.com/docker/docker/api/types/mount"
+    "github.com/docker/docker/api/types/network"
+    "github.com/docker/docker/client"
+)
+
+// Préfixe de l'API native de Podman (acceptée par toutes les versions 4.x et 5.x)
+const libpodAPIPrefix = "/v4.0.0/libpod"
+
+// localSocket retourne le socket à utiliser quand DOCKER_HOST n'est pas défini :
+// le socket Docker s'il existe, sinon le socket Podman (rootless puis root)
+func localSocket() string {
+    if os.Getenv("DOCKER_HOST") != "" {
+        return ""
+    }
+    if _, err := os.Stat("/var/run/docker.sock"); err == nil {
+        return ""
+    }
+
+    var candidates []string
+    if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+        candidates = append(candidates, filepath.Join(dir, "podman", "podman.sock"))
+    }
+    candidates = append(candidates,
+        fmt.Sprintf("/run/user/%d/podman/podman.sock", os.Getuid()),
+        "/run/podman/podman.sock",
+    )
+    for _, path := range candidates {
+        if _, err := os.Stat(path); err == nil {
+            return "unix://" + path
+        }
+    }
+    return ""
+}
+
+// isPodman détecte un démon Podman derrière l'API compatible Docker
+func isPodman(v types.Version) bool {
+    if strings.Contains(strings.ToLower(v.Platform.Name), "podman") {
+        return true
+    }
+    for _, component := range v.Components {
+        if strings.Contains(strings.ToLower(component.Name), "podman") {
+            return true
+        }
+    }
+    return false
+}
+
+// IsPodman indique si le démon est Podman
+func (c *Client) IsPodman() bool {
+    return c.podman
+}
+
+// libpodInspect contient les champs utiles de l'inspection native Podman
+type libpodInspect struct {
+    ID      string `json:"Id"`
+    Pod     string `json:"Pod"`
+    IsInfra bool   `json:"IsInfra"`
+}
+
+// libpod effectue une requête sur l'API native de Podman
+func (c *Client) libpod(ctx context.Context, method, path string, body, out interface{}) error {
+    u, err := client.ParseHostURL(c.cli.DaemonHost())
+    if err != nil {
+        return err
+    }
+    host := u.Host
+    if u.Scheme == "unix" || u.Scheme == "npipe" {
+        host = client.DummyHost
+    }
+    scheme := "http"
+    if c.tls {
+        scheme = "https"
+    }
+
+    var reader io.Reader
+    if body != nil {
+        data, err := json.Marshal(body)
+        if err != nil {
+            return fmt.Errorf("failed to encode libpod request: %w", err)
+        }
+        reader = bytes.NewReader(data)
+    }
+
+    req, err := http.NewRequestWithContext(ctx, method, scheme+"://"+host+libpodAPIPrefix+path, reader)
+    if err != nil {
+        return fmt.Errorf("failed to create libpod request: %w", err)
+    }
+    if body != nil {
+        req.Header.Set("Content-Type", "application/json")
+    }
+
+    resp, err := c.cli.HTTPClient().Do(req)
+    if err != nil {
+        return fmt.Errorf("libpod request %s failed: %w", path, err)
+    }
+    defer resp.Body.Close()
+
+    data, err := io.ReadAll(io.LimitReader(resp.Body, 16<<20))
+    if err != nil {
+        return fmt.Errorf("failed to read libpod response: %w", err)
+    }
+    if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+        return fmt.Errorf("libpod request %s failed with status %d: %s",
+            path, resp.StatusCode, strings.TrimSpace(string(data)))
+    }
+    if out != nil {
+        if err := json.Unmarshal(data, out); err != nil {
+            return fmt.Errorf("failed to decode libpod response: %w", err)
+        }
+    }
+    return nil
+}
+
+// podOf retourne le pod d'un conteneur Podman (vide s'il n'appartient à aucun pod)
+func (c *Client) podOf(ctx context.Context, name string) (string, error) {
+    var inspect libpodInspect
+    if err := c.libpod(ctx, http.MethodGet, "/containers/"+name+"/json", nil, &inspect); err != nil {
+        return "", err
+    }
+    if inspect.IsInfra {
+        return "", fmt.Errorf("container %s is the infra container of pod %s and cannot be recreated", name, inspect.Pod)
+    }
+    return inspect.Pod, nil
+}
+
+// podNamespace est un espace de noms de la SpecGenerator Podman
+type podNamespace struct {
+    Mode  string `json:"nsmode"`
+    Value string `json:"value,omitempty"`
+}
+
+type podMount struct {
+    Type        string   `json:"type"`
+    Source      string   `json:"source,omitempty"`
+    Destination string   `json:"destination"`
+    Options     []string `json:"options,omitempty"`
+}
+
+type podVolume struct {
+    Name    string   `json:"Name"`
+    Dest    string   `json:"Dest"`
+    Options []string `json:"Options,omitempty"`
+}
+
+type podDevice struct {
+    Path string `json:"path"` // source[:destination[:permissions]]
+}
+
+type podDeviceRule struct {
+    Allow  bool   `json:"allow"`
+    Type   string `json:"type,omitempty"`
+    Major  *int64 `json:"major,omitempty"`
+    Minor  *int64 `json:"minor,omitempty"`
+    Access string `json:"access,omitempty"`
+}
+
+type podRlimit struct {
+    Type string `json:"type"`
+    Hard uint64 `json:"hard"`
+    Soft uint64 `json:"soft"`
+}
+
+type podLogConfig struct {
+    Driver  string            `json:"driver,omitempty"`
+    Options map[string]string `json:"options,omitempty"`
+}
+
+// podResources suit le format LinuxResources de la spécification OCI
+type podResources struct {
+    Memory  *podMemory  `json:"memory,omitempty"`
+    CPU     *podCPU     `json:"cpu,omitempty"`
+    Pids    *podPids    `json:"pids,omitempty"`
+    BlockIO *podBlockIO `json:"blockIO,omitempty"`
+}
+
+type podMemory struct {
+    Limit            *int64  `json:"limit,omitempty"`
+    Reservation      *int64  `json:"reservation,omitempty"`
+    Swap             *int64  `json:"swap,omitempty"`
+    Swappiness       *uint64 `json:"swappiness,omitempty"`
+    DisableOOMKiller *bool   `json:"disableOOMKiller,omitempty"`
+}
+
+type podCPU struct {
+    Shares          *uint64 `json:"shares,omitempty"`
+    Quota           *int64  `json:"quota,omitempty"`
+    Period          *uint64 `json:"period,omitempty"`
+    RealtimeRuntime *int64  `json:"realtimeRuntime,omitempty"`
+    RealtimePeriod  *uint64 `json:"realtimePeriod,omitempty"`
+    Cpus            string  `json:"cpus,omitempty"`
+    Mems            string  `json:"mems,omitempty"`
+}
+
+type podPids struct {
+    Limit int64 `json:"limit"`
+}
+
+type podBlockIO struct {
+    Weight *uint16 `json:"weight,omitempty"`
+}
+
+// podSpec est le sous-ensemble de la SpecGenerator de l'API native Podman
+// utilisé pour recréer un conteneur dans un pod
+type podSpec struct {
+    Name        string                  `json:"name"`
+    Image       string                  `json:"image"`
+    Pod         string                  `json:"pod"`
+    Labels      map[string]string       `json:"labels,omitempty"`
+    Annotations map[string]string       `json:"annotations,omitempty"`
+    Env         map[string]string       `json:"env,omitempty"`
+    Command     []string                `json:"command,omitempty"`
+    Entrypoint  []string                `json:"entrypoint,omitempty"`
+    User        string                  `json:"user,omitempty"`
+    WorkDir     string                  `json:"work_dir,omitempty"`
+    Terminal    bool                    `json:"terminal,omitempty"`
+    Stdin       bool                    `json:"stdin,omitempty"`
+    StopTimeout *uint                   `json:"stop_timeout,omitempty"`
+    Health      *container.HealthConfig `json:"healthconfig,omitempty"`
+
+    RestartPolicy string        `json:"restart_policy,omitempty"`
+    RestartTries  *uint         `json:"restart_tries,omitempty"`
+    Remove        bool          `json:"remove,omitempty"`
+    LogConfig     *podLogConfig `json:"log_configuration,omitempty"`
+    OCIRuntime    string        `json:"oci_runtime,omitempty"`
+    Init          bool          `json:"init,omitempty"`
+
+    Mounts      []podMount        `json:"mounts,omitempty"`
+    Volumes     []podVolume       `json:"volumes,omitempty"`
+    VolumesFrom []string          `json:"volumes_from,omitempty"`
+    StorageOpts map[string]string `json:"storage_opts,omitempty"`
+    ShmSize     *int64            `json:"shm_size,omitempty"`
+
+    Privileged      bool            `json:"privileged,omitempty"`
+    Groups          []string        `json:"groups,omitempty"`
+    CapAdd          []string        `json:"cap_add,omitempty"`
+    CapDrop         []string        `json:"cap_drop,omitempty"`
+    SelinuxOpts     []string        `json:"selinux_opts,omitempty"`
+    Apparmor        string          `json:"apparmor_profile,omitempty"`
+    Seccomp         string          `json:"seccomp_profile_path,omitempty"`
+    NoNewPrivileges bool            `json:"no_new_privileges,omitempty"`
+    ReadOnlyRootfs  bool            `json:"read_only_filesystem,omitempty"`
+    Mask            []string        `json:"mask,omitempty"`
+    Unmask          []string        `json:"unmask,omitempty"`
+    Devices         []podDevice     `json:"devices,omitempty"`
+    DeviceRules     []podDeviceRule `json:"device_cgroup_rule,omitempty"`
+
+    PidNS        *podNamespace     `json:"pidns,omitempty"`
+    IpcNS        *podNamespace     `json:"ipcns,omitempty"`
+    UtsNS        *podNamespace     `json:"utsns,omitempty"`
+    UserNS       *podNamespace     `json:"userns,omitempty"`
+    CgroupNS     *podNamespace     `json:"cgroupns,omitempty"`
+    CgroupParent string            `json:"cgroup_parent,omitempty"`
+    Sysctl       map[string]string `json:"sysctl,omitempty"`
+
+    Resources   *podResources `json:"resource_limits,omitempty"`
+    Rlimits     []podRlimit   `json:"r_limits,omitempty"`
+    OOMScoreAdj *int          `json:"oom_score_adj,omitempty"`
+}
+
+// createInPod crée un conteneur dans un pod via l'API native (l'API compatible
+// Docker ne permet pas de rattacher un conteneur à un pod)
+func (c *Client) createInPod(ctx context.Context, pod, name string, config *container.Config,
+    hostConfig *container.HostConfig) (string, error) {
+
+    spec, err := newPodSpec(pod, name, config, hostConfig)
+    if err != nil {
+        return "", err
+    }
+
+    var resp struct {
+        ID string `json:"Id"`
+    }
+    if err := c.libpod(ctx, http.MethodPost, "/containers/create", spec, &resp); err != nil {
+        return "", fmt.Errorf("failed to create container in pod %s: %w", pod, err)
+    }
+    return resp.ID, nil
+}
+
+// newPodSpec traduit la configuration inspectée d'un conteneur en SpecGenerator.
+// La HostConfig est reprise en entier, à l'exception des champs appartenant au pod :
+// réseau (ports, DNS, hôtes, liens), nom d'hôte et espaces de noms partagés
+// avec le conteneur infra (mode "container:<infra>").
+func newPodSpec(pod, name string, config *container.Config, hostConfig *container.HostConfig) (*podSpec, error) {
+    spec := &podSpec{
+        Name:     name,
+        Image:    config.Image,
+        Pod:      pod,
+        Labels:   config.Labels,
+        Env:      envMap(config.Env),
+        User:     config.User,
+        WorkDir:  config.WorkingDir,
+        Terminal: config.Tty,
+        Stdin:    config.OpenStdin,
+        Health:   config.Healthcheck,
+    }
+    if len(config.Cmd) > 0 {
+        spec.Command = []string(config.Cmd)
+    }
+    if len(config.Entrypoint) > 0 {
+        spec.Entrypoint = []string(config.Entrypoint)
+    }
+    if config.StopTimeout != nil && *config.StopTimeout >= 0 {
+        timeout := uint(*config.StopTimeout)
+        spec.StopTimeout = &timeout
+    }
+    if hostConfig == nil {
+        return spec, nil
+    }
+
+    hc := *hostConfig
+    ipcOwned := strings.HasPrefix(string(hc.IpcMode), "container:")
+
+    spec.Annotations = hc.Annotations
+    if hc.RestartPolicy.Name != "" {
+        spec.RestartPolicy = string(hc.RestartPolicy.Name)
+        if hc.RestartPolicy.MaximumRetryCount > 0 {
+            tries := uint(hc.RestartPolicy.MaximumRetryCount)
+            spec.RestartTries = &tries
+        }
+    }
+    spec.Remove = hc.AutoRemove
+    if hc.LogConfig.Type != "" {
+        spec.LogConfig = &podLogConfig{Driver: hc.LogConfig.Type, Options: hc.LogConfig.Config}
+    }
+    spec.OCIRuntime = hc.Runtime
+    spec.Init = hc.Init != nil && *hc.Init
+
+    spec.Mounts, spec.Volumes = podMounts(&hc)
+    spec.VolumesFrom = hc.VolumesFrom
+    spec.StorageOpts = hc.StorageOpt
+    if hc.ShmSize > 0 && !ipcOwned {
+        shm := hc.ShmSize
+        spec.ShmSize = &shm
+    }
+
+    spec.Privileged = hc.Privileged
+    spec.Groups = hc.GroupAdd
+    spec.CapAdd = []string(hc.CapAdd)
+    spec.CapDrop = []string(hc.CapDrop)
+    spec.ReadOnlyRootfs = hc.ReadonlyRootfs
+    spec.Mask = hc.MaskedPaths
+    applySecurityOpts(spec, hc.SecurityOpt)
+
+    for _, d := range hc.Devices {
+        spec.Devices = append(spec.Devices, podDevice{Path: devicePath(d)})
+    }
+    for _, rule := range hc.DeviceCgroupRules {
+        parsed, err := parseDeviceRule(rule)
+        if err != nil {
+            return nil, err
+        }
+        spec.DeviceRules = append(spec.DeviceRules, parsed)
+    }
+
+    spec.PidNS = podNamespaceOf(string(hc.PidMode))
+    spec.IpcNS = podNamespaceOf(string(hc.IpcMode))
+    spec.UtsNS = podNamespaceOf(string(hc.UTSMode))
+    spec.UserNS = podNamespaceOf(string(hc.UsernsMode))
+    spec.CgroupNS = podNamespaceOf(string(hc.CgroupnsMode))
+    spec.CgroupParent = hc.CgroupParent
+    spec.Sysctl = hc.Sysctls
+
+    spec.Resources = podResourcesOf(hc.Resources)
+    for _, u := range hc.Ulimits {
+        if u == nil {
+            continue
+        }
+        spec.Rlimits = append(spec.Rlimits, podRlimit{
+            Type: "RLIMIT_" + strings.ToUpper(u.Name),
+            Hard: uint64(u.Hard),
+            Soft: uint64(u.Soft),
+        })
+    }
+    if hc.OomScoreAdj != 0 {
+        adj := hc.OomScoreAdj
+        spec.OOMScoreAdj = &adj
+    }
+
+    return spec, nil
+}
+
+// podNamespaceOf convertit un mode d'espace de noms Docker (host, private,
+// ns:<chemin>...). Les espaces de noms du conteneur infra appartiennent au pod.
+func podNamespaceOf(mode string) *podNamespace {
+    if mode == "" || strings.HasPrefix(mode, "container:") {
+        return nil
+    }
+    if path, ok := strings.CutPrefix(mode, "ns:"); ok {
+        return &podNamespace{Mode: "path", Value: path}
+    }
+    nsmode, value, _ := strings.Cut(mode, ":")
+    return &podNamespace{Mode: nsmode, Value: value}
+}
+
+// applySecurityOpts reprend les options de sécurité (label=, apparmor=, seccomp=,
+// no-new-privileges, mask=, unmask=, systempaths=)
+func applySecurityOpts(spec *podSpec, opts []string) {
+    for _, opt := range opts {
+        key, value, ok := strings.Cut(opt, "=")
+        if !ok {
+            // Ancienne syntaxe label:disable, no-new-privileges:true
+            key, value, _ = strings.Cut(opt, ":")
+        }
+        switch key {
+        case "label":
+            spec.SelinuxOpts = append(spec.SelinuxOpts, value)
+        case "apparmor":
+            spec.Apparmor = value
+        case "seccomp":
+            // Docker peut conserver le profil JSON lui-même : seul un chemin
+            // ou "unconfined" est transmissible
+            if !strings.HasPrefix(strings.TrimSpace(value), "{") {
+                spec.Seccomp = value
+            }
+        case "no-new-privileges":
+            spec.NoNewPrivileges = value == "" || value == "true"
+        case "mask":
+            spec.Mask = append(spec.Mask, strings.Split(value, ":")...)
+        case "unmask":
+            spec.Unmask = append(spec.Unmask, strings.Split(value, ":")...)
+        case "systempaths":
+            if value == "unconfined" {
+                spec.Unmask = append(spec.Unmask, "ALL")
+            }
+        }
+    }
+}
+
+// devicePath formate un périphérique en source[:destination[:permissions]]
+func devicePath(d container.DeviceMapping) string {
+    path := d.PathOnHost
+    target := d.PathInContainer
+    if target == "" {
+        target = d.PathOnHost
+    }
+    if target != d.PathOnHost || (d.CgroupPermissions != "" && d.CgroupPermissions != "rwm") {
+        path += ":" + target
+    }
+    if d.CgroupPermissions != "" && d.CgroupPermissions != "rwm" {
+        path += ":" + d.CgroupPermissions
+    }
+    return path
+}
+
+// parseDeviceRule analyse une règle de cgroup de périphériques ("c 1:3 rwm")
+func parseDeviceRule(rule string) (podDeviceRule, error) {
+    fields := strings.Fields(rule)
+    if len(fields) != 3 {
+        return podDeviceRule{}, fmt.Errorf("invalid device cgroup rule %q", rule)
+    }
+    major, minor, ok := strings.Cut(fields[1], ":")
+    if !ok {
+        return podDeviceRule{}, fmt.Errorf("invalid device cgroup rule %q", rule)
+    }
+
+    parsed := podDeviceRule{Allow: true, Type: fields[0], Access: fields[2]}
+    for _, n := range []struct {
+        value string
+        dest  **int64
+    }{{major, &parsed.Major}, {minor, &parsed.Minor}} {
+        if n.value == "*" {
+            continue
+        }
+        v, err := strconv.ParseInt(n.value, 10, 64)
+        if err != nil {
+            return podDeviceRule{}, fmt.Errorf("invalid device cgroup rule %q", rule)
+        }
+        *n.dest = &v
+    }
+    return parsed, nil
+}
+
+// podResourcesOf convertit les limites de ressources Docker au format OCI
+func podResourcesOf(r container.Resources) *podResources {
+    var res podResources
+
+    var mem podMemory
+    if r.Memory > 0 {
+        mem.Limit = &r.Memory
+    }
+    if r.MemoryReservation > 0 {
+        mem.Reservation = &r.MemoryReservation
+    }
+    if r.MemorySwap != 0 {
+        mem.Swap = &r.MemorySwap
+    }
+    if r.MemorySwappiness != nil && *r.MemorySwappiness >= 0 {
+        swappiness := uint64(*r.MemorySwappiness)
+        mem.Swappiness = &swappiness
+    }
+    mem.DisableOOMKiller = r.OomKillDisable
+    if mem != (podMemory{}) {
+        res.Memory = &mem
+    }
+
+    var cpu podCPU
+    if r.CPUShares > 0 {
+        shares := uint64(r.CPUShares)
+        cpu.Shares = &shares
+    }
+    if r.NanoCPUs > 0 {
+        // --cpus : quota sur une période de 100 ms
+        period, quota := uint64(100000), r.NanoCPUs/10000
+        cpu.Period, cpu.Quota = &period, &quota
+    }
+    if r.CPUPeriod > 0 {
+        period := uint64(r.CPUPeriod)
+        cpu.Period = &period
+    }
+    if r.CPUQuota > 0 {
+        cpu.Quota = &r.CPUQuota
+    }
+    if r.CPURealtimePeriod > 0 {
+        period := uint64(r.CPURealtimePeriod)
+        cpu.RealtimePeriod = &period
+    }
+    if r.CPURealtimeRuntime > 0 {
+        cpu.RealtimeRuntime = &r.CPURealtimeRuntime
+    }
+    cpu.Cpus, cpu.Mems = r.CpusetCpus, r.CpusetMems
+    if cpu != (podCPU{}) {
+        res.CPU = &cpu
+    }
+
+    if r.PidsLimit != nil && *r.PidsLimit != 0 {
+        res.Pids = &podPids{Limit: *r.PidsLimit}
+    }
+    if r.BlkioWeight > 0 {
+        res.BlockIO = &podBlockIO{Weight: &r.BlkioWeight}
+    }
+
+    if res == (podResources{}) {
+        return nil
+    }
+    return &res
+}
+
+// envMap convertit une liste KEY=VALUE en map
+func envMap(env []string) map[string]string {
+    m := make(map[string]string, len(env))
+    for _, e := range env {
+        k, v, _ := strings.Cut(e, "=")
+        m[k] = v
+    }
+    return m
+}
+
+// podMounts convertit les binds, montages et tmpfs Docker au format de l'API native
+func podMounts(hostConfig *container.HostConfig) ([]podMount, []podVolume) {
+    var mounts []podMount
+    var volumes []podVolume
+
+    for _, bind := range hostConfig.Binds {
+        parts := strings.Split(bind, ":")
+        if len(parts) < 2 {
+            continue
+        }
+        var options []string
+        if len(parts) > 2 {
+            options = strings.Split(parts[2], ",")
+        }
+        if strings.HasPrefix(parts[0], "/") {
+            mounts = append(mounts, podMount{Type: "bind", Source: parts[0], Destination: parts[1], Options: options})
+        } else {
+            volumes = append(volumes, podVolume{Name: parts[0], Dest: parts[1], Options: options})
+        }
+    }
+
+    for _, m := range hostConfig.Mounts {
+        var options []string
+        if m.ReadOnly {
+            options = append(options, "ro")
+        }
+        switch m.Type {
+        case mount.TypeVolume:
+            volumes = append(volumes, podVolume{Name: m.Source, Dest: m.Target, Options: options})
+        case mount.TypeTmpfs:
+            if m.TmpfsOptions != nil && m.TmpfsOptions.SizeBytes > 0 {
+                options = append(options, "size="+strconv.FormatInt(m.TmpfsOptions.SizeBytes, 10))
+            }
+            mounts = append(mounts, podMount{Type: "tmpfs", Source: "tmpfs", Destination: m.Target, Options: options})
+        default:
+            mounts = append(mounts, podMount{Type: string(m.Type), Source: m.Source, Destination: m.Target, Options: options})
+        }
+    }
+
+    targets := make([]string, 0, len(hostConfig.Tmpfs))
+    for target := range hostConfig.Tmpfs {
+        targets = append(targets, target)
+    }
+    sort.Strings(targets)
+    for _, target := range targets {
+        var options []string
+        if opts := hostConfig.Tmpfs[target]; opts != "" {
+            options = strings.Split(opts, ",")
+        }
+        mounts = append(mounts, podMount{Type: "tmpfs", Source: "tmpfs", Destination: target, Options: options})
+    }
+
+    return mounts, volumes
+}
+
+// cleanEndpoints retire les champs d'exécution des endpoints réseau inspectés,
+// que Podman refuse à la création d'un conteneur
+func cleanEndpoints(networkConfig *network.NetworkingConfig) {
+    if networkConfig == nil {
+        return
+    }
+    for name, ep := range networkConfig.EndpointsConfig {
+        if ep == nil {
+            continue
+        }
+        networkConfig.EndpointsConfig[name] = &network.EndpointSettings{
+            IPAMConfig: ep.IPAMConfig,
+            Links:      ep.Links,
+            Aliases:    ep.Aliases,
+            DriverOpts: ep.DriverOpts,
+        }
+    }
+}
@@ -0,0 +1,323 @@
+// internal/docker/podman_test.go
+package docker
+
+import (
+    "context"
+    "encoding/json"
+    "io"
+    "net/http"
+    "net/http/httptest"
+    "reflect"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/docker/docker/api/types"
+    "github.com/docker/docker/api/types/container"
+    "github.com/docker/docker/api/types/mount"
+    "github.com/docker/docker/api/types/network"
+    "github.com/docker/go-connections/nat"
+    "github.com/sirupsen/logrus"
+
+    "zockimate/internal/hosts"
+)
+
+func TestNewClientDetectsPodman(t *testing.T) {
+    tests := []struct {
+        name    string
+        version types.Version
+        podman  bool
+    }{
+        {"docker", types.Version{Version: "27.1.1", Platform: struct{ Name string }{"Docker Engine - Community"}}, false},
+        {"podman platform", types.Version{Version: "5.2.2", Platform: struct{ Name string }{"Podman Engine"}}, true},
+        {"podman component", types.Version{Version: "4.9.4", Components: []types.ComponentVersion{{Name: "Podman Engine", Version: "4.9.4"}}}, true},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            d := NewFakeDaemon()
+            d.Version = tt.version
+            d.Version.APIVersion = "1.41"
+            srv := httptest.NewServer(d)
+            defer srv.Close()
+
+            logger := logrus.New()
+            logger.SetOutput(io.Discard)
+            host := hosts.Host{Name: "test", Endpoint: "tcp://" + strings.TrimPrefix(srv.URL, "http://")}
+            c, err := NewClient(host, nil, PullOptions{}, logger)
+            if err != nil {
+                t.Fatal(err)
+            }
+            defer c.Close()
+            if c.IsPodman() != tt.podman {
+                t.Errorf("IsPodman() = %v, want %v", c.IsPodman(), tt.podman)
+            }
+        })
+    }
+}
+
+func TestIsReady(t *testing.T) {
+    health := func(status string) *types.Health { return &types.Health{Status: status} }
+    tests := []struct {
+        name   string
+        podman bool
+        state  *types.ContainerState
+        want   bool
+    }{
+        {"no state", false, nil, false},
+        {"running without healthcheck", false, &types.ContainerState{Running: true}, true},
+        {"stopped", false, &types.ContainerState{}, false},
+        {"healthy", false, &types.ContainerState{Running: true, Health: health(types.Healthy)}, true},
+        {"starting", false, &types.ContainerState{Running: true, Health: health(types.Starting)}, false},
+        {"unhealthy", true, &types.ContainerState{Running: true, Health: health(types.Unhealthy)}, false},
+        {"docker empty health", false, &types.ContainerState{Running: true, Health: health("")}, false},
+        {"podman empty health", true, &types.ContainerState{Running: true, Health: health("")}, true},
+        {"podman none health", true, &types.ContainerState{Running: true, Health: health("none")}, true},
+        {"podman stopped without healthcheck", true, &types.ContainerState{Health: health("")}, false},
+    }
+    for _, tt := range tests {
+        c := &Client{podman: tt.podman}
+        if got := c.isReady(tt.state); got != tt.want {
+            t.Errorf("%s: isReady() = %v, want %v", tt.name, got, tt.want)
+        }
+    }
+}
+
+func TestWaitForPodmanContainerWithoutHealthcheck(t *testing.T) {
+    d := NewFakeDaemon()
+    d.Handle("GET /containers/app/json", http.StatusOK, inspectedContainer("app", &types.ContainerState{
+        Running: true,
+        Health:  &types.Health{},
+    }))
+    c := fakeClient(t, d, true)
+
+    if err := c.WaitForContainer(context.Background(), "app", 3*time.Second); err != nil {
+        t.Fatal(err)
+    }
+}
+
+func TestPodOf(t *testing.T) {
+    d := NewFakeDaemon()
+    d.Handle("GET /libpod/containers/app/json", http.StatusOK, libpodInspect{ID: "a1", Pod: "p1"})
+    d.Handle("GET /libpod/containers/solo/json", http.StatusOK, libpodInspect{ID: "s1"})
+    d.Handle("GET /libpod/containers/p1-infra/json", http.StatusOK, libpodInspect{ID: "i1", Pod: "p1", IsInfra: true})
+    c := fakeClient(t, d, true)
+    ctx := context.Background()
+
+    if pod, err := c.podOf(ctx, "app"); err != nil || pod != "p1" {
+        t.Errorf("podOf(app) = %q, %v, want p1", pod, err)
+    }
+    if pod, err := c.podOf(ctx, "solo"); err != nil || pod != "" {
+        t.Errorf("podOf(solo) = %q, %v, want no pod", pod, err)
+    }
+    if _, err := c.podOf(ctx, "p1-infra"); err == nil || !strings.Contains(err.Error(), "infra") {
+        t.Errorf("podOf(infra) error = %v, want an infra container error", err)
+    }
+    if _, err := c.podOf(ctx, "missing"); err == nil {
+        t.Error("podOf(missing) succeeded")
+    }
+}
+
+func TestRecreateContainerInPod(t *testing.T) {
+    d := NewFakeDaemon()
+    d.Handle("GET /containers/app/json", http.StatusOK, inspectedContainer("app", &types.ContainerState{Running: true}))
+    d.Handle("GET /libpod/containers/app/json", http.StatusOK, libpodInspect{ID: "old", Pod: "p1"})
+    d.Handle("POST /containers/app/stop", http.StatusNoContent, nil)
+    d.Handle("DELETE /containers/app", http.StatusNoContent, nil)
+    d.Handle("POST /containers/new/start", http.StatusNoContent, nil)
+
+    var spec map[string]interface{}
+    d.Handlers["POST /libpod/containers/create"] = func(w http.ResponseWriter, r *http.Request) {
+        if err := json.NewDecoder(r.Body).Decode(&spec); err != nil {
+            t.Errorf("invalid spec: %v", err)
+        }
+        writeFakeJSON(w, http.StatusCreated, map[string]string{"Id": "new"})
+    }
+    c := fakeClient(t, d, true)
+
+    stopTimeout := 20
+    pids := int64(100)
+    swappiness := int64(10)
+    config := &container.Config{
+        Image:       "nginx:1.27",
+        Hostname:    "pod-host",
+        Env:         []string{"A=1", "B=x=y"},
+        Cmd:         []string{"nginx", "-g", "daemon off;"},
+        Labels:      map[string]string{"app": "web"},
+        StopTimeout: &stopTimeout,
+        Tty:         true,
+    }
+    hostConfig := &container.HostConfig{
+        Binds:         []string{"/srv/www:/usr/share/nginx/html:ro", "cache:/var/cache/nginx"},
+        Mounts:        []mount.Mount{{Type: mount.TypeTmpfs, Target: "/scratch", TmpfsOptions: &mount.TmpfsOptions{SizeBytes: 1 << 20}}},
+        Tmpfs:         map[string]string{"/run": "rw,size=64m"},
+        NetworkMode:   "container:infra",
+        PortBindings:  nat.PortMap{"80/tcp": {{HostPort: "8080"}}},
+        DNS:           []string{"1.1.1.1"},
+        ExtraHosts:    []string{"db:10.0.0.2"},
+        IpcMode:       "container:infra",
+        UTSMode:       "container:infra",
+        PidMode:       "host",
+        ShmSize:       64 << 20,
+        RestartPolicy: container.RestartPolicy{Name: container.RestartPolicyOnFailure, MaximumRetryCount: 3},
+        LogConfig:     container.LogConfig{Type: "journald", Config: map[string]string{"tag": "web"}},
+        CapAdd:        []string{"NET_ADMIN"},
+        CapDrop:       []string{"MKNOD"},
+        SecurityOpt:   []string{"label=disable", "seccomp=unconfined", "apparmor=web", "no-new-privileges:true"},
+        Sysctls:       map[string]string{"kernel.msgmax": "65536"},
+        GroupAdd:      []string{"video"},
+        OomScoreAdj:   -500,
+        ReadonlyRootfs: true,
+        Resources: container.Resources{
+            Memory:            512 << 20,
+            MemorySwappiness:  &swappiness,
+            NanoCPUs:          1500000000,
+            PidsLimit:         &pids,
+            Devices:           []container.DeviceMapping{{PathOnHost: "/dev/fuse", PathInContainer: "/dev/fuse", CgroupPermissions: "rwm"}, {PathOnHost: "/dev/sda", PathInContainer: "/dev/xvda", CgroupPermissions: "r"}},
+            DeviceCgroupRules: []string{"c 10:* rwm"},
+            Ulimits:           []*container.Ulimit{{Name: "nofile", Soft: 1024, Hard: 4096}},
+        },
+    }
+
+    if err := c.RecreateContainer(context.Background(), "app", config, hostConfig, nil); err != nil {
+        t.Fatal(err)
+    }
+    if d.Called("POST /containers/create") {
+        t.Error("pod member created through the Docker-compatible API")
+    }
+    if !d.Called("DELETE /containers/app") || !d.Called("POST /containers/new/start") {
+        t.Errorf("container not replaced: requests %v", d.Requests)
+    }
+
+    expect := func(key string, want interface{}) {
+        t.Helper()
+        wantJSON, _ := json.Marshal(want)
+        var normalized interface{}
+        json.Unmarshal(wantJSON, &normalized)
+        if !reflect.DeepEqual(spec[key], normalized) {
+            got, _ := json.Marshal(spec[key])
+            t.Errorf("%s = %s, want %s", key, got, wantJSON)
+        }
+    }
+    expect("name", "app")
+    expect("pod", "p1")
+    expect("image", "nginx:1.27")
+    expect("env", map[string]string{"A": "1", "B": "x=y"})
+    expect("command", []string{"nginx", "-g", "daemon off;"})
+    expect("terminal", true)
+    expect("stop_timeout", 20)
+    expect("restart_policy", "on-failure")
+    expect("restart_tries", 3)
+    expect("log_configuration", map[string]interface{}{"driver": "journald", "options": map[string]string{"tag": "web"}})
+    expect("mounts", []podMount{
+        {Type: "bind", Source: "/srv/www", Destination: "/usr/share/nginx/html", Options: []string{"ro"}},
+        {Type: "tmpfs", Source: "tmpfs", Destination: "/scratch", Options: []string{"size=1048576"}},
+        {Type: "tmpfs", Source: "tmpfs", Destination: "/run", Options: []string{"rw", "size=64m"}},
+    })
+    expect("volumes", []podVolume{{Name: "cache", Dest: "/var/cache/nginx"}})
+    expect("cap_add", []string{"NET_ADMIN"})
+    expect("cap_drop", []string{"MKNOD"})
+    expect("selinux_opts", []string{"disable"})
+    expect("seccomp_profile_path", "unconfined")
+    expect("apparmor_profile", "web")
+    expect("no_new_privileges", true)
+    expect("read_only_filesystem", true)
+    expect("groups", []string{"video"})
+    expect("sysctl", map[string]string{"kernel.msgmax": "65536"})
+    expect("oom_score_adj", -500)
+    expect("devices", []podDevice{{Path: "/dev/fuse"}, {Path: "/dev/sda:/dev/xvda:r"}})
+    expect("device_cgroup_rule", []map[string]interface{}{{"allow": true, "type": "c", "major": 10, "access": "rwm"}})
+    expect("r_limits", []podRlimit{{Type: "RLIMIT_NOFILE", Soft: 1024, Hard: 4096}})
+    expect("resource_limits", map[string]interface{}{
+        "memory": map[string]interface{}{"limit": 512 << 20, "swappiness": 10},
+        "cpu":    map[string]interface{}{"quota": 150000, "period": 100000},
+        "pids":   map[string]interface{}{"limit": 100},
+    })
+    expect("pidns", podNamespace{Mode: "host"})
+
+    // Champs appartenant au pod
+    for _, key := range []string{"hostname", "portmappings", "dns_server", "hostadd", "netns", "ipcns", "utsns", "shm_size"} {
+        if _, found := spec[key]; found {
+            t.Errorf("pod-owned field %s sent: %v", key, spec[key])
+        }
+    }
+}
+
+func TestRecreateContainerRefusesPodInfra(t *testing.T) {
+    d := NewFakeDaemon()
+    d.Handle("GET /containers/p1-infra/json", http.StatusOK, inspectedContainer("p1-infra", &types.ContainerState{Running: true}))
+    d.Handle("GET /libpod/containers/p1-infra/json", http.StatusOK, libpodInspect{ID: "i1", Pod: "p1", IsInfra: true})
+    c := fakeClient(t, d, true)
+
+    err := c.RecreateContainer(context.Background(), "p1-infra", &container.Config{Image: "pause"}, &container.HostConfig{}, nil)
+    if err == nil {
+        t.Fatal("infra container recreated")
+    }
+    if d.Called("POST /containers/p1-infra/stop") {
+        t.Error("infra container stopped")
+    }
+}
+
+func TestRecreatePodmanContainerOutsidePod(t *testing.T) {
+    d := NewFakeDaemon()
+    d.Handle("GET /containers/app/json", http.StatusOK, inspectedContainer("app", &types.ContainerState{Running: true}))
+    d.Handle("GET /libpod/containers/app/json", http.StatusOK, libpodInspect{ID: "old"})
+    d.Handle("POST /containers/app/stop", http.StatusNoContent, nil)
+    d.Handle("DELETE /containers/app", http.StatusNoContent, nil)
+    d.Handle("POST /containers/new/start", http.StatusNoContent, nil)
+
+    var body struct {
+        NetworkingConfig network.NetworkingConfig
+    }
+    d.Handlers["POST /containers/create"] = func(w http.ResponseWriter, r *http.Request) {
+        if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+            t.Errorf("invalid create request: %v", err)
+        }
+        writeFakeJSON(w, http.StatusCreated, container.CreateResponse{ID: "new"})
+    }
+    c := fakeClient(t, d, true)
+
+    networkConfig := &network.NetworkingConfig{EndpointsConfig: map[string]*network.EndpointSettings{
+        "web": {Aliases: []string{"app"}, NetworkID: "n1", EndpointID: "e1", IPAddress: "10.88.0.5", MacAddress: "aa:bb:cc:dd:ee:ff"},
+    }}
+    if err := c.RecreateContainer(context.Background(), "app", &container.Config{Image: "nginx"}, &container.HostConfig{}, networkConfig); err != nil {
+        t.Fatal(err)
+    }
+    if d.Called("POST /libpod/containers/create") {
+        t.Error("container outside a pod created through the native API")
+    }
+
+    ep := body.NetworkingConfig.EndpointsConfig["web"]
+    if ep == nil || len(ep.Aliases) != 1 || ep.Aliases[0] != "app" {
+        t.Fatalf("endpoint = %+v, want the aliases kept", ep)
+    }
+    if ep.EndpointID != "" || ep.IPAddress != "" || ep.NetworkID != "" || ep.MacAddress != "" {
+        t.Errorf("runtime endpoint fields sent: %+v", ep)
+    }
+}
+
+func TestPodNamespaceOf(t *testing.T) {
+    tests := []struct {
+        mode string
+        want *podNamespace
+    }{
+        {"", nil},
+        {"container:infra", nil},
+        {"host", &podNamespace{Mode: "host"}},
+        {"private", &podNamespace{Mode: "private"}},
+        {"ns:/proc/1/ns/pid", &podNamespace{Mode: "path", Value: "/proc/1/ns/pid"}},
+        {"keep-id:uid=1000", &podNamespace{Mode: "keep-id", Value: "uid=1000"}},
+    }
+    for _, tt := range tests {
+        if got := podNamespaceOf(tt.mode); !reflect.DeepEqual(got, tt.want) {
+            t.Errorf("podNamespaceOf(%q) = %+v, want %+v", tt.mode, got, tt.want)
+        }
+    }
+}
+
+// inspectedContainer retourne l'inspection compatible Docker d'un conteneur
+func inspectedContainer(name string, state *types.ContainerState) types.ContainerJSON {
+    return types.ContainerJSON{
+        ContainerJSONBase: &types.ContainerJSONBase{ID: name + "-id", Name: "/" + name, State: state},
+        Config:            &container.Config{Image: "image"},
+    }
+}
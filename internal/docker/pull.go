@@ -0,0 +1,182 @@
+// internal/docker/pull.go
+package docker
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "io"
+    "os"
+    "strings"
+    "time"
+
+    "github.com/docker/docker/api/types/image"
+    "github.com/docker/docker/errdefs"
+    "github.com/docker/docker/pkg/jsonmessage"
+    "github.com/docker/go-units"
+    "github.com/moby/term"
+)
+
+const (
+    // Nombre de tentatives supplémentaires par défaut en cas d'erreur transitoire
+    DefaultPullRetries = 3
+    // Délai initial entre deux tentatives (doublé à chaque essai)
+    pullBackoff = 2 * time.Second
+    // Intervalle entre deux lignes de progression hors terminal
+    pullLogInterval = 10 * time.Second
+)
+
+// Erreurs de registre définitives, inutile de réessayer
+var permanentPullErrors = []string{
+    "manifest unknown",
+    "not found",
+    "unauthorized",
+    "denied",
+    "no matching manifest",
+    "invalid reference format",
+}
+
+// PullImage télécharge une image avec retry.
+// Les couches déjà téléchargées sont conservées par le démon : une nouvelle
+// tentative reprend là où la précédente s'est arrêtée.
+func (c *Client) PullImage(ctx context.Context, ref string) error {
+    c.logger.Debugf("Starting pull for image: %s", ref)
+
+    auth, err := c.registryAuth(ref)
+    if err != nil {
+        return fmt.Errorf("failed to resolve registry credentials: %w", err)
+    }
+
+    var lastErr error
+    for attempt := 0; attempt <= c.pullRetries; attempt++ {
+        if attempt > 0 {
+            delay := pullBackoff << (attempt - 1)
+            c.logger.Warnf("Pull of %s failed: %v (retry %d/%d in %s)",
+                ref, lastErr, attempt, c.pullRetries, delay)
+            select {
+            case <-ctx.Done():
+                return fmt.Errorf("pull failed: %w", lastErr)
+            case <-time.After(delay):
+            }
+        }
+
+        lastErr = c.pullOnce(ctx, ref, auth)
+        if lastErr == nil {
+            c.logger.Debug("Pull completed successfully")
+            return nil
+        }
+        if !isTransientPullError(ctx, lastErr) {
+            break
+        }
+    }
+
+    c.logger.Debugf("Pull failed with error: %v", lastErr)
+    return fmt.Errorf("pull failed: %w", lastErr)
+}
+
+// pullOnce effectue une tentative de pull et consomme le flux de progression.
+// Avec un plafond de débit, les couches sont d'abord téléchargées par zockimate.
+func (c *Client) pullOnce(ctx context.Context, ref, auth string) error {
+    if c.limiter != nil {
+        if err := c.prefetchImage(ctx, ref); err != nil {
+            return err
+        }
+    }
+
+    reader, err := c.cli.ImagePull(ctx, ref, image.PullOptions{RegistryAuth: auth})
+    if err != nil {
+        return err
+    }
+    defer reader.Close()
+
+    // Terminal : barres de progression par couche, comme la CLI Docker
+    if fd, isTerminal := term.GetFdInfo(os.Stderr); isTerminal {
+        return jsonmessage.DisplayJSONMessagesStream(reader, os.Stderr, fd, true, nil)
+    }
+
+    return c.logPullProgress(ref, reader)
+}
+
+// logPullProgress décode le flux de progression et journalise périodiquement l'avancement
+func (c *Client) logPullProgress(ref string, reader io.Reader) error {
+    type layer struct {
+        current, total int64
+        done           bool
+    }
+    layers := make(map[string]*layer)
+    lastLog := time.Now()
+
+    dec := json.NewDecoder(reader)
+    for {
+        var msg jsonmessage.JSONMessage
+        if err := dec.Decode(&msg); err != nil {
+            if err == io.EOF {
+                return nil
+            }
+            return fmt.Errorf("error reading pull response: %w", err)
+        }
+
+        // Erreur signalée dans le flux (le code HTTP était 200)
+        if msg.Error != nil {
+            return msg.Error
+        }
+        if msg.ErrorMessage != "" {
+            return errors.New(msg.ErrorMessage)
+        }
+
+        if msg.ID == "" {
+            continue
+        }
+        l, ok := layers[msg.ID]
+        if !ok {
+            l = &layer{}
+            layers[msg.ID] = l
+        }
+        switch msg.Status {
+        case "Downloading":
+            if msg.Progress != nil {
+                l.current, l.total = msg.Progress.Current, msg.Progress.Total
+            }
+        case "Download complete", "Pull complete", "Already exists":
+            l.current = l.total
+            l.done = true
+        }
+
+        if time.Since(lastLog) < pullLogInterval {
+            continue
+        }
+        lastLog = time.Now()
+
+        var current, total int64
+        var done int
+        for _, l := range layers {
+            current += l.current
+            total += l.total
+            if l.done {
+                done++
+            }
+        }
+        c.logger.Infof("Pulling %s: %s / %s, %d/%d layers complete",
+            ref, units.HumanSize(float64(current)), units.HumanSize(float64(total)), done, len(layers))
+    }
+}
+
+// isTransientPullError indique si une nouvelle tentative de pull peut réussir
+func isTransientPullError(ctx context.Context, err error) bool {
+    if ctx.Err() != nil {
+        return false
+    }
+    if errdefs.IsNotFound(err) || errdefs.IsUnauthorized(err) ||
+        errdefs.IsForbidden(err) || errdefs.IsInvalidParameter(err) {
+        return false
+    }
+
+    msg := strings.ToLower(err.Error())
+    for _, permanent := range permanentPullErrors {
+        if strings.Contains(msg, permanent) {
+            return false
+        }
+    }
+    return true
+}
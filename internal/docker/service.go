@@ -0,0 +1,135 @@
+// internal/docker/service.go
+package docker
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "time"
+
+    "github.com/docker/docker/api/types"
+    "github.com/docker/docker/api/types/swarm"
+)
+
+// ListServices liste les services Swarm (vide si le démon n'est pas un manager Swarm)
+func (c *Client) ListServices(ctx context.Context) ([]swarm.Service, error) {
+    info, err := c.cli.Info(ctx)
+    if err != nil {
+        return nil, fmt.Errorf("failed to get daemon info: %w", err)
+    }
+    if !info.Swarm.ControlAvailable {
+        return nil, nil
+    }
+    return c.cli.ServiceList(ctx, types.ServiceListOptions{})
+}
+
+// InspectService inspecte un service Swarm
+func (c *Client) InspectService(ctx context.Context, name string) (swarm.Service, error) {
+    service, _, err := c.cli.ServiceInspectWithRaw(ctx, name, types.ServiceInspectOptions{})
+    if err != nil {
+        return swarm.Service{}, fmt.Errorf("failed to inspect service: %w", err)
+    }
+    return service, nil
+}
+
+// RegistryDigest retourne le digest de manifeste d'une image dans son registre, sans la télécharger
+func (c *Client) RegistryDigest(ctx context.Context, ref string) (string, error) {
+    auth, err := c.registryAuth(ref)
+    if err != nil {
+        return "", fmt.Errorf("failed to resolve registry credentials: %w", err)
+    }
+    inspect, err := c.cli.DistributionInspect(ctx, ref, auth)
+    if err != nil {
+        return "", fmt.Errorf("failed to query registry for %s: %w", ref, err)
+    }
+    return inspect.Descriptor.Digest.String(), nil
+}
+
+// UpdateService applique une nouvelle spécification à un service
+func (c *Client) UpdateService(ctx context.Context, service swarm.Service, spec swarm.ServiceSpec) error {
+    auth, err := c.registryAuth(spec.TaskTemplate.ContainerSpec.Image)
+    if err != nil {
+        return fmt.Errorf("failed to resolve registry credentials: %w", err)
+    }
+
+    resp, err := c.cli.ServiceUpdate(ctx, service.ID, service.Version, spec, types.ServiceUpdateOptions{
+        EncodedRegistryAuth: auth,
+        QueryRegistry:       false, // L'image est déjà épinglée par digest
+    })
+    if err != nil {
+        return fmt.Errorf("failed to update service: %w", err)
+    }
+    for _, warning := range resp.Warnings {
+        c.logger.Warnf("Service %s: %s", service.Spec.Name, warning)
+    }
+    return nil
+}
+
+// WaitForService attend la fin de la mise à jour progressive d'un service.
+// previous est l'état de mise à jour avant l'appel à UpdateService.
+func (c *Client) WaitForService(ctx context.Context, name string, previous *swarm.UpdateStatus, timeout time.Duration) error {
+    ctx, cancel := context.WithTimeout(ctx, timeout)
+    defer cancel()
+
+    ticker := time.NewTicker(2 * time.Second)
+    defer ticker.Stop()
+
+    for {
+        select {
+        case <-ctx.Done():
+            return fmt.Errorf("timeout waiting for service update to complete")
+        case <-ticker.C:
+            service, err := c.InspectService(ctx, name)
+            if err != nil {
+                return err
+            }
+
+            status := service.UpdateStatus
+            if status == nil || sameUpdate(status, previous) {
+                // Pas encore de nouvelle mise à jour progressive : vérifier que rien n'est à faire
+                if status == nil && service.Spec.Mode.Replicated != nil &&
+                    service.Spec.Mode.Replicated.Replicas != nil && *service.Spec.Mode.Replicated.Replicas == 0 {
+                    return nil
+                }
+                continue
+            }
+
+            switch status.State {
+            case swarm.UpdateStateCompleted:
+                return nil
+            case swarm.UpdateStatePaused, swarm.UpdateStateRollbackStarted,
+                swarm.UpdateStateRollbackPaused, swarm.UpdateStateRollbackCompleted:
+                return fmt.Errorf("service update %s: %s", status.State, status.Message)
+            }
+        }
+    }
+}
+
+// sameUpdate indique si deux états correspondent à la même mise à jour progressive
+func sameUpdate(a, b *swarm.UpdateStatus) bool {
+    if a == nil || b == nil {
+        return a == b
+    }
+    if a.StartedAt == nil || b.StartedAt == nil {
+        return a.StartedAt == b.StartedAt
+    }
+    return a.StartedAt.Equal(*b.StartedAt)
+}
+
+// MarshalServiceSpec sérialise la spécification d'un service
+func (c *Client) MarshalServiceSpec(spec swarm.ServiceSpec) ([]byte, error) {
+    data, err := json.Marshal(spec)
+    if err != nil {
+        return nil, fmt.Errorf("failed to marshal service spec: %w", err)
+    }
+    return data, nil
+}
+
+// UnmarshalServiceSpec désérialise la spécification d'un service
+func (c *Client) UnmarshalServiceSpec(data []byte) (swarm.ServiceSpec, error) {
+    var spec swarm.ServiceSpec
+    if err := json.Unmarshal(data, &spec); err != nil {
+        return swarm.ServiceSpec{}, fmt.Errorf("failed to unmarshal service spec: %w", err)
+    }
+    return spec, nil
+}
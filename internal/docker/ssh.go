@@ -0,0 +1,90 @@
+// internal/docker/ssh.go
+package docker
+
+import (
+    "context"
+    "fmt"
+    "io"
+    "net"
+    "net/url"
+    "os/exec"
+    "sync"
+    "time"
+)
+
+// sshDialer retourne une fonction de connexion au démon Docker distant via
+// "ssh <cible> docker system dial-stdio" (comme la CLI Docker)
+func sshDialer(endpoint string) (func(ctx context.Context, network, addr string) (net.Conn, error), error) {
+    u, err := url.Parse(endpoint)
+    if err != nil {
+        return nil, fmt.Errorf("invalid ssh endpoint %q: %w", endpoint, err)
+    }
+    if u.Hostname() == "" {
+        return nil, fmt.Errorf("invalid ssh endpoint %q: missing host", endpoint)
+    }
+
+    args := []string{"-o", "BatchMode=yes", "-o", "ConnectTimeout=30"}
+    if port := u.Port(); port != "" {
+        args = append(args, "-p", port)
+    }
+    target := u.Hostname()
+    if u.User != nil {
+        target = u.User.Username() + "@" + target
+    }
+    args = append(args, "--", target, "docker", "system", "dial-stdio")
+
+    return func(ctx context.Context, network, addr string) (net.Conn, error) {
+        // Le processus doit survivre au contexte de connexion
+        cmd := exec.Command("ssh", args...)
+        stdin, err := cmd.StdinPipe()
+        if err != nil {
+            return nil, err
+        }
+        stdout, err := cmd.StdoutPipe()
+        if err != nil {
+            return nil, err
+        }
+        if err := cmd.Start(); err != nil {
+            return nil, fmt.Errorf("failed to start ssh to %s: %w", target, err)
+        }
+        return &commandConn{cmd: cmd, stdin: stdin, stdout: stdout, remote: target}, nil
+    }, nil
+}
+
+// commandConn expose les entrées/sorties d'un processus comme une net.Conn
+type commandConn struct {
+    cmd    *exec.Cmd
+    stdin  io.WriteCloser
+    stdout io.ReadCloser
+    remote string
+    once   sync.Once
+}
+
+func (c *commandConn) Read(p []byte) (int, error)  { return c.stdout.Read(p) }
+func (c *commandConn) Write(p []byte) (int, error) { return c.stdin.Write(p) }
+
+// Close termine le processus ssh
+func (c *commandConn) Close() error {
+    c.once.Do(func() {
+        c.stdin.Close()
+        if c.cmd.Process != nil {
+            c.cmd.Process.Kill()
+        }
+        c.cmd.Wait() //nolint:errcheck
+    })
+    return nil
+}
+
+func (c *commandConn) LocalAddr() net.Addr  { return commandAddr("zockimate") }
+func (c *commandConn) RemoteAddr() net.Addr { return commandAddr(c.remote) }
+
+// Les délais ne sont pas supportés sur des pipes de processus
+func (c *commandConn) SetDeadline(t time.Time) error      { return nil }
+func (c *commandConn) SetReadDeadline(t time.Time) error  { return nil }
+func (c *commandConn) SetWriteDeadline(t time.Time) error { return nil }
+
+// commandAddr est l'adresse factice d'une commandConn
+type commandAddr string
+
+func (a commandAddr) Network() string { return "ssh" }
+func (a commandAddr) String() string  { return string(a) }
@@ -0,0 +1,353 @@
+// internal/docker/throttle.go
+package docker
+
+import (
+    "archive/tar"
+    "context"
+    "crypto/sha256"
+    "encoding/hex"
+    "encoding/json"
+    "fmt"
+    "io"
+    "strings"
+    "time"
+
+    "github.com/distribution/reference"
+    "github.com/docker/go-units"
+    "golang.org/x/time/rate"
+
+    "zockimate/internal/registry"
+)
+
+// Taille maximale d'une lecture limitée (rafale du limiteur)
+const throttleChunk = 64 << 10
+
+// Le démon télécharge lui-même les couches d'un pull et l'API n'offre aucune
+// limite de débit ; lire lentement le flux de progression ne le ralentit pas.
+// Avec un plafond, zockimate télécharge donc lui-même les couches depuis le
+// registre au débit demandé et les transmet au démon par docker load (sans
+// limite : flux local ou SSH). Le pull qui suit trouve l'image déjà présente
+// et ne transfère plus que le manifeste et la configuration, en posant le tag
+// et le digest du dépôt comme un pull ordinaire.
+
+// newLimiter crée le limiteur de débit d'un hôte (nil sans plafond)
+func newLimiter(bandwidth int64) *rate.Limiter {
+    if bandwidth <= 0 {
+        return nil
+    }
+    burst := int64(throttleChunk)
+    if bandwidth < burst {
+        burst = bandwidth
+    }
+    return rate.NewLimiter(rate.Limit(bandwidth), int(burst))
+}
+
+// throttledReader limite le débit de lecture d'un flux
+type throttledReader struct {
+    ctx     context.Context
+    r       io.Reader
+    limiter *rate.Limiter
+}
+
+func (t *throttledReader) Read(p []byte) (int, error) {
+    if burst := t.limiter.Burst(); len(p) > burst {
+        p = p[:burst]
+    }
+    n, err := t.r.Read(p)
+    if n > 0 {
+        if werr := t.limiter.WaitN(t.ctx, n); werr != nil {
+            return n, werr
+        }
+    }
+    return n, err
+}
+
+// imageConfig contient les champs utiles de la configuration d'une image
+type imageConfig struct {
+    OS     string `json:"os"`
+    RootFS struct {
+        DiffIDs []string `json:"diff_ids"`
+    } `json:"rootfs"`
+}
+
+// loadManifest est l'entrée manifest.json d'une archive docker save
+type loadManifest struct {
+    Config   string
+    RepoTags []string
+    Layers   []string
+}
+
+// prefetchImage télécharge au débit limité les couches d'une image absente du
+// démon et les charge par docker load
+func (c *Client) prefetchImage(ctx context.Context, ref string) error {
+    named, err := reference.ParseNormalizedNamed(ref)
+    if err != nil {
+        return fmt.Errorf("invalid image reference %q: %w", ref, err)
+    }
+    named = reference.TagNameOnly(named)
+    repo := registry.Repository{Domain: reference.Domain(named), Path: reference.Path(named)}
+
+    target := ""
+    var tags []string
+    if digested, ok := named.(reference.Digested); ok {
+        target = digested.Digest().String()
+    } else if tagged, ok := named.(reference.Tagged); ok {
+        target = tagged.Tag()
+        tags = []string{reference.FamiliarString(named)}
+    }
+
+    manifest, digests, err := c.platformManifest(ctx, repo, target)
+    if err != nil {
+        return err
+    }
+
+    // Image déjà présente : rien à télécharger
+    if c.imagePresent(ctx, ref, manifest.Config.Digest, digests) {
+        c.logger.Debugf("Image %s already present", ref)
+        return nil
+    }
+
+    configData, err := c.registry.GetBlob(ctx, repo, manifest.Config.Digest)
+    if err != nil {
+        return fmt.Errorf("failed to fetch image config: %w", err)
+    }
+    var config imageConfig
+    if err := json.Unmarshal(configData, &config); err != nil {
+        return fmt.Errorf("invalid image config: %w", err)
+    }
+    if len(config.RootFS.DiffIDs) != len(manifest.Layers) {
+        return fmt.Errorf("invalid image %s: %d layers for %d diff IDs",
+            ref, len(manifest.Layers), len(config.RootFS.DiffIDs))
+    }
+    for _, layer := range manifest.Layers {
+        if strings.Contains(layer.MediaType, "foreign") || strings.Contains(layer.MediaType, "nondistributable") {
+            return fmt.Errorf("image %s has non-distributable layers", ref)
+        }
+    }
+
+    present := c.presentLayers(ctx, ref, config.RootFS.DiffIDs)
+
+    var total int64
+    for i, layer := range manifest.Layers {
+        if !present[i] {
+            total += layer.Size
+        }
+    }
+    c.logger.Infof("Downloading %s of %s at most %s/s", units.HumanSize(float64(total)),
+        reference.FamiliarString(named), units.HumanSize(float64(c.limiter.Limit())))
+
+    // L'archive est produite au fil du chargement ; une erreur de téléchargement
+    // interrompt le chargement et prime sur celle du démon
+    pr, pw := io.Pipe()
+    written := make(chan error, 1)
+    go func() {
+        err := c.writeLoadArchive(ctx, pw, repo, manifest, configData, tags, present)
+        pw.CloseWithError(err)
+        written <- err
+    }()
+    err = c.LoadImage(ctx, pr)
+    pr.CloseWithError(io.ErrClosedPipe)
+    if werr := <-written; werr != nil && werr != io.ErrClosedPipe {
+        return werr
+    }
+    return err
+}
+
+// platformManifest récupère le manifeste d'image de la plateforme du démon, avec
+// les digests de l'index et du manifeste
+func (c *Client) platformManifest(ctx context.Context, repo registry.Repository, target string) (*registry.Manifest, []string, error) {
+    manifest, digest, err := c.registry.GetManifest(ctx, repo, target)
+    if err != nil {
+        return nil, nil, fmt.Errorf("failed to fetch manifest: %w", err)
+    }
+    digests := []string{digest}
+    if manifest.MediaType != registry.MediaTypeOCIIndex && manifest.MediaType != registry.MediaTypeDockerList {
+        return manifest, digests, nil
+    }
+
+    version, err := c.cli.ServerVersion(ctx)
+    if err != nil {
+        return nil, nil, fmt.Errorf("failed to get daemon platform: %w", err)
+    }
+    desc, ok := selectPlatform(manifest.Manifests, version.Os, version.Arch)
+    if !ok {
+        return nil, nil, fmt.Errorf("no image for platform %s/%s in %s:%s", version.Os, version.Arch, repo, target)
+    }
+    manifest, _, err = c.registry.GetManifest(ctx, repo, desc.Digest)
+    if err != nil {
+        return nil, nil, fmt.Errorf("failed to fetch manifest: %w", err)
+    }
+    return manifest, append(digests, desc.Digest), nil
+}
+
+// imagePresent indique si l'image désignée par ref sur le démon a pour ID ou digest
+// de dépôt l'un des digests de l'index, du manifeste ou de la configuration (selon
+// le magasin d'images), ou si une image a pour ID le digest de la configuration
+func (c *Client) imagePresent(ctx context.Context, ref, configDigest string, digests []string) bool {
+    known := map[string]bool{configDigest: true}
+    for _, d := range digests {
+        if d != "" {
+            known[d] = true
+        }
+    }
+
+    if inspect, _, err := c.cli.ImageInspectWithRaw(ctx, ref); err == nil {
+        if known[inspect.ID] {
+            return true
+        }
+        for _, repoDigest := range inspect.RepoDigests {
+            if _, d, ok := strings.Cut(repoDigest, "@"); ok && known[d] {
+                return true
+            }
+        }
+    }
+    _, _, err := c.cli.ImageInspectWithRaw(ctx, configDigest)
+    return err == nil
+}
+
+// selectPlatform choisit l'entrée d'un index correspondant à la plateforme,
+// en préférant la variante par défaut de l'architecture (v8 pour arm64, v7 pour arm)
+func selectPlatform(manifests []registry.Descriptor, os, arch string) (registry.Descriptor, bool) {
+    defaultVariant := map[string]string{"arm64": "v8", "arm": "v7"}[arch]
+
+    var match registry.Descriptor
+    found := false
+    for _, m := range manifests {
+        if m.Platform == nil || m.Platform.OS != os || m.Platform.Architecture != arch {
+            continue
+        }
+        if m.Platform.Variant == "" || m.Platform.Variant == defaultVariant {
+            return m, true
+        }
+        if !found {
+            match, found = m, true
+        }
+    }
+    return match, found
+}
+
+// presentLayers indique les couches que docker load n'aura pas à lire : celles
+// dont la chaîne existe déjà, d'après l'image actuellement désignée par ref.
+// Seul le stockage classique du démon Docker ignore ces couches ; avec le
+// magasin containerd ou Podman, toutes les couches sont téléchargées.
+func (c *Client) presentLayers(ctx context.Context, ref string, diffIDs []string) []bool {
+    present := make([]bool, len(diffIDs))
+    if c.podman {
+        return present
+    }
+    info, err := c.cli.Info(ctx)
+    if err != nil {
+        return present
+    }
+    for _, status := range info.DriverStatus {
+        if status[0] == "driver-type" && strings.Contains(status[1], "containerd") {
+            return present
+        }
+    }
+
+    current, _, err := c.cli.ImageInspectWithRaw(ctx, ref)
+    if err != nil {
+        return present
+    }
+    for i := range diffIDs {
+        if i >= len(current.RootFS.Layers) || current.RootFS.Layers[i] != diffIDs[i] {
+            break
+        }
+        present[i] = true
+    }
+    return present
+}
+
+// writeLoadArchive écrit l'archive docker load de l'image : configuration, couches
+// téléchargées au débit limité (vides si déjà présentes) et manifest.json
+func (c *Client) writeLoadArchive(ctx context.Context, w io.Writer, repo registry.Repository,
+    manifest *registry.Manifest, configData []byte, tags []string, present []bool) error {
+
+    tw := tar.NewWriter(w)
+    now := time.Now()
+    writeHeader := func(name string, size int64) error {
+        return tw.WriteHeader(&tar.Header{
+            Typeflag: tar.TypeReg,
+            Name:     name,
+            Size:     size,
+            Mode:     0644,
+            ModTime:  now,
+        })
+    }
+
+    entry := loadManifest{Config: blobPath(manifest.Config.Digest), RepoTags: tags}
+    if err := writeHeader(entry.Config, int64(len(configData))); err != nil {
+        return err
+    }
+    if _, err := tw.Write(configData); err != nil {
+        return err
+    }
+
+    written := make(map[string]bool)
+    for i, layer := range manifest.Layers {
+        name := blobPath(layer.Digest)
+        entry.Layers = append(entry.Layers, name)
+        if written[name] {
+            continue
+        }
+        written[name] = true
+
+        if present[i] {
+            c.logger.Debugf("Layer %s already present", layer.Digest)
+            if err := writeHeader(name, 0); err != nil {
+                return err
+            }
+            continue
+        }
+        if err := c.copyLayer(ctx, tw, writeHeader, repo, layer); err != nil {
+            return err
+        }
+    }
+
+    data, err := json.Marshal([]loadManifest{entry})
+    if err != nil {
+        return err
+    }
+    if err := writeHeader("manifest.json", int64(len(data))); err != nil {
+        return err
+    }
+    if _, err := tw.Write(data); err != nil {
+        return err
+    }
+    return tw.Close()
+}
+
+// copyLayer copie une couche du registre dans l'archive en vérifiant son digest
+func (c *Client) copyLayer(ctx context.Context, tw *tar.Writer, writeHeader func(string, int64) error,
+    repo registry.Repository, layer registry.Descriptor) error {
+
+    algo, expected, _ := strings.Cut(layer.Digest, ":")
+    if algo != "sha256" {
+        return fmt.Errorf("unsupported layer digest %s", layer.Digest)
+    }
+
+    body, err := c.registry.OpenBlob(ctx, repo, layer.Digest)
+    if err != nil {
+        return fmt.Errorf("failed to fetch layer %s: %w", layer.Digest, err)
+    }
+    defer body.Close()
+
+    c.logger.Debugf("Downloading layer %s (%s)", layer.Digest, units.HumanSize(float64(layer.Size)))
+    if err := writeHeader(blobPath(layer.Digest), layer.Size); err != nil {
+        return err
+    }
+    hash := sha256.New()
+    src := &throttledReader{ctx: ctx, r: body, limiter: c.limiter}
+    if _, err := io.CopyN(io.MultiWriter(tw, hash), src, layer.Size); err != nil {
+        return fmt.Errorf("failed to download layer %s: %w", layer.Digest, err)
+    }
+    if actual := hex.EncodeToString(hash.Sum(nil)); actual != expected {
+        return fmt.Errorf("layer %s is corrupted (got sha256:%s)", layer.Digest, actual)
+    }
+    return nil
+}
+
+// blobPath retourne le chemin d'un blob dans l'archive
+func blobPath(digest string) string {
+    return "blobs/" + strings.Replace(digest, ":", "/", 1)
+}
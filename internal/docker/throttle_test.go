@@ -0,0 +1,323 @@
+// internal/docker/throttle_test.go
+package docker
+
+import (
+    "bytes"
+    "context"
+    "crypto/rand"
+    "crypto/sha256"
+    "encoding/hex"
+    "encoding/json"
+    "io"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/docker/docker/api/types"
+
+    "zockimate/internal/registry"
+)
+
+// testRegistry sert les manifestes et blobs d'un dépôt
+type testRegistry struct {
+    addr      string
+    manifests map[string][]byte // Par tag ou digest
+    types     map[string]string // Type de chaque manifeste
+    blobs     map[string][]byte
+}
+
+func newTestRegistry(t *testing.T) *testRegistry {
+    reg := &testRegistry{
+        manifests: make(map[string][]byte),
+        types:     make(map[string]string),
+        blobs:     make(map[string][]byte),
+    }
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        path := strings.TrimPrefix(r.URL.Path, "/v2/team/app/")
+        if ref, ok := strings.CutPrefix(path, "manifests/"); ok {
+            if data, found := reg.manifests[ref]; found {
+                w.Header().Set("Content-Type", reg.types[ref])
+                w.Header().Set("Docker-Content-Digest", sha256Digest(data))
+                w.Write(data)
+                return
+            }
+        }
+        if digest, ok := strings.CutPrefix(path, "blobs/"); ok {
+            if data, found := reg.blobs[digest]; found {
+                w.Write(data)
+                return
+            }
+        }
+        http.NotFound(w, r)
+    }))
+    t.Cleanup(srv.Close)
+    reg.addr = strings.TrimPrefix(srv.URL, "http://")
+    return reg
+}
+
+func (r *testRegistry) putManifest(tag, mediaType string, m registry.Manifest) string {
+    m.SchemaVersion = 2
+    m.MediaType = mediaType
+    data, _ := json.Marshal(m)
+    digest := sha256Digest(data)
+    for _, ref := range []string{digest, tag} {
+        if ref != "" {
+            r.manifests[ref] = data
+            r.types[ref] = mediaType
+        }
+    }
+    return digest
+}
+
+func (r *testRegistry) putBlob(data []byte) registry.Descriptor {
+    digest := sha256Digest(data)
+    r.blobs[digest] = data
+    return registry.Descriptor{Digest: digest, Size: int64(len(data))}
+}
+
+func sha256Digest(data []byte) string {
+    sum := sha256.Sum256(data)
+    return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+func randomBytes(t *testing.T, n int) []byte {
+    data := make([]byte, n)
+    if _, err := rand.Read(data); err != nil {
+        t.Fatal(err)
+    }
+    return data
+}
+
+// testImage est une image à deux couches publiée dans un index multi-architecture
+type testImage struct {
+    ref         string
+    indexDigest string
+    config      registry.Descriptor
+    configData  []byte
+    layers      []registry.Descriptor
+    layerData   [][]byte
+    diffIDs     []string
+}
+
+func pushTestImage(t *testing.T, reg *testRegistry) testImage {
+    img := testImage{ref: reg.addr + "/team/app:1.0"}
+    for i := 0; i < 2; i++ {
+        data := randomBytes(t, 64<<10)
+        layer := reg.putBlob(data)
+        layer.MediaType = "application/vnd.oci.image.layer.v1.tar+gzip"
+        img.layers = append(img.layers, layer)
+        img.layerData = append(img.layerData, data)
+        img.diffIDs = append(img.diffIDs, sha256Digest(append([]byte("diff"), data...)))
+    }
+
+    var config imageConfig
+    config.OS = "linux"
+    config.RootFS.DiffIDs = img.diffIDs
+    img.configData, _ = json.Marshal(config)
+    img.config = reg.putBlob(img.configData)
+    img.config.MediaType = "application/vnd.oci.image.config.v1+json"
+
+    amd64 := reg.putManifest("", registry.MediaTypeOCIManifest, registry.Manifest{Config: img.config, Layers: img.layers})
+    other := reg.putManifest("", registry.MediaTypeOCIManifest, registry.Manifest{
+        Config: reg.putBlob([]byte(`{"rootfs":{"diff_ids":[]}}`)),
+    })
+    img.indexDigest = reg.putManifest("1.0", registry.MediaTypeOCIIndex, registry.Manifest{Manifests: []registry.Descriptor{
+        {MediaType: registry.MediaTypeOCIManifest, Digest: other, Platform: &registry.Platform{OS: "linux", Architecture: "arm64", Variant: "v8"}},
+        {MediaType: registry.MediaTypeOCIManifest, Digest: amd64, Platform: &registry.Platform{OS: "linux", Architecture: "amd64"}},
+    }})
+    return img
+}
+
+// throttledClient retourne un client avec un plafond de débit élevé
+func throttledClient(t *testing.T, d *FakeDaemon, podman bool) *Client {
+    c := fakeClient(t, d, podman)
+    c.limiter = newLimiter(64 << 20)
+    c.registry = registry.NewClient(nil, nil, c.logger)
+    return c
+}
+
+func TestPullWithBandwidthLoadsLayers(t *testing.T) {
+    reg := newTestRegistry(t)
+    img := pushTestImage(t, reg)
+    d := NewFakeDaemon()
+    c := throttledClient(t, d, false)
+
+    if err := c.PullImage(context.Background(), img.ref); err != nil {
+        t.Fatal(err)
+    }
+
+    if len(d.Loads) != 1 {
+        t.Fatalf("%d images loaded, want 1", len(d.Loads))
+    }
+    files := d.Loads[0]
+    var manifest []loadManifest
+    if err := json.Unmarshal(files["manifest.json"], &manifest); err != nil || len(manifest) != 1 {
+        t.Fatalf("invalid manifest.json: %v %s", err, files["manifest.json"])
+    }
+    entry := manifest[0]
+    if !bytes.Equal(files[entry.Config], img.configData) {
+        t.Errorf("config %s not loaded", entry.Config)
+    }
+    if len(entry.RepoTags) != 1 || entry.RepoTags[0] != img.ref {
+        t.Errorf("RepoTags = %v, want [%s]", entry.RepoTags, img.ref)
+    }
+    if len(entry.Layers) != 2 {
+        t.Fatalf("%d layers, want 2", len(entry.Layers))
+    }
+    for i, name := range entry.Layers {
+        if !bytes.Equal(files[name], img.layerData[i]) {
+            t.Errorf("layer %d (%s) does not match the registry blob", i, name)
+        }
+    }
+
+    // Le pull qui suit pose le tag et le digest du dépôt
+    if len(d.Pulls) != 1 || d.Pulls[0] != img.ref {
+        t.Errorf("pulls = %v, want [%s]", d.Pulls, img.ref)
+    }
+}
+
+func TestPullWithBandwidthSkipsPresentLayers(t *testing.T) {
+    reg := newTestRegistry(t)
+    img := pushTestImage(t, reg)
+    d := NewFakeDaemon()
+    c := throttledClient(t, d, false)
+
+    // Version précédente partageant la première couche
+    previous := types.ImageInspect{ID: "sha256:previous"}
+    previous.RootFS.Layers = []string{img.diffIDs[0], sha256Digest([]byte("old"))}
+    d.Images[img.ref] = previous
+
+    if err := c.PullImage(context.Background(), img.ref); err != nil {
+        t.Fatal(err)
+    }
+    files := d.Loads[0]
+    first, second := files[blobPath(img.layers[0].Digest)], files[blobPath(img.layers[1].Digest)]
+    if len(first) != 0 {
+        t.Errorf("shared layer sent with %d bytes, want an empty entry", len(first))
+    }
+    if !bytes.Equal(second, img.layerData[1]) {
+        t.Error("new layer not sent")
+    }
+
+    // Magasin containerd : docker load lit toutes les couches
+    d = NewFakeDaemon()
+    d.Images[img.ref] = previous
+    d.Info.DriverStatus = [][2]string{{"driver-type", "io.containerd.snapshotter.v1"}}
+    c = throttledClient(t, d, false)
+    if err := c.PullImage(context.Background(), img.ref); err != nil {
+        t.Fatal(err)
+    }
+    if first := d.Loads[0][blobPath(img.layers[0].Digest)]; !bytes.Equal(first, img.layerData[0]) {
+        t.Error("containerd store: shared layer not sent")
+    }
+}
+
+func TestPullWithBandwidthSkipsPresentImage(t *testing.T) {
+    reg := newTestRegistry(t)
+    img := pushTestImage(t, reg)
+
+    tests := []struct {
+        name  string
+        image types.ImageInspect
+    }{
+        {"image ID is the config digest", types.ImageInspect{ID: img.config.Digest}},
+        {"repo digest of the index", types.ImageInspect{ID: "sha256:other", RepoDigests: []string{"team/app@" + img.indexDigest}}},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            d := NewFakeDaemon()
+            d.Images[img.ref] = tt.image
+            c := throttledClient(t, d, false)
+            if err := c.PullImage(context.Background(), img.ref); err != nil {
+                t.Fatal(err)
+            }
+            if len(d.Loads) != 0 {
+                t.Error("present image downloaded again")
+            }
+            if len(d.Pulls) != 1 {
+                t.Errorf("pulls = %v, want one pull", d.Pulls)
+            }
+        })
+    }
+}
+
+func TestPullWithBandwidthRejectsCorruptedLayer(t *testing.T) {
+    reg := newTestRegistry(t)
+    img := pushTestImage(t, reg)
+    corrupted := append([]byte(nil), img.layerData[1]...)
+    corrupted[0] ^= 0xff
+    reg.blobs[img.layers[1].Digest] = corrupted
+
+    d := NewFakeDaemon()
+    c := throttledClient(t, d, false)
+    c.pullRetries = 0
+
+    err := c.PullImage(context.Background(), img.ref)
+    if err == nil || !strings.Contains(err.Error(), "corrupted") {
+        t.Fatalf("error = %v, want a corrupted layer error", err)
+    }
+    if len(d.Pulls) != 0 {
+        t.Error("image pulled after a failed download")
+    }
+}
+
+func TestSelectPlatform(t *testing.T) {
+    platform := func(os, arch, variant string) registry.Descriptor {
+        return registry.Descriptor{
+            Digest:   os + "/" + arch + "/" + variant,
+            Platform: &registry.Platform{OS: os, Architecture: arch, Variant: variant},
+        }
+    }
+    manifests := []registry.Descriptor{
+        platform("linux", "arm", "v6"),
+        platform("linux", "arm", "v7"),
+        platform("linux", "arm64", "v8"),
+        platform("windows", "amd64", ""),
+        {Digest: "attestation"},
+    }
+
+    tests := []struct {
+        os, arch string
+        want     string
+        found    bool
+    }{
+        {"linux", "arm", "linux/arm/v7", true},
+        {"linux", "arm64", "linux/arm64/v8", true},
+        {"windows", "amd64", "windows/amd64/", true},
+        {"linux", "amd64", "", false},
+    }
+    for _, tt := range tests {
+        got, found := selectPlatform(manifests, tt.os, tt.arch)
+        if found != tt.found || got.Digest != tt.want {
+            t.Errorf("selectPlatform(%s/%s) = %q %v, want %q %v", tt.os, tt.arch, got.Digest, found, tt.want, tt.found)
+        }
+    }
+}
+
+func TestThrottledReader(t *testing.T) {
+    const bandwidth = 1 << 20
+    data := make([]byte, 512<<10)
+    r := &throttledReader{ctx: context.Background(), r: bytes.NewReader(data), limiter: newLimiter(bandwidth)}
+
+    start := time.Now()
+    n, err := io.Copy(io.Discard, r)
+    if err != nil || n != int64(len(data)) {
+        t.Fatalf("copied %d bytes: %v", n, err)
+    }
+
+    // Une rafale initiale, puis le reste au débit demandé
+    want := time.Duration(float64(len(data)-throttleChunk) / bandwidth * float64(time.Second))
+    if elapsed := time.Since(start); elapsed < want*9/10 {
+        t.Errorf("read %d bytes in %s, want at least %s", len(data), elapsed, want)
+    }
+
+    // Annulation pendant l'attente
+    ctx, cancel := context.WithCancel(context.Background())
+    cancel()
+    r = &throttledReader{ctx: ctx, r: bytes.NewReader(data), limiter: newLimiter(1024)}
+    if _, err := io.Copy(io.Discard, r); err == nil {
+        t.Error("cancelled read succeeded")
+    }
+}
@@ -0,0 +1,137 @@
+// internal/docker/volume.go
+package docker
+
+import (
+    "context"
+    "fmt"
+    "io"
+
+    "github.com/docker/docker/api/types"
+    "github.com/docker/docker/api/types/container"
+    "github.com/docker/docker/api/types/mount"
+    "github.com/docker/docker/client"
+
+    zTypes "zockimate/internal/types"
+)
+
+// Point de montage des données dans les conteneurs auxiliaires
+const helperMountPath = "/zockimate-data"
+
+// StopContainer arrête un conteneur sans le supprimer
+func (c *Client) StopContainer(ctx context.Context, name string) error {
+    timeout := 30 // secondes
+    if err := c.cli.ContainerStop(ctx, name, container.StopOptions{
+        Timeout: &timeout,
+    }); err != nil && !client.IsErrNotFound(err) {
+        return fmt.Errorf("failed to stop container: %w", err)
+    }
+    return nil
+}
+
+// BackupMount écrit le contenu d'un volume ou d'un bind mount sous forme d'archive tar.
+// Le contenu est lu via un conteneur auxiliaire (jamais démarré) qui monte la source.
+func (c *Client) BackupMount(ctx context.Context, helperImage string, m zTypes.MountBackup, w io.Writer) error {
+    id, err := c.createHelper(ctx, helperImage, m, true, []string{"true"})
+    if err != nil {
+        return err
+    }
+    defer c.removeHelper(id)
+
+    reader, _, err := c.cli.CopyFromContainer(ctx, id, helperMountPath)
+    if err != nil {
+        return fmt.Errorf("failed to read %s %s: %w", m.Type, m.Source, err)
+    }
+    defer reader.Close()
+
+    if _, err := io.Copy(w, reader); err != nil {
+        return fmt.Errorf("failed to archive %s %s: %w", m.Type, m.Source, err)
+    }
+    return nil
+}
+
+// RestoreMount remplace le contenu d'un volume ou d'un bind mount par une archive
+// produite par BackupMount. Le conteneur utilisant la source doit être arrêté.
+func (c *Client) RestoreMount(ctx context.Context, helperImage string, m zTypes.MountBackup, r io.Reader) error {
+    // Vider la source avant extraction pour retirer les fichiers créés depuis la sauvegarde
+    id, err := c.createHelper(ctx, helperImage, m, false, []string{
+        "find", helperMountPath, "-mindepth", "1", "-maxdepth", "1", "-exec", "rm", "-rf", "{}", "+",
+    })
+    if err != nil {
+        return err
+    }
+    defer c.removeHelper(id)
+
+    if err := c.cli.ContainerStart(ctx, id, container.StartOptions{}); err != nil {
+        return fmt.Errorf("failed to start helper container: %w", err)
+    }
+    statusCh, errCh := c.cli.ContainerWait(ctx, id, container.WaitConditionNotRunning)
+    select {
+    case err := <-errCh:
+        return fmt.Errorf("failed to wait for helper container: %w", err)
+    case status := <-statusCh:
+        if status.StatusCode != 0 {
+            return fmt.Errorf("failed to empty %s %s (exit code %d)", m.Type, m.Source, status.StatusCode)
+        }
+    }
+
+    // Les entrées de l'archive sont préfixées par le point de montage auxiliaire
+    if err := c.cli.CopyToContainer(ctx, id, "/", r, types.CopyToContainerOptions{
+        AllowOverwriteDirWithFile: true,
+    }); err != nil {
+        return fmt.Errorf("failed to restore %s %s: %w", m.Type, m.Source, err)
+    }
+    return nil
+}
+
+// createHelper crée un conteneur auxiliaire montant la source d'une sauvegarde
+func (c *Client) createHelper(ctx context.Context, helperImage string, m zTypes.MountBackup,
+    readOnly bool, cmd []string) (string, error) {
+
+    if err := c.ensureImage(ctx, helperImage); err != nil {
+        return "", err
+    }
+
+    mountType := mount.TypeVolume
+    if m.Type == string(mount.TypeBind) {
+        mountType = mount.TypeBind
+    }
+
+    resp, err := c.cli.ContainerCreate(ctx,
+        &container.Config{
+            Image:  helperImage,
+            Cmd:    cmd,
+            Labels: map[string]string{"zockimate.helper": "true"},
+        },
+        &container.HostConfig{
+            Mounts: []mount.Mount{{
+                Type:     mountType,
+                Source:   m.Source,
+                Target:   helperMountPath,
+                ReadOnly: readOnly,
+            }},
+        },
+        nil, nil, "")
+    if err != nil {
+        return "", fmt.Errorf("failed to create helper container: %w", err)
+    }
+    return resp.ID, nil
+}
+
+// removeHelper supprime un conteneur auxiliaire, même si le contexte a expiré
+func (c *Client) removeHelper(id string) {
+    if err := c.cli.ContainerRemove(context.Background(), id, container.RemoveOptions{
+        Force: true,
+    }); err != nil {
+        c.logger.Warnf("Failed to remove helper container %s: %v", id, err)
+    }
+}
+
+// ensureImage télécharge une image si elle n'est pas présente sur l'hôte
+func (c *Client) ensureImage(ctx context.Context, ref string) error {
+    if _, _, err := c.cli.ImageInspectWithRaw(ctx, ref); err == nil {
+        return nil
+    } else if !client.IsErrNotFound(err) {
+        return fmt.Errorf("failed to inspect image %s: %w", ref, err)
+    }
+    return c.PullImage(ctx, ref)
+}
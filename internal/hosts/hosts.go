@@ -0,0 +1,101 @@
+// internal/hosts/hosts.go
+package hosts
+
+import (
+    "encoding/json"
+    "fmt"
+    "net/url"
+    "os"
+    "strings"
+)
+
+// Nom de l'hôte désigné par les noms de conteneurs non qualifiés
+const DefaultHost = "local"
+
+// Host décrit un démon Docker géré par zockimate
+type Host struct {
+    Name     string `json:"name"`
+    Endpoint string `json:"endpoint"`           // unix://, tcp:// ou ssh:// (vide : variables DOCKER_*)
+    TLSCA    string `json:"tls_ca,omitempty"`   // Certificats TLS pour tcp://
+    TLSCert  string `json:"tls_cert,omitempty"`
+    TLSKey   string `json:"tls_key,omitempty"`
+    ZFSSSH   string `json:"zfs_ssh,omitempty"`  // Cible SSH des commandes zfs (user@host[:port])
+
+    // Réplication des snapshots ZFS (zfs send/receive)
+    ZFSReplica    string `json:"zfs_replica,omitempty"`     // Dataset parent recevant les réplicas
+    ZFSReplicaSSH string `json:"zfs_replica_ssh,omitempty"` // Cible SSH du pool de réplication (vide : même machine que zfs)
+}
+
+// Scheme retourne le schéma de l'endpoint (unix, tcp, ssh)
+func (h Host) Scheme() string {
+    scheme, _, ok := strings.Cut(h.Endpoint, "://")
+    if !ok {
+        return ""
+    }
+    return scheme
+}
+
+// ZFSTarget retourne la cible SSH des commandes zfs, vide pour une exécution locale.
+// ok vaut false si ZFS n'est pas accessible sur l'hôte.
+func (h Host) ZFSTarget() (target string, ok bool) {
+    if h.ZFSSSH != "" {
+        return h.ZFSSSH, true
+    }
+    switch h.Scheme() {
+    case "", "unix", "npipe":
+        return "", true
+    case "ssh":
+        u, err := url.Parse(h.Endpoint)
+        if err != nil {
+            return "", false
+        }
+        // Même utilisateur que pour le démon (ssh://user@host:port)
+        if u.User != nil && u.User.Username() != "" {
+            return u.User.Username() + "@" + u.Host, true
+        }
+        return u.Host, true
+    default:
+        // Démon distant joint en TCP : zfs_ssh est requis
+        return "", false
+    }
+}
+
+// Load lit un fichier JSON décrivant les hôtes. Sans fichier, seul l'hôte
+// local (configuré par les variables DOCKER_*) est géré.
+func Load(path string) ([]Host, error) {
+    if path == "" {
+        return []Host{{Name: DefaultHost}}, nil
+    }
+
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return nil, fmt.Errorf("failed to read hosts file %s: %w", path, err)
+    }
+
+    var list []Host
+    if err := json.Unmarshal(data, &list); err != nil {
+        return nil, fmt.Errorf("failed to parse hosts file %s: %w", path, err)
+    }
+    if len(list) == 0 {
+        return nil, fmt.Errorf("no host defined in %s", path)
+    }
+
+    seen := make(map[string]bool)
+    for _, h := range list {
+        if h.Name == "" || strings.Contains(h.Name, "/") {
+            return nil, fmt.Errorf("invalid host name %q in %s", h.Name, path)
+        }
+        if seen[h.Name] {
+            return nil, fmt.Errorf("duplicate host %q in %s", h.Name, path)
+        }
+        seen[h.Name] = true
+
+        switch h.Scheme() {
+        case "unix", "tcp", "ssh", "npipe":
+        default:
+            return nil, fmt.Errorf("unsupported endpoint %q for host %s (use unix://, tcp:// or ssh://)", h.Endpoint, h.Name)
+        }
+    }
+
+    return list, nil
+}
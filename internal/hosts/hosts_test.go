@@ -0,0 +1,29 @@
+// internal/hosts/hosts_test.go
+package hosts
+
+import "testing"
+
+func TestZFSTarget(t *testing.T) {
+    tests := []struct {
+        host   Host
+        target string
+        ok     bool
+    }{
+        {Host{Name: "local"}, "", true},
+        {Host{Endpoint: "unix:///var/run/docker.sock"}, "", true},
+        {Host{Endpoint: "ssh://nas"}, "nas", true},
+        {Host{Endpoint: "ssh://nas:2222"}, "nas:2222", true},
+        {Host{Endpoint: "ssh://backup@nas:2222"}, "backup@nas:2222", true},
+        {Host{Endpoint: "ssh://backup@[fd00::1]:2222"}, "backup@[fd00::1]:2222", true},
+        {Host{Endpoint: "tcp://nas:2376"}, "", false},
+        {Host{Endpoint: "tcp://nas:2376", ZFSSSH: "root@nas"}, "root@nas", true},
+        {Host{Endpoint: "ssh://backup@nas", ZFSSSH: "root@nas"}, "root@nas", true},
+    }
+    for _, tt := range tests {
+        target, ok := tt.host.ZFSTarget()
+        if target != tt.target || ok != tt.ok {
+            t.Errorf("ZFSTarget(%q, zfs_ssh %q) = %q, %v; want %q, %v",
+                tt.host.Endpoint, tt.host.ZFSSSH, target, ok, tt.target, tt.ok)
+        }
+    }
+}
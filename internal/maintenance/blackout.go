@@ -0,0 +1,73 @@
+// internal/maintenance/blackout.go
+package maintenance
+
+import (
+    "fmt"
+    "strings"
+    "time"
+)
+
+const dateLayout = "2006-01-02"
+
+// Blackout représente une période (jours inclus) pendant laquelle aucune mise à jour n'est appliquée
+type Blackout struct {
+    From time.Time // Début (inclus, minuit)
+    To   time.Time // Fin (exclue, minuit du jour suivant le dernier jour)
+}
+
+// Blackouts est une liste de périodes de gel
+type Blackouts []Blackout
+
+// ParseBlackouts parse une liste de dates ou plages séparées par des virgules
+// (ex: "2024-12-24,2024-12-30..2025-01-02"), interprétées dans le fuseau local
+func ParseBlackouts(spec string) (Blackouts, error) {
+    var blackouts Blackouts
+    for _, part := range strings.Split(spec, ",") {
+        part = strings.TrimSpace(part)
+        if part == "" {
+            continue
+        }
+
+        bounds := strings.SplitN(part, "..", 2)
+        from, err := time.ParseInLocation(dateLayout, strings.TrimSpace(bounds[0]), time.Local)
+        if err != nil {
+            return nil, fmt.Errorf("invalid blackout date %q (use YYYY-MM-DD or YYYY-MM-DD..YYYY-MM-DD)", part)
+        }
+        to := from
+        if len(bounds) == 2 {
+            if to, err = time.ParseInLocation(dateLayout, strings.TrimSpace(bounds[1]), time.Local); err != nil {
+                return nil, fmt.Errorf("invalid blackout date %q (use YYYY-MM-DD or YYYY-MM-DD..YYYY-MM-DD)", part)
+            }
+        }
+        if to.Before(from) {
+            return nil, fmt.Errorf("invalid blackout range %q: end is before start", part)
+        }
+
+        blackouts = append(blackouts, Blackout{From: from, To: to.AddDate(0, 0, 1)})
+    }
+    return blackouts, nil
+}
+
+// Contains indique si t tombe dans la période
+func (b Blackout) Contains(t time.Time) bool {
+    return !t.Before(b.From) && t.Before(b.To)
+}
+
+// String retourne la période au format de la configuration
+func (b Blackout) String() string {
+    last := b.To.AddDate(0, 0, -1)
+    if last.Equal(b.From) {
+        return b.From.Format(dateLayout)
+    }
+    return b.From.Format(dateLayout) + ".." + last.Format(dateLayout)
+}
+
+// Active retourne la période contenant t, si elle existe
+func (bs Blackouts) Active(t time.Time) (Blackout, bool) {
+    for _, b := range bs {
+        if b.Contains(t) {
+            return b, true
+        }
+    }
+    return Blackout{}, false
+}
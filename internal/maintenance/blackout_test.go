@@ -0,0 +1,65 @@
+// internal/maintenance/blackout_test.go
+package maintenance
+
+import (
+    "testing"
+    "time"
+)
+
+func TestParseBlackouts(t *testing.T) {
+    bs, err := ParseBlackouts("2026-12-24, 2026-12-30..2027-01-02,")
+    if err != nil {
+        t.Fatal(err)
+    }
+    if len(bs) != 2 {
+        t.Fatalf("%d blackouts, want 2", len(bs))
+    }
+    day := func(y int, m time.Month, d int) time.Time {
+        return time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+    }
+    if !bs[0].From.Equal(day(2026, 12, 24)) || !bs[0].To.Equal(day(2026, 12, 25)) ||
+        !bs[1].From.Equal(day(2026, 12, 30)) || !bs[1].To.Equal(day(2027, 1, 3)) {
+        t.Errorf("blackouts = %+v", bs)
+    }
+    if bs[0].String() != "2026-12-24" || bs[1].String() != "2026-12-30..2027-01-02" {
+        t.Errorf("String = %q, %q", bs[0], bs[1])
+    }
+
+    if bs, err := ParseBlackouts(""); err != nil || bs != nil {
+        t.Errorf("ParseBlackouts(\"\") = %v, %v", bs, err)
+    }
+    for _, spec := range []string{"2026-12-32", "24/12/2026", "2026-12-30..", "2027-01-02..2026-12-30"} {
+        if _, err := ParseBlackouts(spec); err == nil {
+            t.Errorf("ParseBlackouts(%q) accepted", spec)
+        }
+    }
+}
+
+func TestBlackoutsActive(t *testing.T) {
+    bs, err := ParseBlackouts("2026-12-24,2026-12-30..2027-01-02")
+    if err != nil {
+        t.Fatal(err)
+    }
+    at := func(y int, m time.Month, d, hour int) time.Time {
+        return time.Date(y, m, d, hour, 0, 0, 0, time.Local)
+    }
+
+    tests := []struct {
+        t      time.Time
+        active string
+    }{
+        {at(2026, 12, 23, 23), ""},
+        {at(2026, 12, 24, 0), "2026-12-24"},
+        {at(2026, 12, 24, 23), "2026-12-24"},
+        {at(2026, 12, 25, 0), ""},
+        {at(2027, 1, 1, 12), "2026-12-30..2027-01-02"},
+        {at(2027, 1, 2, 23), "2026-12-30..2027-01-02"}, // Dernier jour inclus
+        {at(2027, 1, 3, 0), ""},
+    }
+    for _, tt := range tests {
+        b, ok := bs.Active(tt.t)
+        if ok != (tt.active != "") || (ok && b.String() != tt.active) {
+            t.Errorf("Active(%s) = %v %v, want %q", tt.t, b, ok, tt.active)
+        }
+    }
+}
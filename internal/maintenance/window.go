@@ -0,0 +1,258 @@
+// internal/maintenance/window.go
+package maintenance
+
+import (
+    "fmt"
+    "strconv"
+    "strings"
+    "time"
+)
+
+var weekdays = map[string]time.Weekday{
+    "sun": time.Sunday,
+    "mon": time.Monday,
+    "tue": time.Tuesday,
+    "wed": time.Wednesday,
+    "thu": time.Thursday,
+    "fri": time.Friday,
+    "sat": time.Saturday,
+}
+
+// Window représente une fenêtre de maintenance récurrente
+// (ex: "Sat 02:00-05:00 Europe/Paris" ou "Mon-Fri 22:00-06:00")
+type Window struct {
+    Days     [7]bool          // Jours où la fenêtre s'ouvre (indexés par time.Weekday)
+    Start    time.Duration    // Heure d'ouverture depuis minuit
+    End      time.Duration    // Heure de fermeture depuis minuit
+    Location *time.Location   // Fuseau horaire de la fenêtre
+    spec     string
+}
+
+// Windows est une liste de fenêtres séparées par ";" dans les labels
+type Windows []*Window
+
+// ParseWindows parse une ou plusieurs fenêtres séparées par ";"
+func ParseWindows(spec string) (Windows, error) {
+    var windows Windows
+    for _, part := range strings.Split(spec, ";") {
+        part = strings.TrimSpace(part)
+        if part == "" {
+            continue
+        }
+        w, err := ParseWindow(part)
+        if err != nil {
+            return nil, err
+        }
+        windows = append(windows, w)
+    }
+    return windows, nil
+}
+
+// ParseWindow parse une fenêtre au format "[jours] HH:MM-HH:MM [fuseau]"
+func ParseWindow(spec string) (*Window, error) {
+    fields := strings.Fields(spec)
+    if len(fields) == 0 || len(fields) > 3 {
+        return nil, fmt.Errorf("invalid maintenance window %q (expected \"[days] HH:MM-HH:MM [timezone]\")", spec)
+    }
+
+    w := &Window{Location: time.Local, spec: spec}
+
+    // Les jours sont optionnels : tous les jours par défaut
+    if !strings.Contains(fields[0], ":") {
+        days, err := parseDays(fields[0])
+        if err != nil {
+            return nil, fmt.Errorf("invalid maintenance window %q: %w", spec, err)
+        }
+        w.Days = days
+        fields = fields[1:]
+    } else {
+        for i := range w.Days {
+            w.Days[i] = true
+        }
+    }
+
+    if len(fields) == 0 {
+        return nil, fmt.Errorf("invalid maintenance window %q: missing time range", spec)
+    }
+
+    bounds := strings.SplitN(fields[0], "-", 2)
+    if len(bounds) != 2 {
+        return nil, fmt.Errorf("invalid maintenance window %q: time range must be HH:MM-HH:MM", spec)
+    }
+    var err error
+    if w.Start, err = parseClock(bounds[0]); err != nil {
+        return nil, fmt.Errorf("invalid maintenance window %q: %w", spec, err)
+    }
+    if w.End, err = parseClock(bounds[1]); err != nil {
+        return nil, fmt.Errorf("invalid maintenance window %q: %w", spec, err)
+    }
+
+    if len(fields) == 2 {
+        loc, err := time.LoadLocation(fields[1])
+        if err != nil {
+            return nil, fmt.Errorf("invalid maintenance window %q: unknown timezone %s", spec, fields[1])
+        }
+        w.Location = loc
+    }
+
+    return w, nil
+}
+
+// parseDays parse "Sat", "Mon-Fri", "Sat,Sun" ou "*"
+func parseDays(s string) ([7]bool, error) {
+    var days [7]bool
+    if s == "*" || strings.EqualFold(s, "daily") {
+        for i := range days {
+            days[i] = true
+        }
+        return days, nil
+    }
+
+    for _, part := range strings.Split(s, ",") {
+        bounds := strings.SplitN(part, "-", 2)
+        from, ok := weekdays[strings.ToLower(bounds[0])]
+        if !ok {
+            return days, fmt.Errorf("unknown day %q", bounds[0])
+        }
+        to := from
+        if len(bounds) == 2 {
+            if to, ok = weekdays[strings.ToLower(bounds[1])]; !ok {
+                return days, fmt.Errorf("unknown day %q", bounds[1])
+            }
+        }
+        // Les plages peuvent boucler sur la semaine (ex: Fri-Mon)
+        for d := from; ; d = (d + 1) % 7 {
+            days[d] = true
+            if d == to {
+                break
+            }
+        }
+    }
+    return days, nil
+}
+
+// parseClock parse une heure au format HH:MM
+func parseClock(s string) (time.Duration, error) {
+    parts := strings.SplitN(s, ":", 2)
+    if len(parts) != 2 {
+        return 0, fmt.Errorf("invalid time %q (expected HH:MM)", s)
+    }
+    h, err := strconv.Atoi(parts[0])
+    if err != nil || h < 0 || h > 24 {
+        return 0, fmt.Errorf("invalid hour in %q", s)
+    }
+    m, err := strconv.Atoi(parts[1])
+    if err != nil || m < 0 || m > 59 || (h == 24 && m != 0) {
+        return 0, fmt.Errorf("invalid minute in %q", s)
+    }
+    return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute, nil
+}
+
+// String retourne la spécification d'origine
+func (w *Window) String() string {
+    return w.spec
+}
+
+// crossesMidnight indique si la fenêtre se termine le lendemain de son ouverture
+func (w *Window) crossesMidnight() bool {
+    return w.End <= w.Start
+}
+
+// at retourne l'instant où l'horloge murale du fuseau affiche l'heure offset un jour donné
+// (minuit plus une durée serait décalé d'une heure les jours de changement d'heure ;
+// 24:00 est normalisé en 00:00 le lendemain)
+func (w *Window) at(day time.Time, offset time.Duration) time.Time {
+    return time.Date(day.Year(), day.Month(), day.Day(),
+        int(offset/time.Hour), int(offset%time.Hour/time.Minute), 0, 0, w.Location)
+}
+
+// Contains indique si l'instant t tombe dans la fenêtre
+func (w *Window) Contains(t time.Time) bool {
+    local := t.In(w.Location)
+    tod := time.Duration(local.Hour())*time.Hour + time.Duration(local.Minute())*time.Minute +
+        time.Duration(local.Second())*time.Second
+    wd := local.Weekday()
+
+    if !w.crossesMidnight() {
+        return w.Days[wd] && tod >= w.Start && tod < w.End
+    }
+    prev := (wd + 6) % 7
+    return (w.Days[wd] && tod >= w.Start) || (w.Days[prev] && tod < w.End)
+}
+
+// Next retourne la prochaine ouverture de la fenêtre (t si elle est déjà ouverte)
+func (w *Window) Next(t time.Time) time.Time {
+    if w.Contains(t) {
+        return t
+    }
+    local := t.In(w.Location)
+    for d := 0; d <= 7; d++ {
+        day := local.AddDate(0, 0, d)
+        if !w.Days[day.Weekday()] {
+            continue
+        }
+        if start := w.at(day, w.Start); start.After(t) {
+            return start
+        }
+    }
+    return time.Time{}
+}
+
+// EndOf retourne la fermeture de l'occurrence contenant t (zéro si fermée)
+func (w *Window) EndOf(t time.Time) time.Time {
+    if !w.Contains(t) {
+        return time.Time{}
+    }
+    local := t.In(w.Location)
+    tod := time.Duration(local.Hour())*time.Hour + time.Duration(local.Minute())*time.Minute
+    if w.crossesMidnight() && tod >= w.Start {
+        return w.at(local.AddDate(0, 0, 1), w.End)
+    }
+    return w.at(local, w.End)
+}
+
+// Contains indique si t tombe dans au moins une des fenêtres
+// (une liste vide n'impose aucune contrainte)
+func (ws Windows) Contains(t time.Time) bool {
+    if len(ws) == 0 {
+        return true
+    }
+    for _, w := range ws {
+        if w.Contains(t) {
+            return true
+        }
+    }
+    return false
+}
+
+// Next retourne la prochaine ouverture parmi toutes les fenêtres
+func (ws Windows) Next(t time.Time) time.Time {
+    var next time.Time
+    for _, w := range ws {
+        n := w.Next(t)
+        if !n.IsZero() && (next.IsZero() || n.Before(next)) {
+            next = n
+        }
+    }
+    return next
+}
+
+// EndOf retourne la fermeture la plus tardive des fenêtres ouvertes à t
+func (ws Windows) EndOf(t time.Time) time.Time {
+    var end time.Time
+    for _, w := range ws {
+        if e := w.EndOf(t); e.After(end) {
+            end = e
+        }
+    }
+    return end
+}
+
+// String retourne les fenêtres au format du label
+func (ws Windows) String() string {
+    specs := make([]string, len(ws))
+    for i, w := range ws {
+        specs[i] = w.String()
+    }
+    return strings.Join(specs, "; ")
+}
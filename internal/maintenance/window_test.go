@@ -0,0 +1,223 @@
+// internal/maintenance/window_test.go
+package maintenance
+
+import (
+    "strings"
+    "testing"
+    "time"
+)
+
+func mustParseWindow(t *testing.T, spec string) *Window {
+    t.Helper()
+    w, err := ParseWindow(spec)
+    if err != nil {
+        t.Fatal(err)
+    }
+    return w
+}
+
+func mustLoadLocation(t *testing.T, name string) *time.Location {
+    t.Helper()
+    loc, err := time.LoadLocation(name)
+    if err != nil {
+        t.Skipf("timezone %s not available: %v", name, err)
+    }
+    return loc
+}
+
+func TestParseWindow(t *testing.T) {
+    tests := []struct {
+        spec       string
+        days       string // Jours ouverts, dimanche en premier
+        start, end time.Duration
+        location   string
+    }{
+        {"Sat 02:00-05:00 UTC", "......S", 2 * time.Hour, 5 * time.Hour, "UTC"},
+        {"Mon-Fri 22:00-06:00", ".MTWTF.", 22 * time.Hour, 6 * time.Hour, "Local"},
+        {"Fri-Mon 01:30-02:45 Europe/Paris", "SM...FS", 90 * time.Minute, 165 * time.Minute, "Europe/Paris"},
+        {"sat,SUN 00:00-24:00", "S.....S", 0, 24 * time.Hour, "Local"},
+        {"* 03:00-04:00", "SMTWTFS", 3 * time.Hour, 4 * time.Hour, "Local"},
+        {"daily 03:00-04:00", "SMTWTFS", 3 * time.Hour, 4 * time.Hour, "Local"},
+        {"03:00-04:00", "SMTWTFS", 3 * time.Hour, 4 * time.Hour, "Local"},
+    }
+    for _, tt := range tests {
+        w, err := ParseWindow(tt.spec)
+        if err != nil {
+            t.Errorf("ParseWindow(%q): %v", tt.spec, err)
+            continue
+        }
+        var days strings.Builder
+        for d, open := range w.Days {
+            if open {
+                days.WriteByte("SMTWTFS"[d])
+            } else {
+                days.WriteByte('.')
+            }
+        }
+        if days.String() != tt.days || w.Start != tt.start || w.End != tt.end ||
+            w.Location.String() != tt.location || w.String() != tt.spec {
+            t.Errorf("ParseWindow(%q) = %s %s-%s %s, want %s %s-%s %s", tt.spec,
+                days.String(), w.Start, w.End, w.Location, tt.days, tt.start, tt.end, tt.location)
+        }
+    }
+
+    for _, spec := range []string{
+        "",
+        "Sat",
+        "Sat 02:00",
+        "Sat 02:00-05:00 UTC extra",
+        "Sam 02:00-05:00",
+        "Mon-Fry 02:00-05:00",
+        "25:00-26:00",
+        "02:60-03:00",
+        "02:00-24:30",
+        "2h-5h",
+        "Sat 02:00-05:00 Mars/Olympus",
+    } {
+        if _, err := ParseWindow(spec); err == nil {
+            t.Errorf("ParseWindow(%q) accepted", spec)
+        }
+    }
+}
+
+func TestParseWindows(t *testing.T) {
+    ws, err := ParseWindows("Sat 02:00-05:00 UTC; ; Sun 03:00-04:00 UTC;")
+    if err != nil {
+        t.Fatal(err)
+    }
+    if len(ws) != 2 || ws.String() != "Sat 02:00-05:00 UTC; Sun 03:00-04:00 UTC" {
+        t.Errorf("ParseWindows = %d windows %q", len(ws), ws)
+    }
+    if ws, err := ParseWindows(""); err != nil || ws != nil {
+        t.Errorf("ParseWindows(\"\") = %v, %v", ws, err)
+    }
+    if _, err := ParseWindows("Sat 02:00-05:00; Sat"); err == nil {
+        t.Error("invalid second window accepted")
+    }
+}
+
+func TestWindowContains(t *testing.T) {
+    // Le 2026-03-06 est un vendredi
+    at := func(day, hour, min int) time.Time {
+        return time.Date(2026, 3, day, hour, min, 0, 0, time.UTC)
+    }
+    w := mustParseWindow(t, "Fri 22:00-06:00 UTC")
+
+    tests := []struct {
+        t    time.Time
+        want bool
+    }{
+        {at(6, 21, 59), false},
+        {at(6, 22, 0), true},
+        {at(6, 23, 59), true},
+        {at(7, 3, 0), true},   // Samedi, suite de l'ouverture du vendredi
+        {at(7, 6, 0), false},  // Fermeture exclue
+        {at(7, 22, 0), false}, // Le samedi n'ouvre pas
+        {at(5, 3, 0), false},  // Le jeudi n'ouvre pas
+    }
+    for _, tt := range tests {
+        if got := w.Contains(tt.t); got != tt.want {
+            t.Errorf("Contains(%s) = %v, want %v", tt.t.Format("Mon 15:04"), got, tt.want)
+        }
+    }
+
+    // Fenêtre dans un autre fuseau : 02:00-05:00 à Tokyo est 17:00-20:00 UTC la veille
+    mustLoadLocation(t, "Asia/Tokyo")
+    tokyo := mustParseWindow(t, "Sat 02:00-05:00 Asia/Tokyo")
+    if !tokyo.Contains(at(6, 18, 0)) || tokyo.Contains(at(7, 3, 0)) {
+        t.Error("window not evaluated in its own timezone")
+    }
+
+    // Jusqu'à minuit inclus
+    late := mustParseWindow(t, "22:00-24:00 UTC")
+    if !late.Contains(at(6, 23, 59)) || late.Contains(at(7, 0, 0)) {
+        t.Error("22:00-24:00 bounds")
+    }
+}
+
+func TestWindowNextAndEndOf(t *testing.T) {
+    at := func(day, hour, min int) time.Time {
+        return time.Date(2026, 3, day, hour, min, 0, 0, time.UTC)
+    }
+    w := mustParseWindow(t, "Fri 22:00-06:00 UTC")
+
+    tests := []struct {
+        t         time.Time
+        next, end time.Time
+    }{
+        {at(6, 12, 0), at(6, 22, 0), time.Time{}},
+        {at(6, 23, 0), at(6, 23, 0), at(7, 6, 0)}, // Déjà ouverte : t lui-même
+        {at(7, 3, 0), at(7, 3, 0), at(7, 6, 0)},
+        {at(7, 7, 0), at(13, 22, 0), time.Time{}}, // Vendredi suivant
+    }
+    for _, tt := range tests {
+        if got := w.Next(tt.t); !got.Equal(tt.next) {
+            t.Errorf("Next(%s) = %s, want %s", tt.t, got, tt.next)
+        }
+        if got := w.EndOf(tt.t); !got.Equal(tt.end) {
+            t.Errorf("EndOf(%s) = %s, want %s", tt.t, got, tt.end)
+        }
+    }
+
+    // 24:00 ferme à minuit le lendemain
+    late := mustParseWindow(t, "Fri 22:00-24:00 UTC")
+    if got := late.EndOf(at(6, 23, 0)); !got.Equal(at(7, 0, 0)) {
+        t.Errorf("EndOf 22:00-24:00 = %s, want %s", got, at(7, 0, 0))
+    }
+}
+
+func TestWindowDaylightSaving(t *testing.T) {
+    paris := mustLoadLocation(t, "Europe/Paris")
+    w := mustParseWindow(t, "Sun 02:00-05:00 Europe/Paris")
+    late := mustParseWindow(t, "Sun 04:00-06:00 Europe/Paris")
+
+    // Passage à l'heure d'été le dimanche 2026-03-29 (02:00 CET -> 03:00 CEST)
+    saturday := time.Date(2026, 3, 28, 12, 0, 0, 0, paris)
+    if got, want := late.Next(saturday), time.Date(2026, 3, 29, 4, 0, 0, 0, paris); !got.Equal(want) {
+        t.Errorf("Next on spring forward = %s, want %s", got.In(paris), want)
+    }
+    inside := time.Date(2026, 3, 29, 4, 30, 0, 0, paris)
+    if got, want := w.EndOf(inside), time.Date(2026, 3, 29, 5, 0, 0, 0, paris); !got.Equal(want) {
+        t.Errorf("EndOf on spring forward = %s, want %s", got.In(paris), want)
+    }
+
+    // Retour à l'heure d'hiver le dimanche 2026-10-25 (03:00 CEST -> 02:00 CET)
+    saturday = time.Date(2026, 10, 24, 12, 0, 0, 0, paris)
+    if got, want := late.Next(saturday), time.Date(2026, 10, 25, 4, 0, 0, 0, paris); !got.Equal(want) {
+        t.Errorf("Next on fall back = %s, want %s", got.In(paris), want)
+    }
+    inside = time.Date(2026, 10, 25, 4, 30, 0, 0, paris)
+    if got, want := w.EndOf(inside), time.Date(2026, 10, 25, 5, 0, 0, 0, paris); !got.Equal(want) {
+        t.Errorf("EndOf on fall back = %s, want %s", got.In(paris), want)
+    }
+}
+
+func TestWindows(t *testing.T) {
+    at := func(day, hour int) time.Time {
+        return time.Date(2026, 3, day, hour, 0, 0, 0, time.UTC)
+    }
+
+    // Aucune fenêtre : pas de contrainte
+    var none Windows
+    if !none.Contains(at(6, 12)) || !none.Next(at(6, 12)).IsZero() {
+        t.Error("empty windows")
+    }
+
+    ws, err := ParseWindows("Sat 02:00-05:00 UTC; Fri 22:00-04:00 UTC")
+    if err != nil {
+        t.Fatal(err)
+    }
+    if !ws.Contains(at(7, 3)) || !ws.Contains(at(6, 23)) || ws.Contains(at(6, 12)) {
+        t.Error("Contains does not match any window")
+    }
+    if got := ws.Next(at(6, 12)); !got.Equal(at(6, 22)) {
+        t.Errorf("Next = %s, want the earliest opening %s", got, at(6, 22))
+    }
+    // Les deux fenêtres sont ouvertes : la fermeture la plus tardive
+    if got := ws.EndOf(at(7, 3)); !got.Equal(at(7, 5)) {
+        t.Errorf("EndOf = %s, want %s", got, at(7, 5))
+    }
+    if got := ws.EndOf(at(6, 12)); !got.IsZero() {
+        t.Errorf("EndOf outside windows = %s", got)
+    }
+}
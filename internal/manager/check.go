@@ -4,6 +4,8 @@ package manager
 import (
     "context"
     "fmt"
+    "sort"
+    "time"
 
     "zockimate/pkg/utils"
     "zockimate/internal/types"
@@ -16,11 +18,18 @@ func (cm *ContainerManager) CheckContainer(ctx context.Context, name string, opt
 
     // Pas de lock : opération lecture seule, PullImage peut durer plusieurs minutes
     result := types.CheckResult{}
-    name = utils.CleanContainerName(name)
-    cm.logger.Debugf("Starting check process for container: %s", name)
+    host, name, err := cm.resolve(name)
+    if err != nil {
+        return result, err
+    }
+    cm.logger.Debugf("Starting check process for container: %s", containerID(host.name, name))
+
+    if svc, ok := serviceName(name); ok {
+        return cm.checkService(ctx, host, svc, opts)
+    }
 
     // Inspecter le conteneur
-    ctn, err := cm.docker.InspectContainer(ctx, name)
+    ctn, err := host.docker.InspectContainer(ctx, name)
     if err != nil {
         if client.IsErrNotFound(err) {
             return result, fmt.Errorf("container does not exist: %w", err)
@@ -39,7 +48,7 @@ func (cm *ContainerManager) CheckContainer(ctx context.Context, name string, opt
     }
 
     // Obtenir la référence de l'image actuelle
-    currentImage, err := cm.docker.GetImageInfo(ctx, ctn.Image)
+    currentImage, err := host.docker.GetImageInfo(ctx, ctn.Image)
     if err != nil {
         return result, err
     }
@@ -56,16 +65,17 @@ func (cm *ContainerManager) CheckContainer(ctx context.Context, name string, opt
     defer cancel()
 
     // Pull de la dernière version
-    if err := cm.docker.PullImage(pullCtx, updateRef); err != nil {
+    if err := host.docker.PullImage(pullCtx, updateRef); err != nil {
         return result, fmt.Errorf("failed to pull update image: %w", err)
     }
 
     // Obtenir les infos de la nouvelle image
-    latestImage, err := cm.docker.GetImageInfo(ctx, updateRef)
+    latestImage, err := host.docker.GetImageInfo(ctx, updateRef)
     if err != nil {
         return result, err
     }
     result.UpdateImage = latestImage
+    result.UpdateRef = updateRef
 
     // Vérifier la compatibilité des architectures
     if currentImage.Platform != latestImage.Platform {
@@ -87,15 +97,25 @@ func (cm *ContainerManager) CheckContainer(ctx context.Context, name string, opt
         result.NeedsUpdate = currentImage.ID != latestImage.ID
     }
 
+    // Appliquer le délai de stabilisation (zockimate.min_age)
+    if result.NeedsUpdate {
+        if err := cm.applyCooldown(ctn.Config.Labels, updateRef, latestImage, &result); err != nil {
+            return result, err
+        }
+    }
+
     // Nettoyer l'image téléchargée si demandé
     if opts.Cleanup && result.NeedsUpdate {
         cm.logger.Debugf("Starting cleanup image: %s", name)
-        if err := cm.docker.RemoveImage(ctx, latestImage.ID); err != nil {
+        if err := host.docker.RemoveImage(ctx, latestImage.ID); err != nil {
             cm.logger.Warnf("Failed to cleanup image %s: %v", latestImage.ID, err)
         }
     }
 
-    if result.NeedsUpdate {
+    if result.InCooldown {
+        cm.logger.Debugf("Update available for %s, waiting for cooldown until %s",
+            name, result.CooldownUntil.Format(time.RFC3339))
+    } else if result.NeedsUpdate {
         cm.logger.Debugf("Update available for %s: %s -> %s",
             name,
             utils.ShortenID(currentImage.ID),
@@ -108,19 +128,66 @@ func (cm *ContainerManager) CheckContainer(ctx context.Context, name string, opt
     return result, nil
 }
 
-// GetContainers retourne la liste des conteneurs à gérer
-func (cm *ContainerManager) GetContainers(ctx context.Context) ([]string, error) {
-    containers, err := cm.docker.ListContainers(ctx, cm.config.All)
+// applyCooldown enregistre la première détection de l'image distante et
+// indique si elle est encore trop récente pour être appliquée
+func (cm *ContainerManager) applyCooldown(labels map[string]string, ref string, latest *types.ImageReference, result *types.CheckResult) error {
+    digest := latest.RepoDigest
+    if digest == "" {
+        digest = latest.ID
+    }
+
+    firstSeen, err := cm.db.RecordImageSeen(ref, digest)
     if err != nil {
-        return nil, fmt.Errorf("failed to list containers: %w", err)
+        return err
     }
+    result.FirstSeen = firstSeen
+
+    minAge, err := utils.GetMinAge(labels)
+    if err != nil {
+        return err
+    }
+    if minAge > 0 {
+        result.CooldownUntil = firstSeen.Add(minAge)
+        result.InCooldown = time.Now().Before(result.CooldownUntil)
+    }
+    return nil
+}
+
+// GetContainers retourne la liste des conteneurs à gérer sur l'ensemble des hôtes,
+// qualifiés par leur hôte ("hôte/conteneur") hors de l'hôte par défaut
+func (cm *ContainerManager) GetContainers(ctx context.Context) ([]string, error) {
+    names := make([]string, 0, len(cm.hosts))
+    for name := range cm.hosts {
+        names = append(names, name)
+    }
+    sort.Strings(names)
 
     var managed []string
-    for _, ctn := range containers {
-        name := utils.CleanContainerName(ctn.Names[0])
-        if cm.config.NoFilter || utils.IsContainerEnabled(ctn.Labels) {
-            managed = append(managed, name)
+    for _, hostName := range names {
+        host := cm.hosts[hostName]
+        if host.err != nil {
+            cm.logger.Warnf("Skipping unavailable host %s: %v", hostName, host.err)
+            continue
+        }
+
+        containers, err := host.docker.ListContainers(ctx, cm.config.All)
+        if err != nil {
+            return nil, fmt.Errorf("failed to list containers on host %s: %w", hostName, err)
+        }
+
+        for _, ctn := range containers {
+            name := utils.CleanContainerName(ctn.Names[0])
+            if cm.config.NoFilter || utils.IsContainerEnabled(ctn.Labels) {
+                managed = append(managed, containerID(hostName, name))
+            }
+        }
+
+        // Services Swarm si l'hôte est un manager
+        services, err := cm.serviceIDs(ctx, host)
+        if err != nil {
+            return nil, err
         }
+        managed = append(managed, services...)
     }
 
     return managed, nil
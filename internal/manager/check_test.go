@@ -0,0 +1,67 @@
+// internal/manager/check_test.go
+package manager
+
+import (
+    "context"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/docker/docker/api/types"
+
+    "zockimate/internal/types/options"
+)
+
+func TestCheckCooldown(t *testing.T) {
+    ctx := context.Background()
+    cm := newTestManager(t)
+    d := addTestHost(t, cm, "local")
+    d.AddImage(testImageID, "nginx:1.27", testImageDigest)
+    d.RunContainer("app", "nginx:1.27", map[string]string{"zockimate.enable": "true", "zockimate.min_age": "2h"})
+
+    const newDigest = "nginx@sha256:3333333333333333333333333333333333333333333333333333333333333333"
+    d.Registry["nginx:1.27"] = types.ImageInspect{ID: "sha256:new", Os: "linux", Architecture: "amd64",
+        RepoTags: []string{"nginx:1.27"}, RepoDigests: []string{newDigest}}
+
+    opts := options.NewCheckOptions(options.WithCheckCleanup(false))
+    before := time.Now().Add(-time.Second)
+    result, err := cm.CheckContainer(ctx, "app", opts)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if !result.NeedsUpdate || !result.InCooldown || result.FirstSeen.Before(before) ||
+        !result.CooldownUntil.Equal(result.FirstSeen.Add(2*time.Hour)) {
+        t.Errorf("first check = %+v", result)
+    }
+
+    // La première détection est conservée d'une vérification à l'autre
+    again, err := cm.CheckContainer(ctx, "app", opts)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if !again.FirstSeen.Equal(result.FirstSeen) || !again.InCooldown {
+        t.Errorf("second check first seen = %s, want %s", again.FirstSeen, result.FirstSeen)
+    }
+
+    // La mise à jour attend la fin du délai
+    update, err := cm.UpdateContainer(ctx, "app", options.UpdateOptions{})
+    if err != nil {
+        t.Fatal(err)
+    }
+    if update.Error != nil || !update.Deferred || !strings.Contains(update.DeferReason, "cooldown") ||
+        update.SnapshotID != 0 {
+        t.Errorf("update in cooldown = %+v", update)
+    }
+
+    // Sans délai, la mise à jour est disponible aussitôt
+    d.Containers["app"].Config.Labels["zockimate.min_age"] = "0"
+    if result, err := cm.CheckContainer(ctx, "app", opts); err != nil || !result.NeedsUpdate || result.InCooldown {
+        t.Errorf("check without cooldown = %+v, %v", result, err)
+    }
+
+    // Délai invalide
+    d.Containers["app"].Config.Labels["zockimate.min_age"] = "soon"
+    if _, err := cm.CheckContainer(ctx, "app", opts); err == nil || !strings.Contains(err.Error(), "min_age") {
+        t.Errorf("invalid min_age error = %v", err)
+    }
+}
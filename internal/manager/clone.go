@@ -0,0 +1,399 @@
+// internal/manager/clone.go
+package manager
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "path/filepath"
+    "regexp"
+    "strconv"
+    "strings"
+
+    "github.com/docker/docker/api/types/container"
+    "github.com/docker/docker/api/types/mount"
+    "github.com/docker/go-connections/nat"
+
+    "zockimate/internal/types"
+    "zockimate/internal/types/options"
+    "zockimate/pkg/utils"
+)
+
+// Labels posés sur un clone
+const (
+    labelCloneOf       = "zockimate.clone_of"       // Conteneur d'origine (hôte/nom)
+    labelCloneSnapshot = "zockimate.clone_snapshot" // Entrée clonée
+)
+
+// Préfixes des labels retirés d'un clone : le projet compose ne doit pas l'adopter,
+// ni le reverse proxy lui envoyer le trafic de l'original
+var cloneStrippedLabels = []string{"com.docker.compose.", "traefik."}
+
+// Nom d'hôte attribué par Docker (ID court du conteneur d'origine)
+var shortIDHostname = regexp.MustCompile(`^[0-9a-f]{12}$`)
+
+// CloneContainer crée, à côté d'un conteneur, une copie de son état à un snapshot :
+// configuration sous un autre nom, ports publiés retirés (ou décalés de
+// opts.PortOffset), labels de compose et de reverse proxy retirés, zockimate
+// désactivé sauf opts.Enable, et données fournies par un clone ZFS de chaque
+// dataset du snapshot. Un clone qui ne démarre pas est supprimé avec ses datasets.
+func (cm *ContainerManager) CloneContainer(ctx context.Context, name string, opts options.CloneOptions) (*types.CloneResult, error) {
+    result := &types.CloneResult{
+        ContainerName: name,
+        NewName:       opts.Name,
+        SnapshotID:    opts.SnapshotID,
+    }
+
+    host, name, err := cm.resolve(name)
+    if err != nil {
+        result.Error = err
+        return result, nil
+    }
+    if _, ok := serviceName(name); ok {
+        result.Error = fmt.Errorf("cannot clone a Swarm service")
+        return result, nil
+    }
+    if opts.Name == "" || opts.Name == name {
+        result.Error = fmt.Errorf("a new name is required for the clone")
+        return result, nil
+    }
+    origID := containerID(host.name, name)
+
+    exists, err := host.docker.ContainerExists(ctx, opts.Name)
+    if err != nil {
+        result.Error = err
+        return result, nil
+    }
+    if exists {
+        result.Error = fmt.Errorf("container %s already exists", opts.Name)
+        return result, nil
+    }
+
+    // État actuel : snapshot préalable (CreateSnapshot prend le verrou)
+    if opts.Now {
+        snapshot, err := cm.CreateSnapshot(ctx, origID, options.NewSnapshotOptions(
+            options.WithSnapshotMessage(fmt.Sprintf("Auto-save before clone to %s", opts.Name)),
+            options.WithSnapshotNoCleanup(true),
+        ))
+        if err != nil {
+            result.Error = fmt.Errorf("failed to create snapshot: %w", err)
+            return result, nil
+        }
+        opts.SnapshotID = snapshot.ID
+    }
+
+    cm.lock.Lock()
+    defer cm.lock.Unlock()
+
+    snapshot, err := cm.db.GetSnapshot(host.name, name, opts.SnapshotID)
+    if err != nil {
+        result.Error = fmt.Errorf("failed to get snapshot: %w", err)
+        return result, nil
+    }
+    result.SnapshotID = snapshot.ID
+    cm.logger.Debugf("Cloning container %s from snapshot %d to %s", origID, snapshot.ID, opts.Name)
+
+    config, hostConfig, networkConfig, err := host.docker.UnmarshalConfigs(snapshot.Config, snapshot.HostConfig, snapshot.NetworkConfig)
+    if err != nil {
+        result.Error = fmt.Errorf("failed to unmarshal configs: %w", err)
+        return result, nil
+    }
+
+    if !opts.Force && !snapshot.ImageRef.IsExactReference() {
+        result.Error = fmt.Errorf(
+            "cannot guarantee exact image version for clone (use --force to override)")
+        return result, nil
+    }
+    if result.Image, err = cm.restoreImage(ctx, host, &snapshot.ImageRef); err != nil {
+        result.Error = err
+        return result, nil
+    }
+    config.Image = result.Image
+
+    // Tout défaire si le clone n'aboutit pas
+    var created bool
+    defer func() {
+        if result.Success {
+            return
+        }
+        if created {
+            cm.removeFailedRestore(host, opts.Name)
+        }
+        cm.destroyClones(host, result.Datasets)
+    }()
+
+    // Données : un clone ZFS de chaque dataset du snapshot
+    var mountpoints map[string]string
+    if !opts.NoData && len(snapshot.ZFSSnapshots) > 0 {
+        if mountpoints, err = cm.cloneDatasets(host, snapshot.ZFSSnapshots, opts.Name, result); err != nil {
+            result.Error = err
+            return result, nil
+        }
+    }
+    result.SharedMounts = rewriteCloneMounts(hostConfig, mountpoints)
+    for _, m := range result.SharedMounts {
+        cm.logger.Warnf("Mount %s is shared with %s", m, origID)
+    }
+
+    if result.Ports, err = remapPorts(hostConfig, opts.PortOffset); err != nil {
+        result.Error = err
+        return result, nil
+    }
+
+    // Réseaux : endpoints sans adresse fixe ni alias, pour ne pas capter le trafic de l'original
+    if _, err := host.docker.EnsureNetworks(ctx, hostConfig, networkConfig); err != nil {
+        result.Error = err
+        return result, nil
+    }
+    for _, ep := range networkConfig.EndpointsConfig {
+        if ep != nil {
+            ep.IPAMConfig = nil
+            ep.Aliases = nil
+        }
+    }
+
+    rewriteCloneConfig(config, origID, snapshot.ID, len(mountpoints) > 0, opts.Enable)
+
+    created = true
+    if err := host.docker.RecreateContainer(ctx, opts.Name, config, hostConfig, networkConfig); err != nil {
+        result.Error = fmt.Errorf("failed to create container: %w", err)
+        return result, nil
+    }
+
+    timeout := utils.GetTimeout(config.Labels, opts.Timeout, cm.logger)
+    cm.logger.Debugf("Waiting for container %s to be ready (timeout: %s)", opts.Name, timeout)
+    if err := host.docker.WaitForContainer(ctx, opts.Name, timeout); err != nil {
+        result.Error = fmt.Errorf("clone failed to become ready: %w", err)
+        return result, nil
+    }
+
+    result.Success = true
+    cm.logger.Debugf("Successfully cloned container %s from snapshot %d to %s", origID, snapshot.ID, opts.Name)
+    return result, nil
+}
+
+// DestroyClone supprime un conteneur créé par CloneContainer et détruit ses clones ZFS
+func (cm *ContainerManager) DestroyClone(ctx context.Context, name string) (*types.CloneResult, error) {
+    result := &types.CloneResult{NewName: name}
+
+    host, name, err := cm.resolve(name)
+    if err != nil {
+        result.Error = err
+        return result, nil
+    }
+    result.NewName = name
+
+    cm.lock.Lock()
+    defer cm.lock.Unlock()
+
+    exists, err := host.docker.ContainerExists(ctx, name)
+    if err != nil {
+        result.Error = err
+        return result, nil
+    }
+    if exists {
+        ctn, err := host.docker.InspectContainer(ctx, name)
+        if err != nil {
+            result.Error = err
+            return result, nil
+        }
+        result.ContainerName = ctn.Config.Labels[labelCloneOf]
+        if result.ContainerName == "" {
+            result.Error = fmt.Errorf("container %s is not a clone created by zockimate", name)
+            return result, nil
+        }
+        if err := host.docker.RemoveContainer(ctx, name, true); err != nil {
+            result.Error = err
+            return result, nil
+        }
+        result.ContainerRemoved = true
+    }
+
+    var errs []error
+    if host.zfs != nil {
+        clones, err := host.zfs.Clones(name)
+        if err != nil {
+            result.Error = fmt.Errorf("failed to list ZFS clones: %w", err)
+            return result, nil
+        }
+        for _, clone := range clones {
+            if err := host.zfs.DestroyClone(clone); err != nil {
+                errs = append(errs, err)
+                continue
+            }
+            result.Datasets = append(result.Datasets, clone)
+        }
+    }
+    if len(errs) > 0 {
+        result.Error = errors.Join(errs...)
+        return result, nil
+    }
+    if !result.ContainerRemoved && len(result.Datasets) == 0 {
+        result.Error = fmt.Errorf("no clone named %s", name)
+        return result, nil
+    }
+
+    result.Success = true
+    return result, nil
+}
+
+// cloneDatasets crée un clone ZFS de chaque snapshot du groupe, nommé
+// <dataset>_clone_<nom>, et retourne les points de montage d'origine -> du clone
+func (cm *ContainerManager) cloneDatasets(host *hostBackend, snapshots []string, owner string,
+    result *types.CloneResult) (map[string]string, error) {
+
+    zfsManager, err := host.zfsManager()
+    if err != nil {
+        return nil, err
+    }
+    datasets, err := zfsManager.ListDatasets()
+    if err != nil {
+        return nil, err
+    }
+    sources := make(map[string]string, len(datasets))
+    for _, d := range datasets {
+        sources[d.Name] = d.Mountpoint
+    }
+
+    mountpoints := make(map[string]string)
+    for _, snapshot := range snapshots {
+        source, _, _ := strings.Cut(snapshot, "@")
+        clone := source + "_clone_" + owner
+        mountpoint, err := zfsManager.CloneSnapshot(snapshot, clone, owner)
+        if err != nil {
+            return nil, err
+        }
+        result.Datasets = append(result.Datasets, clone)
+        cm.logger.Debugf("Cloned %s to %s (%s)", snapshot, clone, mountpoint)
+
+        if mp := sources[source]; strings.HasPrefix(mp, "/") {
+            mountpoints[mp] = mountpoint
+        }
+    }
+    return mountpoints, nil
+}
+
+// destroyClones détruit les clones ZFS d'un clonage avorté
+func (cm *ContainerManager) destroyClones(host *hostBackend, clones []string) {
+    for _, clone := range clones {
+        if err := host.zfs.DestroyClone(clone); err != nil {
+            cm.logger.Warnf("Failed to destroy %s: %v", clone, err)
+        }
+    }
+}
+
+// rewriteCloneMounts fait pointer les binds situés dans un dataset cloné vers le clone
+// et retourne les montages restés partagés avec l'original (volumes nommés, binds hors ZFS)
+func rewriteCloneMounts(hostConfig *container.HostConfig, mountpoints map[string]string) []string {
+    var shared []string
+    for i, bind := range hostConfig.Binds {
+        source, rest, _ := strings.Cut(bind, ":")
+        if !strings.HasPrefix(source, "/") {
+            shared = append(shared, "volume "+source)
+            continue
+        }
+        if path, ok := clonePath(source, mountpoints); ok {
+            hostConfig.Binds[i] = path + ":" + rest
+        } else {
+            shared = append(shared, source)
+        }
+    }
+    for i, m := range hostConfig.Mounts {
+        switch m.Type {
+        case mount.TypeBind:
+            if path, ok := clonePath(m.Source, mountpoints); ok {
+                hostConfig.Mounts[i].Source = path
+            } else {
+                shared = append(shared, m.Source)
+            }
+        case mount.TypeVolume:
+            if m.Source != "" {
+                shared = append(shared, "volume "+m.Source)
+            }
+        }
+    }
+    return shared
+}
+
+// clonePath retourne le chemin d'un fichier dans le clone du dataset qui le contient
+// (point de montage le plus long)
+func clonePath(path string, mountpoints map[string]string) (string, bool) {
+    path = filepath.Clean(path)
+    best := ""
+    for mp := range mountpoints {
+        if (path == mp || strings.HasPrefix(path, mp+"/")) && len(mp) > len(best) {
+            best = mp
+        }
+    }
+    if best == "" {
+        return "", false
+    }
+    return filepath.Join(mountpoints[best], strings.TrimPrefix(path, best)), true
+}
+
+// remapPorts décale les ports publiés sur l'hôte de offset, ou les retire si offset
+// vaut 0. Retourne les ports décalés ("8080->18080/tcp").
+func remapPorts(hostConfig *container.HostConfig, offset int) ([]string, error) {
+    if offset == 0 {
+        hostConfig.PortBindings = nil
+        hostConfig.PublishAllPorts = false
+        return nil, nil
+    }
+
+    var remapped []string
+    for port, bindings := range hostConfig.PortBindings {
+        for i, b := range bindings {
+            if b.HostPort == "" {
+                continue // Port attribué par Docker
+            }
+            start, end, err := nat.ParsePortRange(b.HostPort)
+            if err != nil {
+                return nil, fmt.Errorf("invalid host port %s: %w", b.HostPort, err)
+            }
+            newStart, newEnd := int(start)+offset, int(end)+offset
+            if newStart < 1 || newEnd > 65535 {
+                return nil, fmt.Errorf("host port %s shifted by %d is out of range", b.HostPort, offset)
+            }
+            hostPort := strconv.Itoa(newStart)
+            if end != start {
+                hostPort += "-" + strconv.Itoa(newEnd)
+            }
+            remapped = append(remapped, fmt.Sprintf("%s->%s/%s", b.HostPort, hostPort, port.Proto()))
+            bindings[i].HostPort = hostPort
+        }
+    }
+    return remapped, nil
+}
+
+// rewriteCloneConfig adapte les labels et le nom d'hôte d'un clone
+func rewriteCloneConfig(config *container.Config, origID string, snapshotID int64, dataCloned, enable bool) {
+    if shortIDHostname.MatchString(config.Hostname) {
+        config.Hostname = "" // Attribué par Docker au nouveau conteneur
+    }
+
+    if config.Labels == nil {
+        config.Labels = make(map[string]string)
+    }
+    for key := range config.Labels {
+        for _, prefix := range cloneStrippedLabels {
+            if strings.HasPrefix(key, prefix) {
+                delete(config.Labels, key)
+            }
+        }
+    }
+    if !enable {
+        config.Labels["zockimate.enable"] = "false"
+    }
+
+    // Datasets explicites : ceux de l'original, à ne pas snapshoter depuis le clone
+    if _, ok := config.Labels["zockimate.zfs_dataset"]; ok {
+        if dataCloned {
+            config.Labels["zockimate.zfs_dataset"] = zfsDatasetAuto
+        } else {
+            delete(config.Labels, "zockimate.zfs_dataset")
+        }
+    }
+    delete(config.Labels, "zockimate.snapshot_id")
+    config.Labels[labelCloneOf] = origID
+    config.Labels[labelCloneSnapshot] = strconv.FormatInt(snapshotID, 10)
+}
@@ -0,0 +1,225 @@
+// internal/manager/clone_test.go
+package manager
+
+import (
+    "context"
+    "reflect"
+    "sort"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/docker/docker/api/types/container"
+    "github.com/docker/docker/api/types/mount"
+    "github.com/docker/docker/api/types/network"
+    "github.com/docker/go-connections/nat"
+
+    "zockimate/internal/types/options"
+)
+
+func TestRewriteCloneMounts(t *testing.T) {
+    // Datasets tank/app (/srv/app) et tank/app/db (/srv/app/db) clonés
+    mountpoints := map[string]string{
+        "/srv/app":    "/srv/app_clone_test",
+        "/srv/app/db": "/srv/app/db_clone_test",
+    }
+    hostConfig := &container.HostConfig{
+        Binds: []string{
+            "/srv/app/config:/etc/app:ro",
+            "/srv/app/db:/var/lib/db",
+            "/srv/application:/opt", // Préfixe sans être dans le dataset
+            "cache:/cache",
+        },
+        Mounts: []mount.Mount{
+            {Type: mount.TypeBind, Source: "/srv/app/db/wal/", Target: "/wal"},
+            {Type: mount.TypeBind, Source: "/etc/localtime", Target: "/etc/localtime"},
+            {Type: mount.TypeVolume, Source: "logs", Target: "/logs"},
+            {Type: mount.TypeVolume, Target: "/tmp/anonymous"},
+            {Type: mount.TypeTmpfs, Target: "/run"},
+        },
+    }
+
+    shared := rewriteCloneMounts(hostConfig, mountpoints)
+
+    wantBinds := []string{
+        "/srv/app_clone_test/config:/etc/app:ro",
+        "/srv/app/db_clone_test:/var/lib/db", // Point de montage le plus long
+        "/srv/application:/opt",
+        "cache:/cache",
+    }
+    if !reflect.DeepEqual(hostConfig.Binds, wantBinds) {
+        t.Errorf("binds = %v, want %v", hostConfig.Binds, wantBinds)
+    }
+    if hostConfig.Mounts[0].Source != "/srv/app/db_clone_test/wal" || hostConfig.Mounts[1].Source != "/etc/localtime" {
+        t.Errorf("mounts = %+v", hostConfig.Mounts)
+    }
+    wantShared := []string{"/srv/application", "volume cache", "/etc/localtime", "volume logs"}
+    if !reflect.DeepEqual(shared, wantShared) {
+        t.Errorf("shared mounts = %v, want %v", shared, wantShared)
+    }
+
+    // Sans données clonées, tout est partagé et rien n'est réécrit
+    hostConfig = &container.HostConfig{Binds: []string{"/srv/app:/data"}}
+    if shared := rewriteCloneMounts(hostConfig, nil); !reflect.DeepEqual(shared, []string{"/srv/app"}) ||
+        hostConfig.Binds[0] != "/srv/app:/data" {
+        t.Errorf("without clones: binds %v, shared %v", hostConfig.Binds, shared)
+    }
+}
+
+func TestRemapPorts(t *testing.T) {
+    newHostConfig := func() *container.HostConfig {
+        return &container.HostConfig{
+            PublishAllPorts: true,
+            PortBindings: nat.PortMap{
+                "80/tcp":        {{HostIP: "127.0.0.1", HostPort: "8080"}},
+                "53/udp":        {{HostPort: "5353"}},
+                "9000-9001/tcp": {{HostPort: "9000-9001"}},
+                "443/tcp":       {{HostPort: ""}}, // Attribué par Docker
+            },
+        }
+    }
+
+    // Sans décalage : ports retirés
+    hostConfig := newHostConfig()
+    if remapped, err := remapPorts(hostConfig, 0); err != nil || remapped != nil ||
+        hostConfig.PortBindings != nil || hostConfig.PublishAllPorts {
+        t.Errorf("remapPorts(0) = %v, %v, bindings %v", remapped, err, hostConfig.PortBindings)
+    }
+
+    hostConfig = newHostConfig()
+    remapped, err := remapPorts(hostConfig, 10000)
+    if err != nil {
+        t.Fatal(err)
+    }
+    sort.Strings(remapped)
+    want := []string{"5353->15353/udp", "8080->18080/tcp", "9000-9001->19000-19001/tcp"}
+    if !reflect.DeepEqual(remapped, want) {
+        t.Errorf("remapped = %v, want %v", remapped, want)
+    }
+    if b := hostConfig.PortBindings["80/tcp"][0]; b.HostPort != "18080" || b.HostIP != "127.0.0.1" {
+        t.Errorf("80/tcp binding = %+v", b)
+    }
+    if b := hostConfig.PortBindings["443/tcp"][0]; b.HostPort != "" {
+        t.Errorf("443/tcp binding = %+v, want a port chosen by Docker", b)
+    }
+
+    if _, err := remapPorts(newHostConfig(), 60000); err == nil || !strings.Contains(err.Error(), "out of range") {
+        t.Errorf("out of range offset error = %v", err)
+    }
+    if _, err := remapPorts(newHostConfig(), -9000); err == nil {
+        t.Error("negative port accepted")
+    }
+}
+
+func TestRewriteCloneConfig(t *testing.T) {
+    newConfig := func() *container.Config {
+        return &container.Config{
+            Hostname: "0123456789ab",
+            Labels: map[string]string{
+                "com.docker.compose.project":       "shop",
+                "traefik.http.routers.shop.rule":   "Host(`shop.lan`)",
+                "zockimate.enable":                 "true",
+                "zockimate.zfs_dataset":            "tank/app,tank/app/db",
+                "zockimate.snapshot_id":            "3",
+                "org.opencontainers.image.version": "1.27",
+            },
+        }
+    }
+
+    config := newConfig()
+    rewriteCloneConfig(config, "local/app", 7, true, false)
+    want := map[string]string{
+        "zockimate.enable":                 "false",
+        "zockimate.zfs_dataset":            zfsDatasetAuto,
+        "org.opencontainers.image.version": "1.27",
+        labelCloneOf:                       "local/app",
+        labelCloneSnapshot:                 "7",
+    }
+    if !reflect.DeepEqual(config.Labels, want) {
+        t.Errorf("labels = %v, want %v", config.Labels, want)
+    }
+    if config.Hostname != "" {
+        t.Errorf("hostname = %q, want one assigned by Docker", config.Hostname)
+    }
+
+    // Sans données clonées, managé, nom d'hôte choisi conservé
+    config = newConfig()
+    config.Hostname = "shop"
+    rewriteCloneConfig(config, "app", 7, false, true)
+    if _, ok := config.Labels["zockimate.zfs_dataset"]; ok || config.Labels["zockimate.enable"] != "true" ||
+        config.Hostname != "shop" {
+        t.Errorf("config = hostname %q, labels %v", config.Hostname, config.Labels)
+    }
+}
+
+func TestCloneContainer(t *testing.T) {
+    ctx := context.Background()
+    cm := newTestManager(t)
+    d := addTestHost(t, cm, "local")
+    d.AddImage(testImageID, testImageDigest)
+
+    id := saveContainerSnapshot(t, cm, "app",
+        &container.Config{Image: "nginx:1.27", Hostname: "0123456789ab",
+            Labels: map[string]string{"zockimate.enable": "true", "traefik.enable": "true"}},
+        &container.HostConfig{
+            NetworkMode:  "appnet",
+            Binds:        []string{"/srv/app:/data"},
+            PortBindings: nat.PortMap{"80/tcp": {{HostPort: "8080"}}},
+        },
+        &network.NetworkingConfig{EndpointsConfig: map[string]*network.EndpointSettings{
+            "appnet": {IPAMConfig: &network.EndpointIPAMConfig{IPv4Address: "172.20.0.5"}, Aliases: []string{"shop"}},
+        }})
+    d.Networks["appnet"] = true
+
+    result, err := cm.CloneContainer(ctx, "app", options.CloneOptions{
+        Name: "app-test", PortOffset: 1000, NoData: true, Timeout: 5 * time.Second,
+    })
+    if err != nil {
+        t.Fatal(err)
+    }
+    if !result.Success || result.Error != nil {
+        t.Fatalf("clone failed: %v", result.Error)
+    }
+    if result.SnapshotID != id || result.Image != testImageDigest ||
+        !reflect.DeepEqual(result.Ports, []string{"8080->9080/tcp"}) ||
+        !reflect.DeepEqual(result.SharedMounts, []string{"/srv/app"}) || len(result.Datasets) != 0 {
+        t.Errorf("result = %+v", result)
+    }
+
+    ctn := d.Container("app-test")
+    if ctn == nil || !ctn.State.Running {
+        t.Fatal("clone not running")
+    }
+    if ctn.HostConfig.PortBindings["80/tcp"][0].HostPort != "9080" || ctn.Config.Hostname != "" ||
+        ctn.Config.Labels[labelCloneOf] != "app" || ctn.Config.Labels["zockimate.enable"] != "false" {
+        t.Errorf("clone config = %+v, host config = %+v", ctn.Config, ctn.HostConfig)
+    }
+    if _, ok := ctn.Config.Labels["traefik.enable"]; ok {
+        t.Error("reverse proxy label kept on the clone")
+    }
+    // Pas d'adresse fixe ni d'alias de l'original
+    if ep := ctn.NetworkSettings.Networks["appnet"]; ep == nil || ep.IPAMConfig != nil || ep.Aliases != nil {
+        t.Errorf("clone endpoint = %+v", ep)
+    }
+
+    // Le nom est pris, ou absent
+    for _, name := range []string{"app-test", "", "app"} {
+        result, err := cm.CloneContainer(ctx, "app", options.CloneOptions{Name: name, NoData: true})
+        if err != nil {
+            t.Fatal(err)
+        }
+        if result.Success || result.Error == nil {
+            t.Errorf("clone to %q succeeded", name)
+        }
+    }
+
+    // Suppression du clone
+    destroyed, err := cm.DestroyClone(ctx, "app-test")
+    if err != nil {
+        t.Fatal(err)
+    }
+    if !destroyed.Success || !destroyed.ContainerRemoved || destroyed.ContainerName != "app" ||
+        d.Container("app-test") != nil {
+        t.Errorf("destroy = %+v", destroyed)
+    }
+}
@@ -0,0 +1,83 @@
+// internal/manager/datasets.go
+package manager
+
+import (
+    "sort"
+    "strings"
+
+    "zockimate/internal/storage/zfs"
+    "zockimate/pkg/utils"
+)
+
+// Valeur de zockimate.zfs_dataset activant la découverte des datasets depuis les montages
+const zfsDatasetAuto = "auto"
+
+// snapshotDatasets retourne les datasets à snapshoter selon zockimate.zfs_dataset :
+// une liste explicite (séparée par des virgules) ou "auto" pour les datasets
+// contenant les montages du conteneur
+func snapshotDatasets(z *zfs.ZFSManager, labels map[string]string, mountPaths []string) ([]string, error) {
+    value := utils.GetZFSDataset(labels)
+    if value != zfsDatasetAuto {
+        var datasets []string
+        for _, d := range strings.Split(value, ",") {
+            if d = strings.TrimSpace(d); d != "" {
+                datasets = append(datasets, d)
+            }
+        }
+        return datasets, nil
+    }
+
+    mapping, err := discoverDatasets(z, mountPaths)
+    if err != nil {
+        return nil, err
+    }
+    return distinctDatasets(mapping, utils.IsZFSRecursive(labels)), nil
+}
+
+// discoverDatasets associe chaque chemin de montage au dataset qui le contient
+// (chaîne vide si le chemin n'est pas sur ZFS)
+func discoverDatasets(z *zfs.ZFSManager, mountPaths []string) (map[string]string, error) {
+    datasets, err := z.ListDatasets()
+    if err != nil {
+        return nil, err
+    }
+
+    mapping := make(map[string]string, len(mountPaths))
+    for _, path := range mountPaths {
+        if d, ok := zfs.DatasetFor(datasets, path); ok {
+            mapping[path] = d.Name
+        } else {
+            mapping[path] = ""
+        }
+    }
+    return mapping, nil
+}
+
+// distinctDatasets retourne les datasets distincts d'un mapping. En mode récursif,
+// les datasets enfants d'un autre dataset de la liste sont déjà inclus dans son snapshot.
+func distinctDatasets(mapping map[string]string, recursive bool) []string {
+    seen := make(map[string]bool)
+    for _, d := range mapping {
+        if d != "" {
+            seen[d] = true
+        }
+    }
+
+    var datasets []string
+    for d := range seen {
+        covered := false
+        if recursive {
+            for parent := range seen {
+                if strings.HasPrefix(d, parent+"/") {
+                    covered = true
+                    break
+                }
+            }
+        }
+        if !covered {
+            datasets = append(datasets, d)
+        }
+    }
+    sort.Strings(datasets)
+    return datasets
+}
@@ -0,0 +1,130 @@
+// internal/manager/datasets_test.go
+package manager
+
+import (
+    "context"
+    "reflect"
+    "strconv"
+    "strings"
+    "testing"
+    "time"
+
+    zTypes "zockimate/internal/types"
+    "zockimate/internal/types/options"
+)
+
+func TestDistinctDatasets(t *testing.T) {
+    mapping := map[string]string{
+        "/mnt/tank/apps/web":     "tank/apps",
+        "/mnt/tank/apps/db":      "tank/apps/db",
+        "/mnt/tank/apps/db/wal":  "tank/apps/db/wal",
+        "/mnt/tank/apps/uploads": "tank/apps",
+        "/mnt/fast/cache":        "fast/cache",
+        "/mnt/tank/apps-old":     "tank/apps-old",
+        "/var/run/docker.sock":   "",
+    }
+
+    // Non récursif : chaque dataset distinct, montages hors ZFS ignorés
+    want := []string{"fast/cache", "tank/apps", "tank/apps-old", "tank/apps/db", "tank/apps/db/wal"}
+    if got := distinctDatasets(mapping, false); !reflect.DeepEqual(got, want) {
+        t.Errorf("distinctDatasets(non recursive) = %v, want %v", got, want)
+    }
+
+    // Récursif : les enfants sont inclus dans le snapshot de leur parent,
+    // un nom de même préfixe (tank/apps-old) n'est pas un enfant
+    want = []string{"fast/cache", "tank/apps", "tank/apps-old"}
+    if got := distinctDatasets(mapping, true); !reflect.DeepEqual(got, want) {
+        t.Errorf("distinctDatasets(recursive) = %v, want %v", got, want)
+    }
+
+    if got := distinctDatasets(map[string]string{"/srv": ""}, true); got != nil {
+        t.Errorf("distinctDatasets(no ZFS mount) = %v, want none", got)
+    }
+}
+
+func TestSnapshotDatasetsExplicit(t *testing.T) {
+    // Liste explicite : aucune découverte, donc pas d'accès ZFS
+    labels := map[string]string{"zockimate.zfs_dataset": " tank/app/db, ,tank/app/uploads"}
+    got, err := snapshotDatasets(nil, labels, []string{"/mnt/other"})
+    if err != nil || !reflect.DeepEqual(got, []string{"tank/app/db", "tank/app/uploads"}) {
+        t.Errorf("snapshotDatasets = %v, %v", got, err)
+    }
+}
+
+func TestSnapshotDatasetGroup(t *testing.T) {
+    ctx := context.Background()
+    cm := newTestManager(t)
+    cm.config.Retention = 1
+    d, f := addZFSTestHost(t, cm, "local", "tank/app/db", "tank/app/uploads")
+    d.AddImage(testImageID, "nginx:1.27", testImageDigest)
+    d.RunContainer("app", "nginx:1.27", map[string]string{
+        "zockimate.enable": "true", "zockimate.zfs_dataset": "tank/app/db,tank/app/uploads",
+    })
+    save := func() (*zTypes.ContainerSnapshot, error) {
+        return cm.CreateSnapshot(ctx, "app", options.NewSnapshotOptions(options.WithSnapshotMessage("test")))
+    }
+
+    // Un snapshot de même nom par dataset, enregistré comme un groupe
+    first, err := save()
+    if err != nil {
+        t.Fatal(err)
+    }
+    if len(first.ZFSSnapshots) != 2 {
+        t.Fatalf("ZFS snapshots = %v, want one per dataset", first.ZFSSnapshots)
+    }
+    _, name, _ := strings.Cut(first.ZFSSnapshots[0], "@")
+    if !reflect.DeepEqual(first.ZFSSnapshots, []string{"tank/app/db@" + name, "tank/app/uploads@" + name}) {
+        t.Errorf("ZFS snapshots = %v, want the same name on both datasets", first.ZFSSnapshots)
+    }
+    saved, err := cm.db.GetSnapshot("local", "app", first.ID)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if !reflect.DeepEqual(saved.ZFSSnapshots, first.ZFSSnapshots) {
+        t.Errorf("saved group = %v, want %v", saved.ZFSSnapshots, first.ZFSSnapshots)
+    }
+    for _, s := range first.ZFSSnapshots {
+        if id, _, _ := f.GetProperty(s, propSnapshotID); id != strconv.FormatInt(first.ID, 10) {
+            t.Errorf("%s: %s = %q, want %d", s, propSnapshotID, id, first.ID)
+        }
+    }
+
+    // Rétention : le groupe précédent est détruit en entier. Les dates sont
+    // enregistrées à la seconde près, une par entrée.
+    time.Sleep(time.Until(first.CreatedAt.Truncate(time.Second).Add(time.Second)))
+    second, err := save()
+    if err != nil {
+        t.Fatal(err)
+    }
+    for _, s := range first.ZFSSnapshots {
+        if exists, _ := f.Exists(s); exists {
+            t.Errorf("%s kept after its entry was cleaned up", s)
+        }
+    }
+    for _, s := range second.ZFSSnapshots {
+        if exists, _ := f.Exists(s); !exists {
+            t.Errorf("%s missing", s)
+        }
+    }
+
+    // Dataset inexistant : aucun snapshot ni entrée
+    d.Container("app").Config.Labels["zockimate.zfs_dataset"] = "tank/app/db,tank/app/missing"
+    if _, err := save(); err == nil {
+        t.Fatal("snapshot of a missing dataset succeeded")
+    }
+    if got, _ := f.Snapshots("tank/app/db"); len(got) != 1 {
+        t.Errorf("tank/app/db snapshots = %v, want only the last group", got)
+    }
+    if n := len(mustHistory(t, cm)); n != 1 {
+        t.Errorf("%d entries, want 1", n)
+    }
+
+    // Groupe incomplet : aucun dataset n'est restauré
+    if err := f.Destroy(second.ZFSSnapshots[1]); err != nil {
+        t.Fatal(err)
+    }
+    if err := cm.restoreZFS(cm.hosts["local"], second.ZFSSnapshots, false); err == nil ||
+        !strings.Contains(err.Error(), second.ZFSSnapshots[1]) {
+        t.Errorf("restore of an incomplete group = %v, want the missing snapshot", err)
+    }
+}
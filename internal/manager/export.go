@@ -0,0 +1,312 @@
+// internal/manager/export.go
+package manager
+
+import (
+    "context"
+    "fmt"
+    "io"
+    "os"
+    "sort"
+    "time"
+
+    "zockimate/internal/storage/bundle"
+    "zockimate/internal/types"
+    "zockimate/internal/types/options"
+    "zockimate/pkg/utils"
+)
+
+// ExportHistory écrit dans w l'historique des conteneurs demandés (tous par défaut) :
+// entrées, configurations, opérations et, avec opts.Images, les images référencées
+// encore présentes sur leur hôte. Les archives de montages et les données ZFS ne
+// sont pas incluses.
+func (cm *ContainerManager) ExportHistory(ctx context.Context, w io.Writer, opts options.ExportOptions) (*types.ExportResult, error) {
+    cm.lock.RLock()
+    defer cm.lock.RUnlock()
+
+    filter := options.HistoryOptions{Container: opts.Containers}
+    history, err := cm.db.GetHistory(filter)
+    if err != nil {
+        return nil, err
+    }
+    ops, err := cm.db.GetOperations(filter)
+    if err != nil {
+        return nil, err
+    }
+
+    // Ordre chronologique : l'import recrée les entrées dans l'ordre d'origine
+    sort.Slice(history, func(i, j int) bool { return history[i].ID < history[j].ID })
+    sort.Slice(ops, func(i, j int) bool { return ops[i].ID < ops[j].ID })
+
+    result := &types.ExportResult{Snapshots: len(history), Operations: len(ops)}
+    snapshots := make([]bundle.Snapshot, 0, len(history))
+    blobs := make(map[string][]byte)
+    var blobOrder []string
+    addBlob := func(data []byte) string {
+        if len(data) == 0 {
+            return ""
+        }
+        hash := bundle.Hash(data)
+        if _, ok := blobs[hash]; !ok {
+            blobs[hash] = data
+            blobOrder = append(blobOrder, hash)
+        }
+        return hash
+    }
+
+    for _, meta := range history {
+        snapshot, err := cm.db.GetSnapshot(meta.Host, meta.ContainerName, meta.ID)
+        if err != nil {
+            return nil, fmt.Errorf("failed to read snapshot %d: %w", meta.ID, err)
+        }
+        snapshots = append(snapshots, bundle.Snapshot{
+            ID:            snapshot.ID,
+            Host:          snapshot.Host,
+            ContainerName: snapshot.ContainerName,
+            Image: bundle.Image{
+                ID:         snapshot.ImageRef.ID,
+                RepoDigest: snapshot.ImageRef.RepoDigest,
+                Tag:        snapshot.ImageRef.Tag,
+                Original:   snapshot.ImageRef.Original,
+                Platform:   snapshot.ImageRef.Platform,
+            },
+            Config:        addBlob(snapshot.Config),
+            HostConfig:    addBlob(snapshot.HostConfig),
+            NetworkConfig: addBlob(snapshot.NetworkConfig),
+            ZFSSnapshots:  snapshot.ZFSSnapshots,
+            Mounts:        snapshot.Mounts,
+            SnapshotMode:  snapshot.SnapshotMode,
+            FreezeMs:      snapshot.FreezeDuration.Milliseconds(),
+            Message:       snapshot.Message,
+            CreatedAt:     snapshot.CreatedAt,
+            VulnDelta:     meta.VulnDelta,
+        })
+    }
+    result.Blobs = len(blobOrder)
+
+    // Images à inclure : une par ID, sauvegardée depuis l'hôte de sa première entrée
+    type exportImage struct {
+        id   string
+        host *hostBackend
+    }
+    var images []exportImage
+    if opts.Images {
+        seen := make(map[string]bool)
+        for _, s := range snapshots {
+            if s.Image.ID == "" || seen[s.Image.ID] {
+                continue
+            }
+            seen[s.Image.ID] = true
+            if _, ok := serviceName(s.ContainerName); ok {
+                continue // Image d'un service Swarm : présente sur les nœuds, pas sur le manager
+            }
+            host, ok := cm.hosts[s.Host]
+            if ok && host.err == nil {
+                if _, err := host.docker.GetImageInfo(ctx, s.Image.ID); err == nil {
+                    images = append(images, exportImage{id: s.Image.ID, host: host})
+                    continue
+                }
+            }
+            cm.logger.Warnf("Image %s of %s not found, not exported",
+                utils.ShortenID(s.Image.ID), containerID(s.Host, s.ContainerName))
+            result.MissingImages = append(result.MissingImages, s.Image.ID)
+        }
+        result.Images = len(images)
+    }
+
+    bw := bundle.NewWriter(w)
+    if err := bw.WriteManifest(bundle.Manifest{
+        SchemaVersion: cm.schemaVersion(),
+        Containers:    opts.Containers,
+        Snapshots:     result.Snapshots,
+        Operations:    result.Operations,
+        Blobs:         result.Blobs,
+        Images:        result.Images,
+    }); err != nil {
+        return nil, err
+    }
+    if err := bw.WriteSnapshots(snapshots); err != nil {
+        return nil, err
+    }
+    if err := bw.WriteOperations(ops); err != nil {
+        return nil, err
+    }
+    for _, hash := range blobOrder {
+        if err := bw.WriteBlob(blobs[hash]); err != nil {
+            return nil, err
+        }
+    }
+    for _, img := range images {
+        if err := cm.exportImage(ctx, bw, img.host, img.id); err != nil {
+            return nil, err
+        }
+    }
+    if err := bw.Close(); err != nil {
+        return nil, err
+    }
+    return result, nil
+}
+
+// exportImage ajoute une image à l'archive, via un fichier temporaire qui en donne la taille
+func (cm *ContainerManager) exportImage(ctx context.Context, bw *bundle.Writer, host *hostBackend, imageID string) error {
+    tmp, err := os.CreateTemp("", "zockimate-image-*.tar")
+    if err != nil {
+        return fmt.Errorf("failed to create temporary file: %w", err)
+    }
+    defer os.Remove(tmp.Name())
+    defer tmp.Close()
+
+    cm.logger.Infof("Saving image %s from host %s", utils.ShortenID(imageID), host.name)
+    if err := host.docker.SaveImage(ctx, imageID, tmp); err != nil {
+        return err
+    }
+    size, err := tmp.Seek(0, io.SeekCurrent)
+    if err != nil {
+        return fmt.Errorf("failed to read temporary file: %w", err)
+    }
+    if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+        return fmt.Errorf("failed to read temporary file: %w", err)
+    }
+    return bw.WriteImage(imageID, size, tmp)
+}
+
+// schemaVersion retourne la version du schéma de la base (0 si elle ne peut être lue)
+func (cm *ContainerManager) schemaVersion() int {
+    version, err := cm.db.SchemaVersion()
+    if err != nil {
+        cm.logger.Warnf("Failed to read schema version: %v", err)
+    }
+    return version
+}
+
+// ImportHistory fusionne dans la base l'historique d'une archive produite par
+// ExportHistory. Les entrées reçoivent de nouveaux identifiants (références des
+// opérations et des deltas de vulnérabilités réécrites) ; une entrée de même
+// conteneur et date, ou une opération de même conteneur, type et début, est
+// considérée comme déjà présente. Les images de l'archive sont chargées sur l'hôte
+// de leur première entrée s'il est configuré.
+func (cm *ContainerManager) ImportHistory(ctx context.Context, r io.Reader, opts options.ImportOptions) (*types.ImportResult, error) {
+    cm.lock.Lock()
+    defer cm.lock.Unlock()
+
+    result := &types.ImportResult{}
+
+    var loadImage func(b *bundle.Bundle, imageID string, r io.Reader) error
+    if !opts.SkipImages && !opts.DryRun {
+        loadImage = func(b *bundle.Bundle, imageID string, r io.Reader) error {
+            hostName := ""
+            for _, s := range b.Snapshots {
+                if s.Image.ID == imageID {
+                    hostName = s.Host
+                    break
+                }
+            }
+            if opts.Host != "" {
+                hostName = opts.Host
+            }
+            host, ok := cm.hosts[hostName]
+            if !ok || host.err != nil {
+                cm.logger.Warnf("Image %s not loaded: host %q not available", utils.ShortenID(imageID), hostName)
+                return nil
+            }
+            cm.logger.Infof("Loading image %s on host %s", utils.ShortenID(imageID), hostName)
+            if err := host.docker.LoadImage(ctx, r); err != nil {
+                return err
+            }
+            result.Images++
+            return nil
+        }
+    }
+
+    b, err := bundle.Read(r, loadImage)
+    if err != nil {
+        return nil, err
+    }
+    result.Version = b.Manifest.Version
+    result.Snapshots = len(b.Snapshots)
+    result.Operations = len(b.Operations)
+
+    // Identifiants de l'archive -> identifiants de la base
+    ids := make(map[int64]int64)
+    for _, s := range b.Snapshots {
+        snapshot := &types.ContainerSnapshot{
+            Host:          s.Host,
+            ContainerName: s.ContainerName,
+            ImageRef: types.ImageReference{
+                ID:         s.Image.ID,
+                RepoDigest: s.Image.RepoDigest,
+                Tag:        s.Image.Tag,
+                Original:   s.Image.Original,
+                Platform:   s.Image.Platform,
+            },
+            Config:         b.Blobs[s.Config],
+            HostConfig:     b.Blobs[s.HostConfig],
+            NetworkConfig:  b.Blobs[s.NetworkConfig],
+            ZFSSnapshots:   s.ZFSSnapshots,
+            Mounts:         s.Mounts,
+            SnapshotMode:   s.SnapshotMode,
+            FreezeDuration: time.Duration(s.FreezeMs) * time.Millisecond,
+            Message:        s.Message,
+            CreatedAt:      s.CreatedAt,
+        }
+        if opts.Host != "" {
+            snapshot.Host = opts.Host
+        }
+
+        if opts.DryRun {
+            id, err := cm.db.FindSnapshot(snapshot.Host, snapshot.ContainerName, snapshot.CreatedAt)
+            if err != nil {
+                return nil, err
+            }
+            if id > 0 {
+                ids[s.ID] = id
+                result.Existing++
+            } else {
+                result.Imported++
+            }
+            continue
+        }
+
+        id, merged, err := cm.db.MergeSnapshot(snapshot)
+        if err != nil {
+            return nil, fmt.Errorf("failed to import snapshot %d of %s: %w",
+                s.ID, containerID(snapshot.Host, snapshot.ContainerName), err)
+        }
+        ids[s.ID] = id
+        if !merged {
+            result.Existing++
+            continue
+        }
+        result.Imported++
+        if s.VulnDelta != nil {
+            if err := cm.db.SaveVulnDelta(id, s.VulnDelta); err != nil {
+                return nil, err
+            }
+        }
+    }
+
+    for _, op := range b.Operations {
+        op := op
+        if opts.Host != "" {
+            op.Host = opts.Host
+        }
+        // Référence vers une entrée absente de l'archive : non conservée
+        op.SnapshotID = ids[op.SnapshotID]
+        op.TargetSnapshotID = ids[op.TargetSnapshotID]
+
+        var imported bool
+        if opts.DryRun {
+            exists, err := cm.db.OperationExists(&op)
+            if err != nil {
+                return nil, err
+            }
+            imported = !exists
+        } else if imported, err = cm.db.MergeOperation(&op); err != nil {
+            return nil, err
+        }
+        if imported {
+            result.OperationsImported++
+        }
+    }
+
+    return result, nil
+}
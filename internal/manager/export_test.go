@@ -0,0 +1,161 @@
+// internal/manager/export_test.go
+package manager
+
+import (
+    "bytes"
+    "context"
+    "reflect"
+    "testing"
+    "time"
+
+    "zockimate/internal/types"
+    "zockimate/internal/types/options"
+)
+
+// exportSnapshot retourne une entrée de test, de configuration commune à ses
+// semblables de même image
+func exportSnapshot(name, image string, createdAt time.Time) *types.ContainerSnapshot {
+    return &types.ContainerSnapshot{
+        Host:          "local",
+        ContainerName: name,
+        ImageRef:      types.ImageReference{ID: "sha256:" + image, Tag: image, Original: image},
+        Config:        []byte(`{"Image":"` + image + `"}`),
+        HostConfig:    []byte(`{"Binds":["/srv/` + name + `:/data"]}`),
+        NetworkConfig: []byte(`{}`),
+        ZFSSnapshots:  []string{"tank/" + name + "@snapshot_" + createdAt.Format("20060102_150405")},
+        SnapshotMode:  "pause",
+        Message:       "before update",
+        CreatedAt:     createdAt,
+    }
+}
+
+func TestExportImportHistory(t *testing.T) {
+    ctx := context.Background()
+    t1 := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+
+    // Base source : deux entrées de app (même image) et une de db, une mise à jour de app
+    source := newTestManager(t)
+    var ids []int64
+    for _, s := range []*types.ContainerSnapshot{
+        exportSnapshot("app", "nginx:1.27", t1),
+        exportSnapshot("app", "nginx:1.27", t1.Add(time.Hour)),
+        exportSnapshot("db", "postgres:16", t1.Add(2*time.Hour)),
+    } {
+        id, _, err := source.db.MergeSnapshot(s)
+        if err != nil {
+            t.Fatal(err)
+        }
+        ids = append(ids, id)
+    }
+    delta := &types.VulnDelta{Fixed: []types.Vulnerability{{ID: "CVE-2026-0001", Severity: types.SeverityHigh}}}
+    if err := source.db.SaveVulnDelta(ids[1], delta); err != nil {
+        t.Fatal(err)
+    }
+    finished := t1.Add(time.Hour + time.Minute)
+    if _, err := source.db.MergeOperation(&types.Operation{
+        Host: "local", ContainerName: "app", Type: types.OperationUpdate, TriggeredBy: types.TriggerCLI,
+        StartedAt: t1.Add(time.Hour), FinishedAt: &finished, Outcome: types.OutcomeSuccess,
+        SnapshotID: ids[1], FromImage: "sha256:nginx:1.26", ToImage: "sha256:nginx:1.27",
+    }); err != nil {
+        t.Fatal(err)
+    }
+
+    var archive bytes.Buffer
+    exported, err := source.ExportHistory(ctx, &archive, options.ExportOptions{})
+    if err != nil {
+        t.Fatal(err)
+    }
+    // Configurations dédupliquées : nginx, postgres, deux host configs et {}
+    if exported.Snapshots != 3 || exported.Operations != 1 || exported.Blobs != 5 {
+        t.Errorf("export = %+v, want 3 snapshots, 1 operation, 5 blobs", exported)
+    }
+
+    // Base cible : la première entrée y est déjà, sous un autre identifiant, et les
+    // identifiants de la source y sont occupés
+    target := newTestManager(t)
+    for _, s := range []*types.ContainerSnapshot{
+        exportSnapshot("web", "httpd:2.4", t1.Add(-2*time.Hour)),
+        exportSnapshot("web", "httpd:2.4", t1.Add(-time.Hour)),
+        exportSnapshot("app", "nginx:1.27", t1),
+    } {
+        if _, _, err := target.db.MergeSnapshot(s); err != nil {
+            t.Fatal(err)
+        }
+    }
+
+    // Aperçu : rien n'est écrit
+    preview, err := target.ImportHistory(ctx, bytes.NewReader(archive.Bytes()), options.ImportOptions{DryRun: true})
+    if err != nil {
+        t.Fatal(err)
+    }
+    if preview.Imported != 2 || preview.Existing != 1 || preview.OperationsImported != 1 {
+        t.Errorf("dry run = %+v, want 2 imported, 1 existing, 1 operation", preview)
+    }
+    if history, _ := target.db.GetHistory(options.HistoryOptions{}); len(history) != 3 {
+        t.Fatalf("dry run wrote %d entries", len(history)-3)
+    }
+
+    imported, err := target.ImportHistory(ctx, bytes.NewReader(archive.Bytes()), options.ImportOptions{})
+    if err != nil {
+        t.Fatal(err)
+    }
+    if imported.Snapshots != 3 || imported.Imported != 2 || imported.Existing != 1 ||
+        imported.Operations != 1 || imported.OperationsImported != 1 {
+        t.Errorf("import = %+v, want 2 imported, 1 existing, 1 operation", imported)
+    }
+
+    // Entrée importée : configurations, snapshots ZFS et delta conservés
+    second, err := target.db.GetSnapshot("local", "app", 0)
+    if err != nil {
+        t.Fatal(err)
+    }
+    want := exportSnapshot("app", "nginx:1.27", t1.Add(time.Hour))
+    if !second.CreatedAt.Equal(want.CreatedAt) || !bytes.Equal(second.Config, want.Config) ||
+        !bytes.Equal(second.HostConfig, want.HostConfig) || !reflect.DeepEqual(second.ZFSSnapshots, want.ZFSSnapshots) ||
+        second.ImageRef.Tag != want.ImageRef.Tag || second.SnapshotMode != "pause" {
+        t.Errorf("imported entry = %+v", second)
+    }
+    history, err := target.db.GetHistory(options.HistoryOptions{Container: []string{"app"}})
+    if err != nil {
+        t.Fatal(err)
+    }
+    if len(history) != 2 || history[0].ID != second.ID || !reflect.DeepEqual(history[0].VulnDelta, delta) {
+        t.Errorf("app history = %+v, want the vulnerability delta on entry %d", history, second.ID)
+    }
+
+    // L'opération référence l'entrée sous son nouvel identifiant
+    ops, err := target.db.GetOperations(options.HistoryOptions{})
+    if err != nil {
+        t.Fatal(err)
+    }
+    if len(ops) != 1 || ops[0].SnapshotID != second.ID || ops[0].SnapshotID == ids[1] {
+        t.Errorf("operations = %+v, want a reference to entry %d", ops, second.ID)
+    }
+
+    // Réimport : tout est déjà présent
+    again, err := target.ImportHistory(ctx, bytes.NewReader(archive.Bytes()), options.ImportOptions{})
+    if err != nil {
+        t.Fatal(err)
+    }
+    if again.Imported != 0 || again.Existing != 3 || again.OperationsImported != 0 {
+        t.Errorf("second import = %+v, want everything existing", again)
+    }
+
+    // Rattachement à un autre hôte
+    moved, err := target.ImportHistory(ctx, bytes.NewReader(archive.Bytes()), options.ImportOptions{Host: "nas"})
+    if err != nil {
+        t.Fatal(err)
+    }
+    if moved.Imported != 3 || moved.OperationsImported != 1 {
+        t.Errorf("import on host nas = %+v, want 3 entries and 1 operation", moved)
+    }
+    if _, err := target.db.GetSnapshot("nas", "db", 0); err != nil {
+        t.Errorf("entry not imported on host nas: %v", err)
+    }
+
+    // Archive tronquée
+    truncated := archive.Bytes()[:archive.Len()/2]
+    if _, err := newTestManager(t).ImportHistory(ctx, bytes.NewReader(truncated), options.ImportOptions{}); err == nil {
+        t.Error("truncated archive imported")
+    }
+}
@@ -0,0 +1,28 @@
+// internal/manager/fakedocker_test.go
+package manager
+
+import (
+    "net/http/httptest"
+    "strings"
+    "testing"
+
+    "zockimate/internal/docker"
+    "zockimate/internal/hosts"
+)
+
+// addTestHost démarre un démon Docker en mémoire et l'ajoute aux hôtes du manager
+func addTestHost(t *testing.T, cm *ContainerManager, name string) *docker.FakeDaemon {
+    t.Helper()
+    d := docker.NewFakeDaemon()
+    srv := httptest.NewServer(d)
+    t.Cleanup(srv.Close)
+
+    cli, err := docker.NewClient(hosts.Host{Name: name, Endpoint: "tcp://" + strings.TrimPrefix(srv.URL, "http://")},
+        nil, docker.PullOptions{}, cm.logger)
+    if err != nil {
+        t.Fatal(err)
+    }
+    t.Cleanup(func() { cli.Close() })
+    cm.hosts[name] = &hostBackend{name: name, docker: cli}
+    return d
+}
@@ -0,0 +1,158 @@
+// internal/manager/freeze.go
+package manager
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "sync"
+    "time"
+
+    "zockimate/pkg/utils"
+)
+
+// Modes de cohérence des snapshots de données (label zockimate.snapshot_mode)
+const (
+    snapshotModeLive  = "live"  // Snapshot pendant que le conteneur écrit (défaut)
+    snapshotModePause = "pause" // Gel des processus (ContainerPause) pendant le snapshot
+    snapshotModeStop  = "stop"  // Arrêt du conteneur pendant le snapshot
+    snapshotModeHook  = "hook"  // Commandes quiesce/unquiesce exécutées dans le conteneur
+
+    // Durée maximale de gel par défaut (zockimate.freeze_timeout)
+    defaultFreezeTimeout = 30 * time.Second
+)
+
+// freezeData exécute take (le snapshot ZFS) en gelant le conteneur selon
+// zockimate.snapshot_mode. Le gel et le dégel sont chacun bornés par
+// zockimate.freeze_timeout, et le conteneur est toujours dégelé, au plus tard à
+// l'expiration de ce délai ; un snapshot terminé après cette
+// échéance n'est plus cohérent et est retourné avec une erreur pour être supprimé.
+// Retourne les snapshots créés, le mode appliqué et la durée de gel.
+func (cm *ContainerManager) freezeData(ctx context.Context, host *hostBackend, name string,
+    labels map[string]string, take func() ([]string, error)) ([]string, string, time.Duration, error) {
+
+    mode := utils.GetSnapshotMode(labels)
+    switch mode {
+    case "":
+        mode = snapshotModeLive
+    case snapshotModeLive, snapshotModePause, snapshotModeStop, snapshotModeHook:
+    default:
+        return nil, "", 0, fmt.Errorf("invalid zockimate.snapshot_mode label: %q (use live, pause, stop or hook)", mode)
+    }
+
+    timeout, err := utils.GetFreezeTimeout(labels, defaultFreezeTimeout)
+    if err != nil {
+        return nil, "", 0, err
+    }
+
+    live := func() ([]string, string, time.Duration, error) {
+        snapshots, err := take()
+        return snapshots, snapshotModeLive, 0, err
+    }
+
+    if mode == snapshotModeLive {
+        return live()
+    }
+    if _, ok := serviceName(name); ok {
+        cm.logger.Warnf("zockimate.snapshot_mode %s is not supported for Swarm services, taking a live snapshot", mode)
+        return live()
+    }
+
+    // Un conteneur arrêté (ou déjà en pause) n'écrit pas
+    ctn, err := host.docker.InspectContainer(ctx, name)
+    if err != nil {
+        return nil, "", 0, fmt.Errorf("failed to inspect container: %w", err)
+    }
+    if !ctn.State.Running || ctn.State.Paused {
+        cm.logger.Debugf("Container %s is not running, no freeze needed", name)
+        return live()
+    }
+
+    freeze, thaw, err := cm.freezeFuncs(host, name, mode, labels)
+    if err != nil {
+        return nil, "", 0, err
+    }
+
+    // Le dégel ne dépend pas du contexte de l'appelant, qui a pu expirer entre-temps,
+    // mais reste borné pour ne jamais bloquer le snapshot indéfiniment
+    thawWithin := func() error {
+        thawCtx, cancel := context.WithTimeout(context.Background(), timeout)
+        defer cancel()
+        return thaw(thawCtx)
+    }
+
+    cm.logger.Debugf("Freezing container %s (%s, timeout %s)", name, mode, timeout)
+    freezeCtx, cancel := context.WithTimeout(ctx, timeout)
+    err = freeze(freezeCtx)
+    cancel()
+    if err != nil {
+        // Le quiesce a pu être partiellement appliqué, et le démon peut terminer
+        // une pause ou un arrêt dont l'attente a expiré
+        if mode == snapshotModeHook || errors.Is(err, context.DeadlineExceeded) {
+            if thawErr := thawWithin(); thawErr != nil {
+                cm.logger.Warnf("Failed to resume container %s after a failed freeze: %v", name, thawErr)
+            }
+        }
+        return nil, "", 0, fmt.Errorf("failed to freeze container (%s): %w", mode, err)
+    }
+    start := time.Now()
+
+    // Le dégel est garanti une seule fois : à l'échéance ou après le snapshot
+    var once sync.Once
+    var frozen time.Duration
+    var thawErr error
+    doThaw := func() {
+        once.Do(func() {
+            frozen = time.Since(start)
+            thawErr = thawWithin()
+        })
+    }
+    timer := time.AfterFunc(timeout, func() {
+        cm.logger.Warnf("Freeze timeout (%s) reached for container %s, resuming it", timeout, name)
+        doThaw()
+    })
+
+    snapshots, err := take()
+    timedOut := !timer.Stop()
+    doThaw()
+
+    cm.logger.Debugf("Container %s frozen for %s", name, frozen)
+
+    if thawErr != nil {
+        cm.notifyf("Container Not Resumed",
+            "Container %s could not be resumed after its data snapshot (%s): %v", name, mode, thawErr)
+        if err == nil {
+            err = fmt.Errorf("failed to resume container after snapshot (%s): %w", mode, thawErr)
+        }
+    }
+    if timedOut && err == nil {
+        err = fmt.Errorf("data snapshot took longer than the %s freeze timeout", timeout)
+    }
+    return snapshots, mode, frozen, err
+}
+
+// freezeFuncs retourne les opérations de gel et de dégel d'un mode
+func (cm *ContainerManager) freezeFuncs(host *hostBackend, name, mode string,
+    labels map[string]string) (freeze, thaw func(context.Context) error, err error) {
+
+    switch mode {
+    case snapshotModePause:
+        return func(ctx context.Context) error { return host.docker.PauseContainer(ctx, name) },
+            func(ctx context.Context) error { return host.docker.UnpauseContainer(ctx, name) }, nil
+    case snapshotModeStop:
+        return func(ctx context.Context) error { return host.docker.StopContainer(ctx, name) },
+            func(ctx context.Context) error { return host.docker.StartContainer(ctx, name) }, nil
+    default: // snapshotModeHook
+        quiesce, unquiesce := utils.GetQuiesceHooks(labels)
+        if quiesce == "" {
+            return nil, nil, fmt.Errorf("zockimate.snapshot_mode hook requires a zockimate.quiesce_cmd label")
+        }
+        return func(ctx context.Context) error { return host.docker.ExecContainer(ctx, name, quiesce) },
+            func(ctx context.Context) error {
+                if unquiesce == "" {
+                    return nil
+                }
+                return host.docker.ExecContainer(ctx, name, unquiesce)
+            }, nil
+    }
+}
@@ -0,0 +1,200 @@
+// internal/manager/freeze_test.go
+package manager
+
+import (
+    "context"
+    "net/http"
+    "reflect"
+    "strings"
+    "testing"
+    "time"
+
+    "zockimate/internal/docker"
+)
+
+// addFreezeContainer démarre le conteneur app avec les labels de gel donnés
+func addFreezeContainer(t *testing.T, cm *ContainerManager, labels map[string]string) *docker.FakeDaemon {
+    t.Helper()
+    d := addTestHost(t, cm, "local")
+    d.AddImage(testImageID, "nginx:1.27")
+    d.RunContainer("app", "nginx:1.27", labels)
+    return d
+}
+
+// blockUntilCancelled simule une requête qui ne répond qu'à l'annulation du client
+func blockUntilCancelled(w http.ResponseWriter, r *http.Request) {
+    select {
+    case <-r.Context().Done():
+    case <-time.After(5 * time.Second):
+    }
+}
+
+func TestFreezeData(t *testing.T) {
+    tests := []struct {
+        mode   string
+        labels map[string]string
+        frozen func(d *docker.FakeDaemon) bool // État du conteneur pendant le snapshot
+        execs  []string
+    }{
+        {"pause", nil, func(d *docker.FakeDaemon) bool { return d.Container("app").State.Paused }, nil},
+        {"stop", nil, func(d *docker.FakeDaemon) bool { return !d.Container("app").State.Running }, nil},
+        {"hook", map[string]string{"zockimate.quiesce_cmd": "redis-cli SAVE", "zockimate.unquiesce_cmd": "echo done"},
+            func(d *docker.FakeDaemon) bool { return reflect.DeepEqual(d.Execs, []string{"redis-cli SAVE"}) },
+            []string{"redis-cli SAVE", "echo done"}},
+    }
+    for _, tt := range tests {
+        t.Run(tt.mode, func(t *testing.T) {
+            cm := newTestManager(t)
+            labels := map[string]string{"zockimate.snapshot_mode": tt.mode}
+            for k, v := range tt.labels {
+                labels[k] = v
+            }
+            d := addFreezeContainer(t, cm, labels)
+
+            var frozen bool
+            snapshots, mode, duration, err := cm.freezeData(context.Background(), cm.hosts["local"], "app", labels,
+                func() ([]string, error) {
+                    frozen = tt.frozen(d)
+                    return []string{"tank/app@s1"}, nil
+                })
+            if err != nil {
+                t.Fatal(err)
+            }
+            if !frozen {
+                t.Error("container not frozen during the snapshot")
+            }
+            if mode != tt.mode || duration <= 0 || !reflect.DeepEqual(snapshots, []string{"tank/app@s1"}) {
+                t.Errorf("freezeData = %v, %s, %s", snapshots, mode, duration)
+            }
+            if state := d.Container("app").State; !state.Running || state.Paused {
+                t.Errorf("container not resumed: %+v", state)
+            }
+            if !reflect.DeepEqual(d.Execs, tt.execs) {
+                t.Errorf("execs = %v, want %v", d.Execs, tt.execs)
+            }
+        })
+    }
+}
+
+func TestFreezeDataLive(t *testing.T) {
+    cm := newTestManager(t)
+    labels := map[string]string{"zockimate.snapshot_mode": "pause"}
+    d := addFreezeContainer(t, cm, labels)
+    d.Container("app").State.Running = false
+
+    // Conteneur arrêté : snapshot sans gel
+    _, mode, duration, err := cm.freezeData(context.Background(), cm.hosts["local"], "app", labels,
+        func() ([]string, error) { return nil, nil })
+    if err != nil || mode != "live" || duration != 0 {
+        t.Errorf("freezeData(stopped container) = %s, %s, %v, want live", mode, duration, err)
+    }
+    if d.Called("POST /containers/app/pause") {
+        t.Error("stopped container paused")
+    }
+
+    for _, labels := range []map[string]string{
+        {"zockimate.snapshot_mode": "freeze"},
+        {"zockimate.snapshot_mode": "hook"},
+        {"zockimate.snapshot_mode": "pause", "zockimate.freeze_timeout": "0s"},
+    } {
+        d.Container("app").State.Running = true
+        if _, _, _, err := cm.freezeData(context.Background(), cm.hosts["local"], "app", labels,
+            func() ([]string, error) { t.Error("snapshot taken"); return nil, nil }); err == nil {
+            t.Errorf("freezeData(%v) succeeded", labels)
+        }
+    }
+}
+
+func TestFreezeDataHookFailure(t *testing.T) {
+    cm := newTestManager(t)
+    labels := map[string]string{
+        "zockimate.snapshot_mode": "hook",
+        "zockimate.quiesce_cmd":   "pg_ctl stop",
+        "zockimate.unquiesce_cmd": "pg_ctl start",
+    }
+    d := addFreezeContainer(t, cm, labels)
+    d.ExecCodes["pg_ctl stop"] = 1
+
+    // Quiesce en échec : pas de snapshot, unquiesce exécuté
+    _, _, _, err := cm.freezeData(context.Background(), cm.hosts["local"], "app", labels,
+        func() ([]string, error) { t.Error("snapshot taken after a failed quiesce"); return nil, nil })
+    if err == nil || !strings.Contains(err.Error(), "exited with code 1") {
+        t.Errorf("error = %v, want the quiesce exit code", err)
+    }
+    if !reflect.DeepEqual(d.Execs, []string{"pg_ctl stop", "pg_ctl start"}) {
+        t.Errorf("execs = %v, want quiesce then unquiesce", d.Execs)
+    }
+
+    // Unquiesce en échec : le snapshot est retourné avec l'erreur
+    d.ExecCodes["pg_ctl stop"] = 0
+    d.ExecCodes["pg_ctl start"] = 2
+    snapshots, _, _, err := cm.freezeData(context.Background(), cm.hosts["local"], "app", labels,
+        func() ([]string, error) { return []string{"tank/app@s1"}, nil })
+    if err == nil || !strings.Contains(err.Error(), "failed to resume") || len(snapshots) != 1 {
+        t.Errorf("freezeData = %v, %v, want the snapshot and a resume error", snapshots, err)
+    }
+}
+
+func TestFreezeDataTimeout(t *testing.T) {
+    labels := map[string]string{"zockimate.snapshot_mode": "pause", "zockimate.freeze_timeout": "200ms"}
+
+    t.Run("freeze", func(t *testing.T) {
+        cm := newTestManager(t)
+        d := addFreezeContainer(t, cm, labels)
+        d.Handlers["POST /containers/app/pause"] = blockUntilCancelled
+
+        start := time.Now()
+        _, _, _, err := cm.freezeData(context.Background(), cm.hosts["local"], "app", labels,
+            func() ([]string, error) { t.Error("snapshot taken without freeze"); return nil, nil })
+        if err == nil || !strings.Contains(err.Error(), "failed to freeze") {
+            t.Errorf("error = %v, want a freeze failure", err)
+        }
+        if elapsed := time.Since(start); elapsed > 2*time.Second {
+            t.Errorf("freeze waited %s, want the 200ms timeout", elapsed)
+        }
+        // Le gel a pu aboutir côté démon : dégel tenté
+        if !d.Called("POST /containers/app/unpause") {
+            t.Error("container not resumed after a freeze timeout")
+        }
+    })
+
+    t.Run("snapshot", func(t *testing.T) {
+        cm := newTestManager(t)
+        d := addFreezeContainer(t, cm, labels)
+
+        // Dégel à l'échéance, pendant le snapshot ; le snapshot est retourné pour suppression
+        var resumed bool
+        snapshots, _, duration, err := cm.freezeData(context.Background(), cm.hosts["local"], "app", labels,
+            func() ([]string, error) {
+                time.Sleep(500 * time.Millisecond)
+                resumed = !d.Container("app").State.Paused
+                return []string{"tank/app@s1"}, nil
+            })
+        if err == nil || !strings.Contains(err.Error(), "freeze timeout") || len(snapshots) != 1 {
+            t.Errorf("freezeData = %v, %v, want the snapshot and a timeout error", snapshots, err)
+        }
+        if !resumed {
+            t.Error("container still paused after the freeze timeout")
+        }
+        if duration >= 500*time.Millisecond {
+            t.Errorf("freeze duration = %s, want about 200ms", duration)
+        }
+    })
+
+    t.Run("thaw", func(t *testing.T) {
+        cm := newTestManager(t)
+        d := addFreezeContainer(t, cm, labels)
+        d.Handlers["POST /containers/app/unpause"] = blockUntilCancelled
+
+        // Dégel borné même si le contexte de l'appelant n'expire jamais
+        start := time.Now()
+        _, _, _, err := cm.freezeData(context.Background(), cm.hosts["local"], "app", labels,
+            func() ([]string, error) { return []string{"tank/app@s1"}, nil })
+        if err == nil || !strings.Contains(err.Error(), "failed to resume") {
+            t.Errorf("error = %v, want a resume failure", err)
+        }
+        if elapsed := time.Since(start); elapsed > 2*time.Second {
+            t.Errorf("thaw waited %s, want the 200ms timeout", elapsed)
+        }
+    })
+}
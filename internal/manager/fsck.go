@@ -0,0 +1,191 @@
+// internal/manager/fsck.go
+package manager
+
+import (
+    "context"
+    "fmt"
+    "sort"
+    "strings"
+
+    "zockimate/internal/storage/zfs"
+    "zockimate/internal/types"
+    "zockimate/internal/types/options"
+    "zockimate/pkg/utils"
+)
+
+// Fsck compare, sur chaque hôte disposant de ZFS, les snapshots zockimate (snapshot_*)
+// des datasets gérés aux groupes référencés par la base. Sont signalés les snapshots
+// sans entrée (orphelins) et les entrées dont un snapshot a disparu. Une entrée dont
+// les snapshots manquants sont sur la réplique reste valide : le rollback les rapatrie,
+// et ses snapshots locaux sont conservés. Avec Fix, les orphelins sont détruits et les
+// entrées incomplètes marquées sans données ; les snapshots restants d'un groupe
+// incomplet sont eux aussi orphelins.
+func (cm *ContainerManager) Fsck(ctx context.Context, opts options.FsckOptions) ([]*types.FsckResult, error) {
+    cm.lock.Lock()
+    defer cm.lock.Unlock()
+
+    names := make([]string, 0, len(cm.hosts))
+    for name := range cm.hosts {
+        names = append(names, name)
+    }
+    sort.Strings(names)
+
+    var results []*types.FsckResult
+    for _, hostName := range names {
+        host := cm.hosts[hostName]
+        if host.err != nil || host.zfs == nil {
+            continue
+        }
+        result := &types.FsckResult{Host: hostName}
+        if err := cm.fsckHost(ctx, host, opts, result); err != nil {
+            result.Error = err.Error()
+        }
+        results = append(results, result)
+    }
+    return results, nil
+}
+
+// fsckHost vérifie un hôte
+func (cm *ContainerManager) fsckHost(ctx context.Context, host *hostBackend, opts options.FsckOptions,
+    result *types.FsckResult) error {
+
+    groups, err := cm.db.GetZFSGroups(host.name)
+    if err != nil {
+        return err
+    }
+
+    // Datasets gérés (avec leurs enfants si récursif) : référencés par la base,
+    // configurés sur les conteneurs, ou mis de côté par une restauration
+    datasets := make(map[string]bool)
+    for _, g := range groups {
+        for _, s := range g.Snapshots {
+            dataset, _, _ := strings.Cut(s, "@")
+            if _, ok := datasets[dataset]; !ok {
+                datasets[dataset] = false
+            }
+        }
+    }
+
+    containers, err := host.docker.ListContainers(ctx, true)
+    if err != nil {
+        return err
+    }
+    for _, ctn := range containers {
+        if !cm.config.NoFilter && !utils.IsContainerEnabled(ctn.Labels) {
+            continue
+        }
+        if utils.GetZFSDataset(ctn.Labels) == "" {
+            continue
+        }
+        var mountPaths []string
+        for _, m := range ctn.Mounts {
+            mountPaths = append(mountPaths, m.Source)
+        }
+        configured, err := snapshotDatasets(host.zfs, ctn.Labels, mountPaths)
+        if err != nil {
+            return err
+        }
+        for _, d := range configured {
+            datasets[d] = datasets[d] || utils.IsZFSRecursive(ctn.Labels)
+        }
+    }
+
+    previous, err := host.zfs.PreviousDatasets()
+    if err != nil {
+        return err
+    }
+    for _, d := range previous {
+        if _, ok := datasets[d]; !ok {
+            datasets[d] = false
+        }
+    }
+
+    existing := make(map[string]bool)
+    for d, recursive := range datasets {
+        result.Datasets = append(result.Datasets, d)
+        snapshots, err := host.zfs.ListSnapshots(d, recursive)
+        if err != nil {
+            return err
+        }
+        for _, s := range snapshots {
+            existing[s] = true
+        }
+    }
+    sort.Strings(result.Datasets)
+
+    referenced, err := classifyGroups(host.zfs, groups, existing, result)
+    if err != nil {
+        return err
+    }
+
+    for s := range existing {
+        if strings.Contains(s, "@snapshot_") && !referenced[s] {
+            result.OrphanSnapshots = append(result.OrphanSnapshots, s)
+        }
+    }
+    sort.Strings(result.OrphanSnapshots)
+
+    if !opts.Fix || opts.DryRun {
+        return nil
+    }
+
+    // Corriger la base d'abord : une entrée ne doit jamais pointer vers un snapshot détruit
+    if len(result.BrokenEntries) > 0 {
+        ids := make([]int64, 0, len(result.BrokenEntries))
+        for _, e := range result.BrokenEntries {
+            ids = append(ids, e.SnapshotID)
+        }
+        if err := cm.db.ClearZFSSnapshots(ids); err != nil {
+            return err
+        }
+        result.ClearedEntries = len(ids)
+    }
+
+    var failed []string
+    for _, s := range result.OrphanSnapshots {
+        if err := host.zfs.DeleteSnapshot([]string{s}); err != nil {
+            failed = append(failed, err.Error())
+            continue
+        }
+        result.DeletedSnapshots++
+    }
+    if len(failed) > 0 {
+        return fmt.Errorf("failed to delete orphan snapshots: %s", strings.Join(failed, "; "))
+    }
+    return nil
+}
+
+// classifyGroups relève les entrées dont des snapshots manquent : cassées, ou
+// complétables par la réplique. Retourne les snapshots protégés, ceux des groupes
+// complets ou que la réplique peut compléter.
+func classifyGroups(z *zfs.ZFSManager, groups []types.ZFSGroup, existing map[string]bool,
+    result *types.FsckResult) (map[string]bool, error) {
+
+    referenced := make(map[string]bool)
+    for _, g := range groups {
+        var missing []string
+        for _, s := range g.Snapshots {
+            if !existing[s] {
+                missing = append(missing, s)
+            }
+        }
+        if len(missing) > 0 {
+            // Une réplique injoignable interrompt la vérification : sans réponse,
+            // l'entrée ne peut pas être déclarée perdue
+            onReplica, err := z.OnReplica(missing)
+            if err != nil {
+                return nil, err
+            }
+            entry := types.FsckEntry{ZFSGroup: g, Missing: missing}
+            if !onReplica {
+                result.BrokenEntries = append(result.BrokenEntries, entry)
+                continue
+            }
+            result.ReplicaEntries = append(result.ReplicaEntries, entry)
+        }
+        for _, s := range g.Snapshots {
+            referenced[s] = true
+        }
+    }
+    return referenced, nil
+}
@@ -0,0 +1,90 @@
+// internal/manager/fsck_test.go
+package manager
+
+import (
+    "io"
+    "reflect"
+    "testing"
+
+    "github.com/sirupsen/logrus"
+
+    "zockimate/internal/storage/zfs"
+    "zockimate/internal/types"
+)
+
+func TestClassifyGroups(t *testing.T) {
+    logger := logrus.New()
+    logger.SetOutput(io.Discard)
+
+    local := zfs.NewFakeBackend("tank/app", "tank/db")
+    for _, name := range []string{"snapshot_1", "snapshot_2", "snapshot_3"} {
+        if err := local.Snapshot([]string{"tank/app@" + name, "tank/db@" + name}, nil); err != nil {
+            t.Fatal(err)
+        }
+    }
+    // Groupe 2 : tank/db détruit localement mais répliqué ; groupe 3 : perdu
+    replica := zfs.NewFakeBackend("backup/tank/app", "backup/tank/db")
+    if err := replica.Snapshot([]string{"backup/tank/db@snapshot_2"}, nil); err != nil {
+        t.Fatal(err)
+    }
+    for _, s := range []string{"tank/db@snapshot_2", "tank/db@snapshot_3"} {
+        if err := local.Destroy(s); err != nil {
+            t.Fatal(err)
+        }
+    }
+
+    existing := map[string]bool{
+        "tank/app@snapshot_1": true, "tank/db@snapshot_1": true,
+        "tank/app@snapshot_2": true, "tank/app@snapshot_3": true,
+    }
+    groups := []types.ZFSGroup{
+        {SnapshotID: 1, ContainerName: "app", Snapshots: []string{"tank/app@snapshot_1", "tank/db@snapshot_1"}},
+        {SnapshotID: 2, ContainerName: "app", Snapshots: []string{"tank/app@snapshot_2", "tank/db@snapshot_2"}},
+        {SnapshotID: 3, ContainerName: "app", Snapshots: []string{"tank/app@snapshot_3", "tank/db@snapshot_3"}},
+    }
+
+    t.Run("with replica", func(t *testing.T) {
+        z := zfs.NewFakeZFSManager(local, logger)
+        z.SetReplica(zfs.NewFakeReplica("backup", replica, logger))
+
+        result := &types.FsckResult{}
+        referenced, err := classifyGroups(z, groups, existing, result)
+        if err != nil {
+            t.Fatal(err)
+        }
+        if len(result.ReplicaEntries) != 1 || result.ReplicaEntries[0].SnapshotID != 2 ||
+            !reflect.DeepEqual(result.ReplicaEntries[0].Missing, []string{"tank/db@snapshot_2"}) {
+            t.Errorf("replica entries = %+v, want entry 2 missing tank/db@snapshot_2", result.ReplicaEntries)
+        }
+        if len(result.BrokenEntries) != 1 || result.BrokenEntries[0].SnapshotID != 3 {
+            t.Errorf("broken entries = %+v, want entry 3", result.BrokenEntries)
+        }
+
+        // Les survivants d'un groupe que la réplique complète ne sont pas orphelins
+        for _, s := range []string{"tank/app@snapshot_1", "tank/app@snapshot_2"} {
+            if !referenced[s] {
+                t.Errorf("%s not protected", s)
+            }
+        }
+        if referenced["tank/app@snapshot_3"] {
+            t.Error("survivor of a lost group protected")
+        }
+    })
+
+    t.Run("without replica", func(t *testing.T) {
+        z := zfs.NewFakeZFSManager(local, logger)
+
+        result := &types.FsckResult{}
+        referenced, err := classifyGroups(z, groups, existing, result)
+        if err != nil {
+            t.Fatal(err)
+        }
+        if len(result.ReplicaEntries) != 0 || len(result.BrokenEntries) != 2 {
+            t.Errorf("replica entries = %d, broken entries = %d, want 0 and 2",
+                len(result.ReplicaEntries), len(result.BrokenEntries))
+        }
+        if referenced["tank/app@snapshot_2"] {
+            t.Error("survivor of an incomplete group protected without replica")
+        }
+    })
+}
@@ -0,0 +1,157 @@
+// internal/manager/hosts.go
+package manager
+
+import (
+    "fmt"
+    "time"
+
+    "github.com/sirupsen/logrus"
+
+    "zockimate/internal/docker"
+    "zockimate/internal/hosts"
+    "zockimate/internal/registry"
+    "zockimate/internal/storage/zfs"
+    "zockimate/pkg/utils"
+)
+
+// hostBackend regroupe les clients d'un hôte Docker géré
+type hostBackend struct {
+    name   string
+    docker *docker.Client
+    zfs    *zfs.ZFSManager // nil si ZFS n'est pas accessible sur l'hôte
+    err    error           // Erreur de connexion si l'hôte est indisponible
+}
+
+// zfsManager retourne le gestionnaire ZFS de l'hôte
+func (h *hostBackend) zfsManager() (*zfs.ZFSManager, error) {
+    if h.zfs == nil {
+        return nil, fmt.Errorf("ZFS not available on host %s (set zfs_ssh in the hosts file)", h.name)
+    }
+    return h.zfs, nil
+}
+
+// connectHosts crée les clients Docker et ZFS de chaque hôte.
+// Un hôte injoignable est conservé avec son erreur, sauf s'il est le seul configuré.
+func connectHosts(list []hosts.Host, creds *registry.CredentialStore, pull docker.PullOptions,
+    logger *logrus.Logger) (map[string]*hostBackend, error) {
+
+    backends := make(map[string]*hostBackend)
+    for _, h := range list {
+        backend := &hostBackend{name: h.Name}
+
+        dockerClient, err := docker.NewClient(h, creds, pull, logger)
+        if err != nil {
+            if len(list) == 1 {
+                return nil, fmt.Errorf("failed to create Docker client: %w", err)
+            }
+            logger.Warnf("Host %s unavailable: %v", h.Name, err)
+            backend.err = err
+        }
+        backend.docker = dockerClient
+
+        if target, ok := h.ZFSTarget(); ok {
+            if target == "" {
+                backend.zfs = zfs.NewZFSManager(logger)
+            } else {
+                backend.zfs = zfs.NewRemoteZFSManager(target, logger)
+            }
+
+            // Sans cible SSH propre, le pool de réplication est sur la machine de zfs
+            if h.ZFSReplica != "" {
+                replicaSSH := h.ZFSReplicaSSH
+                if replicaSSH == "" {
+                    replicaSSH = target
+                }
+                backend.zfs.SetReplica(zfs.NewReplica(h.ZFSReplica, replicaSSH, logger))
+            }
+        }
+
+        backends[h.Name] = backend
+    }
+    return backends, nil
+}
+
+// configureZFS applique le délai des commandes zfs et choisit le backend de chaque hôte.
+// Les ioctls de /dev/zfs ne sont utilisables que sur la machine locale ; en mode auto,
+// la commande zfs est conservée si /dev/zfs n'est pas accessible.
+func configureZFS(backends map[string]*hostBackend, mode string, timeout time.Duration,
+    logger *logrus.Logger) error {
+
+    for name, b := range backends {
+        if b.zfs == nil {
+            continue
+        }
+        b.zfs.SetTimeout(timeout)
+        if mode == "cli" || !b.zfs.IsLocal() {
+            continue
+        }
+        if err := b.zfs.EnableNative(); err != nil {
+            if mode == "native" {
+                return fmt.Errorf("host %s: %w", name, err)
+            }
+            logger.Debugf("Host %s: using zfs command (%v)", name, err)
+            continue
+        }
+        logger.Debugf("Host %s: using native ZFS ioctls", name)
+    }
+    return nil
+}
+
+// closeHosts ferme les clients Docker et ZFS des hôtes
+func closeHosts(backends map[string]*hostBackend) error {
+    var errs []error
+    for name, b := range backends {
+        if b.zfs != nil {
+            if err := b.zfs.Close(); err != nil {
+                errs = append(errs, fmt.Errorf("failed to close ZFS backend for host %s: %w", name, err))
+            }
+        }
+        if b.docker == nil {
+            continue
+        }
+        if err := b.docker.Close(); err != nil {
+            errs = append(errs, fmt.Errorf("failed to close Docker client for host %s: %w", name, err))
+        }
+    }
+    if len(errs) > 0 {
+        return fmt.Errorf("%v", errs)
+    }
+    return nil
+}
+
+// zfsManagers retourne les gestionnaires ZFS par hôte
+func zfsManagers(backends map[string]*hostBackend) map[string]*zfs.ZFSManager {
+    managers := make(map[string]*zfs.ZFSManager)
+    for name, b := range backends {
+        if b.zfs != nil {
+            managers[name] = b.zfs
+        }
+    }
+    return managers
+}
+
+// resolve retourne l'hôte et le nom d'un conteneur identifié par "hôte/conteneur".
+// Un nom non qualifié désigne l'hôte par défaut.
+func (cm *ContainerManager) resolve(id string) (*hostBackend, string, error) {
+    host, name := utils.SplitContainerID(id)
+    if host == "" {
+        host = hosts.DefaultHost
+    }
+
+    backend, ok := cm.hosts[host]
+    if !ok {
+        if host == hosts.DefaultHost {
+            return nil, "", fmt.Errorf("no %q host configured, use host/container", hosts.DefaultHost)
+        }
+        return nil, "", fmt.Errorf("unknown host %q", host)
+    }
+    if backend.err != nil {
+        return nil, "", fmt.Errorf("host %s unavailable: %w", host, backend.err)
+    }
+    return backend, name, nil
+}
+
+// containerID retourne l'identifiant d'un conteneur tel qu'affiché et accepté par les commandes
+func containerID(host, name string) string {
+    return utils.ContainerID(host, name, hosts.DefaultHost)
+}
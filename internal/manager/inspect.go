@@ -0,0 +1,87 @@
+// internal/manager/inspect.go
+package manager
+
+import (
+    "context"
+    "fmt"
+
+    "github.com/docker/docker/client"
+
+    "zockimate/internal/types"
+    "zockimate/pkg/utils"
+)
+
+// InspectContainer montre comment zockimate sauvegarde un conteneur : montages,
+// datasets ZFS qui les contiennent et datasets inclus dans les snapshots
+func (cm *ContainerManager) InspectContainer(ctx context.Context, name string) (*types.InspectResult, error) {
+    host, name, err := cm.resolve(name)
+    if err != nil {
+        return nil, err
+    }
+    if _, ok := serviceName(name); ok {
+        return nil, fmt.Errorf("inspect is not supported for Swarm services")
+    }
+
+    ctn, err := host.docker.InspectContainer(ctx, name)
+    if err != nil {
+        if client.IsErrNotFound(err) {
+            return nil, fmt.Errorf("container does not exist: %w", err)
+        }
+        return nil, fmt.Errorf("failed to inspect container: %w", err)
+    }
+
+    labels := ctn.Config.Labels
+    result := &types.InspectResult{
+        ContainerName: containerID(host.name, name),
+        Image:         ctn.Config.Image,
+        Running:       ctn.State.Running,
+        Enabled:       utils.IsContainerEnabled(labels),
+        DatasetLabel:  utils.GetZFSDataset(labels),
+        Recursive:     utils.IsZFSRecursive(labels),
+    }
+
+    backups, err := selectMounts(labels, ctn.Mounts)
+    if err != nil {
+        return nil, err
+    }
+    backedUp := make(map[string]bool, len(backups))
+    for _, b := range backups {
+        backedUp[b.Destination] = true
+    }
+
+    var mountPaths []string
+    for _, m := range ctn.Mounts {
+        mountPaths = append(mountPaths, m.Source)
+    }
+
+    // Associer les montages aux datasets si ZFS est accessible sur l'hôte
+    var mapping map[string]string
+    if zfsManager, err := host.zfsManager(); err != nil {
+        result.ZFSError = err.Error()
+    } else if mapping, err = discoverDatasets(zfsManager, mountPaths); err != nil {
+        result.ZFSError = err.Error()
+    } else if result.DatasetLabel != "" {
+        result.Datasets, err = snapshotDatasets(zfsManager, labels, mountPaths)
+        if err != nil {
+            return nil, err
+        }
+    }
+
+    for _, m := range ctn.Mounts {
+        result.Mounts = append(result.Mounts, types.InspectMount{
+            Type:        string(m.Type),
+            Source:      m.Source,
+            Destination: m.Destination,
+            RW:          m.RW,
+            Dataset:     mapping[m.Source],
+            Backup:      backedUp[m.Destination],
+        })
+    }
+
+    // Sans label, proposer les datasets découverts
+    if result.DatasetLabel == "" && mapping != nil {
+        result.Datasets = distinctDatasets(mapping, result.Recursive)
+    }
+
+    return result, nil
+}
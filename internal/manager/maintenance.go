@@ -0,0 +1,52 @@
+// internal/manager/maintenance.go
+package manager
+
+import (
+    "fmt"
+    "time"
+
+    "zockimate/internal/maintenance"
+    "zockimate/pkg/utils"
+)
+
+// containerWindow retourne la fenêtre de maintenance d'un conteneur
+// (label zockimate.window, sinon fenêtre globale)
+func (cm *ContainerManager) containerWindow(labels map[string]string) (maintenance.Windows, error) {
+    if spec := utils.GetMaintenanceWindow(labels); spec != "" {
+        return maintenance.ParseWindows(spec)
+    }
+    return cm.window, nil
+}
+
+// checkMaintenance indique si une mise à jour peut être appliquée à l'instant donné.
+// Retourne la raison du report, ou une chaîne vide si la mise à jour est autorisée.
+func (cm *ContainerManager) checkMaintenance(labels map[string]string, now time.Time) (string, error) {
+    if b, ok := cm.blackouts.Active(now); ok {
+        return fmt.Sprintf("blackout period %s", b.String()), nil
+    }
+
+    window, err := cm.containerWindow(labels)
+    if err != nil {
+        return "", err
+    }
+
+    if !window.Contains(now) {
+        next := window.Next(now)
+        if next.IsZero() {
+            return fmt.Sprintf("outside maintenance window (%s)", window.String()), nil
+        }
+        return fmt.Sprintf("outside maintenance window (%s), next opening %s",
+            window.String(), next.Format("2006-01-02 15:04 MST")), nil
+    }
+
+    return "", nil
+}
+
+// MaintenanceDeadline retourne l'heure de fermeture de la fenêtre globale ouverte à l'instant donné.
+// Retourne un temps zéro si aucune fenêtre globale n'est configurée.
+func (cm *ContainerManager) MaintenanceDeadline(now time.Time) time.Time {
+    if len(cm.window) == 0 {
+        return time.Time{}
+    }
+    return cm.window.EndOf(now)
+}
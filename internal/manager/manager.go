@@ -3,16 +3,24 @@ package manager
 
 import (
     "fmt"
+    "strconv"
+    "strings"
     "sync"
     "time"
     "context"
+    "path/filepath"
     
     "zockimate/pkg/utils"
     "zockimate/internal/config"
     "zockimate/internal/docker"
+    "zockimate/internal/hosts"
+    "zockimate/internal/maintenance"
+    "zockimate/internal/storage/archive"
     "zockimate/internal/storage/database"
-    "zockimate/internal/storage/zfs"
     "zockimate/internal/notify"
+    "zockimate/internal/registry"
+    "zockimate/internal/scan"
+    "zockimate/internal/verify"
     "zockimate/internal/types"
     "zockimate/internal/types/options"
     "github.com/sirupsen/logrus"
@@ -20,13 +28,19 @@ import (
 
 // ContainerManager coordonne toutes les opérations sur les conteneurs
 type ContainerManager struct {
-    docker  *docker.Client
+    hosts   map[string]*hostBackend // Hôtes Docker gérés, par nom
     db      *database.Database
-    zfs     *zfs.ZFSManager
+    archives *archive.Store // Archives des volumes sauvegardés
     notify  *notify.AppriseClient
+    verifier *verify.Verifier
+    scanner  *scan.Scanner
     config  *config.Config
     logger  *logrus.Logger
     lock    sync.RWMutex
+
+    // Fenêtre de maintenance par défaut et périodes de gel
+    window    maintenance.Windows
+    blackouts maintenance.Blackouts
 }
 
 // NewContainerManager crée une nouvelle instance du manager
@@ -37,19 +51,70 @@ func NewContainerManager(cfg *config.Config) (*ContainerManager, error) {
         logger.SetLevel(logrus.InfoLevel)
     }
 
-    // Initialiser le client Docker
-    dockerClient, err := docker.NewClient(logger)
+    // Parser la fenêtre de maintenance et les périodes de gel
+    window, err := maintenance.ParseWindows(cfg.Window)
+    if err != nil {
+        return nil, err
+    }
+    blackouts, err := maintenance.ParseBlackouts(cfg.Blackout)
+    if err != nil {
+        return nil, err
+    }
+
+    // Charger les identifiants des registres privés
+    dockerConfig := cfg.DockerConfig
+    if dockerConfig == "" {
+        dockerConfig = registry.DefaultDockerConfigPath()
+    }
+    creds, err := registry.NewCredentialStore(dockerConfig, cfg.RegistrySecrets, logger)
+    if err != nil {
+        return nil, err
+    }
+
+    // Charger les hôtes Docker gérés
+    hostList, err := hosts.Load(cfg.HostsFile)
+    if err != nil {
+        return nil, err
+    }
+
+    // Initialiser les clients Docker et ZFS de chaque hôte
+    // La réplication configurée par l'environnement s'applique à l'hôte local
+    for i := range hostList {
+        if hostList[i].Name == hosts.DefaultHost && hostList[i].ZFSReplica == "" {
+            hostList[i].ZFSReplica = cfg.ZFSReplica
+            hostList[i].ZFSReplicaSSH = cfg.ZFSReplicaSSH
+        }
+    }
+
+    bandwidth, err := cfg.PullBandwidthBytes()
     if err != nil {
-        return nil, fmt.Errorf("failed to create Docker client: %w", err)
+        return nil, err
+    }
+    backends, err := connectHosts(hostList, creds, docker.PullOptions{
+        Retries:   cfg.PullRetries,
+        Bandwidth: bandwidth,
+        Insecure:  strings.Split(cfg.InsecureRegistries, ","),
+    }, logger)
+    if err != nil {
+        return nil, err
+    }
+    if err := configureZFS(backends, cfg.ZFSBackend, time.Duration(cfg.ZFSTimeout)*time.Second, logger); err != nil {
+        closeHosts(backends)
+        return nil, err
     }
 
-    // Initialiser le gestionnaire ZFS
-    zfsManager := zfs.NewZFSManager(logger)
+    // Magasin des archives de volumes, par défaut à côté de la base SQLite
+    // (dans le répertoire courant avec une base PostgreSQL)
+    archiveDir := cfg.ArchiveDir
+    if archiveDir == "" {
+        archiveDir = filepath.Join(filepath.Dir(database.FilePath(cfg.DbPath)), "archives")
+    }
+    archives := archive.NewStore(archiveDir, logger)
 
     // Initialiser la base de données
-    db, err := database.NewDatabase(cfg.DbPath, zfsManager, logger)
+    db, err := database.NewDatabase(cfg.DbPath, zfsManagers(backends), archives, logger)
     if err != nil {
-        dockerClient.Close()
+        closeHosts(backends)
         return nil, fmt.Errorf("failed to initialize database: %w", err)
     }
 
@@ -71,13 +136,21 @@ func NewContainerManager(cfg *config.Config) (*ContainerManager, error) {
         }
     }
 
+    // Initialiser la vérification des signatures
+    registryClient := registry.NewClient(strings.Split(cfg.InsecureRegistries, ","), creds, logger)
+    verifier := verify.NewVerifier(registryClient, logger)
+
     return &ContainerManager{
-        docker:  dockerClient,
+        hosts:   backends,
         db:      db,
-        zfs:     zfsManager,
+        archives: archives,
         notify:  notifier,
+        verifier: verifier,
+        scanner:  scan.NewScanner(cfg.Scanner, cfg.ScanDir, logger),
         config:  cfg,
         logger:  logger,
+        window:    window,
+        blackouts: blackouts,
     }, nil
 }
 
@@ -85,8 +158,8 @@ func NewContainerManager(cfg *config.Config) (*ContainerManager, error) {
 func (cm *ContainerManager) Close() error {
     var errs []error
     
-    if err := cm.docker.Close(); err != nil {
-        errs = append(errs, fmt.Errorf("failed to close Docker client: %w", err))
+    if err := closeHosts(cm.hosts); err != nil {
+        errs = append(errs, err)
     }
     if err := cm.db.Close(); err != nil {
         errs = append(errs, fmt.Errorf("failed to close database: %w", err))
@@ -125,8 +198,11 @@ func (cm *ContainerManager) CreateSnapshot(ctx context.Context, name string, opt
     cm.lock.Lock()
     defer cm.lock.Unlock()
 
-    name = utils.CleanContainerName(name)
-    cm.logger.Debugf("Creating snapshot for container %s: %s", name, opts.Message)
+    host, name, err := cm.resolve(name)
+    if err != nil {
+        return nil, err
+    }
+    cm.logger.Debugf("Creating snapshot for container %s: %s", containerID(host.name, name), opts.Message)
 
     // Si dry-run, simuler seulement
     if opts.DryRun {
@@ -134,21 +210,120 @@ func (cm *ContainerManager) CreateSnapshot(ctx context.Context, name string, opt
         return nil, nil
     }
 
+    // Capturer l'état du conteneur ou du service
+    var snapshot *types.ContainerSnapshot
+    var labels map[string]string
+    var mountPaths []string
+    if svc, ok := serviceName(name); ok {
+        snapshot, labels, err = cm.captureService(ctx, host, svc)
+    } else {
+        snapshot, labels, mountPaths, err = cm.captureContainer(ctx, host, name, opts.Force)
+    }
+    if err != nil {
+        return nil, err
+    }
+    snapshot.Host = host.name
+    snapshot.ContainerName = name
+    snapshot.Message = opts.Message
+    snapshot.CreatedAt = time.Now().UTC()
+
+    // Créer le snapshot ZFS si configuré (datasets explicites ou découverts)
+    var zfsSnapshots []string
+    if utils.GetZFSDataset(labels) != "" {
+        zfsManager, err := host.zfsManager()
+        if err != nil {
+            return nil, err
+        }
+        datasets, err := snapshotDatasets(zfsManager, labels, mountPaths)
+        if err != nil {
+            return nil, err
+        }
+        if len(datasets) > 0 {
+            // Propriétés décrivant le snapshot, pour reconstruire la base si elle est perdue
+            props, err := snapshotProperties(snapshot)
+            if err != nil {
+                return nil, err
+            }
+
+            // Geler le conteneur pendant le snapshot selon zockimate.snapshot_mode
+            recursive := utils.IsZFSRecursive(labels)
+            zfsSnapshots, snapshot.SnapshotMode, snapshot.FreezeDuration, err = cm.freezeData(ctx, host, name, labels,
+                func() ([]string, error) { return zfsManager.CreateSnapshot(datasets, recursive, props) })
+            if err != nil {
+                if len(zfsSnapshots) > 0 {
+                    zfsManager.DeleteSnapshot(zfsSnapshots)
+                }
+                return nil, err
+            }
+            snapshot.ZFSSnapshots = zfsSnapshots
+        }
+    }
+
+    // Archiver les volumes et binds si configuré (zockimate.backup_mounts)
+    if err := cm.backupMounts(ctx, host, snapshot); err != nil {
+        if len(zfsSnapshots) > 0 {
+            host.zfs.DeleteSnapshot(zfsSnapshots)
+        }
+        return nil, err
+    }
+
+    // Sauvegarder dans la base de données
+    if err := cm.db.SaveSnapshot(snapshot); err != nil {
+        if len(zfsSnapshots) > 0 {
+            host.zfs.DeleteSnapshot(zfsSnapshots)
+        }
+        cm.deleteArchives(snapshot.Mounts)
+        return nil, fmt.Errorf("failed to save snapshot: %w", err)
+    }
+
+    // Compléter les propriétés avec l'identifiant attribué par la base
+    if len(zfsSnapshots) > 0 {
+        if err := host.zfs.SetProperties(zfsSnapshots, map[string]string{
+            propSnapshotID:   strconv.FormatInt(snapshot.ID, 10),
+            propSnapshotMode: snapshot.SnapshotMode,
+            propFreezeMS:     strconv.FormatInt(snapshot.FreezeDuration.Milliseconds(), 10),
+        }); err != nil {
+            cm.logger.Warnf("Failed to tag ZFS snapshot: %v", err)
+        }
+    }
+
+    // Répliquer avant le nettoyage, qui pourrait supprimer la base de l'envoi incrémental
+    if len(zfsSnapshots) > 0 {
+        cm.replicate(host, zfsSnapshots)
+    }
+
+    // Nettoyer les anciens snapshots sauf si NoCleanup
+    if !opts.NoCleanup {
+        if err := cm.db.CleanupSnapshots(host.name, name, cm.config.Retention); err != nil {
+            cm.logger.Warnf("Failed to cleanup old snapshots: %v", err)
+        }
+    }
+
+    cm.logger.Debugf("Successfully created snapshot %d for container %s", snapshot.ID, containerID(host.name, name))
+
+    return snapshot, nil
+}
+
+// captureContainer capture l'image et les configurations d'un conteneur,
+// avec les chemins sur l'hôte de ses montages
+func (cm *ContainerManager) captureContainer(ctx context.Context, host *hostBackend, name string,
+    force bool) (*types.ContainerSnapshot, map[string]string, []string, error) {
+
     // Inspecter le conteneur
-    ctn, err := cm.docker.InspectContainer(ctx, name)
+    ctn, err := host.docker.InspectContainer(ctx, name)
     if err != nil {
-        return nil, fmt.Errorf("failed to inspect container: %w", err)
+        return nil, nil, nil, fmt.Errorf("failed to inspect container: %w", err)
     }
 
     // Vérifier si le conteneur est en cours d'exécution sauf si force
-    if !opts.Force && !ctn.State.Running {
-        return nil, fmt.Errorf("container is not running (use --force to snapshot anyway)")
+    if !force && !ctn.State.Running {
+        return nil, nil, nil, fmt.Errorf("container is not running (use --force to snapshot anyway)")
     }
 
     // Obtenir les références de l'image
-    imageRef, err := cm.docker.GetImageInfo(ctx, ctn.Image)
+    imageRef, err := host.docker.GetImageInfo(ctx, ctn.Image)
     if err != nil {
-        return nil, fmt.Errorf("failed to get image info: %w", err)
+        return nil, nil, nil, fmt.Errorf("failed to get image info: %w", err)
     }
 
     // Si une image originale est définie, la préserver
@@ -159,56 +334,30 @@ func (cm *ContainerManager) CreateSnapshot(ctx context.Context, name string, opt
         imageRef.Original = ctn.Config.Image
     }
 
-    // Créer le snapshot ZFS si configuré
-    var zfsSnapshot string
-    if dataset := utils.GetZFSDataset(ctn.Config.Labels); dataset != "" {
-        snapshot, err := cm.zfs.CreateSnapshot(dataset)
-        if err != nil {
-            return nil, err
-        }
-        zfsSnapshot = snapshot
+    // Obtenir les configurations
+    config, hostConfig, networkConfig, err := host.docker.GetContainerConfigs(ctn)
+    if err != nil {
+        return nil, nil, nil, fmt.Errorf("failed to get container configs: %w", err)
     }
 
-    // Obtenir les configurations
-    config, hostConfig, networkConfig, err := cm.docker.GetContainerConfigs(ctn)
+    // Sélectionner les volumes et binds à archiver
+    mounts, err := selectMounts(ctn.Config.Labels, ctn.Mounts)
     if err != nil {
-        if zfsSnapshot != "" {
-            cm.zfs.DeleteSnapshot(zfsSnapshot)
-        }
-        return nil, fmt.Errorf("failed to get container configs: %w", err)
+        return nil, nil, nil, err
+    }
+
+    var mountPaths []string
+    for _, m := range ctn.Mounts {
+        mountPaths = append(mountPaths, m.Source)
     }
 
-    // Créer le snapshot
-    snapshot := &types.ContainerSnapshot{
-        ContainerName:  name,
+    return &types.ContainerSnapshot{
         ImageRef:      *imageRef,
         Config:        config,
         HostConfig:    hostConfig,
         NetworkConfig: networkConfig,
-        ZFSSnapshot:   zfsSnapshot,
-        Status:        "snapshot",
-        Message:       opts.Message,
-        CreatedAt:     time.Now().UTC(),
-    }
-
-    // Sauvegarder dans la base de données
-    if err := cm.db.SaveSnapshot(snapshot); err != nil {
-        if zfsSnapshot != "" {
-            cm.zfs.DeleteSnapshot(zfsSnapshot)
-        }
-        return nil, fmt.Errorf("failed to save snapshot: %w", err)
-    }
-
-    // Nettoyer les anciens snapshots sauf si NoCleanup
-    if !opts.NoCleanup {
-        if err := cm.db.CleanupSnapshots(name, cm.config.Retention); err != nil {
-            cm.logger.Warnf("Failed to cleanup old snapshots: %v", err)
-        }
-    }
-
-    cm.logger.Debugf("Successfully created snapshot %d for container %s", snapshot.ID, name)
-
-    return snapshot, nil
+        Mounts:        mounts,
+    }, ctn.Config.Labels, mountPaths, nil
 }
 
 // SendNotification envoie une notification via Apprise
@@ -0,0 +1,53 @@
+// internal/manager/manager_test.go
+package manager
+
+import (
+    "io"
+    "path/filepath"
+    "testing"
+
+    "github.com/sirupsen/logrus"
+
+    "zockimate/internal/config"
+    "zockimate/internal/docker"
+    "zockimate/internal/storage/database"
+    "zockimate/internal/storage/zfs"
+)
+
+// newTestManager crée un manager sans hôte, sur une base SQLite vide
+func newTestManager(t *testing.T) *ContainerManager {
+    t.Helper()
+    logger := logrus.New()
+    logger.SetOutput(io.Discard)
+
+    db, err := database.NewDatabase(filepath.Join(t.TempDir(), "zockimate.db"), nil, nil, logger)
+    if err != nil {
+        t.Fatal(err)
+    }
+    t.Cleanup(func() { db.Close() })
+
+    return &ContainerManager{
+        hosts:  make(map[string]*hostBackend),
+        db:     db,
+        config: &config.Config{},
+        logger: logger,
+    }
+}
+
+// addZFSTestHost ajoute un hôte dont les datasets donnés sont sur un ZFS en mémoire.
+// La base est rouverte avec ce gestionnaire ZFS pour détruire les snapshots nettoyés.
+func addZFSTestHost(t *testing.T, cm *ContainerManager, name string, datasets ...string) (*docker.FakeDaemon, *zfs.FakeBackend) {
+    t.Helper()
+    d := addTestHost(t, cm, name)
+    f := zfs.NewFakeBackend(datasets...)
+    cm.hosts[name].zfs = zfs.NewFakeZFSManager(f, cm.logger)
+
+    db, err := database.NewDatabase(filepath.Join(t.TempDir(), "zockimate.db"),
+        map[string]*zfs.ZFSManager{name: cm.hosts[name].zfs}, cm.archives, cm.logger)
+    if err != nil {
+        t.Fatal(err)
+    }
+    t.Cleanup(func() { db.Close() })
+    cm.db = db
+    return d, f
+}
@@ -0,0 +1,105 @@
+// internal/manager/mounts.go
+package manager
+
+import (
+    "context"
+    "fmt"
+
+    dockertypes "github.com/docker/docker/api/types"
+    "github.com/docker/docker/api/types/mount"
+
+    "zockimate/internal/types"
+    "zockimate/pkg/utils"
+)
+
+// Montages sauvegardés (label zockimate.backup_mounts)
+const (
+    backupMountsVolumes = "volumes" // Volumes nommés uniquement
+    backupMountsAll     = "all"     // Volumes nommés et bind mounts
+)
+
+// selectMounts retourne les montages du conteneur à sauvegarder selon zockimate.backup_mounts.
+// Les montages en lecture seule ne sont pas modifiés par le conteneur et sont ignorés.
+func selectMounts(labels map[string]string, mounts []dockertypes.MountPoint) ([]types.MountBackup, error) {
+    policy := utils.GetBackupMounts(labels)
+    switch policy {
+    case "", "false", "none":
+        return nil, nil
+    case backupMountsVolumes, backupMountsAll:
+    default:
+        return nil, fmt.Errorf("invalid zockimate.backup_mounts label: %q (use volumes or all)", policy)
+    }
+
+    var selected []types.MountBackup
+    for _, m := range mounts {
+        if !m.RW {
+            continue
+        }
+        switch {
+        case m.Type == mount.TypeVolume && m.Name != "":
+            selected = append(selected, types.MountBackup{
+                Type: string(mount.TypeVolume), Source: m.Name, Destination: m.Destination,
+            })
+        case m.Type == mount.TypeBind && policy == backupMountsAll:
+            selected = append(selected, types.MountBackup{
+                Type: string(mount.TypeBind), Source: m.Source, Destination: m.Destination,
+            })
+        }
+    }
+    return selected, nil
+}
+
+// backupMounts archive les montages sélectionnés d'un snapshot dans le magasin d'archives
+func (cm *ContainerManager) backupMounts(ctx context.Context, host *hostBackend, snapshot *types.ContainerSnapshot) error {
+    for i := range snapshot.Mounts {
+        m := &snapshot.Mounts[i]
+
+        path, w, err := cm.archives.Create(host.name, snapshot.ContainerName, snapshot.CreatedAt, m.Destination)
+        if err != nil {
+            cm.deleteArchives(snapshot.Mounts[:i])
+            return err
+        }
+        m.Archive = path
+
+        cm.logger.Debugf("Backing up %s %s (%s)", m.Type, m.Source, m.Destination)
+        err = host.docker.BackupMount(ctx, cm.config.HelperImage, *m, w)
+        if closeErr := w.Close(); err == nil {
+            err = closeErr
+        }
+        if err != nil {
+            cm.deleteArchives(snapshot.Mounts[:i+1])
+            return fmt.Errorf("failed to back up mount %s: %w", m.Destination, err)
+        }
+    }
+    return nil
+}
+
+// restoreMounts restaure le contenu des montages sauvegardés (conteneur arrêté)
+func (cm *ContainerManager) restoreMounts(ctx context.Context, host *hostBackend, mounts []types.MountBackup) error {
+    for _, m := range mounts {
+        r, err := cm.archives.Open(m.Archive)
+        if err != nil {
+            return err
+        }
+
+        cm.logger.Debugf("Restoring %s %s (%s)", m.Type, m.Source, m.Destination)
+        err = host.docker.RestoreMount(ctx, cm.config.HelperImage, m, r)
+        r.Close()
+        if err != nil {
+            return fmt.Errorf("failed to restore mount %s: %w", m.Destination, err)
+        }
+    }
+    return nil
+}
+
+// deleteArchives supprime les archives d'un snapshot non enregistré
+func (cm *ContainerManager) deleteArchives(mounts []types.MountBackup) {
+    for _, m := range mounts {
+        if m.Archive == "" {
+            continue
+        }
+        if err := cm.archives.Delete(m.Archive); err != nil {
+            cm.logger.Warnf("Failed to delete archive: %v", err)
+        }
+    }
+}
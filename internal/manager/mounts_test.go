@@ -0,0 +1,170 @@
+// internal/manager/mounts_test.go
+package manager
+
+import (
+    "context"
+    "os"
+    "path/filepath"
+    "reflect"
+    "testing"
+
+    "github.com/docker/docker/api/types"
+    "github.com/docker/docker/api/types/mount"
+
+    "zockimate/internal/config"
+    "zockimate/internal/docker"
+    "zockimate/internal/storage/archive"
+    "zockimate/internal/storage/database"
+    zTypes "zockimate/internal/types"
+    "zockimate/internal/types/options"
+)
+
+const testHelperID = "sha256:3333333333333333333333333333333333333333333333333333333333333333"
+
+func TestSelectMounts(t *testing.T) {
+    mounts := []types.MountPoint{
+        {Type: mount.TypeVolume, Name: "app_data", Source: "/var/lib/docker/volumes/app_data/_data", Destination: "/data", RW: true},
+        {Type: mount.TypeVolume, Name: "app_conf", Destination: "/etc/app", RW: false},
+        {Type: mount.TypeBind, Source: "/srv/app/logs", Destination: "/logs", RW: true},
+        {Type: mount.TypeTmpfs, Destination: "/tmp", RW: true},
+    }
+    volume := zTypes.MountBackup{Type: "volume", Source: "app_data", Destination: "/data"}
+    bind := zTypes.MountBackup{Type: "bind", Source: "/srv/app/logs", Destination: "/logs"}
+
+    for _, c := range []struct {
+        policy  string
+        want    []zTypes.MountBackup
+        wantErr bool
+    }{
+        {"", nil, false},
+        {"none", nil, false},
+        {"false", nil, false},
+        {"volumes", []zTypes.MountBackup{volume}, false},
+        {"ALL", []zTypes.MountBackup{volume, bind}, false},
+        {"binds", nil, true},
+    } {
+        labels := map[string]string{}
+        if c.policy != "" {
+            labels["zockimate.backup_mounts"] = c.policy
+        }
+        got, err := selectMounts(labels, mounts)
+        if (err != nil) != c.wantErr || !reflect.DeepEqual(got, c.want) {
+            t.Errorf("selectMounts(%q) = %+v, %v, want %+v", c.policy, got, err, c.want)
+        }
+    }
+}
+
+// addMountedContainer démarre un conteneur dont le volume app_data est sauvegardé
+// dans le magasin d'archives archiveDir
+func addMountedContainer(t *testing.T, cm *ContainerManager, archiveDir string) *docker.FakeDaemon {
+    t.Helper()
+    cm.archives = archive.NewStore(archiveDir, cm.logger)
+    cm.config = &config.Config{Retention: config.DefaultRetention, HelperImage: config.DefaultHelperImage}
+
+    // La base supprime les archives des entrées nettoyées
+    db, err := database.NewDatabase(filepath.Join(t.TempDir(), "zockimate.db"), nil, cm.archives, cm.logger)
+    if err != nil {
+        t.Fatal(err)
+    }
+    t.Cleanup(func() { db.Close() })
+    cm.db = db
+
+    d := addTestHost(t, cm, "local")
+    d.AddImage(testImageID, "nginx:1.27", testImageDigest)
+    d.AddImage(testHelperID, "busybox:stable")
+    d.RunContainer("app", "nginx:1.27", map[string]string{
+        "zockimate.enable": "true", "zockimate.backup_mounts": "volumes",
+    })
+    d.Container("app").Mounts = []types.MountPoint{
+        {Type: mount.TypeVolume, Name: "app_data", Destination: "/data", RW: true},
+    }
+    d.Volumes["app_data"] = map[string]string{"db.sqlite": "v1", "conf/app.ini": "debug=false"}
+    return d
+}
+
+func TestBackupRestoreMounts(t *testing.T) {
+    ctx := context.Background()
+    cm := newTestManager(t)
+    d := addMountedContainer(t, cm, t.TempDir())
+
+    snapshot, err := cm.CreateSnapshot(ctx, "app", options.NewSnapshotOptions(options.WithSnapshotMessage("test")))
+    if err != nil {
+        t.Fatal(err)
+    }
+    if len(snapshot.Mounts) != 1 || snapshot.Mounts[0].Source != "app_data" || snapshot.Mounts[0].Archive == "" {
+        t.Fatalf("snapshot mounts = %+v", snapshot.Mounts)
+    }
+
+    // Archive enregistrée avec l'entrée, conteneurs auxiliaires supprimés
+    saved, err := cm.db.GetSnapshot("local", "app", snapshot.ID)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if !reflect.DeepEqual(saved.Mounts, snapshot.Mounts) {
+        t.Errorf("saved mounts = %+v, want %+v", saved.Mounts, snapshot.Mounts)
+    }
+    if len(d.Containers) != 1 {
+        t.Errorf("containers after backup = %d, want only app", len(d.Containers))
+    }
+
+    // Le contenu modifié depuis la sauvegarde est remplacé par celui de l'archive
+    d.Volumes["app_data"]["db.sqlite"] = "v2"
+    if err := cm.restoreMounts(ctx, cm.hosts["local"], snapshot.Mounts); err != nil {
+        t.Fatal(err)
+    }
+    want := map[string]string{"db.sqlite": "v1", "conf/app.ini": "debug=false"}
+    if !reflect.DeepEqual(d.Volumes["app_data"], want) {
+        t.Errorf("restored volume = %v, want %v", d.Volumes["app_data"], want)
+    }
+
+    // Échec du vidage de la source : rien n'est extrait
+    d.ExitCodes["busybox:stable"] = 1
+    d.Volumes["app_data"]["db.sqlite"] = "v3"
+    if err := cm.restoreMounts(ctx, cm.hosts["local"], snapshot.Mounts); err == nil {
+        t.Error("restore succeeded although the helper failed")
+    }
+    if d.Volumes["app_data"]["db.sqlite"] != "v3" {
+        t.Errorf("volume restored although the helper failed")
+    }
+    if len(d.Containers) != 1 {
+        t.Errorf("containers after restore = %d, want only app", len(d.Containers))
+    }
+}
+
+func TestBackupMountsFailure(t *testing.T) {
+    ctx := context.Background()
+    cm := newTestManager(t)
+    archiveDir := t.TempDir()
+    d := addMountedContainer(t, cm, archiveDir)
+    d.Container("app").Mounts = append(d.Container("app").Mounts,
+        types.MountPoint{Type: mount.TypeVolume, Name: "app_cache", Destination: "/cache", RW: true})
+
+    // Image auxiliaire introuvable : pas d'entrée ni d'archive partielle
+    cm.config.HelperImage = "busybox:missing"
+    if _, err := cm.CreateSnapshot(ctx, "app", options.NewSnapshotOptions(options.WithSnapshotMessage("test"))); err == nil {
+        t.Fatal("snapshot succeeded without the helper image")
+    }
+    if n := len(mustHistory(t, cm)); n != 0 {
+        t.Errorf("%d entries saved, want none", n)
+    }
+    var files []string
+    filepath.Walk(archiveDir, func(path string, info os.FileInfo, err error) error {
+        if err == nil && !info.IsDir() {
+            files = append(files, path)
+        }
+        return nil
+    })
+    if len(files) != 0 {
+        t.Errorf("archives left after a failed backup: %v", files)
+    }
+}
+
+// mustHistory retourne toutes les entrées de la base
+func mustHistory(t *testing.T, cm *ContainerManager) []zTypes.SnapshotMetadata {
+    t.Helper()
+    history, err := cm.GetHistory(options.HistoryOptions{})
+    if err != nil {
+        t.Fatal(err)
+    }
+    return history
+}
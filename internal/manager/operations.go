@@ -0,0 +1,88 @@
+// internal/manager/operations.go
+package manager
+
+import (
+    "zockimate/internal/types"
+    "zockimate/internal/types/options"
+)
+
+// startOperation enregistre le début d'une mise à jour ou d'un rollback. Une erreur
+// de la base n'empêche pas l'opération, qui n'est alors pas enregistrée (nil).
+func (cm *ContainerManager) startOperation(op *types.Operation) *types.Operation {
+    if op.TriggeredBy == "" {
+        op.TriggeredBy = types.TriggerAPI
+    }
+    if err := cm.db.StartOperation(op); err != nil {
+        cm.logger.Warnf("Failed to record %s operation: %v", op.Type, err)
+        return nil
+    }
+    return op
+}
+
+// finishOperation enregistre l'issue d'une opération
+func (cm *ContainerManager) finishOperation(op *types.Operation, outcome string, err error) {
+    if op == nil {
+        return
+    }
+    op.Outcome = outcome
+    if err != nil {
+        op.Error = err.Error()
+    }
+    if err := cm.db.FinishOperation(op); err != nil {
+        cm.logger.Warnf("Failed to record outcome of %s operation %d: %v", op.Type, op.ID, err)
+    }
+}
+
+// finishUpdate enregistre l'issue d'une mise à jour
+func (cm *ContainerManager) finishUpdate(op *types.Operation, result *types.UpdateResult, err error) {
+    if op == nil {
+        return
+    }
+    op.SnapshotID = result.SnapshotID
+    switch {
+    case err != nil:
+        cm.finishOperation(op, types.OutcomeFailed, err)
+    case result.Success:
+        cm.finishOperation(op, types.OutcomeSuccess, nil)
+    case result.RolledBack:
+        cm.finishOperation(op, types.OutcomeRolledBack, result.Error)
+    default:
+        cm.finishOperation(op, types.OutcomeFailed, result.Error)
+    }
+}
+
+// finishRollback enregistre l'issue d'un rollback, avec les images avant (snapshot
+// de sécurité) et après (snapshot restauré) si l'image a été restaurée
+func (cm *ContainerManager) finishRollback(op *types.Operation, opts options.RollbackOptions,
+    result *types.RollbackResult, err error) {
+    if op == nil {
+        return
+    }
+    op.SnapshotID = result.SafetySnapshot
+    op.TargetSnapshotID = result.SnapshotID
+    if opts.Image && result.SafetySnapshot > 0 {
+        if safety, err := cm.db.GetSnapshot(op.Host, op.ContainerName, result.SafetySnapshot); err == nil {
+            op.FromImage = safety.ImageRef.ID
+        }
+        if target, err := cm.db.GetSnapshot(op.Host, op.ContainerName, result.SnapshotID); err == nil {
+            op.ToImage = target.ImageRef.ID
+        }
+    }
+
+    if err == nil {
+        err = result.Error
+    }
+    if result.Success {
+        cm.finishOperation(op, types.OutcomeSuccess, nil)
+    } else {
+        cm.finishOperation(op, types.OutcomeFailed, err)
+    }
+}
+
+// GetTimeline récupère la chronologie des snapshots et des opérations
+func (cm *ContainerManager) GetTimeline(opts options.HistoryOptions) ([]types.TimelineEntry, error) {
+    cm.lock.RLock()
+    defer cm.lock.RUnlock()
+
+    return cm.db.GetTimeline(opts)
+}
@@ -0,0 +1,164 @@
+// internal/manager/properties.go
+package manager
+
+import (
+    "bytes"
+    "compress/gzip"
+    "encoding/base64"
+    "encoding/json"
+    "fmt"
+    "io"
+    "sort"
+    "strconv"
+    "strings"
+    "time"
+
+    "zockimate/internal/storage/zfs"
+    "zockimate/internal/types"
+)
+
+// Propriétés utilisateur posées sur chaque snapshot ZFS, permettant de reconstruire
+// son entrée en base sans la base
+const (
+    propHost          = zfs.PropertyPrefix + "host"
+    propContainer     = zfs.PropertyPrefix + "container"
+    propSnapshotID    = zfs.PropertyPrefix + "snapshot_id"
+    propImageID       = zfs.PropertyPrefix + "image_id"
+    propImageDigest   = zfs.PropertyPrefix + "image_digest"
+    propImageTag      = zfs.PropertyPrefix + "image_tag"
+    propOriginalImage = zfs.PropertyPrefix + "original_image"
+    propReason        = zfs.PropertyPrefix + "reason"
+    propCreatedAt     = zfs.PropertyPrefix + "created_at"
+    propSnapshotMode  = zfs.PropertyPrefix + "snapshot_mode"
+    propFreezeMS      = zfs.PropertyPrefix + "freeze_ms"
+    propConfigs       = zfs.PropertyPrefix + "configs" // Découpée en configs.0, configs.1...
+
+    // Taille d'un morceau de propriété (les valeurs ZFS sont limitées à 8192 octets)
+    propertyChunkSize = 8000
+)
+
+// snapshotConfigs regroupe les configurations Docker stockées dans les propriétés
+type snapshotConfigs struct {
+    Config        []byte `json:"config"`
+    HostConfig    []byte `json:"host_config"`
+    NetworkConfig []byte `json:"network_config"`
+}
+
+// snapshotProperties retourne les propriétés décrivant un snapshot, hors identifiant,
+// mode de cohérence et durée de gel qui ne sont connus qu'après le snapshot ZFS
+func snapshotProperties(s *types.ContainerSnapshot) (map[string]string, error) {
+    props := map[string]string{
+        propHost:          s.Host,
+        propContainer:     s.ContainerName,
+        propImageID:       s.ImageRef.ID,
+        propImageDigest:   s.ImageRef.RepoDigest,
+        propImageTag:      s.ImageRef.Tag,
+        propOriginalImage: s.ImageRef.Original,
+        propReason:        strings.Join(strings.Fields(s.Message), " "),
+        propCreatedAt:     s.CreatedAt.UTC().Format(time.RFC3339),
+    }
+    for k, v := range props {
+        if v == "" {
+            delete(props, k)
+        }
+    }
+
+    data, err := json.Marshal(snapshotConfigs{s.Config, s.HostConfig, s.NetworkConfig})
+    if err != nil {
+        return nil, fmt.Errorf("failed to marshal configs: %w", err)
+    }
+    var buf bytes.Buffer
+    gz := gzip.NewWriter(&buf)
+    if _, err := gz.Write(data); err != nil {
+        return nil, err
+    }
+    if err := gz.Close(); err != nil {
+        return nil, err
+    }
+    encoded := base64.StdEncoding.EncodeToString(buf.Bytes())
+    for i := 0; len(encoded) > 0; i++ {
+        n := min(propertyChunkSize, len(encoded))
+        props[fmt.Sprintf("%s.%d", propConfigs, i)] = encoded[:n]
+        encoded = encoded[n:]
+    }
+    return props, nil
+}
+
+// snapshotFromProperties reconstruit un snapshot depuis les propriétés d'un snapshot ZFS
+func snapshotFromProperties(props map[string]string) (*types.ContainerSnapshot, error) {
+    id, err := strconv.ParseInt(props[propSnapshotID], 10, 64)
+    if err != nil {
+        return nil, fmt.Errorf("invalid %s: %q", propSnapshotID, props[propSnapshotID])
+    }
+    createdAt, err := time.Parse(time.RFC3339, props[propCreatedAt])
+    if err != nil {
+        return nil, fmt.Errorf("invalid %s: %q", propCreatedAt, props[propCreatedAt])
+    }
+    if props[propContainer] == "" || props[propImageID] == "" {
+        return nil, fmt.Errorf("missing %s or %s", propContainer, propImageID)
+    }
+
+    // Réassembler les morceaux de configs.N dans l'ordre
+    var chunks []string
+    for k := range props {
+        if strings.HasPrefix(k, propConfigs+".") {
+            chunks = append(chunks, k)
+        }
+    }
+    sort.Slice(chunks, func(i, j int) bool {
+        a, _ := strconv.Atoi(strings.TrimPrefix(chunks[i], propConfigs+"."))
+        b, _ := strconv.Atoi(strings.TrimPrefix(chunks[j], propConfigs+"."))
+        return a < b
+    })
+    var encoded strings.Builder
+    for _, k := range chunks {
+        encoded.WriteString(props[k])
+    }
+
+    var configs snapshotConfigs
+    if encoded.Len() > 0 {
+        data, err := base64.StdEncoding.DecodeString(encoded.String())
+        if err != nil {
+            return nil, fmt.Errorf("invalid %s: %w", propConfigs, err)
+        }
+        gz, err := gzip.NewReader(bytes.NewReader(data))
+        if err != nil {
+            return nil, fmt.Errorf("invalid %s: %w", propConfigs, err)
+        }
+        raw, err := io.ReadAll(gz)
+        if err != nil {
+            return nil, fmt.Errorf("invalid %s: %w", propConfigs, err)
+        }
+        if err := json.Unmarshal(raw, &configs); err != nil {
+            return nil, fmt.Errorf("invalid %s: %w", propConfigs, err)
+        }
+    }
+
+    var freeze time.Duration
+    if v := props[propFreezeMS]; v != "" {
+        ms, err := strconv.ParseInt(v, 10, 64)
+        if err != nil {
+            return nil, fmt.Errorf("invalid %s: %q", propFreezeMS, v)
+        }
+        freeze = time.Duration(ms) * time.Millisecond
+    }
+
+    return &types.ContainerSnapshot{
+        ID:            id,
+        Host:          props[propHost],
+        ContainerName: props[propContainer],
+        ImageRef: types.ImageReference{
+            ID:         props[propImageID],
+            RepoDigest: props[propImageDigest],
+            Tag:        props[propImageTag],
+            Original:   props[propOriginalImage],
+        },
+        Config:         configs.Config,
+        HostConfig:     configs.HostConfig,
+        NetworkConfig:  configs.NetworkConfig,
+        SnapshotMode:   props[propSnapshotMode],
+        FreezeDuration: freeze,
+        Message:        props[propReason],
+        CreatedAt:      createdAt,
+    }, nil
+}
@@ -0,0 +1,65 @@
+// internal/manager/properties_test.go
+package manager
+
+import (
+    "bytes"
+    "reflect"
+    "strings"
+    "testing"
+    "time"
+
+    "zockimate/internal/types"
+)
+
+func TestSnapshotPropertiesRoundTrip(t *testing.T) {
+    want := &types.ContainerSnapshot{
+        ID:            7,
+        Host:          "local",
+        ContainerName: "app",
+        ImageRef: types.ImageReference{
+            ID:         "sha256:abc",
+            RepoDigest: "nginx@sha256:def",
+            Tag:        "nginx:1.27",
+            Original:   "nginx:1.27",
+        },
+        Config:         []byte(`{"Image":"nginx:1.27","Env":["` + strings.Repeat("x", 20000) + `"]}`),
+        HostConfig:     []byte(`{"Binds":["/srv/app:/data"]}`),
+        NetworkConfig:  []byte(`{}`),
+        SnapshotMode:   "pause",
+        FreezeDuration: 1250 * time.Millisecond,
+        Message:        "before update",
+        CreatedAt:      time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC),
+    }
+
+    props, err := snapshotProperties(want)
+    if err != nil {
+        t.Fatal(err)
+    }
+    for k, v := range props {
+        if len(v) > propertyChunkSize {
+            t.Errorf("property %s is %d bytes long", k, len(v))
+        }
+    }
+    // Posées après le snapshot ZFS
+    props[propSnapshotID] = "7"
+    props[propSnapshotMode] = want.SnapshotMode
+    props[propFreezeMS] = "1250"
+
+    got, err := snapshotFromProperties(props)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if got.ID != want.ID || got.Host != want.Host || got.ContainerName != want.ContainerName ||
+        !reflect.DeepEqual(got.ImageRef, want.ImageRef) || got.SnapshotMode != want.SnapshotMode ||
+        got.FreezeDuration != want.FreezeDuration || got.Message != want.Message || !got.CreatedAt.Equal(want.CreatedAt) {
+        t.Errorf("snapshot = %+v, want %+v", got, want)
+    }
+    if !bytes.Equal(got.Config, want.Config) || !bytes.Equal(got.HostConfig, want.HostConfig) {
+        t.Error("configs not restored")
+    }
+
+    props[propFreezeMS] = "soon"
+    if _, err := snapshotFromProperties(props); err == nil {
+        t.Error("invalid freeze_ms accepted")
+    }
+}
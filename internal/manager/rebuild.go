@@ -0,0 +1,123 @@
+// internal/manager/rebuild.go
+package manager
+
+import (
+    "context"
+    "fmt"
+    "sort"
+    "strconv"
+    "strings"
+
+    "zockimate/internal/types"
+)
+
+// RebuildDatabase recrée les entrées de container_snapshots perdues à partir des
+// propriétés zockimate:* des snapshots ZFS de chaque hôte. Les entrées existantes
+// (même conteneur et date) sont conservées ; une entrée dont l'identifiant est pris
+// par une autre est importée sous un nouvel identifiant, reporté sur ses snapshots.
+func (cm *ContainerManager) RebuildDatabase(ctx context.Context, dryRun bool) ([]*types.RebuildResult, error) {
+    cm.lock.Lock()
+    defer cm.lock.Unlock()
+
+    names := make([]string, 0, len(cm.hosts))
+    for name := range cm.hosts {
+        names = append(names, name)
+    }
+    sort.Strings(names)
+
+    var results []*types.RebuildResult
+    for _, hostName := range names {
+        host := cm.hosts[hostName]
+        if host.zfs == nil {
+            continue
+        }
+        result := &types.RebuildResult{Host: hostName}
+        if err := cm.rebuildHost(host, dryRun, result); err != nil {
+            result.Error = err.Error()
+        }
+        results = append(results, result)
+    }
+    return results, nil
+}
+
+// rebuildHost reconstruit les entrées d'un hôte
+func (cm *ContainerManager) rebuildHost(host *hostBackend, dryRun bool, result *types.RebuildResult) error {
+    props, err := host.zfs.SnapshotProperties()
+    if err != nil {
+        return err
+    }
+
+    // Les copies répliquées sur un pool de la même machine portent les mêmes propriétés
+    replicaPrefix := ""
+    if r := host.zfs.Replica(); r != nil {
+        replicaPrefix = r.Dataset("")
+    }
+
+    // Regrouper les snapshots ZFS par entrée (un groupe par snapshot_id)
+    groups := make(map[string][]string)
+    for name, p := range props {
+        if replicaPrefix != "" && strings.HasPrefix(name, replicaPrefix) {
+            continue
+        }
+        if p[propHost] != host.name {
+            continue
+        }
+        if p[propSnapshotID] == "" {
+            result.Invalid = append(result.Invalid, name)
+            continue
+        }
+        groups[p[propSnapshotID]] = append(groups[p[propSnapshotID]], name)
+    }
+
+    ids := make([]string, 0, len(groups))
+    for id := range groups {
+        ids = append(ids, id)
+    }
+    sort.Strings(ids)
+
+    for _, id := range ids {
+        members := groups[id]
+        sort.Strings(members)
+        result.Found++
+
+        snapshot, err := snapshotFromProperties(props[members[0]])
+        if err != nil {
+            cm.logger.Warnf("Cannot rebuild entry from %s: %v", members[0], err)
+            result.Invalid = append(result.Invalid, members...)
+            continue
+        }
+        snapshot.Host = host.name
+        snapshot.ZFSSnapshots = members
+
+        if dryRun {
+            cm.logger.Debugf("Dry run: would import snapshot %d for %s", snapshot.ID,
+                containerID(host.name, snapshot.ContainerName))
+            continue
+        }
+
+        newID, imported, err := cm.db.ImportSnapshot(snapshot)
+        if err != nil {
+            return err
+        }
+        if !imported {
+            result.Existing++
+            continue
+        }
+        result.Imported++
+        cm.logger.Debugf("Imported snapshot %d for %s", newID, containerID(host.name, snapshot.ContainerName))
+
+        // Identifiant déjà pris par une autre entrée : les snapshots ZFS portent le nouveau
+        if newID != snapshot.ID {
+            cm.logger.Warnf("Snapshot ID %d of %s is used by another entry, imported as %d",
+                snapshot.ID, containerID(host.name, snapshot.ContainerName), newID)
+            if err := host.zfs.SetProperties(members, map[string]string{
+                propSnapshotID: strconv.FormatInt(newID, 10),
+            }); err != nil {
+                return fmt.Errorf("failed to tag ZFS snapshots of entry %d: %w", newID, err)
+            }
+            result.Renumbered++
+        }
+    }
+    sort.Strings(result.Invalid)
+    return nil
+}
@@ -6,7 +6,6 @@ import (
 
     "zockimate/internal/types"
     "zockimate/internal/types/options"
-    "zockimate/pkg/utils"
 )
 
 func (cm *ContainerManager) RemoveContainer(ctx context.Context, name string, opts options.RemoveOptions) (*types.RemoveResult, error) {
@@ -14,18 +13,32 @@ func (cm *ContainerManager) RemoveContainer(ctx context.Context, name string, op
     defer cm.lock.Unlock()
 
     result := &types.RemoveResult{ContainerName: name}
-    name = utils.CleanContainerName(name)
-    cm.logger.Debugf("Starting remove process for container: %s", name)
+    host, name, err := cm.resolve(name)
+    if err != nil {
+        result.Error = err
+        return result, nil
+    }
+    cm.logger.Debugf("Starting remove process for container: %s", containerID(host.name, name))
 
     if opts.DryRun {
         cm.logger.Debugf("Dry run: would remove container %s", name)
         return result, nil
     }
 
-    // Vérifier si le conteneur existe dans Docker
-    _, err := cm.docker.InspectContainer(ctx, name)
+    // Vérifier si le conteneur (ou le service Swarm) existe dans Docker
+    svc, isService := serviceName(name)
+    if isService {
+        _, err = host.docker.InspectService(ctx, svc)
+    } else {
+        _, err = host.docker.InspectContainer(ctx, name)
+    }
     containerExists := err == nil
 
+    if containerExists && isService && opts.WithContainer {
+        result.Error = fmt.Errorf("removing Swarm service %s is not supported, use docker service rm", svc)
+        return result, nil
+    }
+
     if containerExists {
         if !opts.Force && !opts.WithContainer {
             result.Error = fmt.Errorf("container %s still exists in Docker. Use --force or --with-container to remove anyway", name)
@@ -33,7 +46,7 @@ func (cm *ContainerManager) RemoveContainer(ctx context.Context, name string, op
         }
 
         if opts.WithContainer {
-            if err := cm.docker.RemoveContainer(ctx, name, true); err != nil {
+            if err := host.docker.RemoveContainer(ctx, name, true); err != nil {
                 result.Error = fmt.Errorf("failed to remove Docker container: %w", err)
                 return result, nil
             }
@@ -43,7 +56,7 @@ func (cm *ContainerManager) RemoveContainer(ctx context.Context, name string, op
     }
 
     // Supprimer les entrées de la base de données
-    deleted, err := cm.db.RemoveEntries(name, opts)
+    deleted, err := cm.db.RemoveEntries(host.name, name, opts)
     if err != nil {
         result.Error = fmt.Errorf("failed to remove database entries: %w", err)
         return result, nil
@@ -20,18 +20,34 @@ func (cm *ContainerManager) RenameContainer(ctx context.Context, oldName, newNam
         NewName: newName,
     }
 
-    oldName = utils.CleanContainerName(oldName)
-    newName = utils.CleanContainerName(newName)
+    host, oldName, err := cm.resolve(oldName)
+    if err != nil {
+        result.Error = err
+        return result, nil
+    }
+
+    // Le nom d'un service Swarm est fixé par sa pile
+    if _, ok := serviceName(oldName); ok {
+        result.Error = fmt.Errorf("cannot rename Swarm service %s", oldName)
+        return result, nil
+    }
+
+    // Le nouveau nom peut être qualifié, mais uniquement par le même hôte
+    newHost, newName := utils.SplitContainerID(newName)
+    if newHost != "" && newHost != host.name {
+        result.Error = fmt.Errorf("cannot rename container across hosts (%s to %s)", host.name, newHost)
+        return result, nil
+    }
 
     if !opts.DbOnly {
         // Vérifier si le nouveau nom existe déjà dans Docker 
-        if _, err := cm.docker.InspectContainer(ctx, newName); err == nil {
+        if _, err := host.docker.InspectContainer(ctx, newName); err == nil {
             result.Error = fmt.Errorf("container with name %s already exists in Docker", newName)
             return result, nil
         }
 
         // Inspecter le conteneur source
-        ctn, err := cm.docker.InspectContainer(ctx, oldName)
+        ctn, err := host.docker.InspectContainer(ctx, oldName)
         if client.IsErrNotFound(err) {
             result.Error = fmt.Errorf("source container %s does not exist", oldName)
             return result, nil
@@ -53,7 +69,7 @@ func (cm *ContainerManager) RenameContainer(ctx context.Context, oldName, newNam
         }
 
         // Renommer dans Docker
-        if err := cm.docker.ContainerRename(ctx, oldName, newName); err != nil {
+        if err := host.docker.ContainerRename(ctx, oldName, newName); err != nil {
             result.Error = fmt.Errorf("failed to rename container in Docker: %w", err)
             return result, nil
         }
@@ -62,11 +78,11 @@ func (cm *ContainerManager) RenameContainer(ctx context.Context, oldName, newNam
     }
 
     // Update database
-    affected, err := cm.db.RenameContainer(oldName, newName)
+    affected, err := cm.db.RenameContainer(host.name, oldName, newName)
     if err != nil {
         if !opts.DbOnly && result.DockerRenamed {
             // Try to revert Docker rename if needed
-            if revertErr := cm.docker.ContainerRename(ctx, newName, oldName); revertErr != nil {
+            if revertErr := host.docker.ContainerRename(ctx, newName, oldName); revertErr != nil {
                 result.Error = fmt.Errorf("failed to update database and revert Docker rename failed: %v (original error: %v)", 
                     revertErr, err)
                 return result, nil
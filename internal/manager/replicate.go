@@ -0,0 +1,33 @@
+// internal/manager/replicate.go
+package manager
+
+import (
+    "strings"
+)
+
+// replicate envoie un groupe de snapshots vers la réplique de l'hôte, en incrémental
+// depuis le dernier snapshot répliqué de chaque dataset. Un échec n'invalide pas le
+// snapshot local : il est signalé et le prochain envoi repartira d'un snapshot commun.
+func (cm *ContainerManager) replicate(host *hostBackend, snapshots []string) {
+    if host.zfs == nil || host.zfs.Replica() == nil {
+        return
+    }
+
+    for _, snapshot := range snapshots {
+        dataset, _, _ := strings.Cut(snapshot, "@")
+        base, err := cm.db.GetReplicated(host.name, dataset)
+        if err != nil {
+            cm.logger.Warnf("Failed to get last replicated snapshot of %s: %v", dataset, err)
+        }
+
+        if err := host.zfs.Replicate(snapshot, base); err != nil {
+            cm.logger.Warnf("ZFS replication failed: %v", err)
+            cm.notifyf("Replication Failed", "Snapshot %s could not be replicated to %s: %v",
+                snapshot, host.zfs.Replica(), err)
+            return
+        }
+        if err := cm.db.SetReplicated(host.name, dataset, snapshot); err != nil {
+            cm.logger.Warnf("Failed to record replication of %s: %v", snapshot, err)
+        }
+    }
+}
@@ -0,0 +1,205 @@
+// internal/manager/restore.go
+package manager
+
+import (
+    "context"
+    "fmt"
+
+    "zockimate/internal/types"
+    "zockimate/internal/types/options"
+    "zockimate/pkg/utils"
+)
+
+// RestoreContainer recrée un conteneur supprimé depuis une entrée de la base :
+// image (réutilisée si présente, sinon téléchargée), configuration, réseaux définis
+// par l'utilisateur disparus et, avec opts.Data, données ZFS et archives de montages.
+// Le conteneur recréé (opts.As ou nom d'origine) ne doit pas exister ; s'il ne
+// démarre pas, il est supprimé pour permettre une nouvelle tentative.
+func (cm *ContainerManager) RestoreContainer(ctx context.Context, name string, opts options.RestoreOptions) (_ *types.RestoreResult, err error) {
+    result := &types.RestoreResult{
+        ContainerName: name,
+        SnapshotID:    opts.SnapshotID,
+    }
+
+    host, name, err := cm.resolve(name)
+    if err != nil {
+        result.Error = err
+        return result, nil
+    }
+    if _, ok := serviceName(name); ok {
+        result.Error = fmt.Errorf("cannot restore a Swarm service, use rollback")
+        return result, nil
+    }
+
+    newName := name
+    if opts.As != "" {
+        newName = opts.As
+    }
+    result.NewName = newName
+    id := containerID(host.name, newName)
+
+    cm.lock.Lock()
+    defer cm.lock.Unlock()
+
+    // Récupérer le snapshot
+    snapshot, err := cm.db.GetSnapshot(host.name, name, opts.SnapshotID)
+    if err != nil {
+        result.Error = fmt.Errorf("failed to get snapshot: %w", err)
+        return result, nil
+    }
+    result.SnapshotID = snapshot.ID
+    cm.logger.Debugf("Restoring container %s from snapshot %d", id, snapshot.ID)
+
+    // Enregistrer l'opération ; l'issue est enregistrée au retour
+    op := cm.startOperation(&types.Operation{
+        Host:             host.name,
+        ContainerName:    newName,
+        Type:             types.OperationRestore,
+        TriggeredBy:      opts.Trigger,
+        TargetSnapshotID: snapshot.ID,
+        ToImage:          snapshot.ImageRef.ID,
+    })
+    defer func() {
+        opErr := err
+        if opErr == nil {
+            opErr = result.Error
+        }
+        if result.Success {
+            cm.finishOperation(op, types.OutcomeSuccess, nil)
+        } else {
+            cm.finishOperation(op, types.OutcomeFailed, opErr)
+        }
+    }()
+
+    exists, err := host.docker.ContainerExists(ctx, newName)
+    if err != nil {
+        result.Error = err
+        return result, nil
+    }
+    if exists {
+        result.Error = fmt.Errorf("container %s already exists (use rollback, or restore --as another name)", newName)
+        return result, nil
+    }
+
+    // Les données sont celles du conteneur d'origine : ne pas les restaurer sous
+    // un autre nom tant qu'il les utilise
+    if opts.Data && newName != name {
+        original, err := host.docker.ContainerExists(ctx, name)
+        if err != nil {
+            result.Error = err
+            return result, nil
+        }
+        if original {
+            result.Error = fmt.Errorf("cannot restore data: container %s still uses it", name)
+            return result, nil
+        }
+    }
+
+    config, hostConfig, networkConfig, err := host.docker.UnmarshalConfigs(snapshot.Config, snapshot.HostConfig, snapshot.NetworkConfig)
+    if err != nil {
+        result.Error = fmt.Errorf("failed to unmarshal configs: %w", err)
+        return result, nil
+    }
+
+    // Image : réutiliser l'image locale si c'est la même (importée, par exemple),
+    // sinon la télécharger
+    if !opts.Force && !snapshot.ImageRef.IsExactReference() {
+        result.Error = fmt.Errorf(
+            "cannot guarantee exact image version for restore (use --force to override)")
+        return result, nil
+    }
+    imageRef, err := cm.restoreImage(ctx, host, &snapshot.ImageRef)
+    if err != nil {
+        result.Error = err
+        return result, nil
+    }
+    result.Image = imageRef
+    config.Image = imageRef
+
+    if config.Labels == nil {
+        config.Labels = make(map[string]string)
+    }
+    config.Labels["zockimate.snapshot_id"] = fmt.Sprintf("%d", snapshot.ID)
+    config.Labels["zockimate.original_image"] = snapshot.ImageRef.Original
+
+    // Réseaux définis par l'utilisateur supprimés avec le conteneur (docker compose down)
+    result.NetworksCreated, err = host.docker.EnsureNetworks(ctx, hostConfig, networkConfig)
+    if err != nil {
+        result.Error = err
+        return result, nil
+    }
+
+    // Restaurer les données avant de créer le conteneur
+    if opts.Data && len(snapshot.ZFSSnapshots) > 0 {
+        if err := cm.restoreZFS(host, snapshot.ZFSSnapshots, opts.DestroyNewer); err != nil {
+            result.Error = err
+            return result, nil
+        }
+        result.DataRestored = true
+    }
+    if opts.Data && len(snapshot.Mounts) > 0 {
+        if err := cm.restoreMounts(ctx, host, snapshot.Mounts); err != nil {
+            result.Error = err
+            return result, nil
+        }
+        result.DataRestored = true
+    }
+
+    if err := host.docker.RecreateContainer(ctx, newName, config, hostConfig, networkConfig); err != nil {
+        result.Error = fmt.Errorf("failed to create container: %w", err)
+        cm.removeFailedRestore(host, newName)
+        return result, nil
+    }
+
+    // Attendre que le conteneur soit prêt
+    timeout := utils.GetTimeout(config.Labels, opts.Timeout, cm.logger)
+    cm.logger.Debugf("Waiting for container %s to be ready (timeout: %s)", newName, timeout)
+
+    if err := host.docker.WaitForContainer(ctx, newName, timeout); err != nil {
+        result.Error = fmt.Errorf("container failed to become ready after restore: %w", err)
+        cm.removeFailedRestore(host, newName)
+        return result, nil
+    }
+
+    result.Success = true
+    cm.logger.Debugf("Successfully restored container %s from snapshot %d", id, snapshot.ID)
+
+    cm.notifyf(
+        "Restore Successful",
+        "Container %s successfully restored from snapshot %d (Image: %s)",
+        id, snapshot.ID, snapshot.ImageRef.String(),
+    )
+    return result, nil
+}
+
+// restoreImage retourne la référence de l'image d'une entrée à utiliser, téléchargée
+// si elle n'est pas présente sur l'hôte
+func (cm *ContainerManager) restoreImage(ctx context.Context, host *hostBackend, ref *types.ImageReference) (string, error) {
+    best := ref.BestReference()
+    if info, err := host.docker.GetImageInfo(ctx, best); err == nil && (ref.ID == "" || info.ID == ref.ID) {
+        return best, nil
+    }
+
+    pullErr := host.docker.PullImage(ctx, best)
+    if pullErr == nil {
+        return best, nil
+    }
+
+    // Image introuvable sur le registre mais présente localement sous son ID
+    if ref.ID != "" {
+        if _, err := host.docker.GetImageInfo(ctx, ref.ID); err == nil {
+            cm.logger.Warnf("Failed to pull %s, using local image %s: %v", best, utils.ShortenID(ref.ID), pullErr)
+            return ref.ID, nil
+        }
+    }
+    return "", fmt.Errorf("failed to pull image: %w", pullErr)
+}
+
+// removeFailedRestore supprime un conteneur restauré qui n'a pas démarré
+func (cm *ContainerManager) removeFailedRestore(host *hostBackend, name string) {
+    if err := host.docker.RemoveContainer(context.Background(), name, true); err != nil {
+        cm.logger.Warnf("Failed to remove container %s after failed restore: %v", name, err)
+        return
+    }
+    cm.logger.Infof("Removed container %s after failed restore", name)
+}
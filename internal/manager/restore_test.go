@@ -0,0 +1,181 @@
+// internal/manager/restore_test.go
+package manager
+
+import (
+    "context"
+    "encoding/json"
+    "reflect"
+    "strconv"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/docker/docker/api/types"
+    "github.com/docker/docker/api/types/container"
+    "github.com/docker/docker/api/types/network"
+
+    zTypes "zockimate/internal/types"
+    "zockimate/internal/types/options"
+)
+
+const (
+    testImageID     = "sha256:1111111111111111111111111111111111111111111111111111111111111111"
+    testImageDigest = "nginx@sha256:2222222222222222222222222222222222222222222222222222222222222222"
+)
+
+// saveContainerSnapshot enregistre une entrée du conteneur name avec ses configurations
+func saveContainerSnapshot(t *testing.T, cm *ContainerManager, name string, config *container.Config,
+    hostConfig *container.HostConfig, networkConfig *network.NetworkingConfig) int64 {
+    t.Helper()
+    snapshot := &zTypes.ContainerSnapshot{
+        Host:          "local",
+        ContainerName: name,
+        ImageRef:      zTypes.ImageReference{ID: testImageID, RepoDigest: testImageDigest, Tag: "nginx:1.27", Original: "nginx:1.27"},
+        Message:       "test",
+    }
+    var err error
+    for _, c := range []struct {
+        dst *[]byte
+        v   interface{}
+    }{
+        {&snapshot.Config, config},
+        {&snapshot.HostConfig, hostConfig},
+        {&snapshot.NetworkConfig, networkConfig},
+    } {
+        if *c.dst, err = json.Marshal(c.v); err != nil {
+            t.Fatal(err)
+        }
+    }
+    if err := cm.db.SaveSnapshot(snapshot); err != nil {
+        t.Fatal(err)
+    }
+    return snapshot.ID
+}
+
+func TestRestoreContainer(t *testing.T) {
+    ctx := context.Background()
+    cm := newTestManager(t)
+    d := addTestHost(t, cm, "local")
+    d.Registry[testImageDigest] = types.ImageInspect{ID: testImageID, RepoDigests: []string{testImageDigest}}
+
+    // Conteneur supprimé avec son réseau (docker compose down)
+    id := saveContainerSnapshot(t, cm, "app",
+        &container.Config{Image: "nginx:1.27", Env: []string{"MODE=prod"},
+            Labels: map[string]string{"zockimate.enable": "true"}},
+        &container.HostConfig{NetworkMode: "appnet", Binds: []string{"/srv/app:/data"}},
+        &network.NetworkingConfig{EndpointsConfig: map[string]*network.EndpointSettings{
+            "appnet": {NetworkID: "deleted", EndpointID: "deleted", IPAddress: "172.20.0.5", Aliases: []string{"web"}},
+        }})
+
+    result, err := cm.RestoreContainer(ctx, "app", options.RestoreOptions{Timeout: 5 * time.Second})
+    if err != nil {
+        t.Fatal(err)
+    }
+    if result.Error != nil || !result.Success {
+        t.Fatalf("restore failed: %v", result.Error)
+    }
+    if result.SnapshotID != id || result.NewName != "app" || result.Image != testImageDigest ||
+        !reflect.DeepEqual(result.NetworksCreated, []string{"appnet"}) {
+        t.Errorf("result = %+v", result)
+    }
+    if !reflect.DeepEqual(d.Pulls, []string{testImageDigest}) {
+        t.Errorf("pulls = %v, want the image digest", d.Pulls)
+    }
+
+    ctn := d.Container("app")
+    if ctn == nil || !ctn.State.Running {
+        t.Fatal("container not running after restore")
+    }
+    if ctn.Config.Image != testImageDigest || !reflect.DeepEqual(ctn.Config.Env, []string{"MODE=prod"}) ||
+        !reflect.DeepEqual(ctn.HostConfig.Binds, []string{"/srv/app:/data"}) {
+        t.Errorf("container config = %+v, host config = %+v", ctn.Config, ctn.HostConfig)
+    }
+    if ctn.Config.Labels["zockimate.snapshot_id"] != strconv.FormatInt(id, 10) ||
+        ctn.Config.Labels["zockimate.original_image"] != "nginx:1.27" {
+        t.Errorf("labels = %v", ctn.Config.Labels)
+    }
+    // Endpoint sans les identifiants du réseau supprimé, alias conservés
+    ep := ctn.NetworkSettings.Networks["appnet"]
+    if ep == nil || ep.NetworkID != "" || ep.EndpointID != "" || !reflect.DeepEqual(ep.Aliases, []string{"web"}) {
+        t.Errorf("endpoint = %+v", ep)
+    }
+
+    // Le conteneur existe : restore refusé
+    result, err = cm.RestoreContainer(ctx, "app", options.RestoreOptions{Timeout: 5 * time.Second})
+    if err != nil {
+        t.Fatal(err)
+    }
+    if result.Success || result.Error == nil || !strings.Contains(result.Error.Error(), "already exists") {
+        t.Errorf("restore over an existing container = %v", result.Error)
+    }
+
+    // Sous un autre nom, image déjà présente : pas de téléchargement
+    result, err = cm.RestoreContainer(ctx, "app", options.RestoreOptions{As: "app-restored", Timeout: 5 * time.Second})
+    if err != nil {
+        t.Fatal(err)
+    }
+    if !result.Success || d.Container("app-restored") == nil || len(d.Pulls) != 1 {
+        t.Errorf("restore as app-restored = %v, pulls %v", result.Error, d.Pulls)
+    }
+
+    ops, err := cm.db.GetOperations(options.HistoryOptions{})
+    if err != nil {
+        t.Fatal(err)
+    }
+    outcomes := make(map[string]string)
+    for _, op := range ops {
+        if op.Type != zTypes.OperationRestore || op.TargetSnapshotID != id {
+            t.Errorf("operation = %+v", op)
+        }
+        outcomes[op.ContainerName] += op.Outcome + " "
+    }
+    if outcomes["app"] != "failed success " && outcomes["app"] != "success failed " ||
+        outcomes["app-restored"] != "success " {
+        t.Errorf("operation outcomes = %v", outcomes)
+    }
+}
+
+func TestRestoreContainerFailures(t *testing.T) {
+    ctx := context.Background()
+    cm := newTestManager(t)
+    d := addTestHost(t, cm, "local")
+    saveContainerSnapshot(t, cm, "app", &container.Config{Image: "nginx:1.27"},
+        &container.HostConfig{}, &network.NetworkingConfig{})
+
+    // Image retirée du registre mais présente localement sous son ID
+    d.AddImage(testImageID)
+    d.Crashing["app"] = true
+
+    result, err := cm.RestoreContainer(ctx, "app", options.RestoreOptions{Timeout: 1500 * time.Millisecond})
+    if err != nil {
+        t.Fatal(err)
+    }
+    if result.Image != testImageID {
+        t.Errorf("image = %q, want the local image ID", result.Image)
+    }
+    // Le conteneur qui ne démarre pas est supprimé pour permettre une nouvelle tentative
+    if result.Success || result.Error == nil || !strings.Contains(result.Error.Error(), "ready") {
+        t.Errorf("restore of a crashing container = %v", result.Error)
+    }
+    if d.Container("app") != nil {
+        t.Error("failed restore left the container behind")
+    }
+
+    // Image introuvable
+    delete(d.Images, testImageID)
+    result, err = cm.RestoreContainer(ctx, "app", options.RestoreOptions{Timeout: time.Second})
+    if err != nil {
+        t.Fatal(err)
+    }
+    if result.Success || result.Error == nil || !strings.Contains(result.Error.Error(), "pull") {
+        t.Errorf("restore without image = %v", result.Error)
+    }
+
+    result, err = cm.RestoreContainer(ctx, "other", options.RestoreOptions{})
+    if err != nil {
+        t.Fatal(err)
+    }
+    if result.Error == nil || !strings.Contains(result.Error.Error(), "no snapshot") {
+        t.Errorf("restore without entry = %v", result.Error)
+    }
+}
@@ -11,7 +11,7 @@ import (
     "zockimate/pkg/utils"
 )
 
-func (cm *ContainerManager) RollbackContainer(ctx context.Context, name string, opts options.RollbackOptions) (*types.RollbackResult, error) {
+func (cm *ContainerManager) RollbackContainer(ctx context.Context, name string, opts options.RollbackOptions) (_ *types.RollbackResult, err error) {
     result := &types.RollbackResult{
         ContainerName:   name,
         SnapshotID:     opts.SnapshotID,
@@ -20,11 +20,38 @@ func (cm *ContainerManager) RollbackContainer(ctx context.Context, name string,
         ConfigRollback: opts.Config,
     }
 
-    name = utils.CleanContainerName(name)
-    cm.logger.Debugf("Rolling back container %s to snapshot %d", name, opts.SnapshotID)
+    host, name, err := cm.resolve(name)
+    if err != nil {
+        result.Error = err
+        return result, nil
+    }
+    id := containerID(host.name, name)
+    cm.logger.Debugf("Rolling back container %s to snapshot %d", id, opts.SnapshotID)
+
+    // Enregistrer l'opération ; l'issue est enregistrée au retour
+    op := cm.startOperation(&types.Operation{
+        Host:             host.name,
+        ContainerName:    name,
+        Type:             types.OperationRollback,
+        TriggeredBy:      opts.Trigger,
+        TargetSnapshotID: opts.SnapshotID,
+    })
+    defer func() {
+        cm.finishRollback(op, opts, result, err)
+    }()
+
+    if svc, ok := serviceName(name); ok {
+        return cm.rollbackService(ctx, host, id, name, svc, opts, result)
+    }
+    return cm.rollbackContainer(ctx, host, id, name, opts, result)
+}
+
+// rollbackContainer restaure un conteneur (image, données et/ou configuration) depuis un snapshot
+func (cm *ContainerManager) rollbackContainer(ctx context.Context, host *hostBackend, id, name string,
+    opts options.RollbackOptions, result *types.RollbackResult) (*types.RollbackResult, error) {
 
     // Inspecter le conteneur
-    ctn, err := cm.docker.InspectContainer(ctx, name)
+    ctn, err := host.docker.InspectContainer(ctx, name)
     if err != nil {
         if client.IsErrNotFound(err) {
             result.Error = fmt.Errorf("container does not exist: %w", err)
@@ -41,14 +68,15 @@ func (cm *ContainerManager) RollbackContainer(ctx context.Context, name string,
     }
 
     // Récupérer le snapshot
-    snapshot, err := cm.db.GetSnapshot(name, opts.SnapshotID)
+    snapshot, err := cm.db.GetSnapshot(host.name, name, opts.SnapshotID)
     if err != nil {
         result.Error = fmt.Errorf("failed to get snapshot: %w", err)
         return result, nil
     }
+    result.SnapshotID = snapshot.ID
 
     // Créer un snapshot de sécurité
-    safetySnapshot, err := cm.CreateSnapshot(ctx, name, options.NewSnapshotOptions(
+    safetySnapshot, err := cm.CreateSnapshot(ctx, id, options.NewSnapshotOptions(
         options.WithSnapshotMessage(fmt.Sprintf("Auto-save before rollback to snapshot %d", snapshot.ID)),
         options.WithSnapshotDryRun(false),
         options.WithSnapshotForce(false),
@@ -87,12 +115,13 @@ func (cm *ContainerManager) RollbackContainer(ctx context.Context, name string,
             }
 
             // Appel récursif pour restaurer le snapshot de sécurité
-            safetyResult, err := cm.RollbackContainer(ctx, name, options.RollbackOptions{
+            safetyResult, err := cm.RollbackContainer(ctx, id, options.RollbackOptions{
                 SnapshotID: safetySnapshot.ID,
                 Image:     true,
                 Data:      true,
                 Config:    true,
                 Force:     true,
+                Trigger:    opts.Trigger,
             })
 
             if err != nil || !safetyResult.Success {
@@ -103,7 +132,7 @@ func (cm *ContainerManager) RollbackContainer(ctx context.Context, name string,
         }
     }()
 
-    config, hostConfig, networkConfig, err := cm.docker.UnmarshalConfigs(snapshot.Config, snapshot.HostConfig, snapshot.NetworkConfig)
+    config, hostConfig, networkConfig, err := host.docker.UnmarshalConfigs(snapshot.Config, snapshot.HostConfig, snapshot.NetworkConfig)
     if err != nil {
         result.Error = fmt.Errorf("failed to unmarshal configs: %w", err)
         return result, result.Error
@@ -130,7 +159,7 @@ func (cm *ContainerManager) RollbackContainer(ctx context.Context, name string,
         imageRef := snapshot.ImageRef.BestReference()
 
         // Pull de l'image si nécessaire
-        if err := cm.docker.PullImage(ctx, imageRef); err != nil {
+        if err := host.docker.PullImage(ctx, imageRef); err != nil {
             result.Error = fmt.Errorf("failed to pull rollback image: %w", err)
             return result, result.Error
         }
@@ -142,18 +171,32 @@ func (cm *ContainerManager) RollbackContainer(ctx context.Context, name string,
         config.Labels["zockimate.original_image"] = snapshot.ImageRef.Original
     }
 
-    // Restaurer les données si demandé
-    if opts.Data && snapshot.ZFSSnapshot != "" {
+    // Restaurer les données si demandé, conteneur arrêté (les datasets sont démontés)
+    if opts.Data && (len(snapshot.ZFSSnapshots) > 0 || len(snapshot.Mounts) > 0) {
         containerModified = true
-        if err := cm.zfs.RollbackSnapshot(snapshot.ZFSSnapshot); err != nil {
-            result.Error = fmt.Errorf("failed to rollback ZFS snapshot: %w", err)
+        if err := host.docker.StopContainer(ctx, name); err != nil {
+            result.Error = err
+            return result, result.Error
+        }
+    }
+    if opts.Data && len(snapshot.ZFSSnapshots) > 0 {
+        if err := cm.restoreZFS(host, snapshot.ZFSSnapshots, opts.DestroyNewer); err != nil {
+            result.Error = err
+            return result, result.Error
+        }
+    }
+
+    // Restaurer les volumes et binds archivés
+    if opts.Data && len(snapshot.Mounts) > 0 {
+        if err := cm.restoreMounts(ctx, host, snapshot.Mounts); err != nil {
+            result.Error = err
             return result, result.Error
         }
     }
     
     // Recréer le conteneur avec les pointeurs corrects
     containerModified = true
-    if err := cm.docker.RecreateContainer(ctx, name, config, hostConfig, networkConfig); err != nil {
+    if err := host.docker.RecreateContainer(ctx, name, config, hostConfig, networkConfig); err != nil {
         result.Error = fmt.Errorf("failed to recreate container: %w", err)
         return result, result.Error
     }
@@ -162,7 +205,7 @@ func (cm *ContainerManager) RollbackContainer(ctx context.Context, name string,
     timeout := utils.GetTimeout(config.Labels, opts.Timeout, cm.logger)
     cm.logger.Debugf("Waiting for container %s to be ready (timeout: %s)", name, timeout)
 
-    if err := cm.docker.WaitForContainer(ctx, name, timeout); err != nil {
+    if err := host.docker.WaitForContainer(ctx, name, timeout); err != nil {
         result.Error = fmt.Errorf("container failed to become ready after rollback: %w", err)
         return result, result.Error
     }
@@ -174,8 +217,54 @@ func (cm *ContainerManager) RollbackContainer(ctx context.Context, name string,
     cm.notifyf(
         "Rollback Successful",
         "Container %s successfully rolled back to snapshot %d (Image: %s)",
-        name, snapshot.ID, snapshot.ImageRef.String(),
+        id, snapshot.ID, snapshot.ImageRef.String(),
     )
 
     return result, nil
 }
+
+// restoreZFS restaure un groupe de snapshots ZFS et met à jour les entrées de la
+// base dont les snapshots ont été déplacés (clone promu) ou détruits (destroyNewer)
+func (cm *ContainerManager) restoreZFS(host *hostBackend, snapshots []string, destroyNewer bool) error {
+    zfsManager, err := host.zfsManager()
+    if err != nil {
+        return err
+    }
+
+    // Rapatrier depuis la réplique les snapshots disparus localement
+    fetched, err := zfsManager.FetchFromReplica(snapshots)
+    if len(fetched) > 0 {
+        if _, dbErr := cm.db.ReconcileZFSSnapshots(host.name, fetched, nil); dbErr != nil {
+            cm.logger.Errorf("Failed to update ZFS snapshot references: %v", dbErr)
+        }
+        restored := make([]string, len(snapshots))
+        for i, s := range snapshots {
+            if local, ok := fetched[s]; ok {
+                s = local
+            }
+            restored[i] = s
+        }
+        snapshots = restored
+        if destroyNewer {
+            cm.logger.Warn("Snapshots fetched from the replica are restored by clone, ignoring --destroy-newer")
+            destroyNewer = false
+        }
+    }
+    if err != nil {
+        return fmt.Errorf("failed to restore ZFS snapshot: %w", err)
+    }
+
+    rec, restoreErr := zfsManager.RestoreSnapshot(snapshots, destroyNewer)
+    if rec != nil {
+        // Réconcilier même après un échec partiel : les datasets déjà traités ont changé
+        if n, err := cm.db.ReconcileZFSSnapshots(host.name, rec.Moved, rec.Destroyed); err != nil {
+            cm.logger.Errorf("Failed to update ZFS snapshot references: %v", err)
+        } else if n > 0 {
+            cm.logger.Debugf("Updated ZFS snapshot references of %d snapshot(s)", n)
+        }
+    }
+    if restoreErr != nil {
+        return fmt.Errorf("failed to restore ZFS snapshot: %w", restoreErr)
+    }
+    return nil
+}
@@ -0,0 +1,59 @@
+// internal/manager/scan.go
+package manager
+
+import (
+    "context"
+    "fmt"
+    "strings"
+
+    "zockimate/internal/scan"
+    "zockimate/internal/types"
+    "zockimate/pkg/utils"
+)
+
+const (
+    // Politiques de vulnérabilités (label zockimate.vuln_policy)
+    vulnPolicyBlock = "block" // Refuser les mises à jour introduisant des CVE critiques
+    vulnPolicyFlag  = "flag"  // Signaler seulement les mises à jour de sécurité
+)
+
+// scanUpdate compare les vulnérabilités de l'image actuelle et de la nouvelle image.
+// Retourne une erreur si la politique bloque la mise à jour.
+func (cm *ContainerManager) scanUpdate(ctx context.Context, labels map[string]string, current, latest *types.ImageReference) (*types.VulnDelta, error) {
+    policy := utils.GetVulnPolicy(labels)
+    if policy == "" || policy == "off" {
+        return nil, nil
+    }
+    if policy != vulnPolicyBlock && policy != vulnPolicyFlag {
+        return nil, fmt.Errorf("invalid zockimate.vuln_policy label: %q (use block or flag)", policy)
+    }
+
+    if cm.scanner == nil {
+        if policy == vulnPolicyBlock {
+            return nil, fmt.Errorf("vulnerability policy is block but no scanner is configured")
+        }
+        cm.logger.Warnf("Vulnerability policy set but no scanner configured, skipping scan")
+        return nil, nil
+    }
+
+    currentReport, err := cm.scanner.Scan(ctx, current)
+    if err != nil {
+        return nil, fmt.Errorf("failed to scan current image: %w", err)
+    }
+    latestReport, err := cm.scanner.Scan(ctx, latest)
+    if err != nil {
+        return nil, fmt.Errorf("failed to scan new image: %w", err)
+    }
+
+    delta := scan.Compare(currentReport, latestReport)
+    cm.logger.Debugf("Vulnerability delta %s -> %s: %s", current.String(), latest.String(), delta.String())
+
+    if policy == vulnPolicyBlock {
+        if critical := delta.IntroducedCritical(); len(critical) > 0 {
+            return delta, fmt.Errorf("update blocked: new image introduces %d critical vulnerabilities (%s)",
+                len(critical), strings.Join(critical, ", "))
+        }
+    }
+
+    return delta, nil
+}
@@ -0,0 +1,88 @@
+// internal/manager/scan_test.go
+package manager
+
+import (
+    "context"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+
+    "zockimate/internal/scan"
+    "zockimate/internal/types"
+)
+
+func TestScanUpdatePolicy(t *testing.T) {
+    ctx := context.Background()
+    cm := newTestManager(t)
+
+    // Rapports pré-générés, nommés par ID d'image
+    dir := t.TempDir()
+    report := func(id string, vulns ...string) *types.ImageReference {
+        var entries []string
+        for _, v := range vulns {
+            id, severity, _ := strings.Cut(v, ":")
+            entries = append(entries, `{"VulnerabilityID":"`+id+`","Severity":"`+severity+`"}`)
+        }
+        data := `{"Results":[{"Vulnerabilities":[` + strings.Join(entries, ",") + `]}]}`
+        if err := os.WriteFile(filepath.Join(dir, id+".json"), []byte(data), 0o644); err != nil {
+            t.Fatal(err)
+        }
+        return &types.ImageReference{ID: "sha256:" + id}
+    }
+    current := report("current", "CVE-1:CRITICAL", "CVE-2:HIGH")
+    security := report("security", "CVE-2:HIGH")
+    critical := report("critical", "CVE-1:CRITICAL", "CVE-2:HIGH", "CVE-3:CRITICAL")
+    unscanned := &types.ImageReference{ID: "sha256:unscanned"}
+
+    policy := func(p string) map[string]string {
+        return map[string]string{"zockimate.vuln_policy": p}
+    }
+
+    // Sans scanner : block refuse, flag laisse passer
+    if _, err := cm.scanUpdate(ctx, policy("block"), current, security); err == nil {
+        t.Error("block policy without scanner accepted")
+    }
+    if delta, err := cm.scanUpdate(ctx, policy("flag"), current, security); err != nil || delta != nil {
+        t.Errorf("flag policy without scanner = %v, %v", delta, err)
+    }
+
+    cm.scanner = scan.NewScanner("", dir, cm.logger)
+
+    tests := []struct {
+        name     string
+        labels   map[string]string
+        latest   *types.ImageReference
+        blocked  bool
+        security bool
+        scanned  bool
+    }{
+        {"no policy", nil, critical, false, false, false},
+        {"off", policy("off"), critical, false, false, false},
+        {"flag security update", policy("flag"), security, false, true, true},
+        {"flag critical", policy("flag"), critical, false, false, true},
+        {"block security update", policy("block"), security, false, true, true},
+        {"block critical", policy("block"), critical, true, false, true},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            delta, err := cm.scanUpdate(ctx, tt.labels, current, tt.latest)
+            if (err != nil) != tt.blocked {
+                t.Fatalf("error = %v, want blocked %v", err, tt.blocked)
+            }
+            if tt.blocked && !strings.Contains(err.Error(), "CVE-3") {
+                t.Errorf("block error = %v, want the critical CVE", err)
+            }
+            if (delta != nil) != tt.scanned || delta.IsSecurityUpdate() != tt.security {
+                t.Errorf("delta = %v, want scanned %v, security %v", delta, tt.scanned, tt.security)
+            }
+        })
+    }
+
+    if _, err := cm.scanUpdate(ctx, policy("warn"), current, security); err == nil {
+        t.Error("invalid policy accepted")
+    }
+    if _, err := cm.scanUpdate(ctx, policy("flag"), current, unscanned); err == nil {
+        t.Error("missing report of the new image accepted")
+    }
+}
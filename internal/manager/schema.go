@@ -0,0 +1,32 @@
+// internal/manager/schema.go
+package manager
+
+import (
+    "zockimate/internal/config"
+    "zockimate/internal/storage/database"
+    "zockimate/internal/types"
+)
+
+// DatabaseVersion retourne la version du schéma de la base, sans la migrer
+// (NewContainerManager applique les migrations en attente)
+func DatabaseVersion(cfg *config.Config) (*types.SchemaStatus, error) {
+    return database.SchemaStatus(cfg.DbPath)
+}
+
+// MigrateDatabase applique les migrations en attente, après sauvegarde de la base.
+// Avec dryRun, liste seulement les migrations à appliquer.
+func MigrateDatabase(cfg *config.Config, dryRun bool) (*types.MigrationResult, error) {
+    return database.Migrate(cfg.DbPath, dryRun)
+}
+
+// VacuumDatabase déplace vers les blobs compressés les configurations encore
+// stockées dans les entrées, supprime les blobs orphelins et compacte la base
+func VacuumDatabase(cfg *config.Config) (*types.VacuumResult, error) {
+    db, err := database.NewDatabase(cfg.DbPath, nil, nil, cfg.Logger)
+    if err != nil {
+        return nil, err
+    }
+    defer db.Close()
+
+    return db.Vacuum()
+}
@@ -0,0 +1,288 @@
+// internal/manager/service.go
+package manager
+
+import (
+    "context"
+    "fmt"
+    "strings"
+
+    "github.com/distribution/reference"
+
+    "zockimate/internal/types"
+    "zockimate/internal/types/options"
+    "zockimate/pkg/utils"
+)
+
+// Préfixe identifiant un service Swarm dans les commandes (ex: service:web_api)
+const servicePrefix = "service:"
+
+// serviceName retourne le nom du service si l'identifiant désigne un service Swarm
+func serviceName(name string) (string, bool) {
+    return strings.CutPrefix(name, servicePrefix)
+}
+
+// serviceImage construit la référence d'image d'un service depuis sa spec
+// ("repo:tag@sha256:..."). Sans image locale, le digest tient lieu d'ID.
+func serviceImage(image string) *types.ImageReference {
+    ref, digest, _ := strings.Cut(image, "@")
+    imgRef := &types.ImageReference{
+        ID:  digest,
+        Tag: ref,
+    }
+    if digest != "" {
+        repo := ref
+        if named, err := reference.ParseNormalizedNamed(ref); err == nil {
+            repo = reference.FamiliarName(named)
+        }
+        imgRef.RepoDigest = repo + "@" + digest
+    }
+    return imgRef
+}
+
+// serviceUpdateRef retourne la référence suivie pour les mises à jour d'un service
+func serviceUpdateRef(labels map[string]string, image string) (string, error) {
+    if original := labels["zockimate.original_image"]; original != "" {
+        return original, nil
+    }
+    ref, _, _ := strings.Cut(image, "@")
+    named, err := reference.ParseNormalizedNamed(ref)
+    if err != nil {
+        return "", fmt.Errorf("invalid service image %s: %w", image, err)
+    }
+    // Sans tag, le dépôt désignerait :latest et non la version déployée
+    if _, ok := named.(reference.Tagged); !ok {
+        return "", fmt.Errorf("service image %s is pinned by digest without tag", image)
+    }
+    return ref, nil
+}
+
+// checkService vérifie si une nouvelle version de l'image d'un service est disponible.
+// Le digest est demandé au registre : les nœuds Swarm téléchargent eux-mêmes l'image.
+func (cm *ContainerManager) checkService(ctx context.Context, host *hostBackend, svc string,
+    opts options.CheckOptions) (types.CheckResult, error) {
+
+    result := types.CheckResult{}
+
+    service, err := host.docker.InspectService(ctx, svc)
+    if err != nil {
+        return result, err
+    }
+    labels := service.Spec.Labels
+
+    if !cm.config.NoFilter && !utils.IsContainerEnabled(labels) {
+        return result, fmt.Errorf("service not enabled for management")
+    }
+
+    image := service.Spec.TaskTemplate.ContainerSpec.Image
+    result.CurrentImage = serviceImage(image)
+
+    updateRef, err := serviceUpdateRef(labels, image)
+    if err != nil {
+        return result, err
+    }
+
+    checkCtx, cancel := context.WithTimeout(ctx, opts.Timeout)
+    defer cancel()
+
+    digest, err := host.docker.RegistryDigest(checkCtx, updateRef)
+    if err != nil {
+        return result, err
+    }
+
+    result.UpdateImage = serviceImage(updateRef + "@" + digest)
+    result.UpdateRef = updateRef
+    result.NeedsUpdate = result.CurrentImage.ID != digest
+
+    if result.NeedsUpdate {
+        if err := cm.applyCooldown(labels, updateRef, result.UpdateImage, &result); err != nil {
+            return result, err
+        }
+        cm.logger.Debugf("Update available for service %s: %s -> %s",
+            svc, result.CurrentImage.String(), result.UpdateImage.String())
+    } else {
+        cm.logger.Debugf("No update needed for service %s", svc)
+    }
+
+    return result, nil
+}
+
+// captureService capture la spécification d'un service Swarm
+func (cm *ContainerManager) captureService(ctx context.Context, host *hostBackend,
+    svc string) (*types.ContainerSnapshot, map[string]string, error) {
+
+    service, err := host.docker.InspectService(ctx, svc)
+    if err != nil {
+        return nil, nil, err
+    }
+
+    image := service.Spec.TaskTemplate.ContainerSpec.Image
+    imageRef := serviceImage(image)
+    imageRef.Original, _ = serviceUpdateRef(service.Spec.Labels, image)
+
+    spec, err := host.docker.MarshalServiceSpec(service.Spec)
+    if err != nil {
+        return nil, nil, err
+    }
+
+    return &types.ContainerSnapshot{
+        ImageRef: *imageRef,
+        Config:   spec,
+    }, service.Spec.Labels, nil
+}
+
+// applyServiceUpdate déploie le nouveau digest sur un service et attend la fin de
+// la mise à jour progressive ; restaure le snapshot en cas d'échec.
+// Appelé avec le lock du manager, qui est libéré avant un éventuel rollback.
+func (cm *ContainerManager) applyServiceUpdate(ctx context.Context, host *hostBackend, id, svc string,
+    checkResult types.CheckResult, snapshotID int64, opts options.UpdateOptions,
+    result *types.UpdateResult, unlock func()) {
+
+    service, err := host.docker.InspectService(ctx, svc)
+    if err != nil {
+        result.Error = err
+        return
+    }
+
+    spec := service.Spec
+    spec.TaskTemplate.ContainerSpec.Image = checkResult.UpdateRef + "@" + checkResult.UpdateImage.ID
+    previous := service.UpdateStatus
+
+    cm.logger.Debugf("Updating service %s to image %s", svc, spec.TaskTemplate.ContainerSpec.Image)
+    if err := host.docker.UpdateService(ctx, service, spec); err != nil {
+        result.Error = err
+        return
+    }
+
+    timeout := utils.GetTimeout(spec.Labels, opts.Timeout, cm.logger)
+    cm.logger.Debugf("Waiting for service %s update to complete (timeout: %s)", svc, timeout)
+
+    if err := host.docker.WaitForService(ctx, svc, previous, timeout); err != nil {
+        cm.logger.Error("Service update failed, initiating rollback")
+        result.RollbackNeeded = true
+        unlock()
+
+        rollbackResult, rollbackErr := cm.RollbackContainer(ctx, id, options.RollbackOptions{
+            SnapshotID: snapshotID,
+            Image:      true,
+            Data:       true,
+            Config:     true,
+            Force:      true,
+            Timeout:    opts.Timeout,
+            Trigger:    opts.Trigger,
+        })
+        if rollbackErr != nil || !rollbackResult.Success {
+            result.Error = fmt.Errorf("update failed and rollback failed: %v (original error: %v)",
+                rollbackResult.Error, err)
+            return
+        }
+
+        result.RolledBack = true
+        result.Error = fmt.Errorf("update failed (rolled back to previous version: %d): %v",
+            rollbackResult.SnapshotID, err)
+        return
+    }
+
+    result.Success = true
+    cm.logger.Debugf("Successfully updated service %s to %s", svc, checkResult.UpdateImage.String())
+}
+
+// rollbackService restaure la spécification et/ou l'image d'un service depuis un snapshot
+func (cm *ContainerManager) rollbackService(ctx context.Context, host *hostBackend, id, name, svc string,
+    opts options.RollbackOptions, result *types.RollbackResult) (*types.RollbackResult, error) {
+
+    service, err := host.docker.InspectService(ctx, svc)
+    if err != nil {
+        result.Error = err
+        return result, nil
+    }
+    if !cm.config.NoFilter && !utils.IsContainerEnabled(service.Spec.Labels) {
+        result.Error = fmt.Errorf("service not enabled for management")
+        return result, nil
+    }
+
+    snapshot, err := cm.db.GetSnapshot(host.name, name, opts.SnapshotID)
+    if err != nil {
+        result.Error = fmt.Errorf("failed to get snapshot: %w", err)
+        return result, nil
+    }
+    result.SnapshotID = snapshot.ID
+
+    // Créer un snapshot de sécurité
+    safetySnapshot, err := cm.CreateSnapshot(ctx, id, options.NewSnapshotOptions(
+        options.WithSnapshotMessage(fmt.Sprintf("Auto-save before rollback to snapshot %d", snapshot.ID)),
+        options.WithSnapshotNoCleanup(true),
+    ))
+    if err != nil {
+        result.Error = fmt.Errorf("failed to create safety snapshot: %w", err)
+        return result, nil
+    }
+    result.SafetySnapshot = safetySnapshot.ID
+
+    cm.lock.Lock()
+    defer cm.lock.Unlock()
+
+    stored, err := host.docker.UnmarshalServiceSpec(snapshot.Config)
+    if err != nil {
+        result.Error = err
+        return result, result.Error
+    }
+
+    // Partir de la spec actuelle ou de la spec sauvegardée
+    spec := service.Spec
+    if opts.Config {
+        spec = stored
+    }
+    if opts.Image {
+        spec.TaskTemplate.ContainerSpec.Image = stored.TaskTemplate.ContainerSpec.Image
+    } else {
+        spec.TaskTemplate.ContainerSpec.Image = service.Spec.TaskTemplate.ContainerSpec.Image
+    }
+
+    // Restaurer les données si demandé
+    if opts.Data && len(snapshot.ZFSSnapshots) > 0 {
+        if err := cm.restoreZFS(host, snapshot.ZFSSnapshots, opts.DestroyNewer); err != nil {
+            result.Error = err
+            return result, result.Error
+        }
+    }
+
+    previous := service.UpdateStatus
+    if err := host.docker.UpdateService(ctx, service, spec); err != nil {
+        result.Error = err
+        return result, result.Error
+    }
+
+    timeout := utils.GetTimeout(spec.Labels, opts.Timeout, cm.logger)
+    if err := host.docker.WaitForService(ctx, svc, previous, timeout); err != nil {
+        result.Error = fmt.Errorf("service failed to converge after rollback (safety snapshot: %d): %w",
+            safetySnapshot.ID, err)
+        return result, result.Error
+    }
+
+    result.Success = true
+    cm.logger.Debugf("Successfully rolled back service %s to snapshot %d", svc, snapshot.ID)
+
+    cm.notifyf(
+        "Rollback Successful",
+        "Service %s successfully rolled back to snapshot %d (Image: %s)",
+        id, snapshot.ID, snapshot.ImageRef.String(),
+    )
+
+    return result, nil
+}
+
+// serviceIDs retourne les services Swarm gérés d'un hôte
+func (cm *ContainerManager) serviceIDs(ctx context.Context, host *hostBackend) ([]string, error) {
+    services, err := host.docker.ListServices(ctx)
+    if err != nil {
+        return nil, fmt.Errorf("failed to list services on host %s: %w", host.name, err)
+    }
+
+    var managed []string
+    for _, service := range services {
+        if cm.config.NoFilter || utils.IsContainerEnabled(service.Spec.Labels) {
+            managed = append(managed, containerID(host.name, servicePrefix+service.Spec.Name))
+        }
+    }
+    return managed, nil
+}
@@ -0,0 +1,157 @@
+// internal/manager/service_test.go
+package manager
+
+import (
+    "context"
+    "reflect"
+    "sort"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/docker/docker/api/types"
+    "github.com/docker/docker/api/types/swarm"
+
+    "zockimate/internal/docker"
+    "zockimate/internal/types/options"
+)
+
+const (
+    serviceOldDigest = "sha256:4444444444444444444444444444444444444444444444444444444444444444"
+    serviceNewDigest = "sha256:5555555555555555555555555555555555555555555555555555555555555555"
+)
+
+func TestServiceImage(t *testing.T) {
+    tests := []struct {
+        image               string
+        id, tag, repoDigest string
+    }{
+        {"nginx:1.27@" + serviceOldDigest, serviceOldDigest, "nginx:1.27", "nginx@" + serviceOldDigest},
+        {"docker.io/library/nginx:1.27@" + serviceOldDigest, serviceOldDigest, "docker.io/library/nginx:1.27", "nginx@" + serviceOldDigest},
+        {"ghcr.io/team/api:2@" + serviceOldDigest, serviceOldDigest, "ghcr.io/team/api:2", "ghcr.io/team/api@" + serviceOldDigest},
+        {"nginx:1.27", "", "nginx:1.27", ""},
+    }
+    for _, tt := range tests {
+        got := serviceImage(tt.image)
+        if got.ID != tt.id || got.Tag != tt.tag || got.RepoDigest != tt.repoDigest {
+            t.Errorf("serviceImage(%s) = %+v", tt.image, got)
+        }
+    }
+
+    if ref, err := serviceUpdateRef(nil, "nginx:1.27@"+serviceOldDigest); err != nil || ref != "nginx:1.27" {
+        t.Errorf("serviceUpdateRef = %q, %v", ref, err)
+    }
+    labels := map[string]string{"zockimate.original_image": "nginx:1"}
+    if ref, err := serviceUpdateRef(labels, "nginx:1.27@"+serviceOldDigest); err != nil || ref != "nginx:1" {
+        t.Errorf("serviceUpdateRef(original_image) = %q, %v", ref, err)
+    }
+    if _, err := serviceUpdateRef(nil, "nginx@"+serviceOldDigest); err == nil {
+        t.Error("image pinned by digest only accepted")
+    }
+
+    if svc, ok := serviceName("service:web_api"); !ok || svc != "web_api" {
+        t.Errorf("serviceName = %q, %v", svc, ok)
+    }
+    if _, ok := serviceName("web_api"); ok {
+        t.Error("container treated as a service")
+    }
+}
+
+// addTestService ajoute un service Swarm géré sur l'image nginx:1.27 épinglée par digest
+func addTestService(d *docker.FakeDaemon, name string) *swarm.Service {
+    replicas := uint64(2)
+    service := &swarm.Service{ID: "svc-" + name}
+    service.Version.Index = 10
+    service.Spec.Name = name
+    service.Spec.Labels = map[string]string{"zockimate.enable": "true"}
+    service.Spec.Mode.Replicated = &swarm.ReplicatedService{Replicas: &replicas}
+    service.Spec.TaskTemplate.ContainerSpec = &swarm.ContainerSpec{
+        Image: "nginx:1.27@" + serviceOldDigest,
+        Env:   []string{"MODE=prod"},
+    }
+    d.Services[name] = service
+    d.Registry["nginx:1.27"] = types.ImageInspect{ID: "sha256:new", RepoDigests: []string{"nginx@" + serviceNewDigest}}
+    return service
+}
+
+func TestUpdateService(t *testing.T) {
+    ctx := context.Background()
+    cm := newTestManager(t)
+    d := addTestHost(t, cm, "local")
+    service := addTestService(d, "web")
+
+    // Les services d'un manager Swarm sont gérés avec les conteneurs
+    d.Info.Swarm.ControlAvailable = true
+    d.AddImage(testImageID, "nginx:1.27")
+    d.RunContainer("app", "nginx:1.27", map[string]string{"zockimate.enable": "true"})
+    names, err := cm.GetContainers(ctx)
+    if err != nil {
+        t.Fatal(err)
+    }
+    sort.Strings(names)
+    if !reflect.DeepEqual(names, []string{"app", "service:web"}) {
+        t.Errorf("GetContainers = %v", names)
+    }
+
+    check, err := cm.CheckContainer(ctx, "service:web", options.NewCheckOptions())
+    if err != nil {
+        t.Fatal(err)
+    }
+    if !check.NeedsUpdate || check.UpdateRef != "nginx:1.27" || check.UpdateImage.ID != serviceNewDigest {
+        t.Errorf("check = %+v", check)
+    }
+
+    result, err := cm.UpdateContainer(ctx, "service:web", options.UpdateOptions{Timeout: 10 * time.Second})
+    if err != nil {
+        t.Fatal(err)
+    }
+    if !result.Success || result.Error != nil || result.RolledBack || result.SnapshotID == 0 {
+        t.Fatalf("update = %+v", result)
+    }
+    spec := service.Spec.TaskTemplate.ContainerSpec
+    if spec.Image != "nginx:1.27@"+serviceNewDigest || !reflect.DeepEqual(spec.Env, []string{"MODE=prod"}) ||
+        service.Version.Index != 11 {
+        t.Errorf("service spec = %+v, version %d", spec, service.Version.Index)
+    }
+    if d.Called("POST /images/create") {
+        t.Error("service image pulled by the manager")
+    }
+
+    // Le snapshot conserve la spécification d'avant la mise à jour
+    snapshot, err := cm.db.GetSnapshot("local", "service:web", result.SnapshotID)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if snapshot.ImageRef.ID != serviceOldDigest || !strings.Contains(string(snapshot.Config), serviceOldDigest) {
+        t.Errorf("snapshot = image %+v, spec %s", snapshot.ImageRef, snapshot.Config)
+    }
+
+    // Déjà à jour
+    result, err = cm.UpdateContainer(ctx, "service:web", options.UpdateOptions{Timeout: 10 * time.Second})
+    if err != nil {
+        t.Fatal(err)
+    }
+    if result.NeedsUpdate || result.Error != nil || service.Version.Index != 11 {
+        t.Errorf("second update = %+v", result)
+    }
+}
+
+func TestUpdateServiceRollsBack(t *testing.T) {
+    ctx := context.Background()
+    cm := newTestManager(t)
+    d := addTestHost(t, cm, "local")
+    service := addTestService(d, "web")
+    d.Failing["nginx:1.27@"+serviceNewDigest] = true
+
+    result, err := cm.UpdateContainer(ctx, "service:web", options.UpdateOptions{Timeout: 10 * time.Second})
+    if err != nil {
+        t.Fatal(err)
+    }
+    if result.Success || !result.RollbackNeeded || !result.RolledBack || result.Error == nil ||
+        !strings.Contains(result.Error.Error(), "task failed to start") {
+        t.Fatalf("update = %+v", result)
+    }
+    if image := service.Spec.TaskTemplate.ContainerSpec.Image; image != "nginx:1.27@"+serviceOldDigest {
+        t.Errorf("service image after rollback = %s", image)
+    }
+}
@@ -4,7 +4,9 @@ package manager
 import (
     "context"
     "fmt"
+    "time"
 
+    dockertypes "github.com/docker/docker/api/types"
     "github.com/docker/docker/client"
 
     "zockimate/internal/types"
@@ -12,42 +14,60 @@ import (
     "zockimate/pkg/utils"
 )
 
-func (cm *ContainerManager) UpdateContainer(ctx context.Context, name string, opts options.UpdateOptions) (*types.UpdateResult, error) {
+func (cm *ContainerManager) UpdateContainer(ctx context.Context, name string, opts options.UpdateOptions) (_ *types.UpdateResult, err error) {
     result := &types.UpdateResult{ContainerName: name}
 
-    name = utils.CleanContainerName(name)
-    cm.logger.Debugf("Starting update process for container: %s", name)
+    host, name, err := cm.resolve(name)
+    if err != nil {
+        result.Error = err
+        return result, nil
+    }
+    id := containerID(host.name, name)
+    cm.logger.Debugf("Starting update process for container: %s", id)
 
     if opts.DryRun {
         cm.logger.Debugf("Dry run: would update container %s", name)
         return result, nil
     }
 
-    // Inspecter le conteneur
-    ctn, err := cm.docker.InspectContainer(ctx, name)
-    if err != nil {
-        if client.IsErrNotFound(err) {
-            result.Error = fmt.Errorf("container does not exist: %w", err)
+    // Récupérer les labels du service Swarm ou du conteneur
+    svc, isService := serviceName(name)
+    var labels map[string]string
+    var ctn dockertypes.ContainerJSON
+    if isService {
+        service, err := host.docker.InspectService(ctx, svc)
+        if err != nil {
+            result.Error = err
+            return result, nil
+        }
+        labels = service.Spec.Labels
+    } else {
+        ctn, err = host.docker.InspectContainer(ctx, name)
+        if err != nil {
+            if client.IsErrNotFound(err) {
+                result.Error = fmt.Errorf("container does not exist: %w", err)
+                return result, nil
+            }
+            result.Error = fmt.Errorf("failed to inspect container: %w", err)
+            return result, nil
+        }
+        labels = ctn.Config.Labels
+
+        // Vérifier si le conteneur doit être en cours d'exécution
+        if !cm.config.All && !ctn.State.Running {
+            result.Error = fmt.Errorf("container not running (use --all to include stopped containers)")
             return result, nil
         }
-        result.Error = fmt.Errorf("failed to inspect container: %w", err)
-        return result, nil
     }
 
     // Vérifier si le conteneur doit être géré
-    if !cm.config.NoFilter && !utils.IsContainerEnabled(ctn.Config.Labels) {
+    if !cm.config.NoFilter && !utils.IsContainerEnabled(labels) {
         result.Error = fmt.Errorf("container not enabled for management")
         return result, nil
     }
 
-    // Vérifier si le conteneur doit être en cours d'exécution
-    if !cm.config.All && !ctn.State.Running {
-        result.Error = fmt.Errorf("container not running (use --all to include stopped containers)")
-        return result, nil
-    }
-
     // Vérifier les mises à jour disponibles
-    checkResult, err := cm.CheckContainer(ctx, name, options.NewCheckOptions(options.WithCheckCleanup(false)))
+    checkResult, err := cm.CheckContainer(ctx, id, options.NewCheckOptions(options.WithCheckCleanup(false)))
     if err != nil {
         result.Error = fmt.Errorf("failed to check for updates: %w", err)
         return result, nil
@@ -62,10 +82,68 @@ func (cm *ContainerManager) UpdateContainer(ctx context.Context, name string, op
         return result, nil
     }
 
+    // Reporter la mise à jour hors fenêtre de maintenance ou pendant une période de gel
+    if !opts.IgnoreWindow {
+        reason, err := cm.checkMaintenance(labels, time.Now())
+        if err != nil {
+            result.Error = err
+            return result, nil
+        }
+        if reason != "" {
+            result.Deferred = true
+            result.DeferReason = "update available, " + reason
+            cm.logger.Debugf("Update of container %s deferred: %s", name, result.DeferReason)
+            return result, nil
+        }
+    }
+
+    // Attendre que la nouvelle image soit stable depuis zockimate.min_age
+    if checkResult.InCooldown && !opts.Force {
+        result.Deferred = true
+        result.DeferReason = fmt.Sprintf("update available, waiting for cooldown until %s",
+            checkResult.CooldownUntil.Local().Format("2006-01-02 15:04"))
+        cm.logger.Debugf("Update of container %s deferred: %s", name, result.DeferReason)
+        return result, nil
+    }
+
+    // Vérifier la signature de la nouvelle image avant toute modification
+    verifiedRef, err := cm.verifyImage(ctx, labels, checkResult.UpdateRef, checkResult.UpdateImage)
+    if err != nil {
+        result.Error = fmt.Errorf("image verification failed, update refused: %w", err)
+        return result, nil
+    }
+
+    // Comparer les vulnérabilités de l'ancienne et de la nouvelle image
+    vulnDelta, err := cm.scanUpdate(ctx, labels, checkResult.CurrentImage, checkResult.UpdateImage)
+    result.VulnDelta = vulnDelta
+    result.Security = vulnDelta.IsSecurityUpdate()
+    if err != nil {
+        result.Error = err
+        return result, nil
+    }
+
+    // Enregistrer l'opération et les images concernées ; l'issue est enregistrée au retour
+    for _, image := range []*types.ImageReference{checkResult.CurrentImage, checkResult.UpdateImage} {
+        if err := cm.db.SaveImage(image); err != nil {
+            cm.logger.Warnf("Failed to record image: %v", err)
+        }
+    }
+    op := cm.startOperation(&types.Operation{
+        Host:          host.name,
+        ContainerName: name,
+        Type:          types.OperationUpdate,
+        TriggeredBy:   opts.Trigger,
+        FromImage:     imageID(checkResult.CurrentImage),
+        ToImage:       imageID(checkResult.UpdateImage),
+    })
+    defer func() {
+        cm.finishUpdate(op, result, err)
+    }()
+
     cm.logger.Debugf("Create snapshot for container: %s", name)
 
     // Créer un snapshot de sécurité avant le rollback
-    safetySnapshot, err := cm.CreateSnapshot(ctx, name, options.NewSnapshotOptions(
+    safetySnapshot, err := cm.CreateSnapshot(ctx, id, options.NewSnapshotOptions(
         options.WithSnapshotMessage("Pre-update snapshot"),
         options.WithSnapshotDryRun(false),
         options.WithSnapshotForce(false),
@@ -75,6 +153,14 @@ func (cm *ContainerManager) UpdateContainer(ctx context.Context, name string, op
     if err != nil {
         return result, fmt.Errorf("failed to create pre-update snapshot: %w", err)
     }
+    result.SnapshotID = safetySnapshot.ID
+
+    // Conserver le delta de vulnérabilités avec le snapshot de la mise à jour
+    if vulnDelta != nil {
+        if err := cm.db.SaveVulnDelta(safetySnapshot.ID, vulnDelta); err != nil {
+            cm.logger.Warnf("Failed to save vulnerability delta: %v", err)
+        }
+    }
 
     cm.lock.Lock()
     var unlocked bool
@@ -84,13 +170,22 @@ func (cm *ContainerManager) UpdateContainer(ctx context.Context, name string, op
         }
     }()
 
+    // Les nœuds Swarm remplacent eux-mêmes les tâches du service
+    if isService {
+        cm.applyServiceUpdate(ctx, host, id, svc, checkResult, safetySnapshot.ID, opts, result, func() {
+            cm.lock.Unlock()
+            unlocked = true
+        })
+        return result, nil
+    }
+
     // Récupérer la configuration actuelle
-    containerConfig, hostConfig, networkConfig, err := cm.docker.GetContainerConfigs(ctn)
+    containerConfig, hostConfig, networkConfig, err := host.docker.GetContainerConfigs(ctn)
     if err != nil {
         return result, fmt.Errorf("failed to get container configurations: %w", err)
     }
 
-    config, hostCfg, netConfig, err := cm.docker.UnmarshalConfigs(containerConfig, hostConfig, networkConfig)
+    config, hostCfg, netConfig, err := host.docker.UnmarshalConfigs(containerConfig, hostConfig, networkConfig)
     if err != nil {
         return result, fmt.Errorf("failed to unmarshal configs: %w", err)
     }
@@ -111,9 +206,24 @@ func (cm *ContainerManager) UpdateContainer(ctx context.Context, name string, op
         delete(config.Labels, "zockimate.snapshot_id")
     }    
 
+    // Image vérifiée : créer le conteneur depuis son digest, le tag ayant pu être
+    // déplacé depuis la vérification. Le tag reste suivi via original_image.
+    if verifiedRef != "" {
+        info, err := host.docker.GetImageInfo(ctx, verifiedRef)
+        if err != nil {
+            return result, fmt.Errorf("verified image %s not available: %w", verifiedRef, err)
+        }
+        if info.ID != checkResult.UpdateImage.ID {
+            return result, fmt.Errorf("verified image %s does not match the checked image %s",
+                verifiedRef, utils.ShortenID(checkResult.UpdateImage.ID))
+        }
+        config.Labels["zockimate.original_image"] = checkResult.UpdateRef
+        config.Image = verifiedRef
+    }
+
     // Créer le nouveau conteneur
     cm.logger.Debugf("Creating new container with image: %s", config.Image)
-    if err := cm.docker.RecreateContainer(ctx, name, config, hostCfg, netConfig); err != nil {
+    if err := host.docker.RecreateContainer(ctx, name, config, hostCfg, netConfig); err != nil {
         return result, fmt.Errorf("failed to recreate container: %w", err)
     }    
 
@@ -122,19 +232,20 @@ func (cm *ContainerManager) UpdateContainer(ctx context.Context, name string, op
     cm.logger.Debugf("Waiting for container %s to be ready (timeout: %s)", name, timeout)
     
     // Modifier la gestion des erreurs pour WaitForContainer
-    if err := cm.docker.WaitForContainer(ctx, name, timeout); err != nil {
+    if err := host.docker.WaitForContainer(ctx, name, timeout); err != nil {
         cm.logger.Error("Container failed to become ready, initiating rollback")
         result.RollbackNeeded = true
         
         cm.lock.Unlock()
         unlocked = true
 
-        rollbackResult, rollbackErr := cm.RollbackContainer(ctx, name, options.RollbackOptions{
+        rollbackResult, rollbackErr := cm.RollbackContainer(ctx, id, options.RollbackOptions{
             SnapshotID: safetySnapshot.ID,
             Image:     true,
             Data:      true,
             Config:    true,
             Force:     true,
+            Trigger:    opts.Trigger,
         })
     
         if rollbackErr != nil || !rollbackResult.Success {
@@ -143,6 +254,7 @@ func (cm *ContainerManager) UpdateContainer(ctx context.Context, name string, op
             return result, nil
         }
     
+        result.RolledBack = true
         result.Error = fmt.Errorf("update failed (rolled back to previous version: %d): %v", 
             rollbackResult.SnapshotID, err)
         return result, nil
@@ -154,4 +266,12 @@ func (cm *ContainerManager) UpdateContainer(ctx context.Context, name string, op
         name, utils.ShortenID(checkResult.UpdateImage.ID))
 
     return result, nil
-}
\ No newline at end of file
+}
+
+// imageID retourne l'ID d'une image (vide si inconnue)
+func imageID(ref *types.ImageReference) string {
+    if ref == nil {
+        return ""
+    }
+    return ref.ID
+}
@@ -0,0 +1,57 @@
+// internal/manager/update_test.go
+package manager
+
+import (
+    "context"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/docker/docker/api/types"
+
+    "zockimate/internal/maintenance"
+    "zockimate/internal/types/options"
+)
+
+func TestUpdateDeferredOnlyWhenPending(t *testing.T) {
+    ctx := context.Background()
+    cm := newTestManager(t)
+    d := addTestHost(t, cm, "local")
+
+    // Période de gel couvrant aujourd'hui
+    today := time.Now().Format("2006-01-02")
+    blackouts, err := maintenance.ParseBlackouts(today)
+    if err != nil {
+        t.Fatal(err)
+    }
+    cm.blackouts = blackouts
+
+    d.AddImage(testImageID, "nginx:1.27", testImageDigest)
+    d.RunContainer("app", "nginx:1.27", map[string]string{"zockimate.enable": "true"})
+
+    // Image à jour : rien à reporter
+    d.Registry["nginx:1.27"] = d.Images[testImageID]
+    result, err := cm.UpdateContainer(ctx, "app", options.UpdateOptions{})
+    if err != nil {
+        t.Fatal(err)
+    }
+    if result.Error != nil || result.Deferred || result.NeedsUpdate {
+        t.Errorf("up to date container = %+v", result)
+    }
+
+    // Nouvelle image publiée : mise à jour reportée
+    const newDigest = "nginx@sha256:3333333333333333333333333333333333333333333333333333333333333333"
+    d.Registry["nginx:1.27"] = types.ImageInspect{ID: "sha256:new", Os: "linux", Architecture: "amd64",
+        RepoTags: []string{"nginx:1.27"}, RepoDigests: []string{newDigest}}
+    result, err = cm.UpdateContainer(ctx, "app", options.UpdateOptions{})
+    if err != nil {
+        t.Fatal(err)
+    }
+    if result.Error != nil || !result.Deferred || !result.NeedsUpdate ||
+        !strings.Contains(result.DeferReason, "blackout period "+today) {
+        t.Errorf("pending update = %+v", result)
+    }
+    if result.SnapshotID != 0 || d.Containers["app"].Image != testImageID {
+        t.Error("deferred update modified the container")
+    }
+}
@@ -0,0 +1,63 @@
+// internal/manager/verify.go
+package manager
+
+import (
+    "context"
+    "fmt"
+
+    "github.com/distribution/reference"
+
+    "zockimate/internal/registry"
+    "zockimate/internal/types"
+    "zockimate/internal/verify"
+    "zockimate/pkg/utils"
+)
+
+// verifyImage vérifie la signature de l'image de mise à jour si le conteneur
+// le demande (labels zockimate.verify et zockimate.verify_key). Retourne la
+// référence par digest vérifiée ("dépôt@sha256:..."), vide sans vérification :
+// le conteneur doit être recréé depuis celle-ci et non depuis le tag, qui peut
+// avoir été déplacé depuis la vérification.
+func (cm *ContainerManager) verifyImage(ctx context.Context, labels map[string]string, ref string, image *types.ImageReference) (string, error) {
+    method := utils.GetVerifyMethod(labels)
+    if method == "" || method == verify.MethodNone {
+        return "", nil
+    }
+
+    if image == nil || image.RepoDigest == "" {
+        return "", fmt.Errorf("image %s has no repository digest to verify", ref)
+    }
+
+    _, digest, err := registry.SplitRepoDigest(image.RepoDigest)
+    if err != nil {
+        return "", err
+    }
+    pinned, err := pinDigest(ref, digest)
+    if err != nil {
+        return "", err
+    }
+
+    policy := verify.Policy{
+        Method: method,
+        Key:    utils.GetVerifyKey(labels),
+    }
+    if err := cm.verifier.Verify(ctx, ref, digest, policy); err != nil {
+        return "", err
+    }
+
+    cm.logger.Debugf("Verified %s signature of %s", method, pinned)
+    return pinned, nil
+}
+
+// pinDigest retourne la référence "dépôt@digest" du dépôt d'une référence par tag
+func pinDigest(ref, digest string) (string, error) {
+    named, err := reference.ParseNormalizedNamed(ref)
+    if err != nil {
+        return "", fmt.Errorf("invalid image reference %q: %w", ref, err)
+    }
+    pinned := reference.FamiliarName(named) + "@" + digest
+    if _, err := reference.ParseNormalizedNamed(pinned); err != nil {
+        return "", fmt.Errorf("invalid digest %q: %w", digest, err)
+    }
+    return pinned, nil
+}
@@ -0,0 +1,32 @@
+// internal/manager/verify_test.go
+package manager
+
+import "testing"
+
+func TestPinDigest(t *testing.T) {
+    digest := "sha256:" + "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+    tests := []struct {
+        ref  string
+        want string
+    }{
+        {"nginx:1.27", "nginx@" + digest},
+        {"docker.io/library/nginx:latest", "nginx@" + digest},
+        {"ghcr.io/team/app:1.0", "ghcr.io/team/app@" + digest},
+        {"localhost:5000/app", "localhost:5000/app@" + digest},
+        {"ghcr.io/team/app:1.0@sha256:" + "f" + digest[8:], "ghcr.io/team/app@" + digest},
+    }
+    for _, tt := range tests {
+        got, err := pinDigest(tt.ref, digest)
+        if err != nil {
+            t.Fatalf("pinDigest(%q): %v", tt.ref, err)
+        }
+        if got != tt.want {
+            t.Errorf("pinDigest(%q) = %q, want %q", tt.ref, got, tt.want)
+        }
+    }
+
+    if _, err := pinDigest("nginx:1.27", "sha256:short"); err == nil {
+        t.Error("pinDigest accepted an invalid digest")
+    }
+}
@@ -0,0 +1,412 @@
+// internal/registry/client.go
+package registry
+
+import (
+    "context"
+    "encoding/base64"
+    "encoding/json"
+    "fmt"
+    "io"
+    "net/http"
+    "net/url"
+    "strings"
+    "sync"
+    "time"
+
+    "github.com/distribution/reference"
+    "github.com/sirupsen/logrus"
+)
+
+const (
+    // Types de manifestes acceptés
+    MediaTypeOCIManifest    = "application/vnd.oci.image.manifest.v1+json"
+    MediaTypeOCIIndex       = "application/vnd.oci.image.index.v1+json"
+    MediaTypeDockerManifest = "application/vnd.docker.distribution.manifest.v2+json"
+    MediaTypeDockerList     = "application/vnd.docker.distribution.manifest.list.v2+json"
+
+    // Taille maximale lue pour un manifeste ou un blob de signature
+    maxBodySize = 16 << 20
+)
+
+// Descriptor décrit un contenu stocké dans un registre
+type Descriptor struct {
+    MediaType    string            `json:"mediaType"`
+    ArtifactType string            `json:"artifactType,omitempty"`
+    Digest       string            `json:"digest"`
+    Size         int64             `json:"size"`
+    Annotations  map[string]string `json:"annotations,omitempty"`
+    Platform     *Platform         `json:"platform,omitempty"` // Entrées d'un index
+}
+
+// Platform décrit la plateforme d'une image d'un index multi-architecture
+type Platform struct {
+    Architecture string `json:"architecture"`
+    OS           string `json:"os"`
+    Variant      string `json:"variant,omitempty"`
+}
+
+// Manifest représente un manifeste OCI (image ou index)
+type Manifest struct {
+    SchemaVersion int               `json:"schemaVersion"`
+    MediaType     string            `json:"mediaType"`
+    ArtifactType  string            `json:"artifactType,omitempty"`
+    Config        Descriptor        `json:"config"`
+    Layers        []Descriptor      `json:"layers"`
+    Manifests     []Descriptor      `json:"manifests,omitempty"`
+    Subject       *Descriptor       `json:"subject,omitempty"`
+    Annotations   map[string]string `json:"annotations,omitempty"`
+}
+
+// Repository identifie un dépôt dans un registre
+type Repository struct {
+    Domain string // Domaine du registre (ex: ghcr.io, docker.io)
+    Path   string // Chemin du dépôt (ex: library/nginx)
+}
+
+// ParseRepository extrait le registre et le dépôt d'une référence d'image
+func ParseRepository(ref string) (Repository, error) {
+    named, err := reference.ParseNormalizedNamed(ref)
+    if err != nil {
+        return Repository{}, fmt.Errorf("invalid image reference %q: %w", ref, err)
+    }
+    return Repository{
+        Domain: reference.Domain(named),
+        Path:   reference.Path(named),
+    }, nil
+}
+
+// String retourne le dépôt au format domaine/chemin
+func (r Repository) String() string {
+    return r.Domain + "/" + r.Path
+}
+
+// Client accède en lecture à l'API distribution v2 des registres
+type Client struct {
+    httpClient *http.Client
+    insecure   map[string]bool
+    creds      *CredentialStore
+    logger     *logrus.Logger
+    tokens     map[string]string // En-têtes Authorization par registre et scope
+    lock       sync.Mutex
+}
+
+// NewClient crée un client de registre.
+// Les registres listés dans insecure (ainsi que localhost) sont joints en HTTP.
+// creds peut être nil : l'accès est alors anonyme.
+func NewClient(insecure []string, creds *CredentialStore, logger *logrus.Logger) *Client {
+    hosts := make(map[string]bool)
+    for _, h := range insecure {
+        if h = strings.TrimSpace(h); h != "" {
+            hosts[h] = true
+        }
+    }
+    return &Client{
+        httpClient: &http.Client{Timeout: 60 * time.Second},
+        insecure:   hosts,
+        creds:      creds,
+        logger:     logger,
+        tokens:     make(map[string]string),
+    }
+}
+
+// baseURL retourne l'URL de l'API v2 du registre
+func (c *Client) baseURL(domain string) string {
+    host := domain
+    if domain == "docker.io" {
+        host = "registry-1.docker.io"
+    }
+
+    scheme := "https"
+    hostname := strings.Split(host, ":")[0]
+    if c.insecure[host] || c.insecure[hostname] || hostname == "localhost" || hostname == "127.0.0.1" {
+        scheme = "http"
+    }
+    return scheme + "://" + host + "/v2/"
+}
+
+// GetManifest récupère un manifeste par tag ou digest
+func (c *Client) GetManifest(ctx context.Context, repo Repository, ref string) (*Manifest, string, error) {
+    body, resp, err := c.get(ctx, repo, "manifests/"+ref, strings.Join([]string{
+        MediaTypeOCIManifest, MediaTypeOCIIndex, MediaTypeDockerManifest, MediaTypeDockerList,
+    }, ", "))
+    if err != nil {
+        return nil, "", err
+    }
+
+    var manifest Manifest
+    if err := json.Unmarshal(body, &manifest); err != nil {
+        return nil, "", fmt.Errorf("failed to decode manifest %s: %w", ref, err)
+    }
+    if manifest.MediaType == "" {
+        manifest.MediaType = resp.Header.Get("Content-Type")
+    }
+
+    return &manifest, resp.Header.Get("Docker-Content-Digest"), nil
+}
+
+// GetBlob récupère un blob et vérifie son digest
+func (c *Client) GetBlob(ctx context.Context, repo Repository, digest string) ([]byte, error) {
+    body, _, err := c.get(ctx, repo, "blobs/"+digest, "")
+    if err != nil {
+        return nil, err
+    }
+    if err := VerifyDigest(digest, body); err != nil {
+        return nil, err
+    }
+    return body, nil
+}
+
+// OpenBlob ouvre un blob en lecture continue (couches d'image), sans vérification
+// du digest : l'appelant le vérifie au fil de la lecture
+func (c *Client) OpenBlob(ctx context.Context, repo Repository, digest string) (io.ReadCloser, error) {
+    target := c.baseURL(repo.Domain) + repo.Path + "/blobs/" + digest
+
+    resp, err := c.do(ctx, repo, target, "")
+    if err != nil {
+        return nil, err
+    }
+    if resp.StatusCode == http.StatusNotFound {
+        resp.Body.Close()
+        return nil, &notFoundError{target: target}
+    }
+    if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+        body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+        resp.Body.Close()
+        return nil, fmt.Errorf("registry request %s failed with status %d: %s",
+            target, resp.StatusCode, strings.TrimSpace(string(body)))
+    }
+    return resp.Body, nil
+}
+
+// Referrers liste les artefacts référençant un digest (API referrers OCI 1.1,
+// avec repli sur le schéma de tag "sha256-<hex>")
+func (c *Client) Referrers(ctx context.Context, repo Repository, digest, artifactType string) ([]Descriptor, error) {
+    path := "referrers/" + digest
+    if artifactType != "" {
+        path += "?artifactType=" + url.QueryEscape(artifactType)
+    }
+
+    var index Manifest
+    body, _, err := c.get(ctx, repo, path, MediaTypeOCIIndex)
+    if err == nil {
+        if err := json.Unmarshal(body, &index); err != nil {
+            return nil, fmt.Errorf("failed to decode referrers index: %w", err)
+        }
+    } else if IsNotFound(err) {
+        // Registre sans API referrers : utiliser le tag de repli
+        fallback, _, ferr := c.GetManifest(ctx, repo, strings.Replace(digest, ":", "-", 1))
+        if ferr != nil {
+            if IsNotFound(ferr) {
+                return nil, nil
+            }
+            return nil, ferr
+        }
+        index = *fallback
+    } else {
+        return nil, err
+    }
+
+    var referrers []Descriptor
+    for _, desc := range index.Manifests {
+        if artifactType == "" || desc.ArtifactType == artifactType {
+            referrers = append(referrers, desc)
+        }
+    }
+    return referrers, nil
+}
+
+// get effectue une requête GET authentifiée sur l'API v2 du registre
+func (c *Client) get(ctx context.Context, repo Repository, path, accept string) ([]byte, *http.Response, error) {
+    target := c.baseURL(repo.Domain) + repo.Path + "/" + path
+
+    resp, err := c.do(ctx, repo, target, accept)
+    if err != nil {
+        return nil, nil, err
+    }
+    defer resp.Body.Close()
+
+    body, err := io.ReadAll(io.LimitReader(resp.Body, maxBodySize))
+    if err != nil {
+        return nil, nil, fmt.Errorf("failed to read registry response: %w", err)
+    }
+
+    if resp.StatusCode == http.StatusNotFound {
+        return nil, nil, &notFoundError{target: target}
+    }
+    if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+        return nil, nil, fmt.Errorf("registry request %s failed with status %d: %s",
+            target, resp.StatusCode, strings.TrimSpace(string(body)))
+    }
+
+    return body, resp, nil
+}
+
+// do envoie la requête en gérant les défis d'authentification Bearer et Basic
+func (c *Client) do(ctx context.Context, repo Repository, target, accept string) (*http.Response, error) {
+    send := func(authorization string) (*http.Response, error) {
+        req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+        if err != nil {
+            return nil, fmt.Errorf("failed to create registry request: %w", err)
+        }
+        if accept != "" {
+            req.Header.Set("Accept", accept)
+        }
+        if authorization != "" {
+            req.Header.Set("Authorization", authorization)
+        }
+        resp, err := c.httpClient.Do(req)
+        if err != nil {
+            return nil, fmt.Errorf("registry request %s failed: %w", target, err)
+        }
+        return resp, nil
+    }
+
+    scope := "repository:" + repo.Path + ":pull"
+    key := repo.Domain + "|" + scope
+
+    c.lock.Lock()
+    cached := c.tokens[key]
+    c.lock.Unlock()
+
+    resp, err := send(cached)
+    if err != nil || resp.StatusCode != http.StatusUnauthorized {
+        return resp, err
+    }
+
+    challenge := resp.Header.Get("WWW-Authenticate")
+    resp.Body.Close()
+
+    creds, found, err := c.creds.Lookup(repo.Domain)
+    if err != nil {
+        return nil, err
+    }
+
+    var authorization string
+    if strings.HasPrefix(strings.ToLower(challenge), "basic") {
+        if !found || creds.Username == "" {
+            return nil, fmt.Errorf("registry %s requires credentials", repo.Domain)
+        }
+        authorization = "Basic " + base64.StdEncoding.EncodeToString([]byte(creds.Username+":"+creds.Password))
+    } else {
+        if !found {
+            creds = Credentials{}
+        }
+        token, err := c.fetchToken(ctx, challenge, scope, creds)
+        if err != nil {
+            return nil, err
+        }
+        authorization = "Bearer " + token
+    }
+
+    c.lock.Lock()
+    c.tokens[key] = authorization
+    c.lock.Unlock()
+
+    return send(authorization)
+}
+
+// fetchToken obtient un jeton auprès du service d'authentification du registre,
+// avec les identifiants fournis ou anonymement s'ils sont vides
+func (c *Client) fetchToken(ctx context.Context, challenge, scope string, creds Credentials) (string, error) {
+    params := parseChallenge(challenge)
+    realm := params["realm"]
+    if realm == "" {
+        return "", fmt.Errorf("unsupported registry authentication challenge: %q", challenge)
+    }
+    if s := params["scope"]; s != "" {
+        scope = s
+    }
+
+    var req *http.Request
+    var err error
+    if creds.IdentityToken != "" {
+        // Jeton d'identité : échange OAuth2 refresh_token
+        form := url.Values{}
+        form.Set("grant_type", "refresh_token")
+        form.Set("refresh_token", creds.IdentityToken)
+        form.Set("service", params["service"])
+        form.Set("scope", scope)
+        form.Set("client_id", "zockimate")
+        req, err = http.NewRequestWithContext(ctx, http.MethodPost, realm, strings.NewReader(form.Encode()))
+        if err != nil {
+            return "", fmt.Errorf("failed to create token request: %w", err)
+        }
+        req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+    } else {
+        u, err := url.Parse(realm)
+        if err != nil {
+            return "", fmt.Errorf("invalid authentication realm %q: %w", realm, err)
+        }
+        q := u.Query()
+        if service := params["service"]; service != "" {
+            q.Set("service", service)
+        }
+        q.Set("scope", scope)
+        u.RawQuery = q.Encode()
+
+        req, err = http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+        if err != nil {
+            return "", fmt.Errorf("failed to create token request: %w", err)
+        }
+        if creds.Username != "" {
+            req.SetBasicAuth(creds.Username, creds.Password)
+        }
+    }
+
+    resp, err := c.httpClient.Do(req)
+    if err != nil {
+        return "", fmt.Errorf("token request failed: %w", err)
+    }
+    defer resp.Body.Close()
+
+    if resp.StatusCode != http.StatusOK {
+        body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+        return "", fmt.Errorf("token request failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+    }
+
+    var tokenResp struct {
+        Token       string `json:"token"`
+        AccessToken string `json:"access_token"`
+    }
+    if err := json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
+        return "", fmt.Errorf("failed to decode token response: %w", err)
+    }
+    if tokenResp.Token != "" {
+        return tokenResp.Token, nil
+    }
+    return tokenResp.AccessToken, nil
+}
+
+// parseChallenge parse un en-tête WWW-Authenticate de type Bearer
+func parseChallenge(header string) map[string]string {
+    params := make(map[string]string)
+    if !strings.HasPrefix(strings.ToLower(header), "bearer ") {
+        return params
+    }
+    rest := header[len("bearer "):]
+    for rest != "" {
+        eq := strings.Index(rest, "=")
+        if eq < 0 {
+            break
+        }
+        key := strings.ToLower(strings.TrimSpace(rest[:eq]))
+        rest = rest[eq+1:]
+
+        var value string
+        if strings.HasPrefix(rest, `"`) {
+            end := strings.Index(rest[1:], `"`)
+            if end < 0 {
+                value, rest = rest[1:], ""
+            } else {
+                value, rest = rest[1:end+1], rest[end+2:]
+            }
+        } else if comma := strings.Index(rest, ","); comma >= 0 {
+            value, rest = rest[:comma], rest[comma:]
+        } else {
+            value, rest = rest, ""
+        }
+        params[key] = value
+        rest = strings.TrimLeft(rest, ", ")
+    }
+    return params
+}
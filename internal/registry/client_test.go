@@ -0,0 +1,151 @@
+// internal/registry/client_test.go
+package registry
+
+import (
+    "context"
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "reflect"
+    "strings"
+    "testing"
+)
+
+// authRegistry sert un manifeste derrière une authentification Bearer,
+// avec un service de jetons acceptant identifiants ou jeton d'identité
+type authRegistry struct {
+    domain   string
+    requests []string // Requêtes du service de jetons (méthode, utilisateur, scope)
+}
+
+func newAuthRegistry(t *testing.T, basic bool) *authRegistry {
+    reg := &authRegistry{}
+    tokens := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        r.ParseForm() //nolint:errcheck
+        user, pass, _ := r.BasicAuth()
+        reg.requests = append(reg.requests, r.Method+" "+user+" "+r.Form.Get("scope"))
+        switch {
+        case r.Method == http.MethodPost && r.Form.Get("refresh_token") == "identity":
+            json.NewEncoder(w).Encode(map[string]string{"access_token": "from-identity"}) //nolint:errcheck
+        case user == "alice" && pass == "secret":
+            json.NewEncoder(w).Encode(map[string]string{"token": "from-alice"}) //nolint:errcheck
+        case user == "" && r.Method == http.MethodGet:
+            json.NewEncoder(w).Encode(map[string]string{"token": "anonymous"}) //nolint:errcheck
+        default:
+            http.Error(w, "invalid credentials", http.StatusUnauthorized)
+        }
+    }))
+    t.Cleanup(tokens.Close)
+
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        switch auth := r.Header.Get("Authorization"); {
+        case basic && auth == "Basic YWxpY2U6c2VjcmV0", // alice:secret
+            !basic && (auth == "Bearer from-alice" || auth == "Bearer from-identity"):
+            w.Header().Set("Content-Type", MediaTypeOCIManifest)
+            w.Header().Set("Docker-Content-Digest", "sha256:manifest")
+            w.Write([]byte(`{"schemaVersion":2,"config":{"digest":"sha256:config"}}`)) //nolint:errcheck
+        case basic:
+            w.Header().Set("WWW-Authenticate", `Basic realm="registry"`)
+            w.WriteHeader(http.StatusUnauthorized)
+        case auth == "Bearer anonymous":
+            w.WriteHeader(http.StatusForbidden)
+        default:
+            w.Header().Set("WWW-Authenticate",
+                `Bearer realm="`+tokens.URL+`/token",service="registry.test",scope="repository:team/app:pull"`)
+            w.WriteHeader(http.StatusUnauthorized)
+        }
+    }))
+    t.Cleanup(srv.Close)
+    reg.domain = strings.TrimPrefix(srv.URL, "http://")
+    return reg
+}
+
+func (r *authRegistry) store(t *testing.T, creds string) *CredentialStore {
+    t.Helper()
+    secrets := writeFile(t, t.TempDir(), "secrets.json", `{"`+r.domain+`": `+creds+`}`, 0o600)
+    store, err := NewCredentialStore("", secrets, testLogger())
+    if err != nil {
+        t.Fatal(err)
+    }
+    return store
+}
+
+func TestClientAuthentication(t *testing.T) {
+    ctx := context.Background()
+
+    tests := []struct {
+        name     string
+        basic    bool
+        creds    string
+        requests []string
+        fails    bool
+    }{
+        {"bearer with password", false, `{"username":"alice","password":"secret"}`,
+            []string{"GET alice repository:team/app:pull"}, false},
+        {"bearer with identity token", false, `{"identitytoken":"identity"}`,
+            []string{"POST  repository:team/app:pull"}, false},
+        {"bearer with wrong password", false, `{"username":"alice","password":"wrong"}`,
+            []string{"GET alice repository:team/app:pull"}, true},
+        {"basic", true, `{"username":"alice","password":"secret"}`, nil, false},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            reg := newAuthRegistry(t, tt.basic)
+            c := NewClient(nil, reg.store(t, tt.creds), testLogger())
+            repo := Repository{Domain: reg.domain, Path: "team/app"}
+
+            manifest, digest, err := c.GetManifest(ctx, repo, "1.0")
+            if tt.fails {
+                if err == nil {
+                    t.Fatal("invalid credentials accepted")
+                }
+            } else if err != nil {
+                t.Fatal(err)
+            } else if digest != "sha256:manifest" || manifest.Config.Digest != "sha256:config" {
+                t.Errorf("manifest = %+v, digest %s", manifest, digest)
+            }
+            if !reflect.DeepEqual(reg.requests, tt.requests) {
+                t.Errorf("token requests = %q, want %q", reg.requests, tt.requests)
+            }
+
+            // Le jeton obtenu est réutilisé
+            if !tt.fails {
+                if _, _, err := c.GetManifest(ctx, repo, "1.0"); err != nil || len(reg.requests) != len(tt.requests) {
+                    t.Errorf("second request = %v, %d token requests", err, len(reg.requests))
+                }
+            }
+        })
+    }
+
+    // Sans identifiants : jeton anonyme, refusé par le dépôt privé
+    reg := newAuthRegistry(t, false)
+    c := NewClient(nil, nil, testLogger())
+    if _, _, err := c.GetManifest(ctx, Repository{Domain: reg.domain, Path: "team/app"}, "1.0"); err == nil {
+        t.Error("anonymous access to a private repository succeeded")
+    }
+    if !reflect.DeepEqual(reg.requests, []string{"GET  repository:team/app:pull"}) {
+        t.Errorf("anonymous token requests = %q", reg.requests)
+    }
+
+    // Défi Basic sans identifiants
+    reg = newAuthRegistry(t, true)
+    if _, _, err := c.GetManifest(ctx, Repository{Domain: reg.domain, Path: "team/app"}, "1.0"); err == nil ||
+        !strings.Contains(err.Error(), "requires credentials") {
+        t.Errorf("basic challenge without credentials = %v", err)
+    }
+}
+
+func TestParseChallenge(t *testing.T) {
+    got := parseChallenge(`Bearer realm="https://auth.docker.io/token",service="registry.docker.io",scope="repository:library/nginx:pull,push"`)
+    want := map[string]string{
+        "realm":   "https://auth.docker.io/token",
+        "service": "registry.docker.io",
+        "scope":   "repository:library/nginx:pull,push",
+    }
+    if !reflect.DeepEqual(got, want) {
+        t.Errorf("parseChallenge = %v, want %v", got, want)
+    }
+    if got := parseChallenge(`Basic realm="registry"`); len(got) != 0 {
+        t.Errorf("parseChallenge(basic) = %v", got)
+    }
+}
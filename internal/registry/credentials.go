@@ -0,0 +1,186 @@
+// internal/registry/credentials.go
+package registry
+
+import (
+    "bytes"
+    "encoding/base64"
+    "encoding/json"
+    "fmt"
+    "os"
+    "os/exec"
+    "path/filepath"
+    "strings"
+
+    "github.com/sirupsen/logrus"
+)
+
+// Clé historique de Docker Hub dans ~/.docker/config.json
+const dockerHubAuthKey = "https://index.docker.io/v1/"
+
+// Credentials représente les identifiants d'un registre
+type Credentials struct {
+    Username      string `json:"username"`
+    Password      string `json:"password"`
+    IdentityToken string `json:"identitytoken,omitempty"`
+}
+
+// dockerConfig représente les champs utiles de ~/.docker/config.json
+type dockerConfig struct {
+    Auths map[string]struct {
+        Auth          string `json:"auth"`
+        Username      string `json:"username"`
+        Password      string `json:"password"`
+        IdentityToken string `json:"identitytoken"`
+    } `json:"auths"`
+    CredsStore  string            `json:"credsStore"`
+    CredHelpers map[string]string `json:"credHelpers"`
+}
+
+// CredentialStore résout les identifiants d'un registre depuis un fichier de secrets
+// (prioritaire) puis depuis la configuration du client Docker (credHelpers, auths, credsStore)
+type CredentialStore struct {
+    config  *dockerConfig
+    secrets map[string]Credentials
+    logger  *logrus.Logger
+}
+
+// DefaultDockerConfigPath retourne le chemin par défaut de la configuration du client Docker
+func DefaultDockerConfigPath() string {
+    if dir := os.Getenv("DOCKER_CONFIG"); dir != "" {
+        return filepath.Join(dir, "config.json")
+    }
+    home, err := os.UserHomeDir()
+    if err != nil {
+        return ""
+    }
+    return filepath.Join(home, ".docker", "config.json")
+}
+
+// NewCredentialStore charge la configuration Docker et le fichier de secrets.
+// Les fichiers absents sont ignorés.
+func NewCredentialStore(configPath, secretsPath string, logger *logrus.Logger) (*CredentialStore, error) {
+    store := &CredentialStore{
+        config:  &dockerConfig{},
+        secrets: make(map[string]Credentials),
+        logger:  logger,
+    }
+
+    if configPath != "" {
+        data, err := os.ReadFile(configPath)
+        if err == nil {
+            if err := json.Unmarshal(data, store.config); err != nil {
+                return nil, fmt.Errorf("failed to parse Docker config %s: %w", configPath, err)
+            }
+            logger.Debugf("Loaded Docker client config from %s", configPath)
+        } else if !os.IsNotExist(err) {
+            return nil, fmt.Errorf("failed to read Docker config %s: %w", configPath, err)
+        }
+    }
+
+    if secretsPath != "" {
+        data, err := os.ReadFile(secretsPath)
+        if err != nil {
+            return nil, fmt.Errorf("failed to read registry secrets %s: %w", secretsPath, err)
+        }
+        if err := json.Unmarshal(data, &store.secrets); err != nil {
+            return nil, fmt.Errorf("failed to parse registry secrets %s: %w", secretsPath, err)
+        }
+        logger.Debugf("Loaded credentials for %d registries from %s", len(store.secrets), secretsPath)
+    }
+
+    return store, nil
+}
+
+// registryKeys retourne les clés possibles d'un registre dans les fichiers de configuration
+func registryKeys(domain string) []string {
+    if domain == "docker.io" || domain == "index.docker.io" || domain == "registry-1.docker.io" {
+        return []string{dockerHubAuthKey, "docker.io", "index.docker.io", "registry-1.docker.io"}
+    }
+    return []string{domain, "https://" + domain, "http://" + domain}
+}
+
+// Lookup retourne les identifiants d'un registre, ou false si aucun n'est configuré
+func (s *CredentialStore) Lookup(domain string) (Credentials, bool, error) {
+    if s == nil {
+        return Credentials{}, false, nil
+    }
+    keys := registryKeys(domain)
+
+    // Fichier de secrets
+    for _, key := range keys {
+        if creds, ok := s.secrets[key]; ok {
+            return creds, true, nil
+        }
+    }
+
+    // Helper spécifique au registre
+    for _, key := range keys {
+        if helper, ok := s.config.CredHelpers[key]; ok {
+            return s.fromHelper(helper, key)
+        }
+    }
+
+    // Identifiants en clair dans auths
+    for _, key := range keys {
+        entry, ok := s.config.Auths[key]
+        if !ok {
+            continue
+        }
+        creds := Credentials{
+            Username:      entry.Username,
+            Password:      entry.Password,
+            IdentityToken: entry.IdentityToken,
+        }
+        if entry.Auth != "" {
+            decoded, err := base64.StdEncoding.DecodeString(entry.Auth)
+            if err != nil {
+                return Credentials{}, false, fmt.Errorf("invalid auth entry for %s: %w", key, err)
+            }
+            user, pass, _ := strings.Cut(string(decoded), ":")
+            creds.Username, creds.Password = user, pass
+        }
+        if creds.Username != "" || creds.IdentityToken != "" {
+            return creds, true, nil
+        }
+    }
+
+    // Magasin global
+    if s.config.CredsStore != "" {
+        return s.fromHelper(s.config.CredsStore, keys[0])
+    }
+
+    return Credentials{}, false, nil
+}
+
+// fromHelper interroge un helper docker-credential-<name>
+func (s *CredentialStore) fromHelper(helper, serverURL string) (Credentials, bool, error) {
+    cmd := exec.Command("docker-credential-"+helper, "get")
+    cmd.Stdin = strings.NewReader(serverURL)
+    var stderr bytes.Buffer
+    cmd.Stderr = &stderr
+
+    out, err := cmd.Output()
+    if err != nil {
+        msg := strings.TrimSpace(string(out) + stderr.String())
+        if strings.Contains(msg, "credentials not found") {
+            return Credentials{}, false, nil
+        }
+        return Credentials{}, false, fmt.Errorf("credential helper %s failed for %s: %w: %s", helper, serverURL, err, msg)
+    }
+
+    var resp struct {
+        Username string `json:"Username"`
+        Secret   string `json:"Secret"`
+    }
+    if err := json.Unmarshal(out, &resp); err != nil {
+        return Credentials{}, false, fmt.Errorf("invalid response from credential helper %s: %w", helper, err)
+    }
+
+    s.logger.Debugf("Using credentials from helper %s for %s", helper, serverURL)
+
+    // Convention des helpers : "<token>" désigne un jeton d'identité
+    if resp.Username == "<token>" {
+        return Credentials{IdentityToken: resp.Secret}, true, nil
+    }
+    return Credentials{Username: resp.Username, Password: resp.Secret}, true, nil
+}
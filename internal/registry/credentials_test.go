@@ -0,0 +1,180 @@
+// internal/registry/credentials_test.go
+package registry
+
+import (
+    "encoding/base64"
+    "io"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+
+    "github.com/sirupsen/logrus"
+)
+
+func testLogger() *logrus.Logger {
+    logger := logrus.New()
+    logger.SetOutput(io.Discard)
+    return logger
+}
+
+func writeFile(t *testing.T, dir, name, content string, mode os.FileMode) string {
+    t.Helper()
+    path := filepath.Join(dir, name)
+    if err := os.WriteFile(path, []byte(content), mode); err != nil {
+        t.Fatal(err)
+    }
+    return path
+}
+
+// installHelper installe un faux docker-credential-<name> dans le PATH.
+// Il enregistre l'URL demandée dans <name>.url et répond selon cette URL.
+func installHelper(t *testing.T, dir, name string) {
+    t.Helper()
+    script := `#!/bin/sh
+read url
+echo "$url" >> "$0.url"
+case "$url" in
+    *ghcr.io) echo '{"ServerURL":"ghcr.io","Username":"helper-user","Secret":"helper-pass"}' ;;
+    *quay.io) echo '{"ServerURL":"quay.io","Username":"<token>","Secret":"refresh"}' ;;
+    *broken.example) echo 'keychain locked' >&2; exit 1 ;;
+    *) echo 'credentials not found in native keychain'; exit 1 ;;
+esac
+`
+    writeFile(t, dir, "docker-credential-"+name, script, 0o755)
+}
+
+func TestCredentialStoreLookup(t *testing.T) {
+    dir := t.TempDir()
+    installHelper(t, dir, "pass")
+    installHelper(t, dir, "desktop")
+    t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+    auth := func(user, pass string) string {
+        return base64.StdEncoding.EncodeToString([]byte(user + ":" + pass))
+    }
+    config := writeFile(t, dir, "config.json", `{
+        "auths": {
+            "https://index.docker.io/v1/": {"auth": "`+auth("hub-user", "hub:pass")+`"},
+            "registry.example": {"username": "plain-user", "password": "plain-pass"},
+            "https://token.example": {"identitytoken": "identity"},
+            "secret.example": {"auth": "`+auth("config-user", "config-pass")+`"},
+            "empty.example": {}
+        },
+        "credHelpers": {"ghcr.io": "pass", "quay.io": "pass", "broken.example": "pass"},
+        "credsStore": "desktop"
+    }`, 0o600)
+    secrets := writeFile(t, dir, "secrets.json", `{
+        "secret.example": {"username": "secret-user", "password": "secret-pass"},
+        "https://ghcr.io": {"username": "secret-ghcr", "password": "secret-pass"}
+    }`, 0o600)
+
+    store, err := NewCredentialStore(config, secrets, testLogger())
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    tests := []struct {
+        domain string
+        want   Credentials
+        found  bool
+    }{
+        // Docker Hub sous sa clé historique, mot de passe contenant ":"
+        {"docker.io", Credentials{Username: "hub-user", Password: "hub:pass"}, true},
+        {"registry-1.docker.io", Credentials{Username: "hub-user", Password: "hub:pass"}, true},
+        {"registry.example", Credentials{Username: "plain-user", Password: "plain-pass"}, true},
+        {"token.example", Credentials{IdentityToken: "identity"}, true},
+        // Le fichier de secrets est prioritaire sur auths et credHelpers
+        {"secret.example", Credentials{Username: "secret-user", Password: "secret-pass"}, true},
+        {"ghcr.io", Credentials{Username: "secret-ghcr", Password: "secret-pass"}, true},
+        // Helper spécifique, jeton d'identité
+        {"quay.io", Credentials{IdentityToken: "refresh"}, true},
+        // Entrée vide puis magasin global sans identifiants
+        {"empty.example", Credentials{}, false},
+        {"other.example", Credentials{}, false},
+    }
+    for _, tt := range tests {
+        got, found, err := store.Lookup(tt.domain)
+        if err != nil || found != tt.found || got != tt.want {
+            t.Errorf("Lookup(%s) = %+v %v %v, want %+v %v", tt.domain, got, found, err, tt.want, tt.found)
+        }
+    }
+
+    // Le helper reçoit la clé du registre, le magasin global la première clé
+    if urls, err := os.ReadFile(filepath.Join(dir, "docker-credential-pass.url")); err != nil ||
+        strings.TrimSpace(string(urls)) != "quay.io" {
+        t.Errorf("pass helper asked for %q, %v", urls, err)
+    }
+    if urls, err := os.ReadFile(filepath.Join(dir, "docker-credential-desktop.url")); err != nil ||
+        strings.Fields(string(urls))[1] != "other.example" {
+        t.Errorf("desktop helper asked for %q, %v", urls, err)
+    }
+
+    // Échec du helper
+    if _, _, err := store.Lookup("broken.example"); err == nil || !strings.Contains(err.Error(), "keychain locked") {
+        t.Errorf("broken helper error = %v", err)
+    }
+
+    // Sans magasin : accès anonyme
+    var none *CredentialStore
+    if _, found, err := none.Lookup("ghcr.io"); found || err != nil {
+        t.Errorf("nil store = %v, %v", found, err)
+    }
+}
+
+func TestCredentialStoreHelperFromConfig(t *testing.T) {
+    dir := t.TempDir()
+    installHelper(t, dir, "pass")
+    t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+    config := writeFile(t, dir, "config.json", `{"credHelpers": {"ghcr.io": "pass"}}`, 0o600)
+    store, err := NewCredentialStore(config, "", testLogger())
+    if err != nil {
+        t.Fatal(err)
+    }
+    got, found, err := store.Lookup("ghcr.io")
+    if err != nil || !found || got != (Credentials{Username: "helper-user", Password: "helper-pass"}) {
+        t.Errorf("Lookup(ghcr.io) = %+v %v %v", got, found, err)
+    }
+}
+
+func TestNewCredentialStoreFiles(t *testing.T) {
+    dir := t.TempDir()
+    logger := testLogger()
+
+    // Configuration Docker absente : ignorée
+    store, err := NewCredentialStore(filepath.Join(dir, "missing.json"), "", logger)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if _, found, err := store.Lookup("docker.io"); found || err != nil {
+        t.Errorf("empty store = %v, %v", found, err)
+    }
+
+    // Fichier de secrets absent ou invalide : erreur
+    if _, err := NewCredentialStore("", filepath.Join(dir, "missing.json"), logger); err == nil {
+        t.Error("missing secrets file accepted")
+    }
+    invalid := writeFile(t, dir, "invalid.json", "registry: user", 0o600)
+    if _, err := NewCredentialStore("", invalid, logger); err == nil {
+        t.Error("invalid secrets file accepted")
+    }
+    if _, err := NewCredentialStore(invalid, "", logger); err == nil {
+        t.Error("invalid Docker config accepted")
+    }
+
+    // Entrée auths mal encodée
+    config := writeFile(t, dir, "config.json", `{"auths": {"registry.example": {"auth": "not base64!"}}}`, 0o600)
+    store, err = NewCredentialStore(config, "", logger)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if _, _, err := store.Lookup("registry.example"); err == nil {
+        t.Error("invalid auth entry accepted")
+    }
+
+    t.Setenv("DOCKER_CONFIG", dir)
+    if got := DefaultDockerConfigPath(); got != filepath.Join(dir, "config.json") {
+        t.Errorf("DefaultDockerConfigPath = %s with DOCKER_CONFIG=%s", got, dir)
+    }
+}
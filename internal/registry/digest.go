@@ -0,0 +1,60 @@
+// internal/registry/digest.go
+package registry
+
+import (
+    "crypto/sha256"
+    "crypto/sha512"
+    "encoding/hex"
+    "errors"
+    "fmt"
+    "strings"
+)
+
+// notFoundError indique qu'un contenu est absent du registre
+type notFoundError struct {
+    target string
+}
+
+func (e *notFoundError) Error() string {
+    return fmt.Sprintf("not found in registry: %s", e.target)
+}
+
+// IsNotFound indique si l'erreur correspond à un contenu absent du registre
+func IsNotFound(err error) bool {
+    var nf *notFoundError
+    return errors.As(err, &nf)
+}
+
+// VerifyDigest vérifie qu'un contenu correspond au digest annoncé
+func VerifyDigest(digest string, data []byte) error {
+    algo, expected, ok := strings.Cut(digest, ":")
+    if !ok {
+        return fmt.Errorf("invalid digest %q", digest)
+    }
+
+    var actual string
+    switch algo {
+    case "sha256":
+        sum := sha256.Sum256(data)
+        actual = hex.EncodeToString(sum[:])
+    case "sha512":
+        sum := sha512.Sum512(data)
+        actual = hex.EncodeToString(sum[:])
+    default:
+        return fmt.Errorf("unsupported digest algorithm %q", algo)
+    }
+
+    if actual != expected {
+        return fmt.Errorf("digest mismatch: expected %s, got %s:%s", digest, algo, actual)
+    }
+    return nil
+}
+
+// SplitRepoDigest sépare une référence "repo@sha256:..." en dépôt et digest
+func SplitRepoDigest(repoDigest string) (string, string, error) {
+    repo, digest, ok := strings.Cut(repoDigest, "@")
+    if !ok || !strings.Contains(digest, ":") {
+        return "", "", fmt.Errorf("invalid repository digest %q", repoDigest)
+    }
+    return repo, digest, nil
+}
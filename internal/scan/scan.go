@@ -0,0 +1,162 @@
+// internal/scan/scan.go
+package scan
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "os"
+    "os/exec"
+    "path/filepath"
+    "sort"
+    "strings"
+
+    "github.com/sirupsen/logrus"
+
+    "zockimate/internal/types"
+)
+
+// Report contient les vulnérabilités d'une image (ID → sévérité)
+type Report map[string]string
+
+// Scanner obtient les rapports de vulnérabilités d'une image, soit depuis
+// un répertoire de rapports pré-générés, soit en exécutant Trivy ou Grype
+type Scanner struct {
+    binary string // Binaire du scanner (trivy, grype ou chemin)
+    dir    string // Répertoire de rapports JSON nommés par digest ou ID d'image
+    logger *logrus.Logger
+}
+
+// NewScanner crée un scanner ; retourne nil si aucune source n'est configurée
+func NewScanner(binary, dir string, logger *logrus.Logger) *Scanner {
+    if binary == "" && dir == "" {
+        return nil
+    }
+    return &Scanner{
+        binary: binary,
+        dir:    dir,
+        logger: logger,
+    }
+}
+
+// Scan retourne le rapport de vulnérabilités d'une image
+func (s *Scanner) Scan(ctx context.Context, image *types.ImageReference) (Report, error) {
+    if s.dir != "" {
+        report, err := s.readReport(image)
+        if err == nil {
+            return report, nil
+        }
+        if s.binary == "" || !errors.Is(err, os.ErrNotExist) {
+            return nil, err
+        }
+    }
+    return s.runScanner(ctx, image)
+}
+
+// readReport lit un rapport pré-généré (<digest>.json ou <id>.json, sans préfixe "sha256:")
+func (s *Scanner) readReport(image *types.ImageReference) (Report, error) {
+    var candidates []string
+    if image.RepoDigest != "" {
+        if _, digest, ok := strings.Cut(image.RepoDigest, "@"); ok {
+            candidates = append(candidates, strings.TrimPrefix(digest, "sha256:"))
+        }
+    }
+    if image.ID != "" {
+        candidates = append(candidates, strings.TrimPrefix(image.ID, "sha256:"))
+    }
+
+    for _, name := range candidates {
+        path := filepath.Join(s.dir, name+".json")
+        data, err := os.ReadFile(path)
+        if os.IsNotExist(err) {
+            continue
+        }
+        if err != nil {
+            return nil, fmt.Errorf("failed to read scan report %s: %w", path, err)
+        }
+        s.logger.Debugf("Using scan report %s for %s", path, image.String())
+        return Parse(data)
+    }
+
+    return nil, fmt.Errorf("no scan report for %s in %s: %w", image.String(), s.dir, os.ErrNotExist)
+}
+
+// runScanner exécute Trivy ou Grype et parse sa sortie JSON
+func (s *Scanner) runScanner(ctx context.Context, image *types.ImageReference) (Report, error) {
+    target := image.BestReference()
+
+    var args []string
+    switch filepath.Base(s.binary) {
+    case "grype":
+        args = []string{target, "-o", "json", "-q"}
+    default:
+        args = []string{"image", "--quiet", "--format", "json", target}
+    }
+
+    s.logger.Debugf("Scanning %s with %s", target, s.binary)
+    cmd := exec.CommandContext(ctx, s.binary, args...)
+    out, err := cmd.Output()
+    if err != nil {
+        if exitErr, ok := err.(*exec.ExitError); ok {
+            return nil, fmt.Errorf("scanner %s failed on %s: %w: %s",
+                s.binary, target, err, strings.TrimSpace(string(exitErr.Stderr)))
+        }
+        return nil, fmt.Errorf("scanner %s failed on %s: %w", s.binary, target, err)
+    }
+
+    return Parse(out)
+}
+
+// Parse lit un rapport JSON Trivy ou Grype
+func Parse(data []byte) (Report, error) {
+    var raw struct {
+        // Trivy
+        Results []struct {
+            Vulnerabilities []struct {
+                VulnerabilityID string `json:"VulnerabilityID"`
+                Severity        string `json:"Severity"`
+            } `json:"Vulnerabilities"`
+        } `json:"Results"`
+        // Grype
+        Matches []struct {
+            Vulnerability struct {
+                ID       string `json:"id"`
+                Severity string `json:"severity"`
+            } `json:"vulnerability"`
+        } `json:"matches"`
+    }
+    if err := json.Unmarshal(data, &raw); err != nil {
+        return nil, fmt.Errorf("failed to parse scan report: %w", err)
+    }
+
+    report := make(Report)
+    for _, r := range raw.Results {
+        for _, v := range r.Vulnerabilities {
+            report[v.VulnerabilityID] = strings.ToUpper(v.Severity)
+        }
+    }
+    for _, m := range raw.Matches {
+        report[m.Vulnerability.ID] = strings.ToUpper(m.Vulnerability.Severity)
+    }
+    return report, nil
+}
+
+// Compare calcule les vulnérabilités introduites et corrigées par la nouvelle image
+func Compare(current, latest Report) *types.VulnDelta {
+    delta := &types.VulnDelta{}
+    for id, severity := range latest {
+        if _, ok := current[id]; !ok {
+            delta.Introduced = append(delta.Introduced, types.Vulnerability{ID: id, Severity: severity})
+        }
+    }
+    for id, severity := range current {
+        if _, ok := latest[id]; !ok {
+            delta.Fixed = append(delta.Fixed, types.Vulnerability{ID: id, Severity: severity})
+        }
+    }
+
+    sort.Slice(delta.Introduced, func(i, j int) bool { return delta.Introduced[i].ID < delta.Introduced[j].ID })
+    sort.Slice(delta.Fixed, func(i, j int) bool { return delta.Fixed[i].ID < delta.Fixed[j].ID })
+    return delta
+}
@@ -0,0 +1,170 @@
+// internal/scan/scan_test.go
+package scan
+
+import (
+    "context"
+    "io"
+    "os"
+    "path/filepath"
+    "reflect"
+    "strings"
+    "testing"
+
+    "github.com/sirupsen/logrus"
+
+    "zockimate/internal/types"
+)
+
+const trivyReport = `{
+  "SchemaVersion": 2,
+  "ArtifactName": "nginx:1.27",
+  "Results": [
+    {"Target": "nginx:1.27 (debian 12.6)", "Vulnerabilities": [
+      {"VulnerabilityID": "CVE-2024-0001", "Severity": "CRITICAL"},
+      {"VulnerabilityID": "CVE-2024-0002", "Severity": "high"}
+    ]},
+    {"Target": "usr/local/bin/app", "Vulnerabilities": [
+      {"VulnerabilityID": "GHSA-xxxx-yyyy-zzzz", "Severity": "MEDIUM"}
+    ]},
+    {"Target": "Java"}
+  ]
+}`
+
+const grypeReport = `{
+  "matches": [
+    {"vulnerability": {"id": "CVE-2024-0001", "severity": "Critical"}, "artifact": {"name": "openssl"}},
+    {"vulnerability": {"id": "CVE-2024-0003", "severity": "Low"}, "artifact": {"name": "zlib"}}
+  ],
+  "source": {"type": "image"}
+}`
+
+func testLogger() *logrus.Logger {
+    logger := logrus.New()
+    logger.SetOutput(io.Discard)
+    return logger
+}
+
+func TestParse(t *testing.T) {
+    tests := []struct {
+        name string
+        data string
+        want Report
+    }{
+        {"trivy", trivyReport, Report{
+            "CVE-2024-0001":       types.SeverityCritical,
+            "CVE-2024-0002":       types.SeverityHigh,
+            "GHSA-xxxx-yyyy-zzzz": "MEDIUM",
+        }},
+        {"grype", grypeReport, Report{
+            "CVE-2024-0001": types.SeverityCritical,
+            "CVE-2024-0003": "LOW",
+        }},
+        {"no vulnerability", `{"SchemaVersion": 2, "Results": []}`, Report{}},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            got, err := Parse([]byte(tt.data))
+            if err != nil {
+                t.Fatal(err)
+            }
+            if !reflect.DeepEqual(got, tt.want) {
+                t.Errorf("Parse = %v, want %v", got, tt.want)
+            }
+        })
+    }
+
+    if _, err := Parse([]byte("Total: 3 (CRITICAL: 1)")); err == nil {
+        t.Error("table output accepted")
+    }
+}
+
+func TestCompare(t *testing.T) {
+    current := Report{"CVE-1": types.SeverityCritical, "CVE-2": types.SeverityHigh, "CVE-3": "LOW"}
+    latest := Report{"CVE-3": "LOW", "CVE-5": "MEDIUM", "CVE-4": types.SeverityCritical}
+
+    delta := Compare(current, latest)
+    wantIntroduced := []types.Vulnerability{{ID: "CVE-4", Severity: types.SeverityCritical}, {ID: "CVE-5", Severity: "MEDIUM"}}
+    wantFixed := []types.Vulnerability{{ID: "CVE-1", Severity: types.SeverityCritical}, {ID: "CVE-2", Severity: types.SeverityHigh}}
+    if !reflect.DeepEqual(delta.Introduced, wantIntroduced) || !reflect.DeepEqual(delta.Fixed, wantFixed) {
+        t.Errorf("Compare = +%v -%v, want +%v -%v", delta.Introduced, delta.Fixed, wantIntroduced, wantFixed)
+    }
+    if !reflect.DeepEqual(delta.IntroducedCritical(), []string{"CVE-4"}) || !delta.IsSecurityUpdate() {
+        t.Errorf("delta = %s", delta)
+    }
+
+    // Mêmes vulnérabilités : aucun changement
+    delta = Compare(current, current)
+    if len(delta.Introduced) != 0 || len(delta.Fixed) != 0 || delta.IsSecurityUpdate() || delta.String() != "no change" {
+        t.Errorf("Compare(same) = %s", delta)
+    }
+}
+
+func TestScanReportDir(t *testing.T) {
+    dir := t.TempDir()
+    digest := strings.Repeat("a", 64)
+    id := strings.Repeat("b", 64)
+    if err := os.WriteFile(filepath.Join(dir, digest+".json"), []byte(trivyReport), 0o644); err != nil {
+        t.Fatal(err)
+    }
+    if err := os.WriteFile(filepath.Join(dir, id+".json"), []byte(grypeReport), 0o644); err != nil {
+        t.Fatal(err)
+    }
+
+    s := NewScanner("", dir, testLogger())
+    ctx := context.Background()
+
+    // Le rapport du digest est préféré à celui de l'ID
+    report, err := s.Scan(ctx, &types.ImageReference{RepoDigest: "nginx@sha256:" + digest, ID: "sha256:" + id})
+    if err != nil || len(report) != 3 {
+        t.Errorf("Scan(digest) = %v, %v, want the trivy report", report, err)
+    }
+    report, err = s.Scan(ctx, &types.ImageReference{RepoDigest: "nginx@sha256:" + strings.Repeat("c", 64), ID: "sha256:" + id})
+    if err != nil || len(report) != 2 {
+        t.Errorf("Scan(ID) = %v, %v, want the grype report", report, err)
+    }
+
+    // Sans rapport ni binaire : erreur
+    if _, err := s.Scan(ctx, &types.ImageReference{ID: "sha256:" + strings.Repeat("d", 64)}); err == nil {
+        t.Error("missing report accepted")
+    }
+
+    if NewScanner("", "", testLogger()) != nil {
+        t.Error("scanner created without source")
+    }
+}
+
+func TestScanFallsBackToBinary(t *testing.T) {
+    // Faux Trivy affichant un rapport et ses arguments
+    bin := filepath.Join(t.TempDir(), "trivy")
+    script := "#!/bin/sh\necho \"$@\" > \"$0.args\"\ncat <<'EOF'\n" + trivyReport + "\nEOF\n"
+    if err := os.WriteFile(bin, []byte(script), 0o755); err != nil {
+        t.Fatal(err)
+    }
+
+    s := NewScanner(bin, t.TempDir(), testLogger())
+    image := &types.ImageReference{ID: "sha256:" + strings.Repeat("e", 64), RepoDigest: "nginx@sha256:" + strings.Repeat("f", 64)}
+    report, err := s.Scan(context.Background(), image)
+    if err != nil {
+        t.Fatalf("no fallback to the scanner binary: %v", err)
+    }
+    if len(report) != 3 {
+        t.Errorf("report = %v", report)
+    }
+    args, err := os.ReadFile(bin + ".args")
+    if err != nil {
+        t.Fatal(err)
+    }
+    if want := "image --quiet --format json " + image.BestReference(); strings.TrimSpace(string(args)) != want {
+        t.Errorf("trivy args = %q, want %q", args, want)
+    }
+
+    // Échec du binaire : erreur avec sa sortie d'erreur
+    failing := filepath.Join(t.TempDir(), "grype")
+    if err := os.WriteFile(failing, []byte("#!/bin/sh\necho 'db update failed' >&2\nexit 1\n"), 0o755); err != nil {
+        t.Fatal(err)
+    }
+    s = NewScanner(failing, "", testLogger())
+    if _, err := s.Scan(context.Background(), image); err == nil || !strings.Contains(err.Error(), "db update failed") {
+        t.Errorf("failing scanner error = %v", err)
+    }
+}
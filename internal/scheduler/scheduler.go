@@ -45,6 +45,8 @@ func NewScheduler(m *manager.ContainerManager, opts Options) *Scheduler {
         opts.Logger = logrus.New()
         opts.Logger.SetLevel(logrus.InfoLevel)
     }
+    // Les mises à jour lancées par le planificateur sont enregistrées comme telles
+    opts.UpdateOpts.Trigger = types.TriggerSchedule
 
     return &Scheduler{
         manager:    m,
@@ -146,7 +148,13 @@ func (s *Scheduler) performScheduledCheck(ctx context.Context, containers []stri
             continue
         }
 
-        if result.NeedsUpdate {
+        if result.NeedsUpdate && result.InCooldown {
+            needsUpdate++
+            until := result.CooldownUntil.Local().Format("2006-01-02 15:04")
+            s.logger.Infof("✓ %s: %s → %s (update available, waiting for cooldown until %s)",
+                name, result.CurrentImage.String(), result.UpdateImage.String(), until)
+            updatesAvailable = append(updatesAvailable, fmt.Sprintf("%s (cooldown until %s)", name, until))
+        } else if result.NeedsUpdate {
             needsUpdate++
             s.logger.Infof("✓ %s: %s → %s",
                 name, result.CurrentImage.String(), result.UpdateImage.String())
@@ -187,7 +195,28 @@ func (s *Scheduler) performScheduledUpdate(ctx context.Context, containers []str
     var results []*types.UpdateResult
     var fatalErrors int
     var fatalContainers []string
-    for _, name := range containers {
+
+    // Si la fenêtre de maintenance globale se ferme pendant l'exécution,
+    // ne plus démarrer de nouvelles mises à jour
+    var deadline time.Time
+    if !opts.IgnoreWindow {
+        deadline = s.manager.MaintenanceDeadline(time.Now())
+    }
+
+    for i, name := range containers {
+        if !deadline.IsZero() && !time.Now().Before(deadline) {
+            s.logger.Warnf("Maintenance window ended at %s, deferring %d remaining container(s)",
+                deadline.Format("15:04"), len(containers)-i)
+            for _, remaining := range containers[i:] {
+                results = append(results, &types.UpdateResult{
+                    ContainerName: remaining,
+                    Deferred:      true,
+                    DeferReason:   "maintenance window ended during scheduled run",
+                })
+            }
+            break
+        }
+
         result, err := s.manager.UpdateContainer(ctx, name, opts)
         if err != nil {
             fatalErrors++
@@ -198,20 +227,33 @@ func (s *Scheduler) performScheduledUpdate(ctx context.Context, containers []str
         results = append(results, result)
     }
 
-    var updated, skipped, failed int
+    var updated, skipped, deferred, failed int
     var updatedContainers []string
     var failedContainers []string
+    var deferredContainers []string
 
     for _, r := range results {
         if r.Success {
             updated++
-            s.logger.Infof("✓ %s: %s → %s",
+            updateMsg := fmt.Sprintf("%s: %s → %s",
                 r.ContainerName, r.OldImage.String(), r.NewImage.String())
-            updatedContainers = append(updatedContainers, r.ContainerName)
+            if r.VulnDelta != nil {
+                updateMsg += fmt.Sprintf(" [vulnerabilities: %s]", r.VulnDelta.String())
+            }
+            s.logger.Infof("✓ %s", updateMsg)
+            if r.Security {
+                updatedContainers = append(updatedContainers, r.ContainerName+" (security)")
+            } else {
+                updatedContainers = append(updatedContainers, r.ContainerName)
+            }
         } else if r.Error != nil {
             failed++
             s.logger.Errorf("✗ %s: %v", r.ContainerName, r.Error)
             failedContainers = append(failedContainers, r.ContainerName)
+        } else if r.Deferred {
+            deferred++
+            s.logger.Infof("⏸ %s: deferred (%s)", r.ContainerName, r.DeferReason)
+            deferredContainers = append(deferredContainers, r.ContainerName)
         } else if !r.NeedsUpdate {
             skipped++
             s.logger.Infof("- %s: no update needed", r.ContainerName)
@@ -219,8 +261,8 @@ func (s *Scheduler) performScheduledUpdate(ctx context.Context, containers []str
     }
 
     totalFailed := failed + fatalErrors
-    s.logger.Infof("Summary: %d updated, %d skipped, %d failed",
-        updated, skipped, totalFailed)
+    s.logger.Infof("Summary: %d updated, %d skipped, %d deferred, %d failed",
+        updated, skipped, deferred, totalFailed)
 
     // Envoyer une notification unique avec le résumé
     if opts.Notify && !opts.DryRun && (updated > 0 || totalFailed > 0) {
@@ -235,9 +277,12 @@ func (s *Scheduler) performScheduledUpdate(ctx context.Context, containers []str
         if len(allFailed) > 0 {
             parts = append(parts, fmt.Sprintf("Failed: %s", strings.Join(allFailed, ", ")))
         }
+        if len(deferredContainers) > 0 {
+            parts = append(parts, fmt.Sprintf("Deferred: %s", strings.Join(deferredContainers, ", ")))
+        }
 
-        notifMsg := fmt.Sprintf("%d updated, %d skipped, %d failed.",
-        updated, skipped, totalFailed) + "\nUpdated: " + strings.Join(parts, "\n")
+        notifMsg := fmt.Sprintf("%d updated, %d skipped, %d deferred, %d failed.",
+        updated, skipped, deferred, totalFailed) + "\nUpdated: " + strings.Join(parts, "\n")
 
         if err := s.manager.SendNotification(notifTitle, notifMsg); err != nil {
             s.logger.Warnf("Failed to send notification: %v", err)
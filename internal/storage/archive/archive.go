@@ -0,0 +1,107 @@
+// internal/storage/archive/archive.go
+package archive
+
+import (
+    "compress/gzip"
+    "fmt"
+    "io"
+    "os"
+    "path/filepath"
+    "strings"
+    "time"
+
+    "github.com/sirupsen/logrus"
+)
+
+// Store gère les archives tar compressées des volumes sauvegardés.
+// Les chemins retournés sont relatifs au répertoire du magasin.
+type Store struct {
+    dir    string
+    logger *logrus.Logger
+}
+
+// NewStore crée un magasin d'archives dans le répertoire donné
+func NewStore(dir string, logger *logrus.Logger) *Store {
+    return &Store{
+        dir:    dir,
+        logger: logger,
+    }
+}
+
+// Create ouvre une nouvelle archive pour un point de montage d'un snapshot.
+// L'archive est écrite compressée ; le writer doit être fermé pour la finaliser.
+func (s *Store) Create(host, container string, createdAt time.Time, destination string) (string, io.WriteCloser, error) {
+    name := strings.ReplaceAll(strings.Trim(destination, "/"), "/", "_")
+    if name == "" {
+        name = "root"
+    }
+    path := filepath.Join(host, container, createdAt.UTC().Format("20060102_150405"), name+".tar.gz")
+
+    full := filepath.Join(s.dir, path)
+    if err := os.MkdirAll(filepath.Dir(full), 0700); err != nil {
+        return "", nil, fmt.Errorf("failed to create archive directory: %w", err)
+    }
+    f, err := os.OpenFile(full, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+    if err != nil {
+        return "", nil, fmt.Errorf("failed to create archive %s: %w", path, err)
+    }
+
+    return path, &gzipFile{Writer: gzip.NewWriter(f), file: f}, nil
+}
+
+// Open ouvre une archive en lecture (contenu tar décompressé)
+func (s *Store) Open(path string) (io.ReadCloser, error) {
+    f, err := os.Open(filepath.Join(s.dir, path))
+    if err != nil {
+        return nil, fmt.Errorf("failed to open archive %s: %w", path, err)
+    }
+    zr, err := gzip.NewReader(f)
+    if err != nil {
+        f.Close()
+        return nil, fmt.Errorf("failed to read archive %s: %w", path, err)
+    }
+    return &gunzipFile{Reader: zr, file: f}, nil
+}
+
+// Delete supprime une archive et les répertoires devenus vides
+func (s *Store) Delete(path string) error {
+    full := filepath.Join(s.dir, path)
+    if err := os.Remove(full); err != nil && !os.IsNotExist(err) {
+        return fmt.Errorf("failed to delete archive %s: %w", path, err)
+    }
+
+    // Remonter jusqu'au répertoire du magasin (os.Remove échoue sur un répertoire non vide)
+    for dir := filepath.Dir(full); dir != filepath.Clean(s.dir) && strings.HasPrefix(dir, s.dir); dir = filepath.Dir(dir) {
+        if os.Remove(dir) != nil {
+            break
+        }
+    }
+
+    s.logger.Debugf("Deleted archive: %s", path)
+    return nil
+}
+
+// gzipFile ferme le flux compressé puis le fichier
+type gzipFile struct {
+    *gzip.Writer
+    file *os.File
+}
+
+func (g *gzipFile) Close() error {
+    if err := g.Writer.Close(); err != nil {
+        g.file.Close()
+        return err
+    }
+    return g.file.Close()
+}
+
+// gunzipFile ferme le flux décompressé puis le fichier
+type gunzipFile struct {
+    *gzip.Reader
+    file *os.File
+}
+
+func (g *gunzipFile) Close() error {
+    g.Reader.Close()
+    return g.file.Close()
+}
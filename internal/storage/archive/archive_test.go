@@ -0,0 +1,116 @@
+// internal/storage/archive/archive_test.go
+package archive
+
+import (
+    "io"
+    "os"
+    "path/filepath"
+    "testing"
+    "time"
+
+    "github.com/sirupsen/logrus"
+)
+
+func newTestStore(t *testing.T) *Store {
+    t.Helper()
+    logger := logrus.New()
+    logger.SetOutput(io.Discard)
+    return NewStore(t.TempDir(), logger)
+}
+
+// writeArchive crée une archive contenant data
+func writeArchive(t *testing.T, s *Store, createdAt time.Time, destination, data string) string {
+    t.Helper()
+    path, w, err := s.Create("local", "app", createdAt, destination)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if _, err := io.WriteString(w, data); err != nil {
+        t.Fatal(err)
+    }
+    if err := w.Close(); err != nil {
+        t.Fatal(err)
+    }
+    return path
+}
+
+func TestCreateOpen(t *testing.T) {
+    s := newTestStore(t)
+    createdAt := time.Date(2026, 3, 1, 10, 30, 15, 0, time.FixedZone("CET", 3600))
+
+    for _, c := range []struct {
+        destination, want string
+    }{
+        {"/var/lib/db", "local/app/20260301_093015/var_lib_db.tar.gz"},
+        {"/data/", "local/app/20260301_093015/data.tar.gz"},
+        {"/", "local/app/20260301_093015/root.tar.gz"},
+    } {
+        path := writeArchive(t, s, createdAt, c.destination, "content of "+c.destination)
+        if path != c.want {
+            t.Errorf("Create(%q) = %s, want %s", c.destination, path, c.want)
+        }
+
+        r, err := s.Open(path)
+        if err != nil {
+            t.Fatal(err)
+        }
+        data, err := io.ReadAll(r)
+        r.Close()
+        if err != nil || string(data) != "content of "+c.destination {
+            t.Errorf("Open(%s) = %q, %v", path, data, err)
+        }
+    }
+
+    // Une archive existante n'est jamais écrasée
+    if _, _, err := s.Create("local", "app", createdAt, "/data"); err == nil {
+        t.Error("Create over an existing archive succeeded")
+    }
+
+    if _, err := s.Open("local/app/missing.tar.gz"); err == nil {
+        t.Error("Open(missing archive) succeeded")
+    }
+}
+
+func TestDelete(t *testing.T) {
+    s := newTestStore(t)
+    t1 := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+    data := writeArchive(t, s, t1, "/data", "data")
+    db := writeArchive(t, s, t1, "/db", "db")
+    other := writeArchive(t, s, t1.Add(time.Hour), "/data", "data")
+
+    // Répertoire du snapshot conservé tant qu'il contient une archive
+    if err := s.Delete(data); err != nil {
+        t.Fatal(err)
+    }
+    if _, err := os.Stat(filepath.Join(s.dir, data)); !os.IsNotExist(err) {
+        t.Errorf("archive %s not deleted: %v", data, err)
+    }
+    if _, err := os.Stat(filepath.Join(s.dir, db)); err != nil {
+        t.Errorf("archive %s deleted: %v", db, err)
+    }
+
+    // Dernière archive du snapshot : son répertoire est supprimé, pas celui du conteneur
+    if err := s.Delete(db); err != nil {
+        t.Fatal(err)
+    }
+    if _, err := os.Stat(filepath.Dir(filepath.Join(s.dir, db))); !os.IsNotExist(err) {
+        t.Errorf("empty snapshot directory kept: %v", err)
+    }
+    if _, err := os.Stat(filepath.Join(s.dir, other)); err != nil {
+        t.Errorf("archive %s deleted: %v", other, err)
+    }
+
+    // Dernière archive du magasin : seul le répertoire du magasin reste
+    if err := s.Delete(other); err != nil {
+        t.Fatal(err)
+    }
+    entries, err := os.ReadDir(s.dir)
+    if err != nil || len(entries) != 0 {
+        t.Errorf("store directory = %v, %v, want empty", entries, err)
+    }
+
+    // Archive déjà supprimée : pas d'erreur
+    if err := s.Delete(other); err != nil {
+        t.Errorf("Delete(missing archive) = %v", err)
+    }
+}
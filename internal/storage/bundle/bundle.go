@@ -0,0 +1,292 @@
+// internal/storage/bundle/bundle.go
+package bundle
+
+import (
+    "archive/tar"
+    "crypto/sha256"
+    "encoding/hex"
+    "encoding/json"
+    "fmt"
+    "io"
+    "path"
+    "strings"
+    "time"
+
+    "github.com/klauspost/compress/zstd"
+
+    "zockimate/internal/types"
+)
+
+// Archive d'export de l'historique : fichier tar contenant, dans cet ordre,
+//
+//    manifest.json        format, version et contenu de l'archive
+//    snapshots.json       entrées, configurations désignées par leur empreinte
+//    operations.json      mises à jour et rollbacks
+//    blobs/<sha256>.zst   configurations compressées (zstd)
+//    images/<id>.tar      images sauvegardées par docker save (optionnel)
+//
+// Toute évolution incompatible du contenu incrémente Version.
+const (
+    Format  = "zockimate-bundle"
+    Version = 1
+)
+
+const (
+    manifestFile   = "manifest.json"
+    snapshotsFile  = "snapshots.json"
+    operationsFile = "operations.json"
+    blobsDir       = "blobs/"
+    imagesDir      = "images/"
+)
+
+// Manifest décrit le contenu d'une archive
+type Manifest struct {
+    Format        string    `json:"format"`
+    Version       int       `json:"version"`
+    SchemaVersion int       `json:"schema_version"`       // Version du schéma de la base exportée
+    CreatedAt     time.Time `json:"created_at"`
+    Containers    []string  `json:"containers,omitempty"` // Conteneurs demandés (vide : tous)
+    Snapshots     int       `json:"snapshots"`
+    Operations    int       `json:"operations"`
+    Blobs         int       `json:"blobs"`
+    Images        int       `json:"images"`
+}
+
+// Snapshot est une entrée de la base dans une archive
+type Snapshot struct {
+    ID            int64               `json:"id"`
+    Host          string              `json:"host"`
+    ContainerName string              `json:"container_name"`
+    Image         Image               `json:"image"`
+    Config        string              `json:"config,omitempty"`         // Empreinte du blob de la configuration
+    HostConfig    string              `json:"host_config,omitempty"`    // Empreinte du blob de HostConfig
+    NetworkConfig string              `json:"network_config,omitempty"` // Empreinte du blob de la configuration réseau
+    ZFSSnapshots  []string            `json:"zfs_snapshots,omitempty"`
+    Mounts        []types.MountBackup `json:"mounts,omitempty"`
+    SnapshotMode  string              `json:"snapshot_mode,omitempty"`
+    FreezeMs      int64               `json:"freeze_ms,omitempty"`
+    Message       string              `json:"message"`
+    CreatedAt     time.Time           `json:"created_at"`
+    VulnDelta     *types.VulnDelta    `json:"vuln_delta,omitempty"`
+}
+
+// Image est l'image d'une entrée
+type Image struct {
+    ID         string `json:"id"`
+    RepoDigest string `json:"repo_digest,omitempty"`
+    Tag        string `json:"tag,omitempty"`
+    Original   string `json:"original"`
+    Platform   string `json:"platform,omitempty"`
+}
+
+// Bundle est le contenu lu d'une archive (hors images, transmises au fil de la lecture)
+type Bundle struct {
+    Manifest   Manifest
+    Snapshots  []Snapshot
+    Operations []types.Operation
+    Blobs      map[string][]byte // Empreinte -> contenu décompressé
+}
+
+// Hash retourne l'empreinte d'un contenu (sha256 hexadécimal, comme la table blobs)
+func Hash(data []byte) string {
+    sum := sha256.Sum256(data)
+    return hex.EncodeToString(sum[:])
+}
+
+// Writer écrit une archive
+type Writer struct {
+    tw  *tar.Writer
+    enc *zstd.Encoder
+    now time.Time
+}
+
+// NewWriter crée une archive écrite dans w
+func NewWriter(w io.Writer) *Writer {
+    enc, _ := zstd.NewWriter(nil, zstd.WithEncoderConcurrency(1))
+    return &Writer{tw: tar.NewWriter(w), enc: enc, now: time.Now()}
+}
+
+// WriteManifest écrit le manifeste, premier fichier de l'archive
+func (w *Writer) WriteManifest(m Manifest) error {
+    m.Format = Format
+    m.Version = Version
+    m.CreatedAt = w.now.UTC()
+    return w.writeJSON(manifestFile, m)
+}
+
+// WriteSnapshots écrit les entrées
+func (w *Writer) WriteSnapshots(snapshots []Snapshot) error {
+    return w.writeJSON(snapshotsFile, snapshots)
+}
+
+// WriteOperations écrit les opérations
+func (w *Writer) WriteOperations(ops []types.Operation) error {
+    return w.writeJSON(operationsFile, ops)
+}
+
+// WriteBlob écrit une configuration compressée sous son empreinte
+func (w *Writer) WriteBlob(data []byte) error {
+    return w.writeFile(blobsDir+Hash(data)+".zst", w.enc.EncodeAll(data, nil))
+}
+
+// WriteImage écrit l'archive docker save d'une image, de taille connue
+func (w *Writer) WriteImage(imageID string, size int64, r io.Reader) error {
+    name := imagesDir + imageFileName(imageID)
+    if err := w.tw.WriteHeader(w.header(name, size)); err != nil {
+        return fmt.Errorf("failed to write %s: %w", name, err)
+    }
+    if _, err := io.CopyN(w.tw, r, size); err != nil {
+        return fmt.Errorf("failed to write %s: %w", name, err)
+    }
+    return nil
+}
+
+// Close termine l'archive
+func (w *Writer) Close() error {
+    if err := w.tw.Close(); err != nil {
+        return fmt.Errorf("failed to finish archive: %w", err)
+    }
+    return nil
+}
+
+func (w *Writer) writeJSON(name string, v interface{}) error {
+    data, err := json.MarshalIndent(v, "", "  ")
+    if err != nil {
+        return fmt.Errorf("failed to marshal %s: %w", name, err)
+    }
+    return w.writeFile(name, data)
+}
+
+func (w *Writer) writeFile(name string, data []byte) error {
+    if err := w.tw.WriteHeader(w.header(name, int64(len(data)))); err != nil {
+        return fmt.Errorf("failed to write %s: %w", name, err)
+    }
+    if _, err := w.tw.Write(data); err != nil {
+        return fmt.Errorf("failed to write %s: %w", name, err)
+    }
+    return nil
+}
+
+func (w *Writer) header(name string, size int64) *tar.Header {
+    return &tar.Header{
+        Typeflag: tar.TypeReg,
+        Name:     name,
+        Size:     size,
+        Mode:     0644,
+        ModTime:  w.now,
+        Format:   tar.FormatPAX,
+    }
+}
+
+// imageFileName retourne le nom de fichier d'une image (ID sans préfixe d'algorithme)
+func imageFileName(imageID string) string {
+    _, hex, ok := strings.Cut(imageID, ":")
+    if !ok {
+        hex = imageID
+    }
+    return hex + ".tar"
+}
+
+// Read lit une archive. Les images sont transmises à loadImage au fil de la lecture,
+// avec le contenu déjà lu (les images sont les derniers fichiers de l'archive) ;
+// loadImage nil les ignore. Les blobs sont vérifiés d'après leur empreinte.
+func Read(r io.Reader, loadImage func(b *Bundle, imageID string, r io.Reader) error) (*Bundle, error) {
+    dec, err := zstd.NewReader(nil, zstd.WithDecoderConcurrency(1))
+    if err != nil {
+        return nil, err
+    }
+    defer dec.Close()
+
+    b := &Bundle{Blobs: make(map[string][]byte)}
+    images := 0
+    tr := tar.NewReader(r)
+    for first := true; ; first = false {
+        hdr, err := tr.Next()
+        if err == io.EOF {
+            break
+        }
+        if err != nil {
+            return nil, fmt.Errorf("failed to read archive: %w", err)
+        }
+        if hdr.Typeflag != tar.TypeReg {
+            continue
+        }
+        name := path.Clean(hdr.Name)
+
+        if first != (name == manifestFile) {
+            return nil, fmt.Errorf("not a zockimate export archive (missing %s)", manifestFile)
+        }
+
+        switch {
+        case name == manifestFile:
+            if err := json.NewDecoder(tr).Decode(&b.Manifest); err != nil {
+                return nil, fmt.Errorf("failed to read %s: %w", name, err)
+            }
+            if b.Manifest.Format != Format {
+                return nil, fmt.Errorf("not a zockimate export archive (format %q)", b.Manifest.Format)
+            }
+            if b.Manifest.Version > Version {
+                return nil, fmt.Errorf("archive format version %d is newer than this binary supports (%d)",
+                    b.Manifest.Version, Version)
+            }
+
+        case name == snapshotsFile:
+            if err := json.NewDecoder(tr).Decode(&b.Snapshots); err != nil {
+                return nil, fmt.Errorf("failed to read %s: %w", name, err)
+            }
+
+        case name == operationsFile:
+            if err := json.NewDecoder(tr).Decode(&b.Operations); err != nil {
+                return nil, fmt.Errorf("failed to read %s: %w", name, err)
+            }
+
+        case strings.HasPrefix(name, blobsDir):
+            hash := strings.TrimSuffix(strings.TrimPrefix(name, blobsDir), ".zst")
+            compressed, err := io.ReadAll(tr)
+            if err != nil {
+                return nil, fmt.Errorf("failed to read %s: %w", name, err)
+            }
+            data, err := dec.DecodeAll(compressed, nil)
+            if err != nil {
+                return nil, fmt.Errorf("failed to decompress %s: %w", name, err)
+            }
+            if Hash(data) != hash {
+                return nil, fmt.Errorf("blob %s is corrupted (checksum mismatch)", hash)
+            }
+            b.Blobs[hash] = data
+
+        case strings.HasPrefix(name, imagesDir):
+            images++
+            if loadImage == nil {
+                continue
+            }
+            imageID := "sha256:" + strings.TrimSuffix(strings.TrimPrefix(name, imagesDir), ".tar")
+            if err := loadImage(b, imageID, tr); err != nil {
+                return nil, err
+            }
+        }
+    }
+
+    if b.Manifest.Format == "" {
+        return nil, fmt.Errorf("not a zockimate export archive (missing %s)", manifestFile)
+    }
+
+    // Archive tronquée : contenu différent de celui annoncé par le manifeste
+    m := b.Manifest
+    if len(b.Snapshots) != m.Snapshots || len(b.Operations) != m.Operations ||
+        len(b.Blobs) != m.Blobs || images != m.Images {
+        return nil, fmt.Errorf("archive is incomplete: %d/%d snapshots, %d/%d operations, %d/%d blobs, %d/%d images",
+            len(b.Snapshots), m.Snapshots, len(b.Operations), m.Operations,
+            len(b.Blobs), m.Blobs, images, m.Images)
+    }
+
+    // Toutes les configurations référencées doivent être présentes
+    for _, s := range b.Snapshots {
+        for _, hash := range []string{s.Config, s.HostConfig, s.NetworkConfig} {
+            if _, ok := b.Blobs[hash]; hash != "" && !ok {
+                return nil, fmt.Errorf("archive is incomplete: blob %s of snapshot %d is missing", hash, s.ID)
+            }
+        }
+    }
+    return b, nil
+}
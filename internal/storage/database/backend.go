@@ -0,0 +1,157 @@
+// internal/storage/database/backend.go
+package database
+
+import (
+    "database/sql"
+    "fmt"
+    "io/fs"
+    "strings"
+)
+
+// Schémas d'URL des moteurs (ZOCKIMATE_DB, --db)
+const (
+    sqliteScheme   = "sqlite"
+    postgresScheme = "postgres"
+)
+
+// Backend regroupe ce qui distingue les moteurs SQL sur lesquels repose Database.
+// Deux implémentations : SQLite (fichier local, par défaut) et PostgreSQL (base
+// partagée par les instances de plusieurs hôtes, pour une vue centrale).
+// Les requêtes de Database sont écrites dans le SQL commun aux deux moteurs,
+// avec des paramètres ? réécrits par Rebind.
+type Backend interface {
+    // Name retourne le nom du moteur (sqlite, postgres)
+    Name() string
+    // Open ouvre la base (le fichier SQLite est créé au besoin)
+    Open() (*sql.DB, error)
+    // Exists indique si la base existe déjà, sans la créer
+    Exists() bool
+    // Rebind réécrit les paramètres ? d'une requête dans la syntaxe du moteur
+    Rebind(query string) string
+    // Migrations retourne les fichiers NNNN_nom.sql du schéma pour ce moteur
+    Migrations() fs.FS
+    // TableExists indique si une table existe
+    TableExists(db *sql.DB, name string) (bool, error)
+    // LockSchema sérialise les migrations concurrentes, jusqu'à la fin de la transaction
+    LockSchema(tx *sql.Tx) error
+    // Backup copie la base avant migration et retourne l'emplacement de la copie
+    // (vide si le moteur laisse la sauvegarde à ses propres outils)
+    Backup(db *sql.DB, version int) (string, error)
+    // ResetSequence réaligne la génération des identifiants d'une table après
+    // l'insertion d'identifiants explicites
+    ResetSequence(tx *sql.Tx, table string) error
+    // Size retourne la taille de la base en octets
+    Size(db *sql.DB) (int64, error)
+    // Vacuum compacte la base
+    Vacuum(db *sql.DB) error
+}
+
+// NewBackend choisit le moteur d'après l'URL de la base : postgres://... ou
+// postgresql://... pour PostgreSQL, sqlite://chemin ou un simple chemin pour SQLite
+func NewBackend(url string) (Backend, error) {
+    scheme, rest, ok := strings.Cut(url, "://")
+    if !ok {
+        return newSQLiteBackend(url), nil
+    }
+    switch scheme {
+    case sqliteScheme, "sqlite3":
+        if rest == "" {
+            return nil, fmt.Errorf("missing database path in %s", url)
+        }
+        return newSQLiteBackend(rest), nil
+    case postgresScheme, "postgresql":
+        return newPostgresBackend(url), nil
+    default:
+        return nil, fmt.Errorf("unsupported database scheme %q (use sqlite:// or postgres://)", scheme)
+    }
+}
+
+// FilePath retourne le chemin du fichier d'une base SQLite (vide pour un serveur)
+func FilePath(url string) string {
+    backend, err := NewBackend(url)
+    if err != nil {
+        return ""
+    }
+    if b, ok := backend.(*sqliteBackend); ok {
+        return b.path
+    }
+    return ""
+}
+
+// sqlDB exécute les requêtes de Database dans la syntaxe de son moteur
+type sqlDB struct {
+    *sql.DB
+    backend Backend
+}
+
+func (db *sqlDB) Exec(query string, args ...interface{}) (sql.Result, error) {
+    return db.DB.Exec(db.backend.Rebind(query), args...)
+}
+
+func (db *sqlDB) Query(query string, args ...interface{}) (*sql.Rows, error) {
+    return db.DB.Query(db.backend.Rebind(query), args...)
+}
+
+func (db *sqlDB) QueryRow(query string, args ...interface{}) *sql.Row {
+    return db.DB.QueryRow(db.backend.Rebind(query), args...)
+}
+
+func (db *sqlDB) Begin() (*sqlTx, error) {
+    tx, err := db.DB.Begin()
+    if err != nil {
+        return nil, err
+    }
+    return &sqlTx{Tx: tx, backend: db.backend}, nil
+}
+
+// sqlTx est une transaction de sqlDB
+type sqlTx struct {
+    *sql.Tx
+    backend Backend
+}
+
+func (tx *sqlTx) Exec(query string, args ...interface{}) (sql.Result, error) {
+    return tx.Tx.Exec(tx.backend.Rebind(query), args...)
+}
+
+func (tx *sqlTx) Query(query string, args ...interface{}) (*sql.Rows, error) {
+    return tx.Tx.Query(tx.backend.Rebind(query), args...)
+}
+
+func (tx *sqlTx) QueryRow(query string, args ...interface{}) *sql.Row {
+    return tx.Tx.QueryRow(tx.backend.Rebind(query), args...)
+}
+
+// openDB ouvre la base d'une URL
+func openDB(url string) (*sqlDB, error) {
+    backend, err := NewBackend(url)
+    if err != nil {
+        return nil, err
+    }
+    db, err := backend.Open()
+    if err != nil {
+        return nil, err
+    }
+    return &sqlDB{DB: db, backend: backend}, nil
+}
+
+// rebindNumbered remplace les paramètres ? par $1, $2... (hors chaînes littérales)
+func rebindNumbered(query string) string {
+    var b strings.Builder
+    b.Grow(len(query) + 16)
+    n := 0
+    quoted := false
+    for i := 0; i < len(query); i++ {
+        c := query[i]
+        switch {
+        case c == '\'':
+            quoted = !quoted
+        case c == '?' && !quoted:
+            n++
+            fmt.Fprintf(&b, "$%d", n)
+            continue
+        }
+        b.WriteByte(c)
+    }
+    return b.String()
+}
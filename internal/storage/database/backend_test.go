@@ -0,0 +1,53 @@
+// internal/storage/database/backend_test.go
+package database
+
+import (
+    "testing"
+)
+
+func TestNewBackend(t *testing.T) {
+    tests := []struct {
+        url     string
+        backend string
+        path    string
+        wantErr bool
+    }{
+        {"zockimate.db", sqliteScheme, "zockimate.db", false},
+        {"sqlite:///var/lib/zockimate/zockimate.db", sqliteScheme, "/var/lib/zockimate/zockimate.db", false},
+        {"sqlite3://data.db", sqliteScheme, "data.db", false},
+        {"postgres://zockimate@db.lan/zockimate", postgresScheme, "", false},
+        {"postgresql://zockimate@db.lan/zockimate", postgresScheme, "", false},
+        {"sqlite://", "", "", true},
+        {"mysql://db.lan/zockimate", "", "", true},
+    }
+    for _, tt := range tests {
+        backend, err := NewBackend(tt.url)
+        if (err != nil) != tt.wantErr {
+            t.Errorf("NewBackend(%q) error = %v, wantErr %v", tt.url, err, tt.wantErr)
+            continue
+        }
+        if err != nil {
+            continue
+        }
+        if backend.Name() != tt.backend || FilePath(tt.url) != tt.path {
+            t.Errorf("NewBackend(%q) = %s %q, want %s %q", tt.url, backend.Name(), FilePath(tt.url),
+                tt.backend, tt.path)
+        }
+    }
+}
+
+func TestRebindNumbered(t *testing.T) {
+    tests := []struct {
+        query, want string
+    }{
+        {`SELECT id FROM t WHERE a = ? AND b = ?`, `SELECT id FROM t WHERE a = $1 AND b = $2`},
+        {`UPDATE t SET a = 'why?' WHERE id = ?`, `UPDATE t SET a = 'why?' WHERE id = $1`},
+        {`SELECT 'it''s ?', ?`, `SELECT 'it''s ?', $1`},
+        {`SELECT 1`, `SELECT 1`},
+    }
+    for _, tt := range tests {
+        if got := rebindNumbered(tt.query); got != tt.want {
+            t.Errorf("rebindNumbered(%q) = %q, want %q", tt.query, got, tt.want)
+        }
+    }
+}
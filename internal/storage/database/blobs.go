@@ -0,0 +1,197 @@
+// internal/storage/database/blobs.go
+package database
+
+import (
+    "crypto/sha256"
+    "database/sql"
+    "encoding/hex"
+    "fmt"
+
+    "github.com/klauspost/compress/zstd"
+
+    "zockimate/internal/types"
+)
+
+// Compression des blobs
+const codecZstd = "zstd"
+
+// Taille des lots de conversion des configurations stockées dans les entrées
+const vacuumBatchSize = 500
+
+// Encodeur et décodeur partagés (EncodeAll et DecodeAll sont sûrs en concurrence)
+var (
+    zstdEncoder, _ = zstd.NewWriter(nil, zstd.WithEncoderConcurrency(1))
+    zstdDecoder, _ = zstd.NewReader(nil, zstd.WithDecoderConcurrency(1))
+)
+
+// putBlob enregistre un contenu dans la table blobs s'il n'y est pas déjà et retourne
+// son empreinte (vide pour un contenu vide)
+func putBlob(e execer, data []byte) (string, error) {
+    if len(data) == 0 {
+        return "", nil
+    }
+    sum := sha256.Sum256(data)
+    hash := hex.EncodeToString(sum[:])
+    if _, err := e.Exec(`INSERT INTO blobs (hash, codec, size, data) VALUES (?, ?, ?, ?)
+        ON CONFLICT DO NOTHING`,
+        hash, codecZstd, len(data), zstdEncoder.EncodeAll(data, nil)); err != nil {
+        return "", fmt.Errorf("failed to save blob %s: %w", hash[:12], err)
+    }
+    return hash, nil
+}
+
+// getBlob lit et décompresse un blob, dont l'empreinte est vérifiée
+func (d *Database) getBlob(hash string) ([]byte, error) {
+    var codec string
+    var stored []byte
+    err := d.db.QueryRow(`SELECT codec, data FROM blobs WHERE hash = ?`, hash).Scan(&codec, &stored)
+    if err == sql.ErrNoRows {
+        return nil, fmt.Errorf("blob %s not found", hash)
+    }
+    if err != nil {
+        return nil, fmt.Errorf("failed to query blob %s: %w", hash, err)
+    }
+
+    var data []byte
+    switch codec {
+    case codecZstd:
+        if data, err = zstdDecoder.DecodeAll(stored, nil); err != nil {
+            return nil, fmt.Errorf("failed to decompress blob %s: %w", hash, err)
+        }
+    default:
+        return nil, fmt.Errorf("blob %s: unknown codec %q", hash, codec)
+    }
+
+    sum := sha256.Sum256(data)
+    if hex.EncodeToString(sum[:]) != hash {
+        return nil, fmt.Errorf("blob %s is corrupted", hash)
+    }
+    return data, nil
+}
+
+// loadConfig retourne une configuration : le blob référencé, ou la valeur stockée
+// dans l'entrée pour les entrées pas encore converties
+func (d *Database) loadConfig(inline []byte, hash sql.NullString) ([]byte, error) {
+    if !hash.Valid || hash.String == "" {
+        return inline, nil
+    }
+    return d.getBlob(hash.String)
+}
+
+// saveConfigs enregistre les trois configurations d'une entrée et retourne leurs empreintes
+func saveConfigs(e execer, configs ...[]byte) ([]interface{}, error) {
+    hashes := make([]interface{}, len(configs))
+    for i, data := range configs {
+        hash, err := putBlob(e, data)
+        if err != nil {
+            return nil, err
+        }
+        hashes[i] = sql.NullString{String: hash, Valid: hash != ""}
+    }
+    return hashes, nil
+}
+
+// deleteOrphanBlobs supprime les blobs qu'aucune entrée ne référence
+func (d *Database) deleteOrphanBlobs() (int64, error) {
+    result, err := d.db.Exec(`DELETE FROM blobs WHERE hash NOT IN (
+        SELECT config_hash FROM container_snapshots WHERE config_hash IS NOT NULL
+        UNION SELECT host_config_hash FROM container_snapshots WHERE host_config_hash IS NOT NULL
+        UNION SELECT network_config_hash FROM container_snapshots WHERE network_config_hash IS NOT NULL)`)
+    if err != nil {
+        return 0, fmt.Errorf("failed to delete orphan blobs: %w", err)
+    }
+    return result.RowsAffected()
+}
+
+// Vacuum déplace vers les blobs les configurations encore stockées dans les entrées,
+// supprime les blobs orphelins et compacte le fichier
+func (d *Database) Vacuum() (*types.VacuumResult, error) {
+    result := &types.VacuumResult{}
+    var err error
+    if result.SizeBefore, err = d.db.backend.Size(d.db.DB); err != nil {
+        return nil, err
+    }
+
+    for {
+        n, err := d.convertConfigs()
+        if err != nil {
+            return result, err
+        }
+        result.Converted += n
+        if n < vacuumBatchSize {
+            break
+        }
+    }
+
+    if result.OrphanBlobs, err = d.deleteOrphanBlobs(); err != nil {
+        return result, err
+    }
+    if err := d.db.backend.Vacuum(d.db.DB); err != nil {
+        return result, err
+    }
+
+    if err := d.db.QueryRow(`SELECT COUNT(*), COALESCE(SUM(size), 0), COALESCE(SUM(LENGTH(data)), 0) FROM blobs`).
+        Scan(&result.Blobs, &result.BlobSize, &result.BlobStored); err != nil {
+        return result, fmt.Errorf("failed to query blobs: %w", err)
+    }
+    if result.SizeAfter, err = d.db.backend.Size(d.db.DB); err != nil {
+        return result, err
+    }
+    return result, nil
+}
+
+// convertConfigs convertit un lot d'entrées aux configurations stockées dans la ligne
+func (d *Database) convertConfigs() (int64, error) {
+    type row struct {
+        id                            int64
+        config, hostConfig, netConfig []byte
+    }
+
+    rows, err := d.db.Query(`
+        SELECT id, config, host_config, network_config FROM container_snapshots
+        WHERE config IS NOT NULL OR host_config IS NOT NULL OR network_config IS NOT NULL
+        ORDER BY id LIMIT ?`, vacuumBatchSize)
+    if err != nil {
+        return 0, fmt.Errorf("failed to query configurations: %w", err)
+    }
+    var batch []row
+    for rows.Next() {
+        var r row
+        if err := rows.Scan(&r.id, &r.config, &r.hostConfig, &r.netConfig); err != nil {
+            rows.Close()
+            return 0, fmt.Errorf("failed to scan configurations: %w", err)
+        }
+        batch = append(batch, r)
+    }
+    rows.Close()
+    if err := rows.Err(); err != nil {
+        return 0, fmt.Errorf("failed to iterate configurations: %w", err)
+    }
+
+    tx, err := d.db.Begin()
+    if err != nil {
+        return 0, fmt.Errorf("failed to begin transaction: %w", err)
+    }
+    defer tx.Rollback() //nolint:errcheck
+
+    for _, r := range batch {
+        hashes, err := saveConfigs(tx, r.config, r.hostConfig, r.netConfig)
+        if err != nil {
+            return 0, err
+        }
+        // Conserver une référence déjà présente (entrée partiellement convertie)
+        if _, err := tx.Exec(`
+            UPDATE container_snapshots SET
+                config_hash = COALESCE(?, config_hash),
+                host_config_hash = COALESCE(?, host_config_hash),
+                network_config_hash = COALESCE(?, network_config_hash),
+                config = NULL, host_config = NULL, network_config = NULL
+            WHERE id = ?`, append(hashes, r.id)...); err != nil {
+            return 0, fmt.Errorf("failed to convert configurations of snapshot %d: %w", r.id, err)
+        }
+    }
+    if err := tx.Commit(); err != nil {
+        return 0, fmt.Errorf("failed to commit configuration conversion: %w", err)
+    }
+    return int64(len(batch)), nil
+}
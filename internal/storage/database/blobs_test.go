@@ -0,0 +1,167 @@
+// internal/storage/database/blobs_test.go
+package database
+
+import (
+    "bytes"
+    "strings"
+    "testing"
+    "time"
+)
+
+func countRows(t *testing.T, db *Database, query string, args ...interface{}) int {
+    t.Helper()
+    var n int
+    if err := db.db.QueryRow(query, args...).Scan(&n); err != nil {
+        t.Fatal(err)
+    }
+    return n
+}
+
+func TestBlobDeduplication(t *testing.T) {
+    forEachBackend(t, testBlobDeduplication)
+}
+
+func testBlobDeduplication(t *testing.T, db *Database) {
+    t1 := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+
+    // Trois entrées de même configuration ; host_config et network_config valent "{}"
+    for i := 0; i < 3; i++ {
+        if _, _, err := db.MergeSnapshot(testSnapshot("app", t1.Add(time.Duration(i)*time.Hour))); err != nil {
+            t.Fatal(err)
+        }
+    }
+    if n := countRows(t, db, `SELECT COUNT(*) FROM blobs`); n != 2 {
+        t.Errorf("blobs = %d, want 2 (config and {})", n)
+    }
+    if n := countRows(t, db, `SELECT COUNT(*) FROM container_snapshots WHERE config IS NOT NULL`); n != 0 {
+        t.Errorf("%d entries still store their configuration inline", n)
+    }
+
+    // Contenu vide : aucun blob
+    if hash, err := putBlob(db.db, nil); err != nil || hash != "" {
+        t.Errorf("putBlob(empty) = %q, %v", hash, err)
+    }
+
+    data := []byte(strings.Repeat(`{"Env":["A=1"]}`, 100))
+    hash, err := putBlob(db.db, data)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if again, err := putBlob(db.db, data); err != nil || again != hash {
+        t.Errorf("putBlob(same content) = %q, %v, want %q", again, err, hash)
+    }
+    got, err := db.getBlob(hash)
+    if err != nil || !bytes.Equal(got, data) {
+        t.Errorf("getBlob = %d bytes, %v", len(got), err)
+    }
+    var size, stored int
+    if err := db.db.QueryRow(`SELECT size, LENGTH(data) FROM blobs WHERE hash = ?`, hash).
+        Scan(&size, &stored); err != nil {
+        t.Fatal(err)
+    }
+    if size != len(data) || stored >= size {
+        t.Errorf("blob size = %d, stored %d, want %d compressed", size, stored, len(data))
+    }
+}
+
+func TestBlobCorruption(t *testing.T) {
+    forEachBackend(t, testBlobCorruption)
+}
+
+func testBlobCorruption(t *testing.T, db *Database) {
+    hash, err := putBlob(db.db, []byte(`{"Image":"nginx"}`))
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    tests := []struct {
+        name   string
+        update string
+        args   []interface{}
+        want   string
+    }{
+        {"other content", `UPDATE blobs SET data = ? WHERE hash = ?`,
+            []interface{}{zstdEncoder.EncodeAll([]byte(`{"Image":"httpd"}`), nil), hash}, "corrupted"},
+        {"invalid data", `UPDATE blobs SET data = ? WHERE hash = ?`,
+            []interface{}{[]byte("not zstd"), hash}, "decompress"},
+        {"unknown codec", `UPDATE blobs SET codec = 'lz4' WHERE hash = ?`,
+            []interface{}{hash}, "unknown codec"},
+        {"missing", `DELETE FROM blobs WHERE hash = ?`,
+            []interface{}{hash}, "not found"},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            if _, err := db.db.Exec(tt.update, tt.args...); err != nil {
+                t.Fatal(err)
+            }
+            if _, err := db.getBlob(hash); err == nil || !strings.Contains(err.Error(), tt.want) {
+                t.Errorf("getBlob error = %v, want %q", err, tt.want)
+            }
+        })
+    }
+}
+
+func TestVacuum(t *testing.T) {
+    forEachBackend(t, testVacuum)
+}
+
+func testVacuum(t *testing.T, db *Database) {
+    t1 := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+
+    var ids []int64
+    for i, name := range []string{"app", "app", "db"} {
+        id, _, err := db.MergeSnapshot(testSnapshot(name, t1.Add(time.Duration(i)*time.Hour)))
+        if err != nil {
+            t.Fatal(err)
+        }
+        ids = append(ids, id)
+    }
+
+    // Entrées antérieures aux blobs : configurations dans la ligne
+    if _, err := db.db.Exec(`UPDATE container_snapshots SET
+        config = '{"Image":"app:0.9"}', host_config = '{"Privileged":false}', network_config = NULL,
+        config_hash = NULL, host_config_hash = NULL, network_config_hash = NULL
+        WHERE id IN (?, ?)`, ids[0], ids[1]); err != nil {
+        t.Fatal(err)
+    }
+    // Blob qui n'est plus référencé
+    if _, err := putBlob(db.db, []byte("orphan")); err != nil {
+        t.Fatal(err)
+    }
+
+    result, err := db.Vacuum()
+    if err != nil {
+        t.Fatal(err)
+    }
+    // {"Image":"app:1.0"} et "orphan" ne sont plus référencés
+    if result.Converted != 2 || result.OrphanBlobs != 2 {
+        t.Errorf("vacuum = %d converted, %d orphan blobs, want 2 and 2", result.Converted, result.OrphanBlobs)
+    }
+    // {}, {"Image":"db:1.0"} et les deux configurations converties
+    if result.Blobs != 4 || result.SizeAfter <= 0 {
+        t.Errorf("vacuum = %d blobs, size %d, want 4", result.Blobs, result.SizeAfter)
+    }
+
+    if n := countRows(t, db, `SELECT COUNT(*) FROM container_snapshots
+        WHERE config IS NOT NULL OR host_config IS NOT NULL OR network_config IS NOT NULL`); n != 0 {
+        t.Errorf("%d entries not converted", n)
+    }
+    for _, id := range ids[:2] {
+        s, err := db.GetSnapshot("local", "app", id)
+        if err != nil {
+            t.Fatal(err)
+        }
+        if string(s.Config) != `{"Image":"app:0.9"}` || string(s.HostConfig) != `{"Privileged":false}` ||
+            s.NetworkConfig != nil {
+            t.Errorf("entry %d = %s, %s, %s", id, s.Config, s.HostConfig, s.NetworkConfig)
+        }
+    }
+    if s, err := db.GetSnapshot("local", "db", ids[2]); err != nil || string(s.Config) != `{"Image":"db:1.0"}` {
+        t.Errorf("converted entry lost its blob: %v", err)
+    }
+
+    // Rien à convertir au second passage
+    if result, err := db.Vacuum(); err != nil || result.Converted != 0 || result.OrphanBlobs != 0 {
+        t.Errorf("second vacuum = %+v, %v", result, err)
+    }
+}
@@ -3,14 +3,15 @@ package database
 
 import (
     "database/sql"
+    "encoding/json"
     "fmt"
-    "path/filepath"
-    "os"
     "strings"
     "time"
-    _ "github.com/mattn/go-sqlite3"
+
     "github.com/sirupsen/logrus"
 
+    "zockimate/internal/hosts"
+    "zockimate/internal/storage/archive"
     "zockimate/internal/storage/zfs"
     "zockimate/internal/types"
     "zockimate/internal/types/options"
@@ -19,32 +20,39 @@ import (
 
 // Database gère les opérations de base de données
 type Database struct {
-    db     *sql.DB
-    zfs    *zfs.ZFSManager
+    db     *sqlDB
+    zfs    map[string]*zfs.ZFSManager // Gestionnaires ZFS par hôte
+    archives *archive.Store           // Archives des volumes sauvegardés
     logger *logrus.Logger
 }
 
-// NewDatabase initialise une nouvelle instance de base de données
-func NewDatabase(dbPath string, zfsManager *zfs.ZFSManager, logger *logrus.Logger) (*Database, error) {
-    // Créer le répertoire si nécessaire
-    if err := os.MkdirAll(filepath.Dir(dbPath), 0755); err != nil {
-        return nil, fmt.Errorf("failed to create database directory: %w", err)
-    }
-
-    db, err := sql.Open("sqlite3", dbPath)
+// NewDatabase initialise une nouvelle instance de base de données. url est le chemin
+// d'une base SQLite (éventuellement sqlite://chemin) ou l'URL postgres:// d'une base
+// PostgreSQL partagée.
+func NewDatabase(url string, zfsManagers map[string]*zfs.ZFSManager, archives *archive.Store,
+    logger *logrus.Logger) (*Database, error) {
+    db, err := openDB(url)
     if err != nil {
-        return nil, fmt.Errorf("failed to open database: %w", err)
+        return nil, err
     }
 
-    // Créer le schéma
-    if err := initSchema(db); err != nil {
+    // Mettre le schéma à jour (sauvegarde préalable d'un fichier SQLite)
+    result, err := migrate(db)
+    if err != nil {
         db.Close()
         return nil, err
     }
+    if result.Backup != "" {
+        logger.Infof("Database schema migrated from version %d to %d (backup: %s)",
+            result.From, result.To, result.Backup)
+    } else if result.From > 0 && len(result.Applied) > 0 {
+        logger.Infof("Database schema migrated from version %d to %d", result.From, result.To)
+    }
 
     return &Database{
         db:     db,
-        zfs:    zfsManager,
+        zfs:    zfsManagers,
+        archives: archives,
         logger: logger,
     }, nil
 }
@@ -54,100 +62,290 @@ func (d *Database) Close() error {
     return d.db.Close()
 }
 
-// initSchema initialise le schéma de la base de données
-func initSchema(db *sql.DB) error {
-    _, err := db.Exec(`
-        CREATE TABLE IF NOT EXISTS container_snapshots (
-            id INTEGER PRIMARY KEY AUTOINCREMENT,
-            container_name TEXT NOT NULL,
-            image_id TEXT NOT NULL,
-            image_digest TEXT,
-            image_tag TEXT,
-            original_image TEXT NOT NULL,
-            config BLOB,
-            host_config BLOB,
-            network_config BLOB,
-            zfs_snapshot TEXT,
-            status TEXT,
-            message TEXT,
-            created_at TEXT DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),  -- Format ISO en UTC
-            UNIQUE(container_name, created_at)
-        );
-        CREATE INDEX IF NOT EXISTS idx_container_name ON container_snapshots(container_name);
-        CREATE INDEX IF NOT EXISTS idx_created_at ON container_snapshots(created_at);
-		CREATE INDEX IF NOT EXISTS idx_container_status ON container_snapshots(status);
-		CREATE INDEX IF NOT EXISTS idx_container_message ON container_snapshots(message);
-    `)
-    if err != nil {
-        return fmt.Errorf("failed to create schema: %w", err)
+// encodeZFSSnapshots sérialise un groupe de snapshots ZFS pour la colonne zfs_snapshot
+func encodeZFSSnapshots(snapshots []string) (string, error) {
+    if len(snapshots) == 0 {
+        return "", nil
+    }
+    data, err := json.Marshal(snapshots)
+    if err != nil {
+        return "", fmt.Errorf("failed to marshal ZFS snapshots: %w", err)
+    }
+    return string(data), nil
+}
+
+// decodeZFSSnapshots lit la colonne zfs_snapshot : liste JSON, ou nom simple
+// (éventuellement séparé par des virgules) des entrées plus anciennes
+func decodeZFSSnapshots(value string) []string {
+    if strings.HasPrefix(value, "[") {
+        var snapshots []string
+        if err := json.Unmarshal([]byte(value), &snapshots); err == nil {
+            return snapshots
+        }
+    }
+    var snapshots []string
+    for _, s := range strings.Split(value, ",") {
+        if s = strings.TrimSpace(s); s != "" {
+            snapshots = append(snapshots, s)
+        }
+    }
+    return snapshots
+}
+
+// deleteZFSSnapshot supprime un groupe de snapshots ZFS sur l'hôte concerné
+func (d *Database) deleteZFSSnapshot(host string, snapshots []string) {
+    z, ok := d.zfs[host]
+    if !ok || z == nil {
+        d.logger.Warnf("Cannot delete ZFS snapshot %s: ZFS not available on host %s", strings.Join(snapshots, ", "), host)
+        return
+    }
+    if err := z.DeleteSnapshot(snapshots); err != nil {
+        d.logger.Warnf("Failed to delete ZFS snapshot: %v", err)
+    }
+    if err := z.DeleteReplicas(snapshots); err != nil {
+        d.logger.Warnf("Failed to delete replicated ZFS snapshot: %v", err)
+    }
+}
+
+// GetReplicated retourne le dernier snapshot répliqué d'un dataset (vide si aucun)
+func (d *Database) GetReplicated(host, dataset string) (string, error) {
+    var snapshot string
+    err := d.db.QueryRow("SELECT snapshot FROM zfs_replication WHERE host = ? AND dataset = ?",
+        host, dataset).Scan(&snapshot)
+    if err == sql.ErrNoRows {
+        return "", nil
+    }
+    if err != nil {
+        return "", fmt.Errorf("failed to query replication state: %w", err)
+    }
+    return snapshot, nil
+}
+
+// SetReplicated enregistre le dernier snapshot répliqué d'un dataset
+func (d *Database) SetReplicated(host, dataset, snapshot string) error {
+    _, err := d.db.Exec(`
+        INSERT INTO zfs_replication (host, dataset, snapshot, replicated_at)
+        VALUES (?, ?, ?, ?)
+        ON CONFLICT(host, dataset) DO UPDATE SET snapshot = excluded.snapshot, replicated_at = excluded.replicated_at`,
+        host, dataset, snapshot, time.Now().UTC().Format(time.RFC3339))
+    if err != nil {
+        return fmt.Errorf("failed to save replication state: %w", err)
     }
     return nil
 }
 
-// SaveSnapshot sauvegarde un snapshot dans la base de données
+// SaveSnapshot sauvegarde un snapshot (et ses montages sauvegardés) dans la base de données
 func (d *Database) SaveSnapshot(snapshot *types.ContainerSnapshot) error {
-    result, err := d.db.Exec(`
-        INSERT INTO container_snapshots (
-            container_name, image_id, image_digest, image_tag, original_image,
-            config, host_config, network_config, zfs_snapshot, status, message, created_at
-        ) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+    tx, err := d.db.Begin()
+    if err != nil {
+        return fmt.Errorf("failed to begin transaction: %w", err)
+    }
+    defer tx.Rollback() //nolint:errcheck
+
+    id, err := insertSnapshot(tx, snapshot, 0, time.Now().UTC())
+    if err != nil {
+        return err
+    }
+    if err := tx.Commit(); err != nil {
+        return fmt.Errorf("failed to commit snapshot: %w", err)
+    }
+    snapshot.ID = id
+
+    d.logger.Debugf("Saved snapshot %d for container %s on host %s", id, snapshot.ContainerName, snapshot.Host)
+    return nil
+}
+
+// MergeSnapshot ajoute une entrée d'une autre base sous un nouvel identifiant, en
+// conservant sa date. Une entrée de même conteneur et date est considérée comme
+// déjà présente : son identifiant est retourné avec false.
+func (d *Database) MergeSnapshot(snapshot *types.ContainerSnapshot) (int64, bool, error) {
+    tx, err := d.db.Begin()
+    if err != nil {
+        return 0, false, fmt.Errorf("failed to begin transaction: %w", err)
+    }
+    defer tx.Rollback() //nolint:errcheck
+
+    id, err := findSnapshot(tx, snapshot.Host, snapshot.ContainerName, snapshot.CreatedAt)
+    if err != nil || id > 0 {
+        return id, false, err
+    }
+    if id, err = insertSnapshot(tx, snapshot, 0, snapshot.CreatedAt); err != nil {
+        return 0, false, err
+    }
+    if err := tx.Commit(); err != nil {
+        return 0, false, fmt.Errorf("failed to commit snapshot: %w", err)
+    }
+    return id, true, nil
+}
+
+// FindSnapshot retourne l'identifiant de l'entrée d'un conteneur à une date (0 si aucune)
+func (d *Database) FindSnapshot(host, containerName string, createdAt time.Time) (int64, error) {
+    return findSnapshot(d.db, host, containerName, createdAt)
+}
+
+// querier est implémenté par *sqlDB et *sqlTx
+type querier interface {
+    QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+func findSnapshot(q querier, host, containerName string, createdAt time.Time) (int64, error) {
+    var id int64
+    err := q.QueryRow(`SELECT id FROM container_snapshots
+        WHERE host = ? AND container_name = ? AND created_at = ?`,
+        host, containerName, createdAt.UTC().Format(time.RFC3339)).Scan(&id)
+    if err == sql.ErrNoRows {
+        return 0, nil
+    }
+    if err != nil {
+        return 0, fmt.Errorf("failed to query snapshot: %w", err)
+    }
+    return id, nil
+}
+
+// insertSnapshot insère une entrée, son image, ses configurations et ses montages
+// sauvegardés, et retourne son identifiant (id, ou attribué par la base si 0)
+func insertSnapshot(tx *sqlTx, snapshot *types.ContainerSnapshot, id int64, createdAt time.Time) (int64, error) {
+    zfsSnapshots, err := encodeZFSSnapshots(snapshot.ZFSSnapshots)
+    if err != nil {
+        return 0, err
+    }
+    if err := saveImage(tx, &snapshot.ImageRef); err != nil {
+        return 0, err
+    }
+    configs, err := saveConfigs(tx, snapshot.Config, snapshot.HostConfig, snapshot.NetworkConfig)
+    if err != nil {
+        return 0, err
+    }
+
+    columns := `host, container_name, image_id, original_image,
+            config_hash, host_config_hash, network_config_hash, zfs_snapshot, snapshot_mode, freeze_ms,
+            message, created_at`
+    values := "?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?"
+    var args []interface{}
+    if id > 0 {
+        columns, values = "id, "+columns, "?, "+values
+        args = append(args, id)
+    }
+    args = append(args,
+        snapshot.Host,
         snapshot.ContainerName,
         snapshot.ImageRef.ID,
-        snapshot.ImageRef.RepoDigest,
-        snapshot.ImageRef.Tag,
         snapshot.ImageRef.Original,
-        snapshot.Config,
-        snapshot.HostConfig,
-        snapshot.NetworkConfig,
-        snapshot.ZFSSnapshot,
-        snapshot.Status,
+        configs[0],
+        configs[1],
+        configs[2],
+        zfsSnapshots,
+        snapshot.SnapshotMode,
+        snapshot.FreezeDuration.Milliseconds(),
         snapshot.Message,
-        time.Now().UTC().Format(time.RFC3339),
+        createdAt.UTC().Format(time.RFC3339),
     )
+    err = tx.QueryRow(`INSERT INTO container_snapshots (`+columns+`) VALUES (`+values+`) RETURNING id`,
+        args...).Scan(&id)
     if err != nil {
-        return fmt.Errorf("failed to save snapshot: %w", err)
+        return 0, fmt.Errorf("failed to save snapshot: %w", err)
     }
 
-    id, err := result.LastInsertId()
+    for _, m := range snapshot.Mounts {
+        if _, err := tx.Exec(`
+            INSERT INTO snapshot_mounts (snapshot_id, type, source, destination, archive)
+            VALUES (?, ?, ?, ?, ?)`,
+            id, m.Type, m.Source, m.Destination, m.Archive,
+        ); err != nil {
+            return 0, fmt.Errorf("failed to save mount backup %s: %w", m.Destination, err)
+        }
+    }
+    return id, nil
+}
+
+// ImportSnapshot insère une entrée reconstruite en conservant sa date, et son
+// identifiant s'il est libre. Une entrée de même conteneur et date est considérée
+// comme déjà présente : son identifiant est retourné avec false. Si l'identifiant
+// est pris par une autre entrée, un nouvel identifiant est attribué et retourné.
+func (d *Database) ImportSnapshot(snapshot *types.ContainerSnapshot) (int64, bool, error) {
+    tx, err := d.db.Begin()
     if err != nil {
-        return fmt.Errorf("failed to get last insert ID: %w", err)
+        return 0, false, fmt.Errorf("failed to begin transaction: %w", err)
     }
-    snapshot.ID = id
+    defer tx.Rollback() //nolint:errcheck
 
-    d.logger.Debugf("Saved snapshot %d for container %s", id, snapshot.ContainerName)
-    return nil
+    id, err := findSnapshot(tx, snapshot.Host, snapshot.ContainerName, snapshot.CreatedAt)
+    if err != nil || id > 0 {
+        return id, false, err
+    }
+
+    var taken int
+    err = tx.QueryRow(`SELECT COUNT(*) FROM container_snapshots WHERE id = ?`, snapshot.ID).Scan(&taken)
+    if err != nil {
+        return 0, false, fmt.Errorf("failed to query snapshot %d: %w", snapshot.ID, err)
+    }
+    fixedID := snapshot.ID
+    if taken > 0 {
+        fixedID = 0
+    }
+
+    if id, err = insertSnapshot(tx, snapshot, fixedID, snapshot.CreatedAt); err != nil {
+        return 0, false, fmt.Errorf("failed to import snapshot %d: %w", snapshot.ID, err)
+    }
+    // Un identifiant imposé ne fait pas avancer la séquence PostgreSQL
+    if fixedID > 0 {
+        if err := d.db.backend.ResetSequence(tx.Tx, "container_snapshots"); err != nil {
+            return 0, false, err
+        }
+    }
+    if err := tx.Commit(); err != nil {
+        return 0, false, fmt.Errorf("failed to commit snapshot %d: %w", snapshot.ID, err)
+    }
+    return id, true, nil
 }
 
 // GetSnapshot récupère un snapshot spécifique
-func (d *Database) GetSnapshot(containerName string, id int64) (*types.ContainerSnapshot, error) {
+func (d *Database) GetSnapshot(host, containerName string, id int64) (*types.ContainerSnapshot, error) {
     var query string
     var args []interface{}
 
+    columns := `s.id, s.host, s.container_name, s.image_id, i.repo_digest, i.tag, i.platform,
+        s.original_image, s.config, s.host_config, s.network_config,
+        s.config_hash, s.host_config_hash, s.network_config_hash, s.zfs_snapshot,
+        s.snapshot_mode, s.freeze_ms, s.message, s.created_at
+        FROM container_snapshots s
+        LEFT JOIN images i ON i.id = s.image_id`
     if id > 0 {
-        query = `SELECT * FROM container_snapshots WHERE container_name = ? AND id = ?`
-        args = []interface{}{containerName, id}
+        query = `SELECT ` + columns + `
+                 WHERE s.host = ? AND s.container_name = ? AND s.id = ?`
+        args = []interface{}{host, containerName, id}
     } else {
-        query = `SELECT * FROM container_snapshots WHERE container_name = ? 
-                 ORDER BY created_at DESC LIMIT 1`
-        args = []interface{}{containerName}
+        query = `SELECT ` + columns + `
+                 WHERE s.host = ? AND s.container_name = ?
+                 ORDER BY s.created_at DESC LIMIT 1`
+        args = []interface{}{host, containerName}
     }
 
     var snapshot types.ContainerSnapshot
     var imageRef types.ImageReference
     var createdAt string
+    var zfsSnapshots, snapshotMode, repoDigest, tag, platform sql.NullString
+    var configHash, hostConfigHash, networkConfigHash sql.NullString
+    var freezeMs sql.NullInt64
 
     err := d.db.QueryRow(query, args...).Scan(
         &snapshot.ID,
+        &snapshot.Host,
         &snapshot.ContainerName,
         &imageRef.ID,
-        &imageRef.RepoDigest,
-        &imageRef.Tag,
+        &repoDigest,
+        &tag,
+        &platform,
         &imageRef.Original,
         &snapshot.Config,
         &snapshot.HostConfig,
         &snapshot.NetworkConfig,
-        &snapshot.ZFSSnapshot,
-        &snapshot.Status,
+        &configHash,
+        &hostConfigHash,
+        &networkConfigHash,
+        &zfsSnapshots,
+        &snapshotMode,
+        &freezeMs,
         &snapshot.Message,
         &createdAt,
     )
@@ -159,51 +357,195 @@ func (d *Database) GetSnapshot(containerName string, id int64) (*types.Container
         return nil, fmt.Errorf("failed to query snapshot: %w", err)
     }
 
+    imageRef.RepoDigest = repoDigest.String
+    imageRef.Tag = tag.String
+    imageRef.Platform = platform.String
     snapshot.ImageRef = imageRef
 
-    // Ajouter après le Scan :
+    // Configurations stockées dans les blobs (ou dans l'entrée avant conversion)
+    for _, c := range []struct {
+        value *[]byte
+        hash  sql.NullString
+    }{
+        {&snapshot.Config, configHash},
+        {&snapshot.HostConfig, hostConfigHash},
+        {&snapshot.NetworkConfig, networkConfigHash},
+    } {
+        if *c.value, err = d.loadConfig(*c.value, c.hash); err != nil {
+            return nil, err
+        }
+    }
+    snapshot.ZFSSnapshots = decodeZFSSnapshots(zfsSnapshots.String)
+    snapshot.SnapshotMode = snapshotMode.String
+    snapshot.FreezeDuration = time.Duration(freezeMs.Int64) * time.Millisecond
+
     snapshot.CreatedAt, err = utils.ParseTime(createdAt)
     if err != nil {
         return nil, fmt.Errorf("failed to parse created_at: %w", err)
     }
 
+    snapshot.Mounts, err = d.getMountBackups(snapshot.ID)
+    if err != nil {
+        return nil, err
+    }
+
     return &snapshot, nil
 }
 
+// getMountBackups récupère les montages sauvegardés d'un snapshot
+func (d *Database) getMountBackups(snapshotID int64) ([]types.MountBackup, error) {
+    rows, err := d.db.Query(`
+        SELECT type, source, destination, archive FROM snapshot_mounts
+        WHERE snapshot_id = ? ORDER BY destination`, snapshotID)
+    if err != nil {
+        return nil, fmt.Errorf("failed to query mount backups: %w", err)
+    }
+    defer rows.Close()
+
+    var mounts []types.MountBackup
+    for rows.Next() {
+        var m types.MountBackup
+        if err := rows.Scan(&m.Type, &m.Source, &m.Destination, &m.Archive); err != nil {
+            return nil, fmt.Errorf("failed to scan mount backup: %w", err)
+        }
+        mounts = append(mounts, m)
+    }
+    if err := rows.Err(); err != nil {
+        return nil, fmt.Errorf("failed to iterate mount backups: %w", err)
+    }
+    return mounts, nil
+}
+
+// deleteOrphanMounts supprime les montages sauvegardés (et leurs archives)
+// dont le snapshot n'existe plus
+func (d *Database) deleteOrphanMounts() {
+    rows, err := d.db.Query(`SELECT archive FROM snapshot_mounts
+        WHERE snapshot_id NOT IN (SELECT id FROM container_snapshots)`)
+    if err != nil {
+        d.logger.Warnf("Failed to query orphan mount backups: %v", err)
+        return
+    }
+    var archives []string
+    for rows.Next() {
+        var path string
+        if err := rows.Scan(&path); err != nil {
+            d.logger.Warnf("Failed to scan orphan mount backup: %v", err)
+            continue
+        }
+        archives = append(archives, path)
+    }
+    rows.Close()
+
+    if _, err := d.db.Exec(`DELETE FROM snapshot_mounts
+        WHERE snapshot_id NOT IN (SELECT id FROM container_snapshots)`); err != nil {
+        d.logger.Warnf("Failed to delete orphan mount backups: %v", err)
+        return
+    }
+
+    // Supprimer les archives après succès de la suppression DB
+    for _, path := range archives {
+        if err := d.archives.Delete(path); err != nil {
+            d.logger.Warnf("Failed to delete archive: %v", err)
+        }
+    }
+}
+
+// SaveVulnDelta associe le delta de vulnérabilités d'une mise à jour à son snapshot
+func (d *Database) SaveVulnDelta(snapshotID int64, delta *types.VulnDelta) error {
+    introduced, err := json.Marshal(delta.Introduced)
+    if err != nil {
+        return fmt.Errorf("failed to marshal introduced vulnerabilities: %w", err)
+    }
+    fixed, err := json.Marshal(delta.Fixed)
+    if err != nil {
+        return fmt.Errorf("failed to marshal fixed vulnerabilities: %w", err)
+    }
+
+    _, err = d.db.Exec(`
+        INSERT INTO snapshot_vulnerabilities (snapshot_id, introduced, fixed, scanned_at)
+        VALUES (?, ?, ?, ?)
+        ON CONFLICT(snapshot_id) DO UPDATE SET
+            introduced = excluded.introduced, fixed = excluded.fixed, scanned_at = excluded.scanned_at`,
+        snapshotID, string(introduced), string(fixed), time.Now().UTC().Format(time.RFC3339),
+    )
+    if err != nil {
+        return fmt.Errorf("failed to save vulnerability delta: %w", err)
+    }
+    return nil
+}
+
+// deleteOrphanVulnDeltas supprime les deltas dont le snapshot n'existe plus
+func (d *Database) deleteOrphanVulnDeltas() {
+    if _, err := d.db.Exec(`DELETE FROM snapshot_vulnerabilities
+        WHERE snapshot_id NOT IN (SELECT id FROM container_snapshots)`); err != nil {
+        d.logger.Warnf("Failed to delete orphan vulnerability deltas: %v", err)
+    }
+}
+
+// RecordImageSeen enregistre la première détection d'un digest pour une image
+// et retourne la date de cette première détection
+func (d *Database) RecordImageSeen(image, digest string) (time.Time, error) {
+    _, err := d.db.Exec(`
+        INSERT INTO image_first_seen (image, digest, first_seen)
+        VALUES (?, ?, ?)
+        ON CONFLICT DO NOTHING`,
+        image, digest, time.Now().UTC().Format(time.RFC3339),
+    )
+    if err != nil {
+        return time.Time{}, fmt.Errorf("failed to record image digest: %w", err)
+    }
+
+    var firstSeen string
+    err = d.db.QueryRow(`SELECT first_seen FROM image_first_seen WHERE image = ? AND digest = ?`,
+        image, digest).Scan(&firstSeen)
+    if err != nil {
+        return time.Time{}, fmt.Errorf("failed to query image first seen: %w", err)
+    }
+
+    return utils.ParseTime(firstSeen)
+}
+
 // GetHistory récupère l'historique des snapshots
 func (d *Database) GetHistory(opts options.HistoryOptions) ([]types.SnapshotMetadata, error) {
     var conditions []string
     var args []interface{}
     
-    query := `SELECT id, container_name, image_tag, image_id, 
-              image_digest, status, message, created_at 
-              FROM container_snapshots`
+    query := `SELECT s.id, s.host, s.container_name, i.tag, s.image_id,
+              i.repo_digest, i.platform, s.message, s.created_at, s.snapshot_mode, s.freeze_ms,
+              v.introduced, v.fixed
+              FROM container_snapshots s
+              LEFT JOIN images i ON i.id = s.image_id
+              LEFT JOIN snapshot_vulnerabilities v ON v.snapshot_id = s.id`
 
     // Appliquer les filtres
+    // Les conteneurs sont identifiés par "hôte/conteneur"
     if len(opts.Container) > 0 {
         placeholders := make([]string, len(opts.Container))
-        for i, name := range opts.Container {
-            placeholders[i] = "?"
-            args = append(args, name)
+        for i, id := range opts.Container {
+            host, name := utils.SplitContainerID(id)
+            if host == "" {
+                host = hosts.DefaultHost
+            }
+            placeholders[i] = "(s.host = ? AND s.container_name = ?)"
+            args = append(args, host, name)
         }
-        conditions = append(conditions, 
-            fmt.Sprintf("container_name IN (%s)", strings.Join(placeholders, ",")))
+        conditions = append(conditions,
+            "("+strings.Join(placeholders, " OR ")+")")
     }
 
     if !opts.Since.IsZero() {
-        conditions = append(conditions, "created_at >= ?")
+        conditions = append(conditions, "s.created_at >= ?")
         args = append(args, opts.Since.Format("2006-01-02 15:04:05"))
     }
 
     if !opts.Before.IsZero() {
-        conditions = append(conditions, "created_at <= ?")
+        conditions = append(conditions, "s.created_at <= ?")
         args = append(args, opts.Before.Format("2006-01-02 15:04:05"))
     }
 
     if opts.Search != "" {
-        conditions = append(conditions, "(message LIKE ? OR status LIKE ?)")
-        searchTerm := "%" + opts.Search + "%"
-        args = append(args, searchTerm, searchTerm)
+        conditions = append(conditions, "LOWER(s.message) LIKE LOWER(?)")
+        args = append(args, "%"+opts.Search+"%")
     }
 
     if len(conditions) > 0 {
@@ -213,9 +555,9 @@ func (d *Database) GetHistory(opts options.HistoryOptions) ([]types.SnapshotMeta
     // Tri
     query += " ORDER BY " + func() string {
         if opts.SortBy == "container" {
-            return "container_name, created_at DESC"
+            return "s.host, s.container_name, s.created_at DESC"
         }
-        return "created_at DESC"
+        return "s.created_at DESC"
     }()
 
     rows, err := d.db.Query(query, args...)
@@ -228,26 +570,52 @@ func (d *Database) GetHistory(opts options.HistoryOptions) ([]types.SnapshotMeta
     for rows.Next() {
         var entry types.SnapshotMetadata
         var createdAt string
+        var introduced, fixed, snapshotMode, tag, repoDigest, platform sql.NullString
+        var freezeMs sql.NullInt64
         
         err := rows.Scan(
             &entry.ID,
+            &entry.Host,
             &entry.ContainerName,
-            &entry.ImageTag,
+            &tag,
             &entry.ImageID,
-            &entry.RepoDigest,
-            &entry.Status,
+            &repoDigest,
+            &platform,
             &entry.Message,
             &createdAt,
+            &snapshotMode,
+            &freezeMs,
+            &introduced,
+            &fixed,
         )
         if err != nil {
             return nil, fmt.Errorf("failed to scan history entry: %w", err)
         }
 
+        if introduced.Valid || fixed.Valid {
+            entry.VulnDelta = &types.VulnDelta{}
+            if introduced.Valid {
+                if err := json.Unmarshal([]byte(introduced.String), &entry.VulnDelta.Introduced); err != nil {
+                    return nil, fmt.Errorf("failed to decode vulnerability delta: %w", err)
+                }
+            }
+            if fixed.Valid {
+                if err := json.Unmarshal([]byte(fixed.String), &entry.VulnDelta.Fixed); err != nil {
+                    return nil, fmt.Errorf("failed to decode vulnerability delta: %w", err)
+                }
+            }
+        }
+
         t, err := utils.ParseTime(createdAt)
         if err != nil {
             return nil, fmt.Errorf("failed to parse time createdAt: %w", err)
         }
         entry.CreatedAt = t
+        entry.ImageTag = tag.String
+        entry.RepoDigest = repoDigest.String
+        entry.Platform = platform.String
+        entry.SnapshotMode = snapshotMode.String
+        entry.FreezeDuration = time.Duration(freezeMs.Int64) * time.Millisecond
 
         entries = append(entries, entry)
 
@@ -258,9 +626,10 @@ func (d *Database) GetHistory(opts options.HistoryOptions) ([]types.SnapshotMeta
         seen := make(map[string]bool)
         var filtered []types.SnapshotMetadata
         for _, entry := range entries {
-            if !seen[entry.ContainerName] {
+            key := entry.Host + "/" + entry.ContainerName
+            if !seen[key] {
                 filtered = append(filtered, entry)
-                seen[entry.ContainerName] = true
+                seen[key] = true
             }
         }
         entries = filtered
@@ -274,14 +643,13 @@ func (d *Database) GetHistory(opts options.HistoryOptions) ([]types.SnapshotMeta
 }
 
 // CleanupSnapshots nettoie les anciens snapshots
-func (d *Database) CleanupSnapshots(containerName string, retain int) error {
+func (d *Database) CleanupSnapshots(host, containerName string, retain int) error {
     rows, err := d.db.Query(`
         SELECT id, zfs_snapshot
         FROM container_snapshots
-        WHERE container_name = ?
-        ORDER BY created_at DESC
-        LIMIT -1 OFFSET ?`,
-        containerName, retain,
+        WHERE host = ? AND container_name = ?
+        ORDER BY created_at DESC`,
+        host, containerName,
     )
     if err != nil {
         return fmt.Errorf("failed to query old snapshots: %w", err)
@@ -289,21 +657,22 @@ func (d *Database) CleanupSnapshots(containerName string, retain int) error {
     defer rows.Close()
 
     type entry struct {
-        id          int64
-        zfsSnapshot string
+        id           int64
+        zfsSnapshots []string
     }
     var toDelete []entry
 
-    for rows.Next() {
+    // Conserver les retain entrées les plus récentes
+    for kept := 0; rows.Next(); kept++ {
         var id int64
         var zfsSnapshot sql.NullString
         if err := rows.Scan(&id, &zfsSnapshot); err != nil {
             return fmt.Errorf("failed to scan snapshot row: %w", err)
         }
-        e := entry{id: id}
-        if zfsSnapshot.Valid {
-            e.zfsSnapshot = zfsSnapshot.String
+        if kept < retain {
+            continue
         }
+        e := entry{id: id, zfsSnapshots: decodeZFSSnapshots(zfsSnapshot.String)}
         toDelete = append(toDelete, e)
     }
     if err := rows.Err(); err != nil {
@@ -327,23 +696,149 @@ func (d *Database) CleanupSnapshots(containerName string, retain int) error {
     if err := tx.Commit(); err != nil {
         return fmt.Errorf("failed to commit snapshot cleanup: %w", err)
     }
+    d.deleteOrphanVulnDeltas()
+    d.deleteOrphanMounts()
+    d.deleteOrphanImages()
+    if _, err := d.deleteOrphanBlobs(); err != nil {
+        d.logger.Warn(err)
+    }
 
     // Supprimer les snapshots ZFS après succès de la transaction DB
     for _, e := range toDelete {
-        if e.zfsSnapshot != "" {
-            if err := d.zfs.DeleteSnapshot(e.zfsSnapshot); err != nil {
-                d.logger.Warnf("Failed to delete ZFS snapshot %s: %v", e.zfsSnapshot, err)
-            }
+        if len(e.zfsSnapshots) > 0 {
+            d.deleteZFSSnapshot(host, e.zfsSnapshots)
         }
     }
 
     return nil
 }
 
-func (d *Database) RenameContainer(oldName, newName string) (int64, error) {
+// GetZFSGroups retourne les groupes de snapshots ZFS référencés par les entrées d'un hôte
+func (d *Database) GetZFSGroups(host string) ([]types.ZFSGroup, error) {
+    rows, err := d.db.Query(`
+        SELECT id, container_name, zfs_snapshot
+        FROM container_snapshots
+        WHERE host = ? AND zfs_snapshot IS NOT NULL AND zfs_snapshot != ''
+        ORDER BY id`, host)
+    if err != nil {
+        return nil, fmt.Errorf("failed to query ZFS snapshots: %w", err)
+    }
+    defer rows.Close()
+
+    var groups []types.ZFSGroup
+    for rows.Next() {
+        var g types.ZFSGroup
+        var value string
+        if err := rows.Scan(&g.SnapshotID, &g.ContainerName, &value); err != nil {
+            return nil, fmt.Errorf("failed to scan snapshot row: %w", err)
+        }
+        if g.Snapshots = decodeZFSSnapshots(value); len(g.Snapshots) > 0 {
+            groups = append(groups, g)
+        }
+    }
+    if err := rows.Err(); err != nil {
+        return nil, fmt.Errorf("failed to iterate snapshots: %w", err)
+    }
+    return groups, nil
+}
+
+// ClearZFSSnapshots retire le groupe de snapshots ZFS d'entrées dont les données sont
+// perdues ; elles restent utilisables pour restaurer l'image et la configuration
+func (d *Database) ClearZFSSnapshots(ids []int64) error {
+    tx, err := d.db.Begin()
+    if err != nil {
+        return fmt.Errorf("failed to begin transaction: %w", err)
+    }
+    defer tx.Rollback() //nolint:errcheck
+
+    for _, id := range ids {
+        if _, err := tx.Exec("UPDATE container_snapshots SET zfs_snapshot = '' WHERE id = ?", id); err != nil {
+            return fmt.Errorf("failed to update snapshot %d: %w", id, err)
+        }
+    }
+    if err := tx.Commit(); err != nil {
+        return fmt.Errorf("failed to commit ZFS snapshot update: %w", err)
+    }
+    return nil
+}
+
+// ReconcileZFSSnapshots met à jour les groupes de snapshots ZFS d'un hôte après une
+// restauration : les snapshots déplacés sont renommés, et les groupes contenant un
+// snapshot détruit sont vidés (l'entrée reste, sans données restaurables).
+func (d *Database) ReconcileZFSSnapshots(host string, moved map[string]string, destroyed []string) (int64, error) {
+    if len(moved) == 0 && len(destroyed) == 0 {
+        return 0, nil
+    }
+    lost := make(map[string]bool, len(destroyed))
+    for _, s := range destroyed {
+        lost[s] = true
+    }
+
+    rows, err := d.db.Query(`
+        SELECT id, zfs_snapshot
+        FROM container_snapshots
+        WHERE host = ? AND zfs_snapshot IS NOT NULL AND zfs_snapshot != ''`, host)
+    if err != nil {
+        return 0, fmt.Errorf("failed to query ZFS snapshots: %w", err)
+    }
+    defer rows.Close()
+
+    updates := make(map[int64]string)
+    for rows.Next() {
+        var id int64
+        var value string
+        if err := rows.Scan(&id, &value); err != nil {
+            return 0, fmt.Errorf("failed to scan snapshot row: %w", err)
+        }
+
+        group := decodeZFSSnapshots(value)
+        changed := false
+        for i, s := range group {
+            if lost[s] {
+                d.logger.Warnf("Snapshot %d lost its ZFS data (%s was destroyed)", id, s)
+                group, changed = nil, true
+                break
+            }
+            if newName, ok := moved[s]; ok {
+                group[i], changed = newName, true
+            }
+        }
+        if !changed {
+            continue
+        }
+        encoded, err := encodeZFSSnapshots(group)
+        if err != nil {
+            return 0, err
+        }
+        updates[id] = encoded
+    }
+    if err := rows.Err(); err != nil {
+        return 0, fmt.Errorf("failed to iterate snapshots: %w", err)
+    }
+    rows.Close()
+
+    tx, err := d.db.Begin()
+    if err != nil {
+        return 0, fmt.Errorf("failed to begin transaction: %w", err)
+    }
+    defer tx.Rollback() //nolint:errcheck
+
+    for id, encoded := range updates {
+        if _, err := tx.Exec("UPDATE container_snapshots SET zfs_snapshot = ? WHERE id = ?", encoded, id); err != nil {
+            return 0, fmt.Errorf("failed to update snapshot %d: %w", id, err)
+        }
+    }
+    if err := tx.Commit(); err != nil {
+        return 0, fmt.Errorf("failed to commit ZFS snapshot reconciliation: %w", err)
+    }
+    return int64(len(updates)), nil
+}
+
+func (d *Database) RenameContainer(host, oldName, newName string) (int64, error) {
     // Check if new name exists
     var count int
-    err := d.db.QueryRow("SELECT COUNT(*) FROM container_snapshots WHERE container_name = ?", newName).Scan(&count)
+    err := d.db.QueryRow("SELECT COUNT(*) FROM container_snapshots WHERE host = ? AND container_name = ?",
+        host, newName).Scan(&count)
     if err != nil {
         return 0, fmt.Errorf("failed to check for existing name: %w", err)
     }
@@ -352,20 +847,35 @@ func (d *Database) RenameContainer(oldName, newName string) (int64, error) {
     }
 
     // Perform rename
-    result, err := d.db.Exec("UPDATE container_snapshots SET container_name = ? WHERE container_name = ?", 
-        newName, oldName)
+    tx, err := d.db.Begin()
+    if err != nil {
+        return 0, fmt.Errorf("failed to begin transaction: %w", err)
+    }
+    defer tx.Rollback() //nolint:errcheck
+
+    result, err := tx.Exec("UPDATE container_snapshots SET container_name = ? WHERE host = ? AND container_name = ?",
+        newName, host, oldName)
     if err != nil {
         return 0, fmt.Errorf("failed to update container name: %w", err)
     }
+    if _, err := tx.Exec("UPDATE operations SET container_name = ? WHERE host = ? AND container_name = ?",
+        newName, host, oldName); err != nil {
+        return 0, fmt.Errorf("failed to update container name of operations: %w", err)
+    }
+    if err := tx.Commit(); err != nil {
+        return 0, fmt.Errorf("failed to commit rename: %w", err)
+    }
 
     return result.RowsAffected()
 }
-func (d *Database) RemoveEntries(containerName string, opts options.RemoveOptions) (int64, error) {
+
+// RemoveEntries supprime les entrées d'un conteneur (et leurs opérations) selon les options
+func (d *Database) RemoveEntries(host, containerName string, opts options.RemoveOptions) (int64, error) {
     var conditions []string
     var args []interface{}
 
-    conditions = append(conditions, "container_name = ?")
-    args = append(args, containerName)
+    conditions = append(conditions, "host = ?", "container_name = ?")
+    args = append(args, host, containerName)
 
     if !opts.All {
         if !opts.Before.IsZero() {
@@ -379,9 +889,10 @@ func (d *Database) RemoveEntries(containerName string, opts options.RemoveOption
     }
 
     whereClause := strings.Join(conditions, " AND ")
+    opsWhereClause := strings.ReplaceAll(whereClause, "created_at", "started_at")
 
     // Si ZFS activé, récupérer les snapshots à supprimer avant de supprimer les entrées
-    var zfsSnapshots []string
+    var zfsSnapshots [][]string
     if opts.Zfs {
         rows, err := d.db.Query("SELECT zfs_snapshot FROM container_snapshots WHERE "+whereClause, args...)
         if err != nil {
@@ -390,12 +901,12 @@ func (d *Database) RemoveEntries(containerName string, opts options.RemoveOption
         defer rows.Close()
 
         for rows.Next() {
-            var snapshot string
+            var snapshot sql.NullString
             if err := rows.Scan(&snapshot); err != nil {
                 return 0, fmt.Errorf("failed to scan snapshot: %w", err)
             }
-            if snapshot != "" {
-                zfsSnapshots = append(zfsSnapshots, snapshot)
+            if group := decodeZFSSnapshots(snapshot.String); len(group) > 0 {
+                zfsSnapshots = append(zfsSnapshots, group)
             }
         }
         if err := rows.Err(); err != nil {
@@ -409,12 +920,19 @@ func (d *Database) RemoveEntries(containerName string, opts options.RemoveOption
     if err != nil {
         return 0, fmt.Errorf("failed to delete entries: %w", err)
     }
+    if _, err := d.db.Exec("DELETE FROM operations WHERE "+opsWhereClause, args...); err != nil {
+        d.logger.Warnf("Failed to delete operations: %v", err)
+    }
+    d.deleteOrphanVulnDeltas()
+    d.deleteOrphanMounts()
+    d.deleteOrphanImages()
+    if _, err := d.deleteOrphanBlobs(); err != nil {
+        d.logger.Warn(err)
+    }
 
     // Supprimer les snapshots ZFS après succès de la suppression DB
-    for _, snapshot := range zfsSnapshots {
-        if err := d.zfs.DeleteSnapshot(snapshot); err != nil {
-            d.logger.Warnf("Failed to delete ZFS snapshot %s: %v", snapshot, err)
-        }
+    for _, group := range zfsSnapshots {
+        d.deleteZFSSnapshot(host, group)
     }
 
     return result.RowsAffected()
@@ -0,0 +1,341 @@
+// internal/storage/database/database_test.go
+package database
+
+import (
+    "database/sql"
+    "fmt"
+    "io"
+    "os"
+    "path/filepath"
+    "reflect"
+    "regexp"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/sirupsen/logrus"
+
+    "zockimate/internal/types"
+)
+
+// URL d'une base PostgreSQL pour les tests (ex: postgres://postgres@localhost/test?sslmode=disable).
+// Sans elle, seuls les tests SQLite sont exécutés.
+const testPostgresEnv = "ZOCKIMATE_TEST_POSTGRES"
+
+// openTestDatabase crée une base SQLite vide dans un répertoire temporaire
+func openTestDatabase(t *testing.T) *Database {
+    t.Helper()
+    return openTestURL(t, filepath.Join(t.TempDir(), "zockimate.db"))
+}
+
+// openTestPostgres crée un schéma vide dans la base de ZOCKIMATE_TEST_POSTGRES,
+// supprimé à la fin du test
+func openTestPostgres(t *testing.T) *Database {
+    t.Helper()
+    url := os.Getenv(testPostgresEnv)
+    if url == "" {
+        t.Skipf("%s not set", testPostgresEnv)
+    }
+
+    admin, err := sql.Open("postgres", url)
+    if err != nil {
+        t.Fatal(err)
+    }
+    t.Cleanup(func() { admin.Close() })
+    schema := fmt.Sprintf("zockimate_test_%d", time.Now().UnixNano())
+    if _, err := admin.Exec(`CREATE SCHEMA ` + schema); err != nil {
+        t.Fatal(err)
+    }
+    t.Cleanup(func() {
+        if _, err := admin.Exec(`DROP SCHEMA ` + schema + ` CASCADE`); err != nil {
+            t.Errorf("failed to drop schema %s: %v", schema, err)
+        }
+    })
+
+    sep := "?"
+    if strings.Contains(url, "?") {
+        sep = "&"
+    }
+    return openTestURL(t, url+sep+"search_path="+schema)
+}
+
+func openTestURL(t *testing.T, url string) *Database {
+    t.Helper()
+    logger := logrus.New()
+    logger.SetOutput(io.Discard)
+
+    db, err := NewDatabase(url, nil, nil, logger)
+    if err != nil {
+        t.Fatal(err)
+    }
+    t.Cleanup(func() { db.Close() })
+    return db
+}
+
+// numberedParam est un paramètre PostgreSQL produit par rebindNumbered
+var numberedParam = regexp.MustCompile(`\$([0-9]+)`)
+
+// numberedBackend exécute sur SQLite les requêtes réécrites comme pour PostgreSQL :
+// $1, $2... deviennent ?1, ?2..., paramètres SQLite de même numéro. Vérifie sans
+// serveur la réécriture de chaque requête de la base.
+type numberedBackend struct {
+    *sqliteBackend
+}
+
+func (b numberedBackend) Rebind(query string) string {
+    return numberedParam.ReplaceAllString(rebindNumbered(query), "?$1")
+}
+
+// openTestNumbered crée une base SQLite vide interrogée avec des paramètres numérotés
+func openTestNumbered(t *testing.T) *Database {
+    t.Helper()
+    backend := numberedBackend{newSQLiteBackend(filepath.Join(t.TempDir(), "zockimate.db"))}
+    raw, err := backend.Open()
+    if err != nil {
+        t.Fatal(err)
+    }
+    db := &sqlDB{DB: raw, backend: backend}
+    t.Cleanup(func() { db.Close() })
+    if _, err := migrate(db); err != nil {
+        t.Fatal(err)
+    }
+    logger := logrus.New()
+    logger.SetOutput(io.Discard)
+    return &Database{db: db, logger: logger}
+}
+
+// forEachBackend exécute un test sur une base vide de chaque moteur ; "numbered"
+// remplace PostgreSQL quand ZOCKIMATE_TEST_POSTGRES n'est pas défini
+func forEachBackend(t *testing.T, test func(t *testing.T, db *Database)) {
+    t.Run(sqliteScheme, func(t *testing.T) {
+        test(t, openTestDatabase(t))
+    })
+    t.Run("numbered", func(t *testing.T) {
+        test(t, openTestNumbered(t))
+    })
+    t.Run(postgresScheme, func(t *testing.T) {
+        test(t, openTestPostgres(t))
+    })
+}
+
+// testSnapshot retourne une entrée minimale d'un conteneur
+func testSnapshot(name string, createdAt time.Time) *types.ContainerSnapshot {
+    return &types.ContainerSnapshot{
+        Host:          "local",
+        ContainerName: name,
+        ImageRef:      types.ImageReference{ID: "sha256:" + name, Tag: name + ":1.0"},
+        Config:        []byte(`{"Image":"` + name + `:1.0"}`),
+        HostConfig:    []byte(`{}`),
+        NetworkConfig: []byte(`{}`),
+        Message:       "test",
+        CreatedAt:     createdAt,
+    }
+}
+
+func TestImportSnapshot(t *testing.T) {
+    forEachBackend(t, testImportSnapshot)
+}
+
+func testImportSnapshot(t *testing.T, db *Database) {
+    t1 := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+
+    current := testSnapshot("app", t1)
+    if id, _, err := db.MergeSnapshot(current); err != nil {
+        t.Fatal(err)
+    } else {
+        current.ID = id
+    }
+
+    // Même conteneur et date : déjà présente, quel que soit l'identifiant
+    same := testSnapshot("app", current.CreatedAt)
+    same.ID = 42
+    id, imported, err := db.ImportSnapshot(same)
+    if err != nil || imported || id != current.ID {
+        t.Fatalf("ImportSnapshot(same entry) = %d, %v, %v, want %d, false", id, imported, err, current.ID)
+    }
+
+    // Identifiant libre : conservé, avec la durée de gel
+    free := testSnapshot("db", t1.Add(time.Hour))
+    free.ID = 10
+    free.SnapshotMode = "pause"
+    free.FreezeDuration = 1500 * time.Millisecond
+    id, imported, err = db.ImportSnapshot(free)
+    if err != nil || !imported || id != 10 {
+        t.Fatalf("ImportSnapshot(free ID) = %d, %v, %v, want 10, true", id, imported, err)
+    }
+    got, err := db.GetSnapshot("local", "db", 10)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if got.FreezeDuration != free.FreezeDuration || got.SnapshotMode != "pause" || !got.CreatedAt.Equal(free.CreatedAt) {
+        t.Errorf("imported entry = mode %q, freeze %s, date %s", got.SnapshotMode, got.FreezeDuration, got.CreatedAt)
+    }
+
+    // Identifiant pris par une autre entrée : nouvel identifiant, pas "déjà présente"
+    clash := testSnapshot("web", t1.Add(2*time.Hour))
+    clash.ID = current.ID
+    id, imported, err = db.ImportSnapshot(clash)
+    if err != nil || !imported {
+        t.Fatalf("ImportSnapshot(ID clash) = %d, %v, %v, want a new entry", id, imported, err)
+    }
+    if id == current.ID || id == 10 {
+        t.Errorf("ID clash imported as %d, want a new ID", id)
+    }
+    if _, err := db.GetSnapshot("local", "web", id); err != nil {
+        t.Errorf("renumbered entry not found: %v", err)
+    }
+    if got, err := db.GetSnapshot("local", "app", current.ID); err != nil || got.ContainerName != "app" {
+        t.Errorf("existing entry %d overwritten: %v", current.ID, err)
+    }
+
+    // Les identifiants suivants ne reprennent pas ceux importés
+    next := testSnapshot("app", t1.Add(3*time.Hour))
+    if err := db.SaveSnapshot(next); err != nil {
+        t.Fatal(err)
+    }
+    if next.ID <= id || next.ID <= 10 {
+        t.Errorf("next entry ID = %d, want more than %d and 10", next.ID, id)
+    }
+}
+
+func TestRecordImageSeen(t *testing.T) {
+    forEachBackend(t, testRecordImageSeen)
+}
+
+func testRecordImageSeen(t *testing.T, db *Database) {
+    const digest = "nginx@sha256:2222222222222222222222222222222222222222222222222222222222222222"
+
+    before := time.Now().Add(-time.Second)
+    first, err := db.RecordImageSeen("nginx:1.27", digest)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if first.Before(before) || first.After(time.Now()) {
+        t.Errorf("first seen = %s, want now", first)
+    }
+
+    // Détection ancienne : la date de première détection est conservée
+    old := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+    if _, err := db.db.Exec(`UPDATE image_first_seen SET first_seen = ? WHERE digest = ?`,
+        old.Format(time.RFC3339), digest); err != nil {
+        t.Fatal(err)
+    }
+    if got, err := db.RecordImageSeen("nginx:1.27", digest); err != nil || !got.Equal(old) {
+        t.Errorf("second detection = %s, %v, want %s", got, err, old)
+    }
+
+    // Autre digest, ou même digest sous une autre référence : nouvelle détection
+    for _, seen := range [][2]string{
+        {"nginx:1.27", "nginx@sha256:3333333333333333333333333333333333333333333333333333333333333333"},
+        {"nginx:latest", digest},
+    } {
+        if got, err := db.RecordImageSeen(seen[0], seen[1]); err != nil || got.Before(before) {
+            t.Errorf("RecordImageSeen(%s, %s) = %s, %v, want now", seen[0], seen[1], got, err)
+        }
+    }
+    if n := countRows(t, db, `SELECT COUNT(*) FROM image_first_seen`); n != 3 {
+        t.Errorf("%d rows, want 3", n)
+    }
+}
+
+func TestReconcileZFSSnapshots(t *testing.T) {
+    forEachBackend(t, testReconcileZFSSnapshots)
+}
+
+func testReconcileZFSSnapshots(t *testing.T, db *Database) {
+    t1 := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+    save := func(host string, createdAt time.Time, zfsSnapshots ...string) int64 {
+        s := testSnapshot("app", createdAt)
+        s.Host = host
+        s.ZFSSnapshots = zfsSnapshots
+        id, _, err := db.MergeSnapshot(s)
+        if err != nil {
+            t.Fatal(err)
+        }
+        return id
+    }
+    grouped := save("local", t1, "tank/app@s1", "tank/db@s1")
+    destroyed := save("local", t1.Add(time.Hour), "tank/app@s2", "tank/db@s2")
+    moved := save("local", t1.Add(2*time.Hour), "tank/app@s3")
+    untouched := save("local", t1.Add(3*time.Hour), "tank/app@s4")
+    otherHost := save("nas", t1, "tank/app@s3")
+
+    // Snapshots déplacés par une restauration par clone, tank/app@s2 détruit par rollback -r
+    n, err := db.ReconcileZFSSnapshots("local",
+        map[string]string{"tank/db@s1": "tank/db_prev_1@s1", "tank/app@s3": "tank/app_prev_1@s3"},
+        []string{"tank/app@s2"})
+    if err != nil {
+        t.Fatal(err)
+    }
+    if n != 3 {
+        t.Errorf("%d entries updated, want 3", n)
+    }
+
+    for _, c := range []struct {
+        host string
+        id   int64
+        want []string
+    }{
+        {"local", grouped, []string{"tank/app@s1", "tank/db_prev_1@s1"}},
+        {"local", destroyed, nil},
+        {"local", moved, []string{"tank/app_prev_1@s3"}},
+        {"local", untouched, []string{"tank/app@s4"}},
+        {"nas", otherHost, []string{"tank/app@s3"}},
+    } {
+        got, err := db.GetSnapshot(c.host, "app", c.id)
+        if err != nil {
+            t.Fatal(err)
+        }
+        if len(got.ZFSSnapshots) != len(c.want) || (len(c.want) > 0 && !reflect.DeepEqual(got.ZFSSnapshots, c.want)) {
+            t.Errorf("entry %d on %s = %v, want %v", c.id, c.host, got.ZFSSnapshots, c.want)
+        }
+    }
+
+    if n, err := db.ReconcileZFSSnapshots("local", nil, nil); err != nil || n != 0 {
+        t.Errorf("empty reconciliation = %d, %v", n, err)
+    }
+}
+
+func TestReplicationState(t *testing.T) {
+    forEachBackend(t, testReplicationState)
+}
+
+func testReplicationState(t *testing.T, db *Database) {
+    get := func(host, dataset string) string {
+        t.Helper()
+        snapshot, err := db.GetReplicated(host, dataset)
+        if err != nil {
+            t.Fatal(err)
+        }
+        return snapshot
+    }
+
+    // Jamais répliqué : pas de base pour l'envoi incrémental
+    if got := get("local", "tank/app"); got != "" {
+        t.Errorf("GetReplicated = %q, want empty", got)
+    }
+
+    for _, s := range []struct{ host, dataset, snapshot string }{
+        {"local", "tank/app", "tank/app@s1"},
+        {"local", "tank/app", "tank/app@s2"},
+        {"local", "tank/db", "tank/db@s1"},
+        {"nas", "tank/app", "tank/app@n1"},
+    } {
+        if err := db.SetReplicated(s.host, s.dataset, s.snapshot); err != nil {
+            t.Fatal(err)
+        }
+    }
+    for _, c := range []struct{ host, dataset, want string }{
+        {"local", "tank/app", "tank/app@s2"},
+        {"local", "tank/db", "tank/db@s1"},
+        {"nas", "tank/app", "tank/app@n1"},
+        {"nas", "tank/db", ""},
+    } {
+        if got := get(c.host, c.dataset); got != c.want {
+            t.Errorf("GetReplicated(%s, %s) = %q, want %q", c.host, c.dataset, got, c.want)
+        }
+    }
+    if n := countRows(t, db, "SELECT COUNT(*) FROM zfs_replication"); n != 3 {
+        t.Errorf("%d replication rows, want 3", n)
+    }
+}
@@ -0,0 +1,338 @@
+// internal/storage/database/migrations.go
+package database
+
+import (
+    "embed"
+    "fmt"
+    "io/fs"
+    "sort"
+    "strconv"
+    "strings"
+    "time"
+
+    "zockimate/internal/hosts"
+    "zockimate/internal/types"
+)
+
+// Migrations du schéma, fichiers NNNN_nom.sql appliqués dans l'ordre de leur numéro,
+// un répertoire par moteur. Une migration publiée n'est jamais modifiée : toute
+// évolution passe par un nouveau fichier, de même numéro pour chaque moteur.
+//
+//go:embed migrations/sqlite/*.sql migrations/postgres/*.sql
+var migrationFiles embed.FS
+
+// migration est une migration du schéma
+type migration struct {
+    version int
+    name    string
+    sql     string
+}
+
+// migrationHooks sont exécutés avant le SQL de la migration de même version, par moteur
+var migrationHooks = map[string]map[int]func(tx *sqlTx) error{
+    sqliteScheme: {1: upgradeLegacySchema},
+}
+
+// loadMigrations lit les migrations embarquées d'un moteur, numérotées sans trou à partir de 1
+func loadMigrations(backend Backend) ([]migration, error) {
+    files := backend.Migrations()
+    entries, err := fs.ReadDir(files, ".")
+    if err != nil {
+        return nil, fmt.Errorf("failed to read migrations: %w", err)
+    }
+
+    var migrations []migration
+    for _, e := range entries {
+        num, name, ok := strings.Cut(strings.TrimSuffix(e.Name(), ".sql"), "_")
+        version, err := strconv.Atoi(num)
+        if !ok || err != nil {
+            return nil, fmt.Errorf("invalid migration file name %s", e.Name())
+        }
+        data, err := fs.ReadFile(files, e.Name())
+        if err != nil {
+            return nil, fmt.Errorf("failed to read migration %s: %w", e.Name(), err)
+        }
+        migrations = append(migrations, migration{version: version, name: name, sql: string(data)})
+    }
+
+    sort.Slice(migrations, func(i, j int) bool {
+        return migrations[i].version < migrations[j].version
+    })
+    for i, m := range migrations {
+        if m.version != i+1 {
+            return nil, fmt.Errorf("migration %d is missing", i+1)
+        }
+    }
+    return migrations, nil
+}
+
+// schemaVersion retourne la version du schéma (0 : base vide ou antérieure au suivi des versions)
+func schemaVersion(db *sqlDB) (int, error) {
+    exists, err := db.backend.TableExists(db.DB, "schema_version")
+    if err != nil || !exists {
+        return 0, err
+    }
+    var version int
+    if err := db.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_version`).Scan(&version); err != nil {
+        return 0, fmt.Errorf("failed to read schema version: %w", err)
+    }
+    return version, nil
+}
+
+// SchemaVersion retourne la version du schéma de la base ouverte
+func (d *Database) SchemaVersion() (int, error) {
+    return schemaVersion(d.db)
+}
+
+// schemaStatus retourne les migrations appliquées et en attente (db nil : base pas encore créée)
+func schemaStatus(backend Backend, db *sqlDB) (*types.SchemaStatus, error) {
+    migrations, err := loadMigrations(backend)
+    if err != nil {
+        return nil, err
+    }
+    current := 0
+    if db != nil {
+        if current, err = schemaVersion(db); err != nil {
+            return nil, err
+        }
+    }
+
+    status := &types.SchemaStatus{
+        Current: current,
+        Latest:  migrations[len(migrations)-1].version,
+    }
+    if current > status.Latest {
+        return status, fmt.Errorf("database schema version %d is newer than this binary supports (%d)",
+            current, status.Latest)
+    }
+
+    if current > 0 {
+        rows, err := db.Query(`SELECT version, name, applied_at FROM schema_version ORDER BY version`)
+        if err != nil {
+            return nil, fmt.Errorf("failed to read schema version: %w", err)
+        }
+        defer rows.Close()
+        for rows.Next() {
+            var info types.MigrationInfo
+            var appliedAt string
+            if err := rows.Scan(&info.Version, &info.Name, &appliedAt); err != nil {
+                return nil, fmt.Errorf("failed to read schema version: %w", err)
+            }
+            if t, err := time.Parse(time.RFC3339, appliedAt); err == nil {
+                info.AppliedAt = &t
+            }
+            status.Applied = append(status.Applied, info)
+        }
+        if err := rows.Err(); err != nil {
+            return nil, fmt.Errorf("failed to read schema version: %w", err)
+        }
+    }
+    for _, m := range migrations[current:] {
+        status.Pending = append(status.Pending, types.MigrationInfo{Version: m.version, Name: m.name})
+    }
+    return status, nil
+}
+
+// migrate applique les migrations en attente, chacune dans sa transaction. Une base
+// SQLite existante est d'abord copiée à côté du fichier (<base>.v<version>-<date>.bak).
+func migrate(db *sqlDB) (*types.MigrationResult, error) {
+    status, err := schemaStatus(db.backend, db)
+    if err != nil {
+        return nil, err
+    }
+    result := &types.MigrationResult{From: status.Current, To: status.Current, Pending: status.Pending}
+    if len(status.Pending) == 0 {
+        return result, nil
+    }
+
+    migrations, err := loadMigrations(db.backend)
+    if err != nil {
+        return nil, err
+    }
+
+    // Sauvegarder une base existante (y compris antérieure au suivi des versions)
+    existing, err := db.backend.TableExists(db.DB, "container_snapshots")
+    if err != nil {
+        return nil, err
+    }
+    if existing {
+        if result.Backup, err = db.backend.Backup(db.DB, status.Current); err != nil {
+            return nil, err
+        }
+    }
+
+    if _, err := db.Exec(`
+        CREATE TABLE IF NOT EXISTS schema_version (
+            version INTEGER PRIMARY KEY,
+            name TEXT NOT NULL,
+            applied_at TEXT NOT NULL    -- Format ISO en UTC
+        )`); err != nil {
+        return nil, fmt.Errorf("failed to create schema_version table: %w", err)
+    }
+
+    for _, m := range migrations[status.Current:] {
+        applied, err := applyMigration(db, m)
+        if err != nil {
+            return result, err
+        }
+        result.To = m.version
+        if !applied {
+            continue // Appliquée entre-temps par une autre instance
+        }
+        result.Applied = append(result.Applied, types.MigrationInfo{Version: m.version, Name: m.name})
+    }
+    return result, nil
+}
+
+// applyMigration exécute une migration et l'enregistre dans schema_version.
+// Retourne false si une autre instance partageant la base l'a déjà appliquée.
+func applyMigration(db *sqlDB, m migration) (bool, error) {
+    tx, err := db.Begin()
+    if err != nil {
+        return false, fmt.Errorf("failed to begin migration %d: %w", m.version, err)
+    }
+    defer tx.Rollback() //nolint:errcheck
+
+    if err := db.backend.LockSchema(tx.Tx); err != nil {
+        return false, err
+    }
+    var count int
+    if err := tx.QueryRow(`SELECT COUNT(*) FROM schema_version WHERE version = ?`, m.version).
+        Scan(&count); err != nil {
+        return false, fmt.Errorf("failed to read schema version: %w", err)
+    }
+    if count > 0 {
+        return false, nil
+    }
+
+    if hook, ok := migrationHooks[db.backend.Name()][m.version]; ok {
+        if err := hook(tx); err != nil {
+            return false, fmt.Errorf("migration %d (%s) failed: %w", m.version, m.name, err)
+        }
+    }
+    if _, err := tx.Tx.Exec(m.sql); err != nil {
+        return false, fmt.Errorf("migration %d (%s) failed: %w", m.version, m.name, err)
+    }
+    if _, err := tx.Exec(`INSERT INTO schema_version (version, name, applied_at) VALUES (?, ?, ?)`,
+        m.version, m.name, time.Now().UTC().Format(time.RFC3339)); err != nil {
+        return false, fmt.Errorf("failed to record migration %d: %w", m.version, err)
+    }
+
+    if err := tx.Commit(); err != nil {
+        return false, fmt.Errorf("failed to commit migration %d: %w", m.version, err)
+    }
+    return true, nil
+}
+
+// SchemaStatus retourne la version du schéma d'une base sans la modifier
+func SchemaStatus(url string) (*types.SchemaStatus, error) {
+    backend, err := NewBackend(url)
+    if err != nil {
+        return nil, err
+    }
+    if !backend.Exists() {
+        // Base pas encore créée : toutes les migrations sont en attente
+        return schemaStatus(backend, nil)
+    }
+    db, err := openDB(url)
+    if err != nil {
+        return nil, err
+    }
+    defer db.Close()
+    return schemaStatus(backend, db)
+}
+
+// Migrate applique (ou liste avec dryRun) les migrations en attente d'une base
+func Migrate(url string, dryRun bool) (*types.MigrationResult, error) {
+    if dryRun {
+        status, err := SchemaStatus(url)
+        if err != nil {
+            return nil, err
+        }
+        return &types.MigrationResult{From: status.Current, To: status.Current, Pending: status.Pending}, nil
+    }
+
+    db, err := openDB(url)
+    if err != nil {
+        return nil, err
+    }
+    defer db.Close()
+    return migrate(db)
+}
+
+// Schéma v1 de container_snapshots (migrations/0001_initial.sql), nom de la table
+// en paramètre, pour la reconstruction des bases antérieures au support multi-hôtes
+const snapshotsTable = `
+    CREATE TABLE %s (
+        id INTEGER PRIMARY KEY AUTOINCREMENT,
+        host TEXT NOT NULL DEFAULT '` + hosts.DefaultHost + `',
+        container_name TEXT NOT NULL,
+        image_id TEXT NOT NULL,
+        image_digest TEXT,
+        image_tag TEXT,
+        original_image TEXT NOT NULL,
+        config BLOB,
+        host_config BLOB,
+        network_config BLOB,
+        zfs_snapshot TEXT,
+        snapshot_mode TEXT,
+        freeze_ms INTEGER,
+        status TEXT,
+        message TEXT,
+        created_at TEXT DEFAULT (strftime('%%Y-%%m-%%dT%%H:%%M:%%SZ', 'now')),
+        UNIQUE(host, container_name, created_at)
+    );`
+
+// upgradeLegacySchema amène une base antérieure au suivi des versions au niveau
+// attendu par la migration 1 : colonne host (table reconstruite pour l'inclure dans
+// la contrainte d'unicité, entrées rattachées à l'hôte par défaut) et colonnes de
+// cohérence des snapshots
+func upgradeLegacySchema(tx *sqlTx) error {
+    columns := make(map[string]bool)
+    rows, err := tx.Query(`SELECT name FROM pragma_table_info('container_snapshots')`)
+    if err != nil {
+        return fmt.Errorf("failed to inspect schema: %w", err)
+    }
+    for rows.Next() {
+        var name string
+        if err := rows.Scan(&name); err != nil {
+            rows.Close()
+            return fmt.Errorf("failed to inspect schema: %w", err)
+        }
+        columns[name] = true
+    }
+    rows.Close()
+    if len(columns) == 0 {
+        return nil // Nouvelle base
+    }
+
+    if !columns["host"] {
+        legacy := `container_name, image_id, image_digest, image_tag, original_image,
+            config, host_config, network_config, zfs_snapshot, status, message, created_at`
+        for _, stmt := range []string{
+            fmt.Sprintf(snapshotsTable, "container_snapshots_new"),
+            `INSERT INTO container_snapshots_new (id, host, ` + legacy + `)
+             SELECT id, '` + hosts.DefaultHost + `', ` + legacy + ` FROM container_snapshots`,
+            `DROP TABLE container_snapshots`,
+            `ALTER TABLE container_snapshots_new RENAME TO container_snapshots`,
+        } {
+            if _, err := tx.Exec(stmt); err != nil {
+                return fmt.Errorf("failed to add host column: %w", err)
+            }
+        }
+        return nil
+    }
+
+    for _, col := range []struct{ name, def string }{
+        {"snapshot_mode", "TEXT"},
+        {"freeze_ms", "INTEGER"},
+    } {
+        if columns[col.name] {
+            continue
+        }
+        if _, err := tx.Exec(`ALTER TABLE container_snapshots ADD COLUMN ` + col.name + ` ` + col.def); err != nil {
+            return fmt.Errorf("failed to add column %s: %w", col.name, err)
+        }
+    }
+    return nil
+}
@@ -0,0 +1,57 @@
+-- Schéma initial, équivalent PostgreSQL de la version 1 du schéma SQLite
+CREATE TABLE IF NOT EXISTS container_snapshots (
+    id BIGSERIAL PRIMARY KEY,
+    host TEXT NOT NULL DEFAULT 'local',
+    container_name TEXT NOT NULL,
+    image_id TEXT NOT NULL,
+    image_digest TEXT,
+    image_tag TEXT,
+    original_image TEXT NOT NULL,
+    config BYTEA,
+    host_config BYTEA,
+    network_config BYTEA,
+    zfs_snapshot TEXT,    -- Liste JSON des snapshots ZFS du groupe
+    snapshot_mode TEXT,   -- Mode de cohérence des données (live, pause, stop, hook)
+    freeze_ms BIGINT,     -- Durée de gel du conteneur pendant le snapshot
+    status TEXT,
+    message TEXT,
+    created_at TEXT DEFAULT (to_char(now() AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS"Z"')),  -- Format ISO en UTC
+    UNIQUE(host, container_name, created_at)
+);
+
+CREATE TABLE IF NOT EXISTS image_first_seen (
+    image TEXT NOT NULL,        -- Référence suivie (ex: nginx:latest)
+    digest TEXT NOT NULL,       -- Digest (ou ID) de l'image distante
+    first_seen TEXT NOT NULL,   -- Format ISO en UTC
+    PRIMARY KEY(image, digest)
+);
+
+CREATE TABLE IF NOT EXISTS snapshot_vulnerabilities (
+    snapshot_id BIGINT PRIMARY KEY,   -- Snapshot pris avant la mise à jour analysée
+    introduced TEXT,                  -- JSON des vulnérabilités introduites
+    fixed TEXT,                       -- JSON des vulnérabilités corrigées
+    scanned_at TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS zfs_replication (
+    host TEXT NOT NULL,
+    dataset TEXT NOT NULL,      -- Dataset source
+    snapshot TEXT NOT NULL,     -- Dernier snapshot répliqué (base du prochain envoi incrémental)
+    replicated_at TEXT NOT NULL,
+    PRIMARY KEY(host, dataset)
+);
+
+CREATE TABLE IF NOT EXISTS snapshot_mounts (
+    snapshot_id BIGINT NOT NULL,
+    type TEXT NOT NULL,         -- volume ou bind
+    source TEXT NOT NULL,       -- Nom du volume ou chemin sur l'hôte
+    destination TEXT NOT NULL,  -- Point de montage dans le conteneur
+    archive TEXT NOT NULL,      -- Chemin relatif dans le magasin d'archives
+    PRIMARY KEY(snapshot_id, destination)
+);
+
+CREATE INDEX IF NOT EXISTS idx_container_name ON container_snapshots(container_name);
+CREATE INDEX IF NOT EXISTS idx_created_at ON container_snapshots(created_at);
+CREATE INDEX IF NOT EXISTS idx_container_status ON container_snapshots(status);
+CREATE INDEX IF NOT EXISTS idx_container_message ON container_snapshots(message);
+CREATE INDEX IF NOT EXISTS idx_container_host ON container_snapshots(host, container_name);
@@ -0,0 +1,42 @@
+-- Images référencées par les snapshots et les opérations, une ligne par ID d'image
+CREATE TABLE images (
+    id TEXT PRIMARY KEY,        -- ID local de l'image (digest pour les services Swarm)
+    repo_digest TEXT,           -- Digest du repository (repo@sha256:...)
+    tag TEXT,                   -- Premier tag de l'image
+    platform TEXT,              -- Architecture/OS
+    labels TEXT,                -- JSON des labels de l'image
+    recorded_at TEXT NOT NULL   -- Première référence, format ISO en UTC
+);
+
+INSERT INTO images (id, repo_digest, tag, recorded_at)
+    SELECT image_id, MAX(NULLIF(image_digest, '')), MAX(NULLIF(image_tag, '')), MIN(created_at)
+    FROM container_snapshots
+    WHERE image_id != ''
+    GROUP BY image_id;
+
+-- Les métadonnées d'image ne sont plus dupliquées dans chaque snapshot ; le statut
+-- valait toujours "snapshot", l'issue des mises à jour est portée par les opérations
+DROP INDEX IF EXISTS idx_container_status;
+ALTER TABLE container_snapshots DROP COLUMN image_digest;
+ALTER TABLE container_snapshots DROP COLUMN image_tag;
+ALTER TABLE container_snapshots DROP COLUMN status;
+
+-- Mises à jour et rollbacks
+CREATE TABLE operations (
+    id BIGSERIAL PRIMARY KEY,
+    host TEXT NOT NULL,
+    container_name TEXT NOT NULL,
+    type TEXT NOT NULL,            -- update, rollback
+    triggered_by TEXT NOT NULL,    -- cli, schedule, api
+    started_at TEXT NOT NULL,      -- Format ISO en UTC
+    finished_at TEXT,              -- NULL si l'opération a été interrompue
+    outcome TEXT NOT NULL,         -- running, success, failed, rolled_back
+    error TEXT,
+    snapshot_id BIGINT,            -- Snapshot de sécurité pris avant l'opération
+    target_snapshot_id BIGINT,     -- Snapshot restauré (rollback)
+    from_image TEXT,               -- ID de l'image avant l'opération (table images)
+    to_image TEXT                  -- ID de l'image visée
+);
+
+CREATE INDEX idx_operations_container ON operations(host, container_name, started_at);
+CREATE INDEX idx_container_image ON container_snapshots(image_id);
@@ -0,0 +1,14 @@
+-- Configurations sérialisées, stockées compressées une seule fois par contenu
+CREATE TABLE blobs (
+    hash TEXT PRIMARY KEY,      -- sha256 du contenu décompressé
+    codec TEXT NOT NULL,        -- Compression de data (zstd)
+    size INTEGER NOT NULL,      -- Taille décompressée
+    data BLOB NOT NULL
+);
+
+-- Références aux blobs des configurations. Les colonnes config, host_config et
+-- network_config ne restent renseignées que pour les entrées antérieures, jusqu'à
+-- leur conversion par db vacuum.
+ALTER TABLE container_snapshots ADD COLUMN config_hash TEXT;
+ALTER TABLE container_snapshots ADD COLUMN host_config_hash TEXT;
+ALTER TABLE container_snapshots ADD COLUMN network_config_hash TEXT;
@@ -0,0 +1,343 @@
+// internal/storage/database/migrations_test.go
+package database
+
+import (
+    "database/sql"
+    "path/filepath"
+    "reflect"
+    "regexp"
+    "strings"
+    "testing"
+)
+
+// Schémas des bases antérieures au suivi des versions
+const (
+    // Avant le support multi-hôtes
+    legacySchemaSingleHost = `
+        CREATE TABLE container_snapshots (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            container_name TEXT NOT NULL,
+            image_id TEXT NOT NULL,
+            image_digest TEXT,
+            image_tag TEXT,
+            original_image TEXT NOT NULL,
+            config BLOB,
+            host_config BLOB,
+            network_config BLOB,
+            zfs_snapshot TEXT,
+            status TEXT,
+            message TEXT,
+            created_at TEXT DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
+            UNIQUE(container_name, created_at)
+        );
+        CREATE INDEX idx_container_name ON container_snapshots(container_name);
+        CREATE INDEX idx_container_status ON container_snapshots(status);`
+
+    // Avec la colonne host, avant les modes de cohérence des snapshots
+    legacySchemaMultiHost = `
+        CREATE TABLE container_snapshots (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            host TEXT NOT NULL DEFAULT 'local',
+            container_name TEXT NOT NULL,
+            image_id TEXT NOT NULL,
+            image_digest TEXT,
+            image_tag TEXT,
+            original_image TEXT NOT NULL,
+            config BLOB,
+            host_config BLOB,
+            network_config BLOB,
+            zfs_snapshot TEXT,
+            status TEXT,
+            message TEXT,
+            created_at TEXT DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
+            UNIQUE(host, container_name, created_at)
+        );
+        CREATE INDEX idx_container_status ON container_snapshots(status);`
+)
+
+// createLegacyDatabase crée une base sans table schema_version contenant deux entrées
+func createLegacyDatabase(t *testing.T, schema string) string {
+    t.Helper()
+    path := filepath.Join(t.TempDir(), "zockimate.db")
+    db, err := sql.Open(sqliteDriver, path)
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer db.Close()
+
+    if _, err := db.Exec(schema); err != nil {
+        t.Fatal(err)
+    }
+    for _, stmt := range []string{
+        `INSERT INTO container_snapshots (container_name, image_id, image_digest, image_tag, original_image,
+            config, host_config, network_config, zfs_snapshot, status, message, created_at)
+         VALUES ('app', 'sha256:aaa', 'nginx@sha256:111', 'nginx:1.25', 'nginx:1.25',
+            '{"Image":"nginx:1.25"}', '{}', '{}', '["tank/app@snapshot_1"]', 'snapshot', 'first', '2024-05-01T10:00:00Z')`,
+        `INSERT INTO container_snapshots (container_name, image_id, image_digest, image_tag, original_image,
+            config, host_config, network_config, zfs_snapshot, status, message, created_at)
+         VALUES ('app', 'sha256:bbb', '', 'nginx:1.26', 'nginx:1.26',
+            '{"Image":"nginx:1.26"}', '{}', '{}', 'tank/app@snapshot_2', 'snapshot', 'second', '2024-06-01T10:00:00Z')`,
+    } {
+        if _, err := db.Exec(stmt); err != nil {
+            t.Fatal(err)
+        }
+    }
+    return path
+}
+
+func TestUpgradeLegacySchema(t *testing.T) {
+    tests := []struct {
+        name   string
+        schema string
+    }{
+        {"single host", legacySchemaSingleHost},
+        {"multi host", legacySchemaMultiHost},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            path := createLegacyDatabase(t, tt.schema)
+
+            status, err := SchemaStatus(path)
+            if err != nil {
+                t.Fatal(err)
+            }
+            if status.Current != 0 || len(status.Pending) != status.Latest {
+                t.Fatalf("status = version %d, %d pending, want 0 and %d", status.Current,
+                    len(status.Pending), status.Latest)
+            }
+
+            result, err := Migrate(path, false)
+            if err != nil {
+                t.Fatal(err)
+            }
+            if result.From != 0 || result.To != status.Latest || len(result.Applied) != status.Latest {
+                t.Errorf("migration = %d -> %d (%d applied), want 0 -> %d", result.From, result.To,
+                    len(result.Applied), status.Latest)
+            }
+            if !strings.HasPrefix(result.Backup, path+".v0-") {
+                t.Errorf("backup = %q, want a copy next to the database", result.Backup)
+            }
+
+            db := openTestURL(t, path)
+
+            // Entrées rattachées à l'hôte par défaut, images reprises dans la table images
+            first, err := db.GetSnapshot("local", "app", 1)
+            if err != nil {
+                t.Fatal(err)
+            }
+            if first.ImageRef.RepoDigest != "nginx@sha256:111" || first.ImageRef.Tag != "nginx:1.25" ||
+                first.Message != "first" || string(first.Config) != `{"Image":"nginx:1.25"}` {
+                t.Errorf("first entry = %+v", first)
+            }
+            if len(first.ZFSSnapshots) != 1 || first.ZFSSnapshots[0] != "tank/app@snapshot_1" {
+                t.Errorf("first entry ZFS snapshots = %v", first.ZFSSnapshots)
+            }
+            second, err := db.GetSnapshot("local", "app", 0)
+            if err != nil {
+                t.Fatal(err)
+            }
+            if second.ID != 2 || second.ImageRef.RepoDigest != "" || second.SnapshotMode != "" ||
+                len(second.ZFSSnapshots) != 1 || second.ZFSSnapshots[0] != "tank/app@snapshot_2" {
+                t.Errorf("latest entry = %+v", second)
+            }
+
+            // Les nouvelles entrées suivent les anciennes
+            entry := testSnapshot("app", first.CreatedAt)
+            entry.SnapshotMode = "pause"
+            if err := db.SaveSnapshot(entry); err != nil {
+                t.Fatal(err)
+            }
+            if entry.ID != 3 {
+                t.Errorf("new entry ID = %d, want 3", entry.ID)
+            }
+        })
+    }
+}
+
+func TestMigrationsMatch(t *testing.T) {
+    // Chaque migration existe pour les deux moteurs, sous le même numéro et le même nom
+    sqlite, err := loadMigrations(newSQLiteBackend(""))
+    if err != nil {
+        t.Fatal(err)
+    }
+    postgres, err := loadMigrations(newPostgresBackend(""))
+    if err != nil {
+        t.Fatal(err)
+    }
+    names := func(migrations []migration) []string {
+        var names []string
+        for _, m := range migrations {
+            names = append(names, m.name)
+        }
+        return names
+    }
+    if !reflect.DeepEqual(names(sqlite), names(postgres)) {
+        t.Errorf("migrations differ: sqlite %v, postgres %v", names(sqlite), names(postgres))
+    }
+}
+
+// Instructions des migrations PostgreSQL reconnues par postgresSchema
+var (
+    pgCreateTable = regexp.MustCompile(`(?is)^CREATE TABLE (?:IF NOT EXISTS )?(\w+)\s*\((.*)\)$`)
+    pgAddColumn   = regexp.MustCompile(`(?is)^ALTER TABLE (\w+) ADD COLUMN (\w+)`)
+    pgDropColumn  = regexp.MustCompile(`(?is)^ALTER TABLE (\w+) DROP COLUMN (\w+)$`)
+    pgCreateIndex = regexp.MustCompile(`(?is)^CREATE INDEX (?:IF NOT EXISTS )?(\w+) ON \w+`)
+    pgDropIndex   = regexp.MustCompile(`(?is)^DROP INDEX (?:IF EXISTS )?(\w+)$`)
+    pgInsert      = regexp.MustCompile(`(?is)^INSERT INTO \w+`)
+    comment       = regexp.MustCompile(`--[^\n]*`)
+    identifier    = regexp.MustCompile(`^\w+`)
+
+    // Syntaxe propre à SQLite, refusée par PostgreSQL
+    sqliteOnly = regexp.MustCompile(`(?i)\b(AUTOINCREMENT|BLOB|strftime|INSERT OR|PRAGMA)\b|\?`)
+)
+
+// postgresSchema rejoue les migrations PostgreSQL sur une description du schéma :
+// colonnes de chaque table dans l'ordre de création, et index
+func postgresSchema(t *testing.T, migrations []migration) (map[string][]string, map[string]bool) {
+    t.Helper()
+    tables := make(map[string][]string)
+    indexes := make(map[string]bool)
+    for _, m := range migrations {
+        for _, stmt := range strings.Split(comment.ReplaceAllString(m.sql, ""), ";") {
+            stmt = strings.TrimSpace(stmt)
+            if match := pgCreateTable.FindStringSubmatch(stmt); match != nil {
+                tables[match[1]] = tableColumns(match[2])
+            } else if match := pgAddColumn.FindStringSubmatch(stmt); match != nil {
+                tables[match[1]] = append(tables[match[1]], match[2])
+            } else if match := pgDropColumn.FindStringSubmatch(stmt); match != nil {
+                columns := tables[match[1]][:0]
+                for _, c := range tables[match[1]] {
+                    if c != match[2] {
+                        columns = append(columns, c)
+                    }
+                }
+                tables[match[1]] = columns
+            } else if match := pgCreateIndex.FindStringSubmatch(stmt); match != nil {
+                indexes[match[1]] = true
+            } else if match := pgDropIndex.FindStringSubmatch(stmt); match != nil {
+                delete(indexes, match[1])
+            } else if stmt != "" && !pgInsert.MatchString(stmt) {
+                t.Errorf("migration %d: unexpected statement %q", m.version, stmt)
+            }
+        }
+    }
+    return tables, indexes
+}
+
+// tableColumns retourne les colonnes d'une définition de table, sans les contraintes
+func tableColumns(body string) []string {
+    var columns []string
+    depth, start := 0, 0
+    for i := 0; i <= len(body); i++ {
+        if i < len(body) {
+            switch body[i] {
+            case '(':
+                depth++
+            case ')':
+                depth--
+            }
+            if body[i] != ',' || depth > 0 {
+                continue
+            }
+        }
+        name := identifier.FindString(strings.TrimSpace(body[start:i]))
+        start = i + 1
+        switch strings.ToUpper(name) {
+        case "", "PRIMARY", "UNIQUE", "FOREIGN", "CHECK", "CONSTRAINT":
+        default:
+            columns = append(columns, name)
+        }
+    }
+    return columns
+}
+
+func TestPostgresMigrations(t *testing.T) {
+    // Les migrations PostgreSQL ne sont exécutées qu'avec ZOCKIMATE_TEST_POSTGRES :
+    // vérifier sans serveur qu'elles aboutissent au schéma des migrations SQLite
+    postgres, err := loadMigrations(newPostgresBackend(""))
+    if err != nil {
+        t.Fatal(err)
+    }
+    sqlite, err := loadMigrations(newSQLiteBackend(""))
+    if err != nil {
+        t.Fatal(err)
+    }
+    for i, m := range postgres {
+        if loc := sqliteOnly.FindStringIndex(comment.ReplaceAllString(m.sql, "")); loc != nil {
+            t.Errorf("migration %d: SQLite syntax %q", m.version, comment.ReplaceAllString(m.sql, "")[loc[0]:loc[1]])
+        }
+        // Identifiants auto-incrémentés : BIGSERIAL pour chaque AUTOINCREMENT
+        if a, b := strings.Count(sqlite[i].sql, "AUTOINCREMENT"), strings.Count(m.sql, "BIGSERIAL"); a != b {
+            t.Errorf("migration %d: %d BIGSERIAL columns, want %d", m.version, b, a)
+        }
+    }
+
+    tables, indexes := postgresSchema(t, postgres)
+
+    db := openTestDatabase(t)
+    want := make(map[string][]string)
+    rows, err := db.db.Query(`SELECT m.name, p.name FROM sqlite_master m, pragma_table_info(m.name) p
+        WHERE m.type = 'table' AND m.name NOT LIKE 'sqlite_%' AND m.name != 'schema_version'
+        ORDER BY m.name, p.cid`)
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer rows.Close()
+    for rows.Next() {
+        var table, column string
+        if err := rows.Scan(&table, &column); err != nil {
+            t.Fatal(err)
+        }
+        want[table] = append(want[table], column)
+    }
+    if !reflect.DeepEqual(tables, want) {
+        t.Errorf("postgres tables = %v, want %v", tables, want)
+    }
+
+    wantIndexes := make(map[string]bool)
+    rows, err = db.db.Query(`SELECT name FROM sqlite_master WHERE type = 'index' AND sql IS NOT NULL`)
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer rows.Close()
+    for rows.Next() {
+        var name string
+        if err := rows.Scan(&name); err != nil {
+            t.Fatal(err)
+        }
+        wantIndexes[name] = true
+    }
+    if !reflect.DeepEqual(indexes, wantIndexes) {
+        t.Errorf("postgres indexes = %v, want %v", indexes, wantIndexes)
+    }
+}
+
+func TestMigrateUpToDate(t *testing.T) {
+    forEachBackend(t, testMigrateUpToDate)
+}
+
+func testMigrateUpToDate(t *testing.T, db *Database) {
+    version, err := db.SchemaVersion()
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    // Une base à jour n'est ni migrée ni sauvegardée
+    result, err := migrate(db.db)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if result.From != version || result.To != version || len(result.Applied) != 0 || result.Backup != "" {
+        t.Errorf("migration of an up-to-date database = %+v", result)
+    }
+
+    // Base créée par une version plus récente
+    if _, err := db.db.Exec(`INSERT INTO schema_version (version, name, applied_at) VALUES (?, 'future', '')`,
+        version+1); err != nil {
+        t.Fatal(err)
+    }
+    if _, err := migrate(db.db); err == nil || !strings.Contains(err.Error(), "newer") {
+        t.Errorf("error = %v, want a newer schema error", err)
+    }
+}
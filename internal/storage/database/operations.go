@@ -0,0 +1,325 @@
+// internal/storage/database/operations.go
+package database
+
+import (
+    "database/sql"
+    "encoding/json"
+    "fmt"
+    "sort"
+    "strings"
+    "time"
+
+    "zockimate/internal/hosts"
+    "zockimate/internal/types"
+    "zockimate/internal/types/options"
+    "zockimate/pkg/utils"
+)
+
+// execer est implémenté par *sqlDB et *sqlTx
+type execer interface {
+    Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// SaveImage enregistre une image dans la table images
+func (d *Database) SaveImage(ref *types.ImageReference) error {
+    return saveImage(d.db, ref)
+}
+
+// saveImage insère une image ou complète une image connue : les valeurs déjà
+// enregistrées ne sont remplacées que par des valeurs non vides
+func saveImage(e execer, ref *types.ImageReference) error {
+    if ref == nil || ref.ID == "" {
+        return nil
+    }
+    var labels string
+    if len(ref.Labels) > 0 {
+        data, err := json.Marshal(ref.Labels)
+        if err != nil {
+            return fmt.Errorf("failed to marshal image labels: %w", err)
+        }
+        labels = string(data)
+    }
+
+    _, err := e.Exec(`
+        INSERT INTO images (id, repo_digest, tag, platform, labels, recorded_at)
+        VALUES (?, NULLIF(?, ''), NULLIF(?, ''), NULLIF(?, ''), NULLIF(?, ''), ?)
+        ON CONFLICT(id) DO UPDATE SET
+            repo_digest = COALESCE(excluded.repo_digest, images.repo_digest),
+            tag = COALESCE(excluded.tag, images.tag),
+            platform = COALESCE(excluded.platform, images.platform),
+            labels = COALESCE(excluded.labels, images.labels)`,
+        ref.ID, ref.RepoDigest, ref.Tag, ref.Platform, labels, time.Now().UTC().Format(time.RFC3339),
+    )
+    if err != nil {
+        return fmt.Errorf("failed to save image %s: %w", utils.ShortenID(ref.ID), err)
+    }
+    return nil
+}
+
+// deleteOrphanImages supprime les images référencées ni par un snapshot ni par une opération
+func (d *Database) deleteOrphanImages() {
+    if _, err := d.db.Exec(`DELETE FROM images
+        WHERE id NOT IN (SELECT image_id FROM container_snapshots)
+          AND id NOT IN (SELECT from_image FROM operations WHERE from_image IS NOT NULL)
+          AND id NOT IN (SELECT to_image FROM operations WHERE to_image IS NOT NULL)`); err != nil {
+        d.logger.Warnf("Failed to delete orphan images: %v", err)
+    }
+}
+
+// StartOperation enregistre le début d'une opération et renseigne son identifiant
+func (d *Database) StartOperation(op *types.Operation) error {
+    if op.StartedAt.IsZero() {
+        op.StartedAt = time.Now().UTC()
+    }
+    op.Outcome = types.OutcomeRunning
+
+    err := d.db.QueryRow(`
+        INSERT INTO operations (
+            host, container_name, type, triggered_by, started_at, outcome,
+            snapshot_id, target_snapshot_id, from_image, to_image
+        ) VALUES (?, ?, ?, ?, ?, ?, NULLIF(?, 0), NULLIF(?, 0), NULLIF(?, ''), NULLIF(?, ''))
+        RETURNING id`,
+        op.Host, op.ContainerName, op.Type, op.TriggeredBy, op.StartedAt.UTC().Format(time.RFC3339),
+        op.Outcome, op.SnapshotID, op.TargetSnapshotID, op.FromImage, op.ToImage,
+    ).Scan(&op.ID)
+    if err != nil {
+        return fmt.Errorf("failed to save operation: %w", err)
+    }
+    return nil
+}
+
+// FinishOperation enregistre l'issue d'une opération
+func (d *Database) FinishOperation(op *types.Operation) error {
+    finished := time.Now().UTC()
+    op.FinishedAt = &finished
+
+    _, err := d.db.Exec(`
+        UPDATE operations SET finished_at = ?, outcome = ?, error = NULLIF(?, ''),
+            snapshot_id = NULLIF(?, 0), target_snapshot_id = NULLIF(?, 0),
+            from_image = NULLIF(?, ''), to_image = NULLIF(?, '')
+        WHERE id = ?`,
+        finished.Format(time.RFC3339), op.Outcome, op.Error,
+        op.SnapshotID, op.TargetSnapshotID, op.FromImage, op.ToImage, op.ID,
+    )
+    if err != nil {
+        return fmt.Errorf("failed to update operation %d: %w", op.ID, err)
+    }
+    return nil
+}
+
+// MergeOperation ajoute une opération d'une autre base sous un nouvel identifiant.
+// Retourne false si une opération de même conteneur, type et début existe déjà.
+func (d *Database) MergeOperation(op *types.Operation) (bool, error) {
+    startedAt := op.StartedAt.UTC().Format(time.RFC3339)
+    var finishedAt sql.NullString
+    if op.FinishedAt != nil {
+        finishedAt = sql.NullString{String: op.FinishedAt.UTC().Format(time.RFC3339), Valid: true}
+    }
+
+    tx, err := d.db.Begin()
+    if err != nil {
+        return false, fmt.Errorf("failed to begin transaction: %w", err)
+    }
+    defer tx.Rollback() //nolint:errcheck
+
+    exists, err := operationExists(tx, op)
+    if err != nil || exists {
+        return false, err
+    }
+
+    err = tx.QueryRow(`
+        INSERT INTO operations (
+            host, container_name, type, triggered_by, started_at, finished_at, outcome, error,
+            snapshot_id, target_snapshot_id, from_image, to_image
+        ) VALUES (?, ?, ?, ?, ?, ?, ?, NULLIF(?, ''), NULLIF(?, 0), NULLIF(?, 0), NULLIF(?, ''), NULLIF(?, ''))
+        RETURNING id`,
+        op.Host, op.ContainerName, op.Type, op.TriggeredBy, startedAt, finishedAt,
+        op.Outcome, op.Error, op.SnapshotID, op.TargetSnapshotID, op.FromImage, op.ToImage,
+    ).Scan(&op.ID)
+    if err != nil {
+        return false, fmt.Errorf("failed to save operation: %w", err)
+    }
+    if err := tx.Commit(); err != nil {
+        return false, fmt.Errorf("failed to commit operation: %w", err)
+    }
+    return true, nil
+}
+
+// OperationExists indique si une opération de même conteneur, type et début est enregistrée
+func (d *Database) OperationExists(op *types.Operation) (bool, error) {
+    return operationExists(d.db, op)
+}
+
+func operationExists(q querier, op *types.Operation) (bool, error) {
+    var count int
+    if err := q.QueryRow(`SELECT COUNT(*) FROM operations
+        WHERE host = ? AND container_name = ? AND type = ? AND started_at = ?`,
+        op.Host, op.ContainerName, op.Type, op.StartedAt.UTC().Format(time.RFC3339)).Scan(&count); err != nil {
+        return false, fmt.Errorf("failed to query operations: %w", err)
+    }
+    return count > 0, nil
+}
+
+// GetOperations récupère les opérations, avec les filtres de l'historique
+// (la recherche porte sur le type et l'erreur)
+func (d *Database) GetOperations(opts options.HistoryOptions) ([]types.Operation, error) {
+    var conditions []string
+    var args []interface{}
+
+    query := `SELECT id, host, container_name, type, triggered_by, started_at, finished_at,
+              outcome, error, snapshot_id, target_snapshot_id, from_image, to_image
+              FROM operations`
+
+    if len(opts.Container) > 0 {
+        placeholders := make([]string, len(opts.Container))
+        for i, id := range opts.Container {
+            host, name := utils.SplitContainerID(id)
+            if host == "" {
+                host = hosts.DefaultHost
+            }
+            placeholders[i] = "(host = ? AND container_name = ?)"
+            args = append(args, host, name)
+        }
+        conditions = append(conditions,
+            "("+strings.Join(placeholders, " OR ")+")")
+    }
+
+    if !opts.Since.IsZero() {
+        conditions = append(conditions, "started_at >= ?")
+        args = append(args, opts.Since.Format("2006-01-02 15:04:05"))
+    }
+
+    if !opts.Before.IsZero() {
+        conditions = append(conditions, "started_at <= ?")
+        args = append(args, opts.Before.Format("2006-01-02 15:04:05"))
+    }
+
+    if opts.Search != "" {
+        conditions = append(conditions, "(LOWER(type) LIKE LOWER(?) OR LOWER(error) LIKE LOWER(?))")
+        searchTerm := "%" + opts.Search + "%"
+        args = append(args, searchTerm, searchTerm)
+    }
+
+    if len(conditions) > 0 {
+        query += " WHERE " + strings.Join(conditions, " AND ")
+    }
+    if opts.SortBy == "container" {
+        query += " ORDER BY host, container_name, started_at DESC, id DESC"
+    } else {
+        query += " ORDER BY started_at DESC, id DESC"
+    }
+
+    rows, err := d.db.Query(query, args...)
+    if err != nil {
+        return nil, fmt.Errorf("failed to query operations: %w", err)
+    }
+    defer rows.Close()
+
+    var ops []types.Operation
+    for rows.Next() {
+        var op types.Operation
+        var startedAt string
+        var finishedAt, opErr, fromImage, toImage sql.NullString
+        var snapshotID, targetID sql.NullInt64
+        if err := rows.Scan(&op.ID, &op.Host, &op.ContainerName, &op.Type, &op.TriggeredBy,
+            &startedAt, &finishedAt, &op.Outcome, &opErr, &snapshotID, &targetID,
+            &fromImage, &toImage); err != nil {
+            return nil, fmt.Errorf("failed to scan operation: %w", err)
+        }
+
+        if op.StartedAt, err = utils.ParseTime(startedAt); err != nil {
+            return nil, fmt.Errorf("failed to parse started_at: %w", err)
+        }
+        if finishedAt.Valid {
+            t, err := utils.ParseTime(finishedAt.String)
+            if err != nil {
+                return nil, fmt.Errorf("failed to parse finished_at: %w", err)
+            }
+            op.FinishedAt = &t
+        }
+        op.Error = opErr.String
+        op.SnapshotID = snapshotID.Int64
+        op.TargetSnapshotID = targetID.Int64
+        op.FromImage = fromImage.String
+        op.ToImage = toImage.String
+
+        ops = append(ops, op)
+    }
+    if err := rows.Err(); err != nil {
+        return nil, fmt.Errorf("failed to iterate operations: %w", err)
+    }
+    return ops, nil
+}
+
+// GetTimeline fusionne snapshots et opérations en une chronologie, du plus récent
+// au plus ancien ; Last et Limit s'appliquent à la chronologie fusionnée
+func (d *Database) GetTimeline(opts options.HistoryOptions) ([]types.TimelineEntry, error) {
+    all := opts
+    all.Last = false
+    all.Limit = 0
+
+    snapshots, err := d.GetHistory(all)
+    if err != nil {
+        return nil, err
+    }
+    ops, err := d.GetOperations(all)
+    if err != nil {
+        return nil, err
+    }
+
+    entries := make([]types.TimelineEntry, 0, len(snapshots)+len(ops))
+    for i := range snapshots {
+        s := &snapshots[i]
+        entries = append(entries, types.TimelineEntry{
+            Kind:          types.TimelineSnapshot,
+            Host:          s.Host,
+            ContainerName: s.ContainerName,
+            Time:          s.CreatedAt,
+            Snapshot:      s,
+        })
+    }
+    for i := range ops {
+        op := &ops[i]
+        entries = append(entries, types.TimelineEntry{
+            Kind:          types.TimelineOperation,
+            Host:          op.Host,
+            ContainerName: op.ContainerName,
+            Time:          op.StartedAt,
+            Operation:     op,
+        })
+    }
+
+    // À date égale, le snapshot de sécurité (pris après le début de l'opération) d'abord
+    sort.SliceStable(entries, func(i, j int) bool {
+        a, b := entries[i], entries[j]
+        if opts.SortBy == "container" && (a.Host != b.Host || a.ContainerName != b.ContainerName) {
+            if a.Host != b.Host {
+                return a.Host < b.Host
+            }
+            return a.ContainerName < b.ContainerName
+        }
+        if !a.Time.Equal(b.Time) {
+            return a.Time.After(b.Time)
+        }
+        return a.Kind == types.TimelineSnapshot && b.Kind != types.TimelineSnapshot
+    })
+
+    if opts.Last {
+        seen := make(map[string]bool)
+        var filtered []types.TimelineEntry
+        for _, entry := range entries {
+            key := entry.Host + "/" + entry.ContainerName
+            if !seen[key] {
+                filtered = append(filtered, entry)
+                seen[key] = true
+            }
+        }
+        entries = filtered
+    }
+
+    if opts.Limit > 0 && len(entries) > opts.Limit {
+        entries = entries[:opts.Limit]
+    }
+
+    return entries, nil
+}
@@ -0,0 +1,164 @@
+// internal/storage/database/operations_test.go
+package database
+
+import (
+    "testing"
+    "time"
+
+    "zockimate/internal/types"
+    "zockimate/internal/types/options"
+)
+
+func TestOperations(t *testing.T) {
+    forEachBackend(t, testOperations)
+}
+
+func testOperations(t *testing.T, db *Database) {
+    t1 := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+
+    op := &types.Operation{
+        Host:          "local",
+        ContainerName: "app",
+        Type:          types.OperationUpdate,
+        TriggeredBy:   types.TriggerSchedule,
+        StartedAt:     t1,
+        FromImage:     "sha256:old",
+    }
+    if err := db.StartOperation(op); err != nil {
+        t.Fatal(err)
+    }
+    if op.ID == 0 || op.Outcome != types.OutcomeRunning {
+        t.Fatalf("started operation = %+v", op)
+    }
+
+    // Opération interrompue : en cours, sans fin
+    ops, err := db.GetOperations(options.HistoryOptions{})
+    if err != nil {
+        t.Fatal(err)
+    }
+    if len(ops) != 1 || ops[0].Outcome != types.OutcomeRunning || ops[0].FinishedAt != nil {
+        t.Fatalf("operations = %+v, want one running operation", ops)
+    }
+
+    op.Outcome = types.OutcomeRolledBack
+    op.Error = "health check failed"
+    op.SnapshotID = 4
+    op.ToImage = "sha256:new"
+    if err := db.FinishOperation(op); err != nil {
+        t.Fatal(err)
+    }
+
+    ops, err = db.GetOperations(options.HistoryOptions{Container: []string{"app"}, Search: "HEALTH"})
+    if err != nil {
+        t.Fatal(err)
+    }
+    if len(ops) != 1 {
+        t.Fatalf("operations = %+v, want the finished operation", ops)
+    }
+    got := ops[0]
+    if got.Outcome != types.OutcomeRolledBack || got.Error != op.Error || got.SnapshotID != 4 ||
+        got.TargetSnapshotID != 0 || got.FromImage != "sha256:old" || got.ToImage != "sha256:new" ||
+        got.TriggeredBy != types.TriggerSchedule || !got.StartedAt.Equal(t1) || got.FinishedAt == nil {
+        t.Errorf("operation = %+v", got)
+    }
+
+    if ops, err := db.GetOperations(options.HistoryOptions{Container: []string{"remote/app"}}); err != nil || len(ops) != 0 {
+        t.Errorf("operations of another host = %+v, %v", ops, err)
+    }
+
+    // Fusion : même conteneur, type et début déjà présents
+    dup := got
+    dup.Error = "other"
+    if merged, err := db.MergeOperation(&dup); err != nil || merged {
+        t.Errorf("MergeOperation(duplicate) = %v, %v, want false", merged, err)
+    }
+    other := got
+    other.StartedAt = t1.Add(time.Hour)
+    other.FinishedAt = nil
+    if merged, err := db.MergeOperation(&other); err != nil || !merged || other.ID == got.ID {
+        t.Errorf("MergeOperation(new) = %v, %v, id %d", merged, err, other.ID)
+    }
+}
+
+func TestSaveImage(t *testing.T) {
+    forEachBackend(t, testSaveImage)
+}
+
+func testSaveImage(t *testing.T, db *Database) {
+    ref := &types.ImageReference{ID: "sha256:abc", Tag: "nginx:1.27", Labels: map[string]string{"a": "b"}}
+    if err := db.SaveImage(ref); err != nil {
+        t.Fatal(err)
+    }
+    // Les valeurs connues ne sont remplacées que par des valeurs non vides
+    if err := db.SaveImage(&types.ImageReference{ID: "sha256:abc", RepoDigest: "nginx@sha256:def"}); err != nil {
+        t.Fatal(err)
+    }
+    if err := db.SaveImage(&types.ImageReference{}); err != nil {
+        t.Errorf("SaveImage without ID = %v", err)
+    }
+
+    var tag, digest, labels string
+    if err := db.db.QueryRow(`SELECT tag, repo_digest, labels FROM images WHERE id = ?`, ref.ID).
+        Scan(&tag, &digest, &labels); err != nil {
+        t.Fatal(err)
+    }
+    if tag != "nginx:1.27" || digest != "nginx@sha256:def" || labels != `{"a":"b"}` {
+        t.Errorf("image = %s, %s, %s", tag, digest, labels)
+    }
+}
+
+func TestGetTimeline(t *testing.T) {
+    forEachBackend(t, testGetTimeline)
+}
+
+func testGetTimeline(t *testing.T, db *Database) {
+    t1 := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+
+    for _, s := range []*types.ContainerSnapshot{
+        testSnapshot("app", t1),
+        testSnapshot("app", t1.Add(time.Hour)),
+        testSnapshot("db", t1.Add(30*time.Minute)),
+    } {
+        if _, _, err := db.MergeSnapshot(s); err != nil {
+            t.Fatal(err)
+        }
+    }
+    // Opération commencée à la date du snapshot de sécurité
+    op := &types.Operation{Host: "local", ContainerName: "app", Type: types.OperationUpdate,
+        TriggeredBy: types.TriggerCLI, StartedAt: t1.Add(time.Hour)}
+    if err := db.StartOperation(op); err != nil {
+        t.Fatal(err)
+    }
+
+    entries, err := db.GetTimeline(options.HistoryOptions{})
+    if err != nil {
+        t.Fatal(err)
+    }
+    want := []struct {
+        kind, name string
+        time       time.Time
+    }{
+        {types.TimelineSnapshot, "app", t1.Add(time.Hour)},
+        {types.TimelineOperation, "app", t1.Add(time.Hour)},
+        {types.TimelineSnapshot, "db", t1.Add(30 * time.Minute)},
+        {types.TimelineSnapshot, "app", t1},
+    }
+    if len(entries) != len(want) {
+        t.Fatalf("timeline = %d entries, want %d", len(entries), len(want))
+    }
+    for i, w := range want {
+        if e := entries[i]; e.Kind != w.kind || e.ContainerName != w.name || !e.Time.Equal(w.time) {
+            t.Errorf("entry %d = %s %s %s, want %s %s %s", i, e.Kind, e.ContainerName, e.Time, w.kind, w.name, w.time)
+        }
+    }
+
+    // Last et Limit portent sur la chronologie fusionnée
+    entries, err = db.GetTimeline(options.HistoryOptions{Last: true, Limit: 1, SortBy: "container"})
+    if err != nil {
+        t.Fatal(err)
+    }
+    if len(entries) != 1 || entries[0].Kind != types.TimelineSnapshot || entries[0].ContainerName != "app" ||
+        !entries[0].Time.Equal(t1.Add(time.Hour)) {
+        t.Errorf("last timeline entry = %+v", entries)
+    }
+}
@@ -0,0 +1,101 @@
+// internal/storage/database/postgres.go
+package database
+
+import (
+    "database/sql"
+    "fmt"
+    "io/fs"
+
+    _ "github.com/lib/pq"
+)
+
+// Clé du verrou consultatif sérialisant les migrations des instances partageant la base
+const postgresSchemaLock = 0x7a6f636b // "zock"
+
+// postgresBackend stocke la base sur un serveur PostgreSQL, partagé par les
+// instances de plusieurs hôtes. Les tables sont créées dans le schéma courant
+// (search_path, ou paramètre search_path de l'URL).
+type postgresBackend struct {
+    dsn string
+}
+
+func newPostgresBackend(dsn string) *postgresBackend {
+    return &postgresBackend{dsn: dsn}
+}
+
+func (b *postgresBackend) Name() string {
+    return postgresScheme
+}
+
+func (b *postgresBackend) Open() (*sql.DB, error) {
+    db, err := sql.Open("postgres", b.dsn)
+    if err != nil {
+        return nil, fmt.Errorf("failed to open database: %w", err)
+    }
+    if err := db.Ping(); err != nil {
+        db.Close()
+        return nil, fmt.Errorf("failed to connect to database: %w", err)
+    }
+    return db, nil
+}
+
+// Exists : la base d'un serveur existe toujours, son schéma est examiné à la connexion
+func (b *postgresBackend) Exists() bool {
+    return true
+}
+
+func (b *postgresBackend) Rebind(query string) string {
+    return rebindNumbered(query)
+}
+
+func (b *postgresBackend) Migrations() fs.FS {
+    sub, _ := fs.Sub(migrationFiles, "migrations/postgres")
+    return sub
+}
+
+func (b *postgresBackend) TableExists(db *sql.DB, name string) (bool, error) {
+    var count int
+    if err := db.QueryRow(`SELECT COUNT(*) FROM information_schema.tables
+        WHERE table_schema = current_schema() AND table_name = $1`, name).Scan(&count); err != nil {
+        return false, fmt.Errorf("failed to inspect schema: %w", err)
+    }
+    return count > 0, nil
+}
+
+// LockSchema prend un verrou consultatif libéré à la fin de la transaction
+func (b *postgresBackend) LockSchema(tx *sql.Tx) error {
+    if _, err := tx.Exec(`SELECT pg_advisory_xact_lock($1)`, postgresSchemaLock); err != nil {
+        return fmt.Errorf("failed to lock schema: %w", err)
+    }
+    return nil
+}
+
+// Backup : les migrations PostgreSQL sont transactionnelles, la sauvegarde du
+// serveur relève de pg_dump
+func (b *postgresBackend) Backup(db *sql.DB, version int) (string, error) {
+    return "", nil
+}
+
+func (b *postgresBackend) ResetSequence(tx *sql.Tx, table string) error {
+    if _, err := tx.Exec(fmt.Sprintf(`SELECT setval(pg_get_serial_sequence('%[1]s', 'id'),
+        (SELECT COALESCE(MAX(id), 0) + 1 FROM %[1]s), false)`, table)); err != nil {
+        return fmt.Errorf("failed to reset %s identifiers: %w", table, err)
+    }
+    return nil
+}
+
+func (b *postgresBackend) Size(db *sql.DB) (int64, error) {
+    var size int64
+    if err := db.QueryRow(`SELECT pg_database_size(current_database())`).Scan(&size); err != nil {
+        return 0, fmt.Errorf("failed to read database size: %w", err)
+    }
+    return size, nil
+}
+
+// Vacuum réécrit les tables pour rendre au système l'espace libéré
+func (b *postgresBackend) Vacuum(db *sql.DB) error {
+    if _, err := db.Exec(`VACUUM FULL`); err != nil {
+        return fmt.Errorf("failed to vacuum database: %w", err)
+    }
+    return nil
+}
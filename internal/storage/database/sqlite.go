@@ -0,0 +1,100 @@
+// internal/storage/database/sqlite.go
+package database
+
+import (
+    "database/sql"
+    "fmt"
+    "io/fs"
+    "os"
+    "path/filepath"
+    "time"
+)
+
+// sqliteBackend stocke la base dans un fichier local
+type sqliteBackend struct {
+    path string
+}
+
+func newSQLiteBackend(path string) *sqliteBackend {
+    return &sqliteBackend{path: path}
+}
+
+func (b *sqliteBackend) Name() string {
+    return sqliteScheme
+}
+
+func (b *sqliteBackend) Open() (*sql.DB, error) {
+    // Créer le répertoire si nécessaire
+    if err := os.MkdirAll(filepath.Dir(b.path), 0755); err != nil {
+        return nil, fmt.Errorf("failed to create database directory: %w", err)
+    }
+    db, err := sql.Open(sqliteDriver, b.path)
+    if err != nil {
+        return nil, fmt.Errorf("failed to open database: %w", err)
+    }
+    return db, nil
+}
+
+func (b *sqliteBackend) Exists() bool {
+    _, err := os.Stat(b.path)
+    return err == nil
+}
+
+func (b *sqliteBackend) Rebind(query string) string {
+    return query
+}
+
+func (b *sqliteBackend) Migrations() fs.FS {
+    sub, _ := fs.Sub(migrationFiles, "migrations/sqlite")
+    return sub
+}
+
+func (b *sqliteBackend) TableExists(db *sql.DB, name string) (bool, error) {
+    var count int
+    if err := db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?`, name).
+        Scan(&count); err != nil {
+        return false, fmt.Errorf("failed to inspect schema: %w", err)
+    }
+    return count > 0, nil
+}
+
+// LockSchema : les écritures SQLite sont déjà sérialisées par le verrou du fichier
+func (b *sqliteBackend) LockSchema(tx *sql.Tx) error {
+    return nil
+}
+
+// Backup copie la base à côté du fichier (VACUUM INTO : copie cohérente)
+func (b *sqliteBackend) Backup(db *sql.DB, version int) (string, error) {
+    backup := fmt.Sprintf("%s.v%d-%s.bak", b.path, version, time.Now().Format("20060102_150405"))
+    if _, err := os.Stat(backup); err == nil {
+        return "", fmt.Errorf("backup file %s already exists", backup)
+    }
+    if _, err := db.Exec(`VACUUM INTO ?`, backup); err != nil {
+        return "", fmt.Errorf("failed to back up database before migration: %w", err)
+    }
+    return backup, nil
+}
+
+// ResetSequence : AUTOINCREMENT repart déjà du plus grand identifiant inséré
+func (b *sqliteBackend) ResetSequence(tx *sql.Tx, table string) error {
+    return nil
+}
+
+// Size retourne la taille du fichier (pages libres comprises)
+func (b *sqliteBackend) Size(db *sql.DB) (int64, error) {
+    var pages, pageSize int64
+    if err := db.QueryRow(`PRAGMA page_count`).Scan(&pages); err != nil {
+        return 0, fmt.Errorf("failed to read database size: %w", err)
+    }
+    if err := db.QueryRow(`PRAGMA page_size`).Scan(&pageSize); err != nil {
+        return 0, fmt.Errorf("failed to read database size: %w", err)
+    }
+    return pages * pageSize, nil
+}
+
+func (b *sqliteBackend) Vacuum(db *sql.DB) error {
+    if _, err := db.Exec(`VACUUM`); err != nil {
+        return fmt.Errorf("failed to vacuum database: %w", err)
+    }
+    return nil
+}
@@ -0,0 +1,11 @@
+//go:build cgo
+
+// internal/storage/database/sqlite_cgo.go
+package database
+
+import (
+    _ "github.com/mattn/go-sqlite3"
+)
+
+// Pilote SQLite : go-sqlite3, qui compile SQLite via CGO
+const sqliteDriver = "sqlite3"
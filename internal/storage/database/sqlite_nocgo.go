@@ -0,0 +1,12 @@
+//go:build !cgo
+
+// internal/storage/database/sqlite_nocgo.go
+package database
+
+import (
+    _ "github.com/glebarez/go-sqlite"
+)
+
+// Pilote SQLite sans CGO (binaire statique, CGO_ENABLED=0) : go-sqlite, qui
+// embarque SQLite traduit en Go (modernc.org/sqlite)
+const sqliteDriver = "sqlite"
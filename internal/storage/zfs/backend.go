@@ -0,0 +1,28 @@
+// internal/storage/zfs/backend.go
+package zfs
+
+// Backend regroupe les opérations ZFS élémentaires sur lesquelles repose ZFSManager.
+// Trois implémentations : la commande zfs (locale ou via SSH), les ioctls de
+// /dev/zfs (Linux, hôte local) et un backend en mémoire pour les tests.
+// Les opérations composées (clone, rename, promote, send/receive) passent
+// toujours par la commande zfs.
+type Backend interface {
+    // Snapshot crée atomiquement un groupe de snapshots (dataset@nom) portant les
+    // propriétés utilisateur props : en cas d'erreur, aucun n'est créé
+    Snapshot(snapshots []string, props map[string]string) error
+    // Destroy détruit un snapshot
+    Destroy(snapshot string) error
+    // Rollback restaure le dataset vers son snapshot le plus récent
+    Rollback(snapshot string) error
+    // Exists indique si un dataset ou un snapshot existe
+    Exists(name string) (bool, error)
+    // Datasets retourne root et ses datasets descendants
+    Datasets(root string) ([]string, error)
+    // Snapshots retourne les snapshots directs d'un dataset par ordre de création
+    Snapshots(dataset string) ([]string, error)
+    // GetProperty retourne la valeur d'une propriété et sa source telles
+    // qu'affichées par "zfs get" (local, default, inherited from ..., -)
+    GetProperty(name, property string) (value string, source string, err error)
+    // SetProperties pose des propriétés sur un dataset ou un snapshot
+    SetProperties(name string, props map[string]string) error
+}
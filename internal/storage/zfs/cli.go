@@ -0,0 +1,146 @@
+// internal/storage/zfs/cli.go
+package zfs
+
+import (
+    "context"
+    "fmt"
+    "net"
+    "os/exec"
+    "sort"
+    "strings"
+    "time"
+)
+
+// Durée maximale par défaut d'une commande zfs
+const DefaultTimeout = 5 * time.Minute
+
+// cliBackend exécute la commande zfs, localement ou via SSH
+type cliBackend struct {
+    ssh     string        // Cible SSH (user@host[:port]), vide pour une exécution locale
+    timeout time.Duration // Durée maximale d'une commande
+}
+
+// command prépare une commande zfs, locale ou distante
+func (c *cliBackend) command(ctx context.Context, args ...string) *exec.Cmd {
+    if c.ssh == "" {
+        return exec.CommandContext(ctx, "zfs", args...)
+    }
+
+    sshArgs := []string{"-o", "BatchMode=yes", "-o", "ConnectTimeout=10"}
+    target, port := splitSSHTarget(c.ssh)
+    if port != "" {
+        sshArgs = append(sshArgs, "-p", port)
+    }
+    // ssh transmet la commande à un shell distant : protéger chaque argument
+    sshArgs = append(sshArgs, "--", target, "zfs")
+    for _, arg := range args {
+        sshArgs = append(sshArgs, shellQuote(arg))
+    }
+    return exec.CommandContext(ctx, "ssh", sshArgs...)
+}
+
+// splitSSHTarget sépare une cible user@host[:port] en destination ssh (user@host)
+// et port ; les adresses IPv6 s'écrivent entre crochets ([::1]:22)
+func splitSSHTarget(target string) (dest, port string) {
+    user, host := "", target
+    if i := strings.LastIndex(target, "@"); i >= 0 {
+        user, host = target[:i+1], target[i+1:]
+    }
+    if h, p, err := net.SplitHostPort(host); err == nil {
+        host, port = h, p
+    }
+    host = strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+    return user + host, port
+}
+
+// run exécute une commande zfs et retourne sa sortie standard
+func (c *cliBackend) run(args ...string) (string, error) {
+    ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+    defer cancel()
+
+    var stderr strings.Builder
+    cmd := c.command(ctx, args...)
+    cmd.Stderr = &stderr
+    out, err := cmd.Output()
+    if err != nil {
+        if ctx.Err() == context.DeadlineExceeded {
+            return string(out), fmt.Errorf("zfs %s timed out after %s", args[0], c.timeout)
+        }
+        return string(out), fmt.Errorf("zfs %s failed: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+    }
+    return string(out), nil
+}
+
+// shellQuote protège un argument pour un shell POSIX
+func shellQuote(arg string) string {
+    if arg != "" && strings.Trim(arg, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789@%_-+=:,./") == "" {
+        return arg
+    }
+    return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
+
+func (c *cliBackend) Snapshot(snapshots []string, props map[string]string) error {
+    args := append([]string{"snapshot"}, propertyArgs(props)...)
+    _, err := c.run(append(args, snapshots...)...)
+    return err
+}
+
+func (c *cliBackend) Destroy(snapshot string) error {
+    _, err := c.run("destroy", snapshot)
+    return err
+}
+
+func (c *cliBackend) Rollback(snapshot string) error {
+    _, err := c.run("rollback", snapshot)
+    return err
+}
+
+func (c *cliBackend) Exists(name string) (bool, error) {
+    if _, err := c.run("list", "-H", "-o", "name", "-t", "all", name); err != nil {
+        if strings.Contains(err.Error(), "does not exist") {
+            return false, nil
+        }
+        return false, err
+    }
+    return true, nil
+}
+
+func (c *cliBackend) Datasets(root string) ([]string, error) {
+    out, err := c.run("list", "-H", "-o", "name", "-t", "filesystem,volume", "-r", root)
+    if err != nil {
+        return nil, err
+    }
+    return strings.Fields(out), nil
+}
+
+func (c *cliBackend) Snapshots(dataset string) ([]string, error) {
+    out, err := c.run("list", "-H", "-o", "name", "-t", "snapshot", "-s", "createtxg", "-d", "1", dataset)
+    if err != nil {
+        return nil, err
+    }
+    return strings.Fields(out), nil
+}
+
+func (c *cliBackend) GetProperty(name, property string) (string, string, error) {
+    out, err := c.run("get", "-H", "-o", "value,source", property, name)
+    if err != nil {
+        return "", "", err
+    }
+    value, source, _ := strings.Cut(strings.TrimSpace(out), "\t")
+    return value, source, nil
+}
+
+func (c *cliBackend) SetProperties(name string, props map[string]string) error {
+    keys := make([]string, 0, len(props))
+    for k := range props {
+        keys = append(keys, k)
+    }
+    sort.Strings(keys)
+
+    args := []string{"set"}
+    for _, k := range keys {
+        args = append(args, k+"="+props[k])
+    }
+    _, err := c.run(append(args, name)...)
+    return err
+}
@@ -0,0 +1,30 @@
+// internal/storage/zfs/cli_test.go
+package zfs
+
+import (
+    "context"
+    "reflect"
+    "testing"
+)
+
+func TestCLICommand(t *testing.T) {
+    ssh := []string{"ssh", "-o", "BatchMode=yes", "-o", "ConnectTimeout=10"}
+    tests := []struct {
+        target string
+        want   []string
+    }{
+        {"", []string{"zfs", "list", "tank/app data"}},
+        {"nas", append(ssh, "--", "nas", "zfs", "list", "'tank/app data'")},
+        {"nas:2222", append(ssh, "-p", "2222", "--", "nas", "zfs", "list", "'tank/app data'")},
+        {"backup@nas:2222", append(ssh, "-p", "2222", "--", "backup@nas", "zfs", "list", "'tank/app data'")},
+        {"backup@[fd00::1]:2222", append(ssh, "-p", "2222", "--", "backup@fd00::1", "zfs", "list", "'tank/app data'")},
+        {"backup@fd00::1", append(ssh, "--", "backup@fd00::1", "zfs", "list", "'tank/app data'")},
+    }
+    for _, tt := range tests {
+        c := &cliBackend{ssh: tt.target}
+        got := c.command(context.Background(), "list", "tank/app data").Args
+        if !reflect.DeepEqual(got, tt.want) {
+            t.Errorf("command(%q) = %q, want %q", tt.target, got, tt.want)
+        }
+    }
+}
@@ -0,0 +1,60 @@
+// internal/storage/zfs/clone.go
+package zfs
+
+import (
+    "fmt"
+    "strings"
+)
+
+// Propriété utilisateur désignant, sur un clone créé pour un conteneur de test,
+// le conteneur qui l'utilise
+const cloneProperty = "zockimate:clone"
+
+// CloneSnapshot crée un clone modifiable d'un snapshot pour le conteneur owner et
+// retourne son point de montage. Le clone est monté sous le point de montage hérité
+// de son parent : un dataset au point de montage explicite n'est pas masqué.
+func (z *ZFSManager) CloneSnapshot(snapshot, clone, owner string) (string, error) {
+    if _, err := z.run("clone", "-o", cloneProperty+"="+owner, snapshot, clone); err != nil {
+        return "", fmt.Errorf("failed to clone %s: %w", snapshot, err)
+    }
+    mountpoint, err := z.getProperty(clone, "mountpoint")
+    if err != nil {
+        z.destroyQuietly(clone)
+        return "", err
+    }
+    if !strings.HasPrefix(mountpoint, "/") {
+        z.destroyQuietly(clone)
+        return "", fmt.Errorf("clone %s has no usable mountpoint (%s)", clone, mountpoint)
+    }
+    return mountpoint, nil
+}
+
+// Clones liste les clones créés pour le conteneur owner
+func (z *ZFSManager) Clones(owner string) ([]string, error) {
+    out, err := z.run("get", "-H", "-o", "name,value", "-s", "local", "-t", "filesystem", cloneProperty)
+    if err != nil {
+        return nil, err
+    }
+    var clones []string
+    for _, line := range strings.Split(out, "\n") {
+        if name, value, ok := strings.Cut(line, "\t"); ok && value == owner {
+            clones = append(clones, name)
+        }
+    }
+    return clones, nil
+}
+
+// DestroyClone détruit un clone créé par CloneSnapshot, avec ses éventuels snapshots
+func (z *ZFSManager) DestroyClone(clone string) error {
+    _, source, err := z.getPropertySource(clone, cloneProperty)
+    if err != nil {
+        return err
+    }
+    if source != "local" {
+        return fmt.Errorf("%s is not a zockimate clone", clone)
+    }
+    if _, err := z.run("destroy", "-r", clone); err != nil {
+        return fmt.Errorf("failed to destroy %s: %w", clone, err)
+    }
+    return nil
+}
@@ -0,0 +1,210 @@
+// internal/storage/zfs/fake.go
+package zfs
+
+import (
+    "fmt"
+    "sort"
+    "strings"
+    "sync"
+
+    "github.com/sirupsen/logrus"
+)
+
+// FakeBackend est un backend ZFS en mémoire pour les tests unitaires : il simule
+// datasets, snapshots (ordonnés par txg de création) et propriétés utilisateur,
+// sans contenu de fichiers. Les erreurs reprennent les messages de la commande zfs.
+type FakeBackend struct {
+    mu        sync.Mutex
+    txg       uint64
+    datasets  map[string]bool
+    snapshots map[string]uint64            // Snapshot -> txg de création
+    props     map[string]map[string]string // Dataset ou snapshot -> propriétés locales
+}
+
+// NewFakeBackend crée un backend en mémoire contenant les datasets donnés
+func NewFakeBackend(datasets ...string) *FakeBackend {
+    f := &FakeBackend{
+        datasets:  make(map[string]bool),
+        snapshots: make(map[string]uint64),
+        props:     make(map[string]map[string]string),
+    }
+    for _, d := range datasets {
+        f.AddDataset(d)
+    }
+    return f
+}
+
+// NewFakeZFSManager crée un gestionnaire ZFS reposant sur un backend en mémoire
+func NewFakeZFSManager(f *FakeBackend, logger *logrus.Logger) *ZFSManager {
+    z := NewZFSManager(logger)
+    z.SetBackend(f)
+    return z
+}
+
+// NewFakeReplica crée une destination de réplication reposant sur un backend en mémoire
+func NewFakeReplica(root string, f *FakeBackend, logger *logrus.Logger) *Replica {
+    return &Replica{zfs: NewFakeZFSManager(f, logger), root: strings.TrimSuffix(root, "/")}
+}
+
+// AddDataset crée un dataset (et ses parents)
+func (f *FakeBackend) AddDataset(name string) {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+    for i := range name {
+        if name[i] == '/' {
+            f.datasets[name[:i]] = true
+        }
+    }
+    f.datasets[name] = true
+}
+
+func (f *FakeBackend) exists(name string) bool {
+    _, ok := f.snapshots[name]
+    return ok || f.datasets[name]
+}
+
+func (f *FakeBackend) Snapshot(snapshots []string, props map[string]string) error {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+
+    // Tout valider avant de créer : l'opération est atomique
+    seen := make(map[string]bool)
+    for _, s := range snapshots {
+        dataset, name, ok := strings.Cut(s, "@")
+        if !ok || name == "" {
+            return fmt.Errorf("cannot create snapshot '%s': invalid snapshot name", s)
+        }
+        if !f.datasets[dataset] {
+            return fmt.Errorf("cannot open '%s': dataset does not exist", dataset)
+        }
+        if f.exists(s) || seen[s] {
+            return fmt.Errorf("cannot create snapshot '%s': dataset already exists", s)
+        }
+        seen[s] = true
+    }
+    for k := range props {
+        if !strings.Contains(k, ":") {
+            return fmt.Errorf("property '%s' is not a user property", k)
+        }
+    }
+
+    f.txg++
+    for _, s := range snapshots {
+        f.snapshots[s] = f.txg
+        if len(props) > 0 {
+            f.props[s] = make(map[string]string)
+            for k, v := range props {
+                f.props[s][k] = v
+            }
+        }
+    }
+    return nil
+}
+
+func (f *FakeBackend) Destroy(snapshot string) error {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+    if _, ok := f.snapshots[snapshot]; !ok {
+        return fmt.Errorf("could not find any snapshots to destroy; check snapshot names")
+    }
+    delete(f.snapshots, snapshot)
+    delete(f.props, snapshot)
+    return nil
+}
+
+func (f *FakeBackend) Rollback(snapshot string) error {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+    txg, ok := f.snapshots[snapshot]
+    if !ok {
+        return fmt.Errorf("cannot open '%s': dataset does not exist", snapshot)
+    }
+    dataset, _, _ := strings.Cut(snapshot, "@")
+    for s, t := range f.snapshots {
+        if strings.HasPrefix(s, dataset+"@") && t > txg {
+            return fmt.Errorf("cannot rollback to '%s': more recent snapshots or bookmarks exist", snapshot)
+        }
+    }
+    return nil
+}
+
+func (f *FakeBackend) Exists(name string) (bool, error) {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+    return f.exists(name), nil
+}
+
+func (f *FakeBackend) Datasets(root string) ([]string, error) {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+    if !f.datasets[root] {
+        return nil, fmt.Errorf("cannot open '%s': dataset does not exist", root)
+    }
+    var children []string
+    for d := range f.datasets {
+        if strings.HasPrefix(d, root+"/") {
+            children = append(children, d)
+        }
+    }
+    sort.Strings(children)
+    return append([]string{root}, children...), nil
+}
+
+func (f *FakeBackend) Snapshots(dataset string) ([]string, error) {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+    if !f.datasets[dataset] {
+        return nil, fmt.Errorf("cannot open '%s': dataset does not exist", dataset)
+    }
+    var snapshots []string
+    for s := range f.snapshots {
+        if strings.HasPrefix(s, dataset+"@") {
+            snapshots = append(snapshots, s)
+        }
+    }
+    sort.Slice(snapshots, func(i, j int) bool {
+        return f.snapshots[snapshots[i]] < f.snapshots[snapshots[j]]
+    })
+    return snapshots, nil
+}
+
+func (f *FakeBackend) GetProperty(name, property string) (string, string, error) {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+    if !f.exists(name) {
+        return "", "", fmt.Errorf("cannot open '%s': dataset does not exist", name)
+    }
+
+    // Héritage : le snapshot, puis son dataset et ses parents
+    for current := name; current != ""; {
+        if v, ok := f.props[current][property]; ok {
+            if current == name {
+                return v, "local", nil
+            }
+            return v, "inherited from " + current, nil
+        }
+        if dataset, _, ok := strings.Cut(current, "@"); ok {
+            current = dataset
+        } else if i := strings.LastIndex(current, "/"); i >= 0 {
+            current = current[:i]
+        } else {
+            current = ""
+        }
+    }
+    return "-", "-", nil
+}
+
+func (f *FakeBackend) SetProperties(name string, props map[string]string) error {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+    if !f.exists(name) {
+        return fmt.Errorf("cannot open '%s': dataset does not exist", name)
+    }
+    if f.props[name] == nil {
+        f.props[name] = make(map[string]string)
+    }
+    for k, v := range props {
+        f.props[name][k] = v
+    }
+    return nil
+}
@@ -0,0 +1,367 @@
+//go:build linux
+
+// internal/storage/zfs/native_linux.go
+package zfs
+
+import (
+    "bytes"
+    "fmt"
+    "runtime"
+    "sort"
+    "strings"
+    "syscall"
+    "unsafe"
+
+    "github.com/sirupsen/logrus"
+)
+
+const zfsDevice = "/dev/zfs"
+
+// Numéros des ioctls (enum zfs_ioc, sys/fs/zfs.h), stables depuis OpenZFS 0.7
+const (
+    ioctlObjsetStats      = 0x5a12
+    ioctlDatasetListNext  = 0x5a14
+    ioctlSnapshotListNext = 0x5a15
+    ioctlSetProp          = 0x5a16
+    ioctlRollback         = 0x5a19
+    ioctlSnapshot         = 0x5a23
+    ioctlDestroySnaps     = 0x5a3b
+)
+
+// Champs utilisés de zfs_cmd_t (sys/zfs_ioctl.h)
+const (
+    zcNameLen       = 4096
+    zcNvlistSrc     = 4096
+    zcNvlistSrcSize = 4104
+    zcNvlistDst     = 4112
+    zcNvlistDstSize = 4120
+    zcSize          = 32 * 1024 // Supérieur à sizeof(zfs_cmd_t) quelle que soit la version
+)
+
+// Taille initiale du tampon de sortie ; agrandi si le module répond ENOMEM
+const zcDstSize = 64 * 1024
+
+// nativeBackend dialogue directement avec le module ZFS via les ioctls de /dev/zfs.
+// Chaque opération repasse par la commande zfs en cas d'échec : messages d'erreur
+// explicites et compatibilité avec les versions dont zfs_cmd_t diffère.
+// Les propriétés natives (mountpoint, canmount...) sont toujours lues et écrites
+// par la commande zfs, qui seule sait les interpréter.
+type nativeBackend struct {
+    fd     int
+    cli    *cliBackend
+    logger *logrus.Logger
+}
+
+// newNativeBackend ouvre /dev/zfs
+func newNativeBackend(cli *cliBackend, logger *logrus.Logger) (Backend, error) {
+    fd, err := syscall.Open(zfsDevice, syscall.O_RDWR|syscall.O_CLOEXEC, 0)
+    if err != nil {
+        return nil, fmt.Errorf("cannot open %s: %w", zfsDevice, err)
+    }
+    return &nativeBackend{fd: fd, cli: cli, logger: logger}, nil
+}
+
+func (n *nativeBackend) Close() error {
+    return syscall.Close(n.fd)
+}
+
+// fallback journalise l'échec d'une opération native avant de repasser par la commande zfs
+func (n *nativeBackend) fallback(op string, err error) {
+    n.logger.Debugf("Native ZFS %s failed (%v), falling back to zfs command", op, err)
+}
+
+func (n *nativeBackend) Snapshot(snapshots []string, props map[string]string) error {
+    if len(snapshots) == 0 {
+        return nil
+    }
+    snaps := make(map[string]interface{})
+    for _, s := range snapshots {
+        snaps[s] = true
+    }
+    args := map[string]interface{}{"snaps": snaps}
+    if len(props) > 0 {
+        args["props"] = stringNvlist(props)
+    }
+
+    if _, err := n.call(ioctlSnapshot, poolName(snapshots[0]), args, true); err != nil {
+        n.fallback("snapshot", err)
+        return n.cli.Snapshot(snapshots, props)
+    }
+    return nil
+}
+
+func (n *nativeBackend) Destroy(snapshot string) error {
+    // ZFS_IOC_DESTROY_SNAPS ignore les snapshots inexistants, contrairement à zfs destroy
+    if exists, err := n.Exists(snapshot); err != nil || !exists {
+        return n.cli.Destroy(snapshot)
+    }
+    args := map[string]interface{}{"snaps": map[string]interface{}{snapshot: true}}
+    if _, err := n.call(ioctlDestroySnaps, poolName(snapshot), args, true); err != nil {
+        n.fallback("destroy", err)
+        return n.cli.Destroy(snapshot)
+    }
+    return nil
+}
+
+func (n *nativeBackend) Rollback(snapshot string) error {
+    dataset, _, _ := strings.Cut(snapshot, "@")
+    // Le module refuse (EXDEV) si snapshot n'est pas le plus récent
+    if _, err := n.call(ioctlRollback, dataset, map[string]interface{}{"target": snapshot}, true); err != nil {
+        n.fallback("rollback", err)
+        return n.cli.Rollback(snapshot)
+    }
+    return nil
+}
+
+func (n *nativeBackend) Exists(name string) (bool, error) {
+    _, err := n.call(ioctlObjsetStats, name, nil, false)
+    if err == syscall.ENOENT {
+        return false, nil
+    } else if err != nil {
+        n.fallback("list", err)
+        return n.cli.Exists(name)
+    }
+    return true, nil
+}
+
+func (n *nativeBackend) Datasets(root string) ([]string, error) {
+    if exists, err := n.Exists(root); err != nil || !exists {
+        return n.cli.Datasets(root)
+    }
+    datasets := []string{root}
+    for i := 0; i < len(datasets); i++ {
+        children, err := n.listNext(ioctlDatasetListNext, datasets[i])
+        if err != nil {
+            n.fallback("list", err)
+            return n.cli.Datasets(root)
+        }
+        sort.Strings(children)
+        datasets = append(datasets, children...)
+    }
+    return datasets, nil
+}
+
+func (n *nativeBackend) Snapshots(dataset string) ([]string, error) {
+    snapshots, err := n.snapshots(dataset)
+    if err != nil {
+        n.fallback("list", err)
+        return n.cli.Snapshots(dataset)
+    }
+    return snapshots, nil
+}
+
+// snapshots liste les snapshots d'un dataset, triés par createtxg
+func (n *nativeBackend) snapshots(dataset string) ([]string, error) {
+    names, err := n.listNext(ioctlSnapshotListNext, dataset)
+    if err != nil {
+        return nil, err
+    }
+    txg := make(map[string]uint64, len(names))
+    for _, name := range names {
+        props, err := n.objsetProps(name)
+        if err != nil {
+            return nil, err
+        }
+        txg[name], _ = propValue(props, "createtxg").(uint64)
+    }
+    sort.SliceStable(names, func(i, j int) bool {
+        return txg[names[i]] < txg[names[j]]
+    })
+    return names, nil
+}
+
+func (n *nativeBackend) GetProperty(name, property string) (string, string, error) {
+    if !strings.Contains(property, ":") {
+        return n.cli.GetProperty(name, property)
+    }
+    props, err := n.objsetProps(name)
+    if err != nil {
+        n.fallback("get", err)
+        return n.cli.GetProperty(name, property)
+    }
+    value, ok := propValue(props, property).(string)
+    if !ok {
+        return "-", "-", nil
+    }
+    prop, _ := props[property].(map[string]interface{})
+    switch source, _ := prop["source"].(string); source {
+    case name, "":
+        return value, "local", nil
+    case "$recvd":
+        return value, "received", nil
+    default:
+        return value, "inherited from " + source, nil
+    }
+}
+
+func (n *nativeBackend) SetProperties(name string, props map[string]string) error {
+    for k := range props {
+        if !strings.Contains(k, ":") {
+            return n.cli.SetProperties(name, props)
+        }
+    }
+    if _, err := n.call(ioctlSetProp, name, stringNvlist(props), false); err != nil {
+        n.fallback("set", err)
+        return n.cli.SetProperties(name, props)
+    }
+    return nil
+}
+
+// objsetProps retourne les propriétés d'un dataset ou d'un snapshot :
+// nom -> {value, source}
+func (n *nativeBackend) objsetProps(name string) (map[string]interface{}, error) {
+    zc, err := n.call(ioctlObjsetStats, name, nil, true)
+    if err != nil {
+        return nil, err
+    }
+    return zc.destination()
+}
+
+// listNext énumère les enfants directs (datasets ou snapshots) de parent ;
+// le module reprend l'énumération à partir de zc_cookie
+func (n *nativeBackend) listNext(req uintptr, parent string) ([]string, error) {
+    zc, err := newZfsCmd(parent)
+    if err != nil {
+        return nil, err
+    }
+    var names []string
+    seen := make(map[string]bool)
+    for {
+        err := n.ioctl(req, zc)
+        if err == syscall.ESRCH {
+            return names, nil
+        } else if err != nil {
+            return nil, err
+        }
+        name := zc.name()
+        if seen[name] {
+            return nil, fmt.Errorf("listing of %s does not advance", parent)
+        }
+        seen[name] = true
+        names = append(names, name)
+        zc.setName(parent)
+    }
+}
+
+// poolName retourne le pool d'un dataset ou d'un snapshot
+func poolName(name string) string {
+    if i := strings.IndexAny(name, "/@"); i >= 0 {
+        return name[:i]
+    }
+    return name
+}
+
+// stringNvlist convertit des propriétés en nvlist
+func stringNvlist(props map[string]string) map[string]interface{} {
+    nvl := make(map[string]interface{}, len(props))
+    for k, v := range props {
+        nvl[k] = v
+    }
+    return nvl
+}
+
+// propValue retourne la valeur d'une propriété de ZFS_IOC_OBJSET_STATS ({value, source})
+func propValue(props map[string]interface{}, property string) interface{} {
+    prop, _ := props[property].(map[string]interface{})
+    return prop["value"]
+}
+
+// call exécute un ioctl avec une nvlist d'arguments (nil : aucune). Avec output,
+// un tampon reçoit la nvlist produite par le module (zfsCmd.destination).
+func (n *nativeBackend) call(req uintptr, name string, args map[string]interface{}, output bool) (*zfsCmd, error) {
+    zc, err := newZfsCmd(name)
+    if err != nil {
+        return nil, err
+    }
+    if args != nil {
+        if err := zc.setSource(args); err != nil {
+            return nil, err
+        }
+    }
+
+    size := zcDstSize
+    for attempt := 0; ; attempt++ {
+        if output {
+            zc.setDestination(size)
+        }
+        err := n.ioctl(req, zc)
+        if err == syscall.ENOMEM && output && attempt < 3 {
+            // zc_nvlist_dst_size contient la taille nécessaire
+            size = int(zc.uint64(zcNvlistDstSize)) + zcDstSize
+            zc.setName(name)
+            continue
+        }
+        return zc, err
+    }
+}
+
+func (n *nativeBackend) ioctl(req uintptr, zc *zfsCmd) error {
+    _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(n.fd), req, uintptr(unsafe.Pointer(&zc.buf[0])))
+    runtime.KeepAlive(zc)
+    if errno != 0 {
+        return errno
+    }
+    return nil
+}
+
+// zfsCmd est un zfs_cmd_t et les tampons de nvlist qu'il référence
+type zfsCmd struct {
+    buf []byte
+    src []byte
+    dst []byte
+}
+
+func newZfsCmd(name string) (*zfsCmd, error) {
+    if len(name) >= zcNameLen {
+        return nil, fmt.Errorf("name too long: %s", name)
+    }
+    zc := &zfsCmd{buf: make([]byte, zcSize)}
+    zc.setName(name)
+    return zc, nil
+}
+
+func (zc *zfsCmd) setName(name string) {
+    clear(zc.buf[:zcNameLen])
+    copy(zc.buf, name)
+}
+
+func (zc *zfsCmd) name() string {
+    name := zc.buf[:zcNameLen]
+    if i := bytes.IndexByte(name, 0); i >= 0 {
+        name = name[:i]
+    }
+    return string(name)
+}
+
+func (zc *zfsCmd) uint64(offset int) uint64 {
+    return nvOrder.Uint64(zc.buf[offset:])
+}
+
+func (zc *zfsCmd) putUint64(offset int, v uint64) {
+    nvOrder.PutUint64(zc.buf[offset:], v)
+}
+
+func (zc *zfsCmd) setSource(nvl map[string]interface{}) error {
+    packed, err := packNvlist(nvl)
+    if err != nil {
+        return err
+    }
+    zc.src = packed
+    zc.putUint64(zcNvlistSrc, uint64(uintptr(unsafe.Pointer(&packed[0]))))
+    zc.putUint64(zcNvlistSrcSize, uint64(len(packed)))
+    return nil
+}
+
+func (zc *zfsCmd) setDestination(size int) {
+    zc.dst = make([]byte, size)
+    zc.putUint64(zcNvlistDst, uint64(uintptr(unsafe.Pointer(&zc.dst[0]))))
+    zc.putUint64(zcNvlistDstSize, uint64(size))
+}
+
+func (zc *zfsCmd) destination() (map[string]interface{}, error) {
+    size := int(zc.uint64(zcNvlistDstSize))
+    if size == 0 || size > len(zc.dst) {
+        return map[string]interface{}{}, nil
+    }
+    return unpackNvlist(zc.dst[:size])
+}
@@ -0,0 +1,15 @@
+//go:build !linux
+
+// internal/storage/zfs/native_other.go
+package zfs
+
+import (
+    "fmt"
+
+    "github.com/sirupsen/logrus"
+)
+
+// newNativeBackend : les ioctls de /dev/zfs ne sont implémentés que sous Linux
+func newNativeBackend(cli *cliBackend, logger *logrus.Logger) (Backend, error) {
+    return nil, fmt.Errorf("native ZFS backend is only supported on Linux")
+}
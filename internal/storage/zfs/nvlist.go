@@ -0,0 +1,210 @@
+// internal/storage/zfs/nvlist.go
+package zfs
+
+import (
+    "encoding/binary"
+    "fmt"
+    "sort"
+)
+
+// Encodage des nvlists échangées avec le module ZFS (encodage NV_ENCODE_NATIVE,
+// voir nvpair.c). Seuls les types utiles à zockimate sont encodés : bool (paire
+// BOOLEAN sans valeur), uint64, string et nvlist imbriquée (map[string]interface{}).
+// Au décodage, les types non gérés sont ignorés.
+
+// Types de paires (sys/nvpair.h)
+const (
+    nvTypeBoolean      = 1
+    nvTypeUint64       = 8
+    nvTypeString       = 9
+    nvTypeNvlist       = 19
+    nvTypeNvlistArray  = 20
+    nvTypeBooleanValue = 21
+)
+
+const (
+    nvEncodeNative = 0
+    nvUniqueName   = 1
+    nvPairHeader   = 16 // nvp_size, nvp_name_sz, nvp_reserve, nvp_value_elem, nvp_type
+    nvlistSize     = 24 // nvlist_t embarqué dans la valeur d'une paire NVLIST
+)
+
+var nvOrder = binary.NativeEndian
+
+// nvHostEndian est l'octet d'en-tête désignant l'ordre des octets de la machine
+func nvHostEndian() byte {
+    if nvOrder.Uint16([]byte{1, 0}) == 1 {
+        return 1
+    }
+    return 0
+}
+
+func nvAlign(n int) int {
+    return (n + 7) &^ 7
+}
+
+// packNvlist encode une nvlist
+func packNvlist(nvl map[string]interface{}) ([]byte, error) {
+    buf := []byte{nvEncodeNative, nvHostEndian(), 0, 0}
+    return appendNvlist(buf, nvl)
+}
+
+func appendNvlist(buf []byte, nvl map[string]interface{}) ([]byte, error) {
+    buf = nvOrder.AppendUint32(buf, 0) // nvl_version
+    buf = nvOrder.AppendUint32(buf, nvUniqueName)
+
+    keys := make([]string, 0, len(nvl))
+    for k := range nvl {
+        keys = append(keys, k)
+    }
+    sort.Strings(keys)
+
+    for _, k := range keys {
+        var err error
+        if buf, err = appendNvpair(buf, k, nvl[k]); err != nil {
+            return nil, err
+        }
+    }
+    // Quatre octets nuls marquent la fin de la liste
+    return nvOrder.AppendUint32(buf, 0), nil
+}
+
+func appendNvpair(buf []byte, name string, value interface{}) ([]byte, error) {
+    var (
+        typ      uint32
+        nelem    uint32 = 1
+        data     []byte
+        embedded map[string]interface{}
+    )
+    switch v := value.(type) {
+    case bool:
+        typ, nelem = nvTypeBoolean, 0
+    case uint64:
+        typ, data = nvTypeUint64, nvOrder.AppendUint64(nil, v)
+    case string:
+        typ, data = nvTypeString, append([]byte(v), 0)
+    case map[string]interface{}:
+        // nvlist_t : nvl_version, nvl_nvflag, nvl_priv, nvl_flag, nvl_pad
+        typ, embedded = nvTypeNvlist, v
+        data = make([]byte, nvlistSize)
+        nvOrder.PutUint32(data[4:], nvUniqueName)
+    default:
+        return nil, fmt.Errorf("unsupported nvlist value type %T for %s", value, name)
+    }
+
+    nameSize := len(name) + 1
+    valueOffset := nvAlign(nvPairHeader + nameSize)
+    size := valueOffset + nvAlign(len(data))
+
+    pair := make([]byte, size)
+    nvOrder.PutUint32(pair[0:], uint32(size))
+    nvOrder.PutUint16(pair[4:], uint16(nameSize))
+    nvOrder.PutUint32(pair[8:], nelem)
+    nvOrder.PutUint32(pair[12:], typ)
+    copy(pair[nvPairHeader:], name)
+    copy(pair[valueOffset:], data)
+    buf = append(buf, pair...)
+
+    // La liste imbriquée suit immédiatement la paire
+    if embedded != nil {
+        return appendNvlist(buf, embedded)
+    }
+    return buf, nil
+}
+
+// unpackNvlist décode une nvlist produite par le module ZFS
+func unpackNvlist(buf []byte) (map[string]interface{}, error) {
+    if len(buf) < 4 {
+        return nil, fmt.Errorf("malformed nvlist: truncated header")
+    }
+    if buf[0] != nvEncodeNative || buf[1] != nvHostEndian() {
+        return nil, fmt.Errorf("unsupported nvlist encoding %d/%d", buf[0], buf[1])
+    }
+    d := &nvDecoder{buf: buf, off: 4}
+    return d.nvlist()
+}
+
+type nvDecoder struct {
+    buf []byte
+    off int
+}
+
+func (d *nvDecoder) need(n int) error {
+    if d.off < 0 || d.off+n > len(d.buf) {
+        return fmt.Errorf("malformed nvlist: truncated at offset %d", d.off)
+    }
+    return nil
+}
+
+func (d *nvDecoder) nvlist() (map[string]interface{}, error) {
+    if err := d.need(8); err != nil {
+        return nil, err
+    }
+    d.off += 8 // nvl_version, nvl_nvflag
+
+    nvl := make(map[string]interface{})
+    for {
+        if err := d.need(4); err != nil {
+            return nil, err
+        }
+        size := int(nvOrder.Uint32(d.buf[d.off:]))
+        if size == 0 {
+            d.off += 4
+            return nvl, nil
+        }
+        if size < nvPairHeader {
+            return nil, fmt.Errorf("malformed nvlist: invalid pair size %d", size)
+        }
+        if err := d.need(size); err != nil {
+            return nil, err
+        }
+
+        pair := d.buf[d.off : d.off+size]
+        nameSize := int(nvOrder.Uint16(pair[4:]))
+        nelem := int(nvOrder.Uint32(pair[8:]))
+        typ := nvOrder.Uint32(pair[12:])
+        valueOffset := nvAlign(nvPairHeader + nameSize)
+        if nameSize < 1 || valueOffset > size {
+            return nil, fmt.Errorf("malformed nvlist: invalid name size %d", nameSize)
+        }
+        name := string(pair[nvPairHeader : nvPairHeader+nameSize-1])
+        value := pair[valueOffset:]
+        d.off += size
+
+        switch typ {
+        case nvTypeBoolean:
+            nvl[name] = true
+        case nvTypeBooleanValue:
+            if len(value) >= 4 {
+                nvl[name] = nvOrder.Uint32(value) != 0
+            }
+        case nvTypeUint64:
+            if len(value) >= 8 {
+                nvl[name] = nvOrder.Uint64(value)
+            }
+        case nvTypeString:
+            for i, c := range value {
+                if c == 0 {
+                    nvl[name] = string(value[:i])
+                    break
+                }
+            }
+        case nvTypeNvlist:
+            sub, err := d.nvlist()
+            if err != nil {
+                return nil, err
+            }
+            nvl[name] = sub
+        case nvTypeNvlistArray:
+            var subs []map[string]interface{}
+            for i := 0; i < nelem; i++ {
+                sub, err := d.nvlist()
+                if err != nil {
+                    return nil, err
+                }
+                subs = append(subs, sub)
+            }
+            nvl[name] = subs
+        }
+    }
+}
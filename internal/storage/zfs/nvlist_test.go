@@ -0,0 +1,154 @@
+// internal/storage/zfs/nvlist_test.go
+package zfs
+
+import (
+    "bytes"
+    "encoding/hex"
+    "reflect"
+    "strings"
+    "testing"
+)
+
+// Octets attendus de l'encodage NV_ENCODE_NATIVE little-endian, établis d'après
+// nvs_native_nvlist, nvs_native_nvpair et nvs_native_nvl_fini (nvpair.c)
+const (
+    // {"a": uint64(5), "snaps": {"p@s": boolean}}
+    nvlistPacked = "" +
+        "00010000" + // nvs_header_t : NV_ENCODE_NATIVE, little-endian
+        "00000000" + "01000000" + // nvl_version, nvl_nvflag (NV_UNIQUE_NAME)
+        // "a" : nvp_size 32, nvp_name_sz 2, nvp_value_elem 1, DATA_TYPE_UINT64, valeur à 24
+        "20000000" + "0200" + "0000" + "01000000" + "08000000" + "6100000000000000" + "0500000000000000" +
+        // "snaps" : nvp_size 48, nvp_name_sz 6, DATA_TYPE_NVLIST, nvlist_t embarqué (24 octets)
+        "30000000" + "0600" + "0000" + "01000000" + "13000000" + "736e617073000000" +
+        "00000000" + "01000000" + "0000000000000000" + "00000000" + "00000000" +
+        // nvlist imbriquée
+        "00000000" + "01000000" +
+        // "p@s" : nvp_size 24, nvp_name_sz 4, aucun élément, DATA_TYPE_BOOLEAN
+        "18000000" + "0400" + "0000" + "00000000" + "01000000" + "7040730000000000" +
+        "00000000" + // fin de la liste imbriquée
+        "00000000" // fin de la liste
+
+    // {"enabled": boolean_value true, "n": int32 7, "name": "x"}, tel que produit par le module
+    nvlistFromKernel = "" +
+        "00010000" +
+        "00000000" + "01000000" +
+        // DATA_TYPE_BOOLEAN_VALUE (boolean_t sur 4 octets)
+        "20000000" + "0800" + "0000" + "01000000" + "15000000" + "656e61626c656400" + "0100000000000000" +
+        // DATA_TYPE_INT32 : non géré, ignoré
+        "20000000" + "0200" + "0000" + "01000000" + "05000000" + "6e00000000000000" + "0700000000000000" +
+        // DATA_TYPE_STRING
+        "20000000" + "0500" + "0000" + "01000000" + "09000000" + "6e616d6500000000" + "7800000000000000" +
+        "00000000"
+)
+
+func decodeHex(t *testing.T, s string) []byte {
+    t.Helper()
+    data, err := hex.DecodeString(s)
+    if err != nil {
+        t.Fatal(err)
+    }
+    return data
+}
+
+func TestPackNvlist(t *testing.T) {
+    if nvHostEndian() != 1 {
+        t.Skip("expected bytes are little-endian")
+    }
+    nvl := map[string]interface{}{
+        "a":     uint64(5),
+        "snaps": map[string]interface{}{"p@s": true},
+    }
+
+    packed, err := packNvlist(nvl)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if want := decodeHex(t, nvlistPacked); !bytes.Equal(packed, want) {
+        t.Errorf("packNvlist =\n%x\nwant\n%x", packed, want)
+    }
+
+    unpacked, err := unpackNvlist(packed)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if !reflect.DeepEqual(unpacked, nvl) {
+        t.Errorf("round trip = %v, want %v", unpacked, nvl)
+    }
+
+    if _, err := packNvlist(map[string]interface{}{"x": 1.5}); err == nil {
+        t.Error("unsupported value type packed")
+    }
+}
+
+func TestUnpackNvlist(t *testing.T) {
+    if nvHostEndian() != 1 {
+        t.Skip("expected bytes are little-endian")
+    }
+    got, err := unpackNvlist(decodeHex(t, nvlistFromKernel))
+    if err != nil {
+        t.Fatal(err)
+    }
+    want := map[string]interface{}{"enabled": true, "name": "x"}
+    if !reflect.DeepEqual(got, want) {
+        t.Errorf("unpackNvlist = %v, want %v", got, want)
+    }
+}
+
+func TestNvlistRoundTrip(t *testing.T) {
+    nvl := map[string]interface{}{
+        "snaps": map[string]interface{}{
+            "tank/app@snapshot_20260301_100000_a1b2c3": true,
+            "tank/db@snapshot_20260301_100000_a1b2c3":  true,
+        },
+        "props": map[string]interface{}{
+            "zockimate:host":    "local",
+            "zockimate:configs": strings.Repeat("H4sI", 2000),
+            "zockimate:empty":   "",
+        },
+        "createtxg": map[string]interface{}{"value": uint64(1 << 40), "source": "tank/app"},
+    }
+    packed, err := packNvlist(nvl)
+    if err != nil {
+        t.Fatal(err)
+    }
+    got, err := unpackNvlist(packed)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if !reflect.DeepEqual(got, nvl) {
+        t.Errorf("round trip = %v, want %v", got, nvl)
+    }
+}
+
+func TestUnpackNvlistMalformed(t *testing.T) {
+    packed, err := packNvlist(map[string]interface{}{"name": "value", "sub": map[string]interface{}{"n": uint64(1)}})
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    // Toute troncature est détectée
+    for n := 0; n < len(packed); n++ {
+        if _, err := unpackNvlist(packed[:n]); err == nil {
+            t.Fatalf("nvlist truncated to %d bytes decoded", n)
+        }
+    }
+
+    // Encodage XDR ou ordre des octets étranger refusés
+    bad := append([]byte(nil), packed...)
+    bad[0] = 1
+    if _, err := unpackNvlist(bad); err == nil {
+        t.Error("XDR encoding accepted")
+    }
+    bad = append([]byte(nil), packed...)
+    bad[1] ^= 1
+    if _, err := unpackNvlist(bad); err == nil {
+        t.Error("foreign byte order accepted")
+    }
+
+    // Taille de paire invalide
+    bad = append([]byte(nil), packed...)
+    nvOrder.PutUint32(bad[12:], 8)
+    if _, err := unpackNvlist(bad); err == nil {
+        t.Error("invalid pair size accepted")
+    }
+}
@@ -0,0 +1,61 @@
+// internal/storage/zfs/properties.go
+package zfs
+
+import (
+    "fmt"
+    "sort"
+    "strings"
+)
+
+// Préfixe des propriétés utilisateur posées par zockimate
+const PropertyPrefix = "zockimate:"
+
+// propertyArgs retourne les options "-o clé=valeur" d'un ensemble de propriétés, triées
+func propertyArgs(props map[string]string) []string {
+    keys := make([]string, 0, len(props))
+    for k := range props {
+        keys = append(keys, k)
+    }
+    sort.Strings(keys)
+
+    var args []string
+    for _, k := range keys {
+        args = append(args, "-o", k+"="+props[k])
+    }
+    return args
+}
+
+// SetProperties pose des propriétés utilisateur sur un groupe de snapshots
+func (z *ZFSManager) SetProperties(snapshots []string, props map[string]string) error {
+    if len(props) == 0 {
+        return nil
+    }
+    for _, snapshot := range snapshots {
+        if err := z.backend.SetProperties(snapshot, props); err != nil {
+            return fmt.Errorf("failed to set properties on %s: %w", snapshot, err)
+        }
+    }
+    return nil
+}
+
+// SnapshotProperties retourne les propriétés zockimate:* de tous les snapshots de
+// l'hôte qui en portent, indexées par nom de snapshot
+func (z *ZFSManager) SnapshotProperties() (map[string]map[string]string, error) {
+    out, err := z.run("get", "-H", "-p", "-o", "name,property,value", "-s", "local", "-t", "snapshot", "all")
+    if err != nil {
+        return nil, err
+    }
+
+    snapshots := make(map[string]map[string]string)
+    for _, line := range strings.Split(out, "\n") {
+        fields := strings.SplitN(line, "\t", 3)
+        if len(fields) != 3 || !strings.HasPrefix(fields[1], PropertyPrefix) {
+            continue
+        }
+        if snapshots[fields[0]] == nil {
+            snapshots[fields[0]] = make(map[string]string)
+        }
+        snapshots[fields[0]][fields[1]] = fields[2]
+    }
+    return snapshots, nil
+}
@@ -0,0 +1,272 @@
+// internal/storage/zfs/replicate.go
+package zfs
+
+import (
+    "context"
+    "fmt"
+    "strings"
+    "time"
+
+    "github.com/sirupsen/logrus"
+)
+
+// Replica est la destination des snapshots répliqués : un dataset parent sur un
+// pool local ou joint via SSH. Le dataset tank/app est répliqué sous <root>/tank/app.
+type Replica struct {
+    zfs  *ZFSManager
+    root string
+}
+
+// NewReplica crée une destination de réplication ; ssh vide pour un pool local
+func NewReplica(root, ssh string, logger *logrus.Logger) *Replica {
+    z := NewZFSManager(logger)
+    if ssh != "" {
+        z = NewRemoteZFSManager(ssh, logger)
+    }
+    return &Replica{zfs: z, root: strings.TrimSuffix(root, "/")}
+}
+
+// String retourne la destination telle qu'affichée dans les logs
+func (r *Replica) String() string {
+    if r.zfs.cli.ssh != "" {
+        return r.zfs.cli.ssh + ":" + r.root
+    }
+    return r.root
+}
+
+// Dataset retourne le dataset de la réplique correspondant à un dataset source
+func (r *Replica) Dataset(source string) string {
+    return r.root + "/" + source
+}
+
+// SetReplica configure la réplication des snapshots du gestionnaire
+func (z *ZFSManager) SetReplica(r *Replica) {
+    z.replica = r
+}
+
+// Replica retourne la destination de réplication, nil si non configurée
+func (z *ZFSManager) Replica() *Replica {
+    return z.replica
+}
+
+// Replicate envoie un snapshot vers la réplique par "zfs send -i base snapshot | zfs receive".
+// Si base n'existe plus d'un côté, l'envoi part du plus récent snapshot commun ; sans
+// snapshot commun, le snapshot est envoyé intégralement si la réplique du dataset n'existe pas.
+func (z *ZFSManager) Replicate(snapshot, base string) error {
+    r := z.replica
+    if r == nil {
+        return fmt.Errorf("no ZFS replica configured")
+    }
+    dataset, name, _ := strings.Cut(snapshot, "@")
+    target := r.Dataset(dataset)
+
+    remote, err := r.zfs.ListSnapshots(target, false)
+    if err != nil {
+        return fmt.Errorf("failed to list replica snapshots: %w", err)
+    }
+    onReplica := make(map[string]bool, len(remote))
+    for _, s := range remote {
+        _, n, _ := strings.Cut(s, "@")
+        onReplica[n] = true
+    }
+    if onReplica[name] {
+        return nil
+    }
+
+    local, err := z.ListSnapshots(dataset, false)
+    if err != nil {
+        return err
+    }
+
+    // Snapshot de départ de l'envoi incrémental
+    from := ""
+    _, baseName, _ := strings.Cut(base, "@")
+    if baseName != "" && onReplica[baseName] && contains(local, dataset+"@"+baseName) {
+        from = baseName
+    } else {
+        // Parcourir les snapshots antérieurs, du plus récent au plus ancien
+        i := len(local) - 1
+        for i >= 0 && local[i] != snapshot {
+            i--
+        }
+        for i--; i >= 0; i-- {
+            if _, n, _ := strings.Cut(local[i], "@"); onReplica[n] {
+                from = n
+                break
+            }
+        }
+    }
+
+    sendArgs := []string{"send", snapshot}
+    recvArgs := []string{"receive", "-u", target}
+    if from != "" {
+        sendArgs = []string{"send", "-i", "@" + from, snapshot}
+        // -F annule les modifications faites sur la réplique depuis le dernier snapshot reçu
+        recvArgs = []string{"receive", "-u", "-F", target}
+    } else if len(remote) > 0 {
+        return fmt.Errorf("replica %s has no snapshot in common with %s", target, dataset)
+    } else if i := strings.LastIndex(target, "/"); i > 0 {
+        if _, err := r.zfs.run("create", "-p", target[:i]); err != nil {
+            return err
+        }
+    }
+
+    if err := pipe(z, r.zfs, sendArgs, recvArgs); err != nil {
+        return fmt.Errorf("failed to replicate %s to %s: %w", snapshot, r, err)
+    }
+    z.logger.Debugf("Replicated %s to %s (base: %s)", snapshot, r, from)
+    return nil
+}
+
+// FetchFromReplica rapatrie depuis la réplique les snapshots absents localement.
+// Chaque snapshot est reçu dans un nouveau dataset <dataset>_replica_<date> non monté,
+// rattaché au dataset actif par zockimate:origin pour pouvoir être restauré.
+// Retourne les anciens noms des snapshots rapatriés associés à leurs nouveaux noms.
+func (z *ZFSManager) FetchFromReplica(snapshots []string) (map[string]string, error) {
+    fetched := make(map[string]string)
+    missing, err := z.missingSnapshots(snapshots)
+    if err != nil || len(missing) == 0 {
+        return fetched, err
+    }
+
+    r := z.replica
+    if r == nil {
+        return fetched, fmt.Errorf("ZFS snapshot group incomplete, missing: %s", strings.Join(missing, ", "))
+    }
+
+    suffix := time.Now().Format("20060102_150405")
+    for _, snapshot := range missing {
+        dataset, name, _ := strings.Cut(snapshot, "@")
+        source := r.Dataset(dataset) + "@" + name
+        if gone, err := r.zfs.missingSnapshots([]string{source}); err != nil {
+            return fetched, err
+        } else if len(gone) > 0 {
+            return fetched, fmt.Errorf("ZFS snapshot %s missing locally and on replica %s", snapshot, r)
+        }
+
+        local := dataset + "_replica_" + suffix
+        if err := pipe(r.zfs, z, []string{"send", source},
+            []string{"receive", "-u", "-o", "canmount=noauto", local}); err != nil {
+            return fetched, fmt.Errorf("failed to fetch %s from replica %s: %w", snapshot, r, err)
+        }
+        if _, err := z.run("set", originProperty+"="+dataset, local); err != nil {
+            return fetched, err
+        }
+        fetched[snapshot] = local + "@" + name
+        z.logger.Infof("Fetched missing ZFS snapshot %s from replica %s", snapshot, r)
+    }
+    return fetched, nil
+}
+
+// OnReplica indique si la réplique possède une copie de chacun des snapshots,
+// que FetchFromReplica peut rapatrier ; faux sans réplique configurée
+func (z *ZFSManager) OnReplica(snapshots []string) (bool, error) {
+    r := z.replica
+    if r == nil {
+        return false, nil
+    }
+    copies := make([]string, 0, len(snapshots))
+    for _, snapshot := range snapshots {
+        dataset, name, _ := strings.Cut(snapshot, "@")
+        copies = append(copies, r.Dataset(dataset)+"@"+name)
+    }
+    missing, err := r.zfs.missingSnapshots(copies)
+    if err != nil {
+        return false, fmt.Errorf("failed to check replica %s: %w", r, err)
+    }
+    return len(missing) == 0, nil
+}
+
+// DeleteReplicas supprime les copies répliquées d'un groupe de snapshots, si elles existent
+func (z *ZFSManager) DeleteReplicas(snapshots []string) error {
+    r := z.replica
+    if r == nil {
+        return nil
+    }
+    var copies []string
+    for _, snapshot := range snapshots {
+        dataset, name, _ := strings.Cut(snapshot, "@")
+        copies = append(copies, r.Dataset(dataset)+"@"+name)
+    }
+    missing, err := r.zfs.missingSnapshots(copies)
+    if err != nil {
+        return err
+    }
+    gone := make(map[string]bool, len(missing))
+    for _, s := range missing {
+        gone[s] = true
+    }
+    var existing []string
+    for _, s := range copies {
+        if !gone[s] {
+            existing = append(existing, s)
+        }
+    }
+    if len(existing) == 0 {
+        return nil
+    }
+    return r.zfs.DeleteSnapshot(existing)
+}
+
+// pipe relie "zfs send" (côté from) à "zfs receive" (côté to)
+func pipe(from, to *ZFSManager, sendArgs, recvArgs []string) error {
+    ctx, cancel := context.WithCancel(context.Background())
+    defer cancel()
+
+    send := from.command(ctx, sendArgs...)
+    recv := to.command(ctx, recvArgs...)
+
+    var sendErr, recvErr strings.Builder
+    send.Stderr = &sendErr
+    recv.Stderr = &recvErr
+
+    stream, err := send.StdoutPipe()
+    if err != nil {
+        return err
+    }
+    recv.Stdin = stream
+
+    if err := recv.Start(); err != nil {
+        return err
+    }
+    if err := send.Start(); err != nil {
+        cancel()
+        recv.Wait() //nolint:errcheck
+        return err
+    }
+
+    // Si receive échoue, interrompre send qui resterait bloqué sur le pipe
+    recvDone := make(chan error, 1)
+    go func() {
+        err := recv.Wait()
+        if err != nil {
+            cancel()
+        }
+        recvDone <- err
+    }()
+    sendRes := send.Wait()
+    recvRes := <-recvDone
+
+    if recvRes != nil {
+        return commandError("receive", recvRes, recvErr.String())
+    }
+    if sendRes != nil {
+        return commandError("send", sendRes, sendErr.String())
+    }
+    return nil
+}
+
+// commandError formate l'échec d'une commande zfs avec sa sortie d'erreur
+func commandError(name string, err error, stderr string) error {
+    return fmt.Errorf("zfs %s failed: %w: %s", name, err, strings.TrimSpace(stderr))
+}
+
+// contains indique si une liste contient une valeur
+func contains(list []string, value string) bool {
+    for _, v := range list {
+        if v == value {
+            return true
+        }
+    }
+    return false
+}
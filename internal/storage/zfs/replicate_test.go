@@ -0,0 +1,178 @@
+// internal/storage/zfs/replicate_test.go
+package zfs
+
+import (
+    "io"
+    "reflect"
+    "strings"
+    "testing"
+
+    "github.com/sirupsen/logrus"
+)
+
+// newReplicatedManager crée un gestionnaire répliquant vers le pool local backup
+func newReplicatedManager(t *testing.T, datasets ...string) (*ZFSManager, *fakeZFS) {
+    t.Helper()
+    z, f := newCLIManager(t, append(datasets, "backup")...)
+    logger := logrus.New()
+    logger.SetOutput(io.Discard)
+    z.SetReplica(NewReplica("backup", "", logger))
+    return z, f
+}
+
+// lastReceived retourne le dernier flux reçu
+func lastReceived(t *testing.T, f *fakeZFS) fakeZFSReceive {
+    t.Helper()
+    f.load(t)
+    if len(f.Received) == 0 {
+        t.Fatal("nothing received")
+    }
+    return f.Received[len(f.Received)-1]
+}
+
+func TestReplicate(t *testing.T) {
+    z, f := newReplicatedManager(t, "tank/app")
+    snapshotHistory(t, f)
+
+    // Premier envoi complet : le parent de la réplique est créé
+    if err := z.Replicate("tank/app@s1", ""); err != nil {
+        t.Fatal(err)
+    }
+    if got := lastReceived(t, f); got != (fakeZFSReceive{Snapshot: "backup/tank/app@s1"}) {
+        t.Errorf("received %+v, want a full stream", got)
+    }
+    if replica := f.Datasets["backup/tank/app"]; replica == nil || replica.Data != "v1" || replica.Mounted {
+        t.Errorf("backup/tank/app = %+v, want v1 not mounted", replica)
+    }
+
+    // Envoi incrémental depuis la base enregistrée
+    if err := z.Replicate("tank/app@s2", "tank/app@s1"); err != nil {
+        t.Fatal(err)
+    }
+    if got := lastReceived(t, f); got != (fakeZFSReceive{Snapshot: "backup/tank/app@s2", From: "backup/tank/app@s1"}) {
+        t.Errorf("received %+v, want an incremental stream from s1", got)
+    }
+
+    // Snapshot déjà répliqué : rien n'est envoyé
+    if err := z.Replicate("tank/app@s2", "tank/app@s1"); err != nil {
+        t.Fatal(err)
+    }
+    if f.load(t); len(f.Received) != 2 {
+        t.Errorf("%d streams received, want 2", len(f.Received))
+    }
+
+    // Base supprimée localement : envoi depuis le plus récent snapshot commun,
+    // les modifications de la réplique sont annulées
+    f.load(t)
+    delete(f.Snapshots, "tank/app@s1")
+    f.save(t)
+    f.snapshot(t, "backup/tank/app@manual")
+    if err := z.Replicate("tank/app@s3", "tank/app@s1"); err != nil {
+        t.Fatal(err)
+    }
+    if got := lastReceived(t, f); got.From != "backup/tank/app@s2" {
+        t.Errorf("received %+v, want an incremental stream from s2", got)
+    }
+    want := []string{"backup/tank/app@s1", "backup/tank/app@s2", "backup/tank/app@s3"}
+    if got := f.snapshotsOf("backup/tank/app"); !reflect.DeepEqual(got, want) {
+        t.Errorf("replica snapshots = %v, want %v", got, want)
+    }
+    if f.Datasets["backup/tank/app"].Data != "v3" {
+        t.Errorf("replica data = %s, want v3", f.Datasets["backup/tank/app"].Data)
+    }
+
+    // Base supprimée sur la réplique
+    f.load(t)
+    delete(f.Snapshots, "backup/tank/app@s3")
+    f.save(t)
+    f.setData(t, "tank/app", "v5")
+    f.snapshot(t, "tank/app@s4")
+    if err := z.Replicate("tank/app@s4", "tank/app@s3"); err != nil {
+        t.Fatal(err)
+    }
+    if got := lastReceived(t, f); got.From != "backup/tank/app@s2" {
+        t.Errorf("received %+v, want an incremental stream from s2", got)
+    }
+}
+
+func TestReplicateNoCommonSnapshot(t *testing.T) {
+    z, f := newReplicatedManager(t, "tank/app", "backup/tank/app")
+    f.snapshot(t, "backup/tank/app@foreign")
+    f.snapshot(t, "tank/app@s1")
+
+    // Réplique d'un autre dataset : jamais écrasée par un envoi complet
+    if err := z.Replicate("tank/app@s1", ""); err == nil || !strings.Contains(err.Error(), "no snapshot in common") {
+        t.Errorf("Replicate = %v, want no snapshot in common", err)
+    }
+    if f.load(t); len(f.Received) != 0 {
+        t.Errorf("streams received: %+v", f.Received)
+    }
+
+    if err := NewZFSManager(z.logger).Replicate("tank/app@s1", ""); err == nil {
+        t.Error("Replicate succeeded without replica")
+    }
+}
+
+func TestFetchFromReplica(t *testing.T) {
+    z, f := newReplicatedManager(t, "tank/app")
+    snapshotHistory(t, f)
+    for _, s := range []string{"s1", "s2"} {
+        if err := z.Replicate("tank/app@"+s, ""); err != nil {
+            t.Fatal(err)
+        }
+    }
+    f.load(t)
+    delete(f.Snapshots, "tank/app@s1")
+    f.save(t)
+
+    // Snapshot absent localement : rapatrié dans un dataset non monté
+    fetched, err := z.FetchFromReplica([]string{"tank/app@s1", "tank/app@s2"})
+    if err != nil {
+        t.Fatal(err)
+    }
+    local := fetched["tank/app@s1"]
+    if len(fetched) != 1 || !strings.HasPrefix(local, "tank/app_replica_") || !strings.HasSuffix(local, "@s1") {
+        t.Fatalf("fetched = %v, want only s1", fetched)
+    }
+    f.load(t)
+    dataset, _, _ := strings.Cut(local, "@")
+    replica := f.Datasets[dataset]
+    if replica.Data != "v1" || replica.Mounted || replica.Props["canmount"] != "noauto" || replica.Props[originProperty] != "tank/app" {
+        t.Errorf("%s = %+v, want v1 attached to tank/app", dataset, replica)
+    }
+
+    // Le snapshot rapatrié restaure le dataset actif
+    if _, err := z.RestoreSnapshot([]string{local}, false); err != nil {
+        t.Fatal(err)
+    }
+    f.load(t)
+    if app := f.Datasets["tank/app"]; app.Data != "v1" || !app.Mounted {
+        t.Errorf("tank/app = %+v, want v1 mounted", app)
+    }
+
+    // Absent des deux côtés
+    if _, err := z.FetchFromReplica([]string{"tank/app@s9"}); err == nil ||
+        !strings.Contains(err.Error(), "missing locally and on replica") {
+        t.Errorf("FetchFromReplica = %v, want missing on replica", err)
+    }
+}
+
+func TestDeleteReplicas(t *testing.T) {
+    z, f := newReplicatedManager(t, "tank/app")
+    snapshotHistory(t, f)
+    if err := z.Replicate("tank/app@s1", ""); err != nil {
+        t.Fatal(err)
+    }
+
+    // Les copies absentes de la réplique sont ignorées
+    if err := z.DeleteReplicas([]string{"tank/app@s1", "tank/app@s2"}); err != nil {
+        t.Fatal(err)
+    }
+    f.load(t)
+    if got := f.snapshotsOf("backup/tank/app"); len(got) != 0 {
+        t.Errorf("replica snapshots = %v, want none", got)
+    }
+    if got := f.snapshotsOf("tank/app"); len(got) != 3 {
+        t.Errorf("local snapshots = %v, want all kept", got)
+    }
+}
@@ -0,0 +1,247 @@
+// internal/storage/zfs/restore.go
+package zfs
+
+import (
+    "fmt"
+    "strings"
+    "time"
+)
+
+// Propriété utilisateur désignant, sur un dataset mis de côté par une restauration,
+// le dataset actif dont il est issu
+const originProperty = "zockimate:origin"
+
+// Reconciliation décrit les snapshots renommés ou détruits par une restauration,
+// pour mettre à jour les entrées de la base qui les référencent
+type Reconciliation struct {
+    Moved     map[string]string // Ancien nom -> nouveau nom
+    Destroyed []string          // Snapshots détruits (rollback -r)
+}
+
+// RestoreSnapshot restaure un groupe de snapshots sans détruire les snapshots plus récents.
+// Pour chaque snapshot, le dataset actif est remplacé par un clone promu du snapshot ;
+// l'ancien dataset est conservé (non monté) sous <dataset>_prev_<date> avec les
+// snapshots postérieurs. Les datasets doivent être démontables (conteneur arrêté).
+// Avec destroyNewer, utilise "zfs rollback -r" qui détruit les snapshots plus récents.
+func (z *ZFSManager) RestoreSnapshot(snapshots []string, destroyNewer bool) (*Reconciliation, error) {
+    if missing, err := z.missingSnapshots(snapshots); err != nil {
+        return nil, err
+    } else if len(missing) > 0 {
+        return nil, fmt.Errorf("ZFS snapshot group incomplete, missing: %s", strings.Join(missing, ", "))
+    }
+
+    rec := &Reconciliation{Moved: make(map[string]string)}
+
+    if destroyNewer {
+        for _, snapshot := range snapshots {
+            dataset, _, _ := strings.Cut(snapshot, "@")
+            newer, err := z.newerSnapshots(dataset, snapshot)
+            if err != nil {
+                return rec, err
+            }
+            if err := z.RollbackSnapshot([]string{snapshot}); err != nil {
+                return rec, err
+            }
+            rec.Destroyed = append(rec.Destroyed, newer...)
+        }
+        return rec, nil
+    }
+
+    suffix := time.Now().Format("20060102_150405")
+    for _, snapshot := range snapshots {
+        if err := z.restoreClone(snapshot, suffix, rec); err != nil {
+            return rec, err
+        }
+    }
+    return rec, nil
+}
+
+// restoreClone remplace le dataset actif par un clone promu du snapshot
+func (z *ZFSManager) restoreClone(snapshot, suffix string, rec *Reconciliation) error {
+    source, _, _ := strings.Cut(snapshot, "@")
+
+    // Le snapshot peut provenir d'un dataset mis de côté par une restauration précédente
+    target := source
+    if origin, err := z.getProperty(source, originProperty); err != nil {
+        return err
+    } else if origin != "" && origin != "-" {
+        target = origin
+    }
+
+    children, err := z.run("list", "-H", "-o", "name", "-t", "filesystem", "-d", "1", target)
+    if err != nil && strings.Contains(err.Error(), "does not exist") {
+        // Dataset actif disparu (pool recréé) : le clone prend simplement sa place
+        return z.restoreMissing(snapshot, target, rec)
+    } else if err != nil {
+        return err
+    }
+    if len(strings.Fields(children)) > 1 {
+        return fmt.Errorf("dataset %s has child datasets: restore with destroy-newer (zfs rollback -r) instead", target)
+    }
+
+    // Snapshots avant l'opération, identifiés par leur GUID (conservé par rename et promote)
+    before, err := z.snapshotGUIDs(source, target)
+    if err != nil {
+        return err
+    }
+
+    mountpoint, mountSource, err := z.getPropertySource(target, "mountpoint")
+    if err != nil {
+        return err
+    }
+
+    clone := target + "_restore_" + suffix
+    previous := target + "_prev_" + suffix
+
+    cloneArgs := []string{"clone", "-o", "canmount=noauto"}
+    if mountSource == "local" {
+        cloneArgs = append(cloneArgs, "-o", "mountpoint="+mountpoint)
+    }
+    if _, err := z.run(append(cloneArgs, snapshot, clone)...); err != nil {
+        return err
+    }
+
+    // Mettre l'ancien dataset de côté, sans le remonter
+    if out, err := z.run("unmount", target); err != nil && !strings.Contains(out+err.Error(), "not currently mounted") {
+        z.destroyQuietly(clone)
+        return fmt.Errorf("cannot unmount %s (is it still in use?): %w", target, err)
+    }
+    if _, err := z.run("rename", "-u", target, previous); err != nil {
+        z.run("mount", target) //nolint:errcheck
+        z.destroyQuietly(clone)
+        return err
+    }
+    if _, err := z.run("set", "canmount=noauto", originProperty+"="+target, previous); err != nil {
+        z.logger.Warnf("Failed to set properties on %s: %v", previous, err)
+    }
+
+    // Le clone prend la place du dataset actif et récupère les snapshots antérieurs
+    if _, err := z.run("rename", "-u", clone, target); err != nil {
+        if _, revertErr := z.run("rename", "-u", previous, target); revertErr == nil {
+            z.run("set", "canmount=on", target) //nolint:errcheck
+            z.run("mount", target)              //nolint:errcheck
+        }
+        z.destroyQuietly(clone)
+        return err
+    }
+    if _, err := z.run("set", "canmount=on", target); err != nil {
+        return err
+    }
+    if _, err := z.run("promote", target); err != nil {
+        return err
+    }
+    if _, err := z.run("mount", target); err != nil {
+        return err
+    }
+
+    after, err := z.snapshotGUIDs(target, previous, source)
+    if err != nil {
+        return err
+    }
+    for guid, oldName := range before {
+        if newName, ok := after[guid]; ok && newName != oldName {
+            rec.Moved[oldName] = newName
+        }
+    }
+
+    z.logger.Debugf("Restored %s from %s (previous data kept in %s)", target, snapshot, previous)
+    return nil
+}
+
+// restoreMissing recrée un dataset actif disparu à partir d'un clone promu du snapshot
+func (z *ZFSManager) restoreMissing(snapshot, target string, rec *Reconciliation) error {
+    source, _, _ := strings.Cut(snapshot, "@")
+    before, err := z.snapshotGUIDs(source)
+    if err != nil {
+        return err
+    }
+
+    if _, err := z.run("clone", snapshot, target); err != nil {
+        return err
+    }
+    if _, err := z.run("promote", target); err != nil {
+        return err
+    }
+
+    after, err := z.snapshotGUIDs(target, source)
+    if err != nil {
+        return err
+    }
+    for guid, oldName := range before {
+        if newName, ok := after[guid]; ok && newName != oldName {
+            rec.Moved[oldName] = newName
+        }
+    }
+
+    z.logger.Debugf("Recreated %s from %s", target, snapshot)
+    return nil
+}
+
+// PreviousDatasets liste les datasets mis de côté par des restaurations (propriété zockimate:origin)
+func (z *ZFSManager) PreviousDatasets() ([]string, error) {
+    out, err := z.run("get", "-H", "-o", "name", "-s", "local", "-t", "filesystem", originProperty)
+    if err != nil {
+        return nil, err
+    }
+    return strings.Fields(out), nil
+}
+
+// newerSnapshots retourne les snapshots d'un dataset créés après snapshot
+func (z *ZFSManager) newerSnapshots(dataset, snapshot string) ([]string, error) {
+    names, err := z.backend.Snapshots(dataset)
+    if err != nil {
+        return nil, err
+    }
+    var newer []string
+    found := false
+    for _, name := range names {
+        if found {
+            newer = append(newer, name)
+        }
+        if name == snapshot {
+            found = true
+        }
+    }
+    return newer, nil
+}
+
+// snapshotGUIDs retourne les snapshots des datasets indexés par GUID
+func (z *ZFSManager) snapshotGUIDs(datasets ...string) (map[string]string, error) {
+    guids := make(map[string]string)
+    seen := make(map[string]bool)
+    for _, dataset := range datasets {
+        if seen[dataset] {
+            continue
+        }
+        seen[dataset] = true
+
+        out, err := z.run("list", "-H", "-o", "name,guid", "-t", "snapshot", "-d", "1", dataset)
+        if err != nil {
+            return nil, err
+        }
+        for _, line := range strings.Split(out, "\n") {
+            if name, guid, ok := strings.Cut(line, "\t"); ok {
+                guids[guid] = name
+            }
+        }
+    }
+    return guids, nil
+}
+
+// getProperty retourne la valeur d'une propriété ZFS
+func (z *ZFSManager) getProperty(dataset, property string) (string, error) {
+    value, _, err := z.getPropertySource(dataset, property)
+    return value, err
+}
+
+// getPropertySource retourne la valeur d'une propriété ZFS et sa source (local, inherited, default...)
+func (z *ZFSManager) getPropertySource(dataset, property string) (string, string, error) {
+    return z.backend.GetProperty(dataset, property)
+}
+
+// destroyQuietly détruit un dataset créé lors d'une opération avortée
+func (z *ZFSManager) destroyQuietly(dataset string) {
+    if _, err := z.run("destroy", dataset); err != nil {
+        z.logger.Warnf("Failed to destroy %s: %v", dataset, err)
+    }
+}
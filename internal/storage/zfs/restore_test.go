@@ -0,0 +1,200 @@
+// internal/storage/zfs/restore_test.go
+package zfs
+
+import (
+    "io"
+    "reflect"
+    "strconv"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/sirupsen/logrus"
+)
+
+// newCLIManager crée un gestionnaire utilisant la commande zfs simulée
+func newCLIManager(t *testing.T, datasets ...string) (*ZFSManager, *fakeZFS) {
+    t.Helper()
+    logger := logrus.New()
+    logger.SetOutput(io.Discard)
+    return NewZFSManager(logger), newFakeZFS(t, datasets...)
+}
+
+// snapshotHistory crée les snapshots s1 à s3 de tank/app (contenus v1 à v3),
+// puis laisse le dataset au contenu v4
+func snapshotHistory(t *testing.T, f *fakeZFS) {
+    t.Helper()
+    for i, name := range []string{"s1", "s2", "s3"} {
+        f.setData(t, "tank/app", "v"+strconv.Itoa(i+1))
+        f.snapshot(t, "tank/app@"+name)
+    }
+    f.setData(t, "tank/app", "v4")
+}
+
+// nextSecond attend la seconde suivante : les datasets mis de côté sont suffixés par la date
+func nextSecond() {
+    time.Sleep(time.Until(time.Now().Truncate(time.Second).Add(time.Second)))
+}
+
+func TestRestoreSnapshotClone(t *testing.T) {
+    z, f := newCLIManager(t, "tank/app")
+    snapshotHistory(t, f)
+
+    rec, err := z.RestoreSnapshot([]string{"tank/app@s2"}, false)
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    // Le dataset actif est un clone promu du snapshot, monté au même endroit
+    f.load(t)
+    app := f.Datasets["tank/app"]
+    if app.Data != "v2" || !app.Mounted || app.Origin != "" {
+        t.Errorf("tank/app = %+v, want v2 mounted and promoted", app)
+    }
+    if _, ok := app.Props["mountpoint"]; ok {
+        t.Errorf("inherited mountpoint set locally: %v", app.Props)
+    }
+    if got := f.snapshotsOf("tank/app"); !reflect.DeepEqual(got, []string{"tank/app@s1", "tank/app@s2"}) {
+        t.Errorf("tank/app snapshots = %v, want s1 and s2", got)
+    }
+
+    // L'ancien dataset est conservé, non monté, avec les snapshots plus récents
+    previous, err := z.PreviousDatasets()
+    if err != nil || len(previous) != 1 || !strings.HasPrefix(previous[0], "tank/app_prev_") {
+        t.Fatalf("PreviousDatasets = %v, %v", previous, err)
+    }
+    prev := f.Datasets[previous[0]]
+    if prev.Data != "v4" || prev.Mounted || prev.Props["canmount"] != "noauto" || prev.Props[originProperty] != "tank/app" {
+        t.Errorf("%s = %+v, want v4 kept aside", previous[0], prev)
+    }
+    want := map[string]string{"tank/app@s3": previous[0] + "@s3"}
+    if !reflect.DeepEqual(rec.Moved, want) || len(rec.Destroyed) != 0 {
+        t.Errorf("reconciliation = %+v, want %v moved", rec, want)
+    }
+
+    // Un snapshot du dataset mis de côté restaure le dataset actif d'origine
+    nextSecond()
+    rec, err = z.RestoreSnapshot([]string{previous[0] + "@s3"}, false)
+    if err != nil {
+        t.Fatal(err)
+    }
+    f.load(t)
+    if app := f.Datasets["tank/app"]; app.Data != "v3" || !app.Mounted {
+        t.Errorf("tank/app = %+v, want v3 mounted", app)
+    }
+
+    // Les snapshots antérieurs suivent le dataset remplacé, mis de côté à son tour
+    previous2, err := z.PreviousDatasets()
+    if err != nil || len(previous2) != 2 {
+        t.Fatalf("PreviousDatasets = %v, %v, want two datasets", previous2, err)
+    }
+    second := previous2[0]
+    if second == previous[0] {
+        second = previous2[1]
+    }
+    want = map[string]string{
+        "tank/app@s1":       second + "@s1",
+        "tank/app@s2":       second + "@s2",
+        previous[0] + "@s3": "tank/app@s3",
+    }
+    if !reflect.DeepEqual(rec.Moved, want) {
+        t.Errorf("moved = %v, want %v", rec.Moved, want)
+    }
+    if f.Datasets[second].Data != "v2" || f.Datasets[second].Mounted {
+        t.Errorf("%s = %+v, want v2 kept aside", second, f.Datasets[second])
+    }
+}
+
+func TestRestoreSnapshotLocalMountpoint(t *testing.T) {
+    z, f := newCLIManager(t, "tank/app")
+    f.load(t)
+    f.Datasets["tank/app"].Props["mountpoint"] = "/srv/app"
+    f.save(t)
+    snapshotHistory(t, f)
+
+    if _, err := z.RestoreSnapshot([]string{"tank/app@s1"}, false); err != nil {
+        t.Fatal(err)
+    }
+    f.load(t)
+    if mp, source := f.property("tank/app", "mountpoint"); mp != "/srv/app" || source != "local" {
+        t.Errorf("mountpoint = %s (%s), want /srv/app kept", mp, source)
+    }
+}
+
+func TestRestoreSnapshotDestroyNewer(t *testing.T) {
+    z, f := newCLIManager(t, "tank/app")
+    snapshotHistory(t, f)
+
+    rec, err := z.RestoreSnapshot([]string{"tank/app@s1"}, true)
+    if err != nil {
+        t.Fatal(err)
+    }
+    f.load(t)
+    if f.Datasets["tank/app"].Data != "v1" {
+        t.Errorf("tank/app = %s, want v1", f.Datasets["tank/app"].Data)
+    }
+    if !reflect.DeepEqual(rec.Destroyed, []string{"tank/app@s2", "tank/app@s3"}) || len(rec.Moved) != 0 {
+        t.Errorf("reconciliation = %+v, want s2 and s3 destroyed", rec)
+    }
+    if previous, _ := z.PreviousDatasets(); len(previous) != 0 {
+        t.Errorf("datasets kept aside by rollback -r: %v", previous)
+    }
+}
+
+func TestRestoreSnapshotMissingDataset(t *testing.T) {
+    // Pool recréé : seul un dataset rapatrié de la réplique porte le snapshot
+    z, f := newCLIManager(t, "tank/app_replica_1")
+    f.setData(t, "tank/app_replica_1", "v1")
+    f.snapshot(t, "tank/app_replica_1@s1")
+    f.load(t)
+    f.Datasets["tank/app_replica_1"].Props[originProperty] = "tank/app"
+    f.save(t)
+
+    rec, err := z.RestoreSnapshot([]string{"tank/app_replica_1@s1"}, false)
+    if err != nil {
+        t.Fatal(err)
+    }
+    f.load(t)
+    if app := f.Datasets["tank/app"]; app == nil || app.Data != "v1" || app.Origin != "" {
+        t.Fatalf("tank/app = %+v, want v1 recreated", app)
+    }
+    if !reflect.DeepEqual(rec.Moved, map[string]string{"tank/app_replica_1@s1": "tank/app@s1"}) {
+        t.Errorf("moved = %v", rec.Moved)
+    }
+}
+
+func TestRestoreSnapshotRefused(t *testing.T) {
+    z, f := newCLIManager(t, "tank/app", "tank/db", "tank/db/wal")
+    snapshotHistory(t, f)
+    f.snapshot(t, "tank/db@s1")
+
+    // Groupe incomplet : aucun dataset n'est touché
+    if _, err := z.RestoreSnapshot([]string{"tank/app@s1", "tank/db@missing"}, false); err == nil ||
+        !strings.Contains(err.Error(), "tank/db@missing") {
+        t.Errorf("incomplete group = %v, want the missing snapshot", err)
+    }
+
+    // Enfants : seul rollback -r sait restaurer la hiérarchie
+    if _, err := z.RestoreSnapshot([]string{"tank/db@s1"}, false); err == nil ||
+        !strings.Contains(err.Error(), "child datasets") {
+        t.Errorf("dataset with children = %v, want a refusal", err)
+    }
+
+    // Dataset occupé : le clone est supprimé, le dataset actif reste en place
+    f.load(t)
+    f.Datasets["tank/app"].Busy = true
+    f.save(t)
+    if _, err := z.RestoreSnapshot([]string{"tank/app@s1"}, false); err == nil ||
+        !strings.Contains(err.Error(), "still in use") {
+        t.Errorf("busy dataset = %v, want an unmount failure", err)
+    }
+    f.load(t)
+    if app := f.Datasets["tank/app"]; app.Data != "v4" || !app.Mounted {
+        t.Errorf("tank/app = %+v, want v4 still mounted", app)
+    }
+    for name := range f.Datasets {
+        if strings.Contains(name, "_restore_") || strings.Contains(name, "_prev_") {
+            t.Errorf("%s left behind", name)
+        }
+    }
+}
@@ -3,8 +3,11 @@ package zfs
 
 import (
     "context"
+    "crypto/rand"
     "fmt"
+    "io"
     "os/exec"
+    "path/filepath"
     "strings"
     "time"
     "github.com/sirupsen/logrus"
@@ -12,59 +15,257 @@ import (
 
 // ZFSManager gère les opérations ZFS
 type ZFSManager struct {
-    logger *logrus.Logger
+    cli     *cliBackend // Commande zfs : opérations composées et repli du backend natif
+    backend Backend     // Opérations élémentaires (snapshot, destroy, rollback, list, propriétés)
+    replica *Replica    // Destination de réplication des snapshots, nil si désactivée
+    logger  *logrus.Logger
 }
 
 // NewZFSManager crée une nouvelle instance du gestionnaire ZFS
 func NewZFSManager(logger *logrus.Logger) *ZFSManager {
+    return NewRemoteZFSManager("", logger)
+}
+
+// NewRemoteZFSManager crée un gestionnaire ZFS exécutant les commandes via SSH
+func NewRemoteZFSManager(target string, logger *logrus.Logger) *ZFSManager {
+    cli := &cliBackend{ssh: target, timeout: DefaultTimeout}
     return &ZFSManager{
-        logger: logger,
+        cli:     cli,
+        backend: cli,
+        logger:  logger,
     }
 }
 
-// CreateSnapshot crée un nouveau snapshot ZFS
-func (z *ZFSManager) CreateSnapshot(dataset string) (string, error) {
-    snapshotName := fmt.Sprintf("%s@snapshot_%s", 
-        dataset, 
-        time.Now().Format("20060102_150405"),
-    )
-    
-    ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-    defer cancel()
+// IsLocal indique si les commandes s'exécutent sur la machine locale
+func (z *ZFSManager) IsLocal() bool {
+    return z.cli.ssh == ""
+}
 
-    cmd := exec.CommandContext(ctx, "zfs", "snapshot", snapshotName)
-    if out, err := cmd.CombinedOutput(); err != nil {
-        return "", fmt.Errorf("failed to create ZFS snapshot %s: %w: %s", snapshotName, err, strings.TrimSpace(string(out)))
+// SetTimeout configure la durée maximale d'une commande zfs, réplique comprise
+func (z *ZFSManager) SetTimeout(timeout time.Duration) {
+    if timeout <= 0 {
+        return
+    }
+    z.cli.timeout = timeout
+    if z.replica != nil {
+        z.replica.zfs.SetTimeout(timeout)
     }
-    
-    z.logger.Debugf("Created ZFS snapshot: %s", snapshotName)
-    return snapshotName, nil
 }
 
-// RollbackSnapshot effectue un rollback vers un snapshot ZFS
-func (z *ZFSManager) RollbackSnapshot(snapshot string) error {
-    ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-    defer cancel()
+// SetBackend remplace le backend des opérations élémentaires (tests, backend natif)
+func (z *ZFSManager) SetBackend(b Backend) {
+    z.backend = b
+}
 
-    cmd := exec.CommandContext(ctx, "zfs", "rollback", "-r", snapshot)
-    if out, err := cmd.CombinedOutput(); err != nil {
-        return fmt.Errorf("failed to rollback ZFS snapshot %s: %w: %s", snapshot, err, strings.TrimSpace(string(out)))
+// EnableNative remplace la commande zfs par les ioctls de /dev/zfs pour les
+// opérations élémentaires. Réservé à l'hôte local.
+func (z *ZFSManager) EnableNative() error {
+    if !z.IsLocal() {
+        return fmt.Errorf("native ZFS backend requires a local host")
+    }
+    b, err := newNativeBackend(z.cli, z.logger)
+    if err != nil {
+        return err
     }
+    z.Close() //nolint:errcheck
+    z.backend = b
+    return nil
+}
 
-    z.logger.Debugf("Rolled back to ZFS snapshot: %s", snapshot)
+// Close libère les ressources du backend (descripteur de /dev/zfs)
+func (z *ZFSManager) Close() error {
+    if c, ok := z.backend.(io.Closer); ok {
+        return c.Close()
+    }
     return nil
 }
 
-// DeleteSnapshot supprime un snapshot ZFS
-func (z *ZFSManager) DeleteSnapshot(snapshot string) error {
-    ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-    defer cancel()
+// command prépare une commande zfs, locale ou distante
+func (z *ZFSManager) command(ctx context.Context, args ...string) *exec.Cmd {
+    return z.cli.command(ctx, args...)
+}
+
+// run exécute une commande zfs et retourne sa sortie standard
+func (z *ZFSManager) run(args ...string) (string, error) {
+    return z.cli.run(args...)
+}
+
+// Dataset associe un dataset ZFS à son point de montage
+type Dataset struct {
+    Name       string `json:"name"`
+    Mountpoint string `json:"mountpoint"`
+}
+
+// snapshotName retourne le nom de snapshot partagé par les datasets d'un même snapshot.
+// Le suffixe aléatoire évite les collisions entre snapshots pris dans la même seconde.
+func snapshotName() string {
+    suffix := make([]byte, 3)
+    rand.Read(suffix) //nolint:errcheck
+    return fmt.Sprintf("snapshot_%s_%x", time.Now().Format("20060102_150405"), suffix)
+}
+
+// CreateSnapshot crée un snapshot de même nom sur un groupe de datasets (et leurs
+// enfants si recursive) en une seule opération, donc au même instant pour tous,
+// portant les propriétés utilisateur props.
+// Retourne la liste des snapshots créés.
+func (z *ZFSManager) CreateSnapshot(datasets []string, recursive bool, props map[string]string) ([]string, error) {
+    if len(datasets) == 0 {
+        return nil, fmt.Errorf("no dataset to snapshot")
+    }
+    name := snapshotName()
+
+    // Les enfants sont listés avant le snapshot pour pouvoir les restaurer individuellement
+    targets := datasets
+    if recursive {
+        targets = nil
+        for _, dataset := range datasets {
+            children, err := z.backend.Datasets(dataset)
+            if err != nil {
+                return nil, fmt.Errorf("failed to list children of %s: %w", dataset, err)
+            }
+            targets = append(targets, children...)
+        }
+    }
+
+    var snapshots []string
+    for _, dataset := range targets {
+        snapshots = append(snapshots, dataset+"@"+name)
+    }
+
+    // La création est atomique : en cas d'erreur aucun snapshot n'est créé
+    if err := z.backend.Snapshot(snapshots, props); err != nil {
+        return nil, fmt.Errorf("failed to create ZFS snapshot %s: %w", strings.Join(snapshots, " "), err)
+    }
+
+    z.logger.Debugf("Created ZFS snapshot: %s", strings.Join(snapshots, " "))
+    return snapshots, nil
+}
+
+// RollbackSnapshot effectue un rollback vers un groupe de snapshots ZFS, en détruisant
+// les snapshots plus récents (zfs rollback -r).
+// Tous les snapshots du groupe doivent exister avant qu'un seul dataset soit restauré.
+func (z *ZFSManager) RollbackSnapshot(snapshots []string) error {
+    if missing, err := z.missingSnapshots(snapshots); err != nil {
+        return err
+    } else if len(missing) > 0 {
+        return fmt.Errorf("ZFS snapshot group incomplete, missing: %s", strings.Join(missing, ", "))
+    }
 
-    cmd := exec.CommandContext(ctx, "zfs", "destroy", snapshot)
-    if out, err := cmd.CombinedOutput(); err != nil {
-        return fmt.Errorf("failed to delete ZFS snapshot %s: %w: %s", snapshot, err, strings.TrimSpace(string(out)))
+    for _, snapshot := range snapshots {
+        dataset, _, _ := strings.Cut(snapshot, "@")
+        newer, err := z.newerSnapshots(dataset, snapshot)
+        if err != nil {
+            return fmt.Errorf("failed to rollback ZFS snapshot %s: %w", snapshot, err)
+        }
+        // Du plus récent au plus ancien, comme zfs rollback -r
+        for i := len(newer) - 1; i >= 0; i-- {
+            if err := z.backend.Destroy(newer[i]); err != nil {
+                return fmt.Errorf("failed to rollback ZFS snapshot %s: %w", snapshot, err)
+            }
+        }
+        if err := z.backend.Rollback(snapshot); err != nil {
+            return fmt.Errorf("failed to rollback ZFS snapshot %s: %w", snapshot, err)
+        }
+        z.logger.Debugf("Rolled back to ZFS snapshot: %s", snapshot)
     }
+    return nil
+}
 
-    z.logger.Debugf("Deleted ZFS snapshot: %s", snapshot)
+// DeleteSnapshot supprime un groupe de snapshots ZFS. Tous les snapshots sont
+// tentés ; les échecs sont regroupés dans l'erreur retournée.
+func (z *ZFSManager) DeleteSnapshot(snapshots []string) error {
+    var errs []string
+    for _, snapshot := range snapshots {
+        if err := z.backend.Destroy(snapshot); err != nil {
+            errs = append(errs, fmt.Sprintf("%s: %v", snapshot, err))
+            continue
+        }
+        z.logger.Debugf("Deleted ZFS snapshot: %s", snapshot)
+    }
+    if len(errs) > 0 {
+        return fmt.Errorf("failed to delete ZFS snapshot %s", strings.Join(errs, "; "))
+    }
     return nil
-}
\ No newline at end of file
+}
+
+// ListSnapshots liste les snapshots d'un dataset (et de ses enfants si recursive)
+// par ordre de création. Un dataset inexistant n'a aucun snapshot.
+func (z *ZFSManager) ListSnapshots(dataset string, recursive bool) ([]string, error) {
+    if exists, err := z.backend.Exists(dataset); err != nil {
+        return nil, err
+    } else if !exists {
+        return nil, nil
+    }
+
+    datasets := []string{dataset}
+    if recursive {
+        var err error
+        if datasets, err = z.backend.Datasets(dataset); err != nil {
+            return nil, err
+        }
+    }
+
+    var snapshots []string
+    for _, d := range datasets {
+        names, err := z.backend.Snapshots(d)
+        if err != nil {
+            return nil, err
+        }
+        snapshots = append(snapshots, names...)
+    }
+    return snapshots, nil
+}
+
+// missingSnapshots retourne les snapshots du groupe qui n'existent pas
+func (z *ZFSManager) missingSnapshots(snapshots []string) ([]string, error) {
+    var missing []string
+    for _, snapshot := range snapshots {
+        exists, err := z.backend.Exists(snapshot)
+        if err != nil {
+            return nil, fmt.Errorf("failed to check ZFS snapshot %s: %w", snapshot, err)
+        }
+        if !exists {
+            missing = append(missing, snapshot)
+        }
+    }
+    return missing, nil
+}
+
+// ListDatasets liste les systèmes de fichiers ZFS et leurs points de montage
+func (z *ZFSManager) ListDatasets() ([]Dataset, error) {
+    out, err := z.run("list", "-H", "-o", "name,mountpoint", "-t", "filesystem")
+    if err != nil {
+        return nil, fmt.Errorf("failed to list ZFS datasets: %w", err)
+    }
+
+    var datasets []Dataset
+    for _, line := range strings.Split(out, "\n") {
+        name, mountpoint, ok := strings.Cut(line, "\t")
+        if !ok {
+            continue
+        }
+        datasets = append(datasets, Dataset{Name: name, Mountpoint: mountpoint})
+    }
+    return datasets, nil
+}
+
+// DatasetFor retourne le dataset contenant un chemin (point de montage le plus long).
+// Le dataset monté sur / n'est jamais retenu : le snapshoter restaurerait tout le système.
+func DatasetFor(datasets []Dataset, path string) (Dataset, bool) {
+    path = filepath.Clean(path)
+
+    var best Dataset
+    for _, d := range datasets {
+        mp := d.Mountpoint
+        if !strings.HasPrefix(mp, "/") || mp == "/" {
+            continue // none, legacy, -
+        }
+        if path != mp && !strings.HasPrefix(path, mp+"/") {
+            continue
+        }
+        if len(mp) > len(best.Mountpoint) {
+            best = d
+        }
+    }
+    return best, best.Name != ""
+}
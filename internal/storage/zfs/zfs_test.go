@@ -0,0 +1,239 @@
+// internal/storage/zfs/zfs_test.go
+package zfs
+
+import (
+    "io"
+    "reflect"
+    "strings"
+    "testing"
+
+    "github.com/sirupsen/logrus"
+)
+
+func newTestManager(datasets ...string) (*ZFSManager, *FakeBackend) {
+    logger := logrus.New()
+    logger.SetOutput(io.Discard)
+    f := NewFakeBackend(datasets...)
+    return NewFakeZFSManager(f, logger), f
+}
+
+func TestCreateSnapshot(t *testing.T) {
+    z, f := newTestManager("tank/app", "tank/app/db", "tank/app/logs", "tank/other")
+    props := map[string]string{"zockimate:host": "local"}
+
+    snapshots, err := z.CreateSnapshot([]string{"tank/app"}, true, props)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if len(snapshots) != 3 {
+        t.Fatalf("snapshots = %v, want tank/app and its two children", snapshots)
+    }
+    _, name, _ := strings.Cut(snapshots[0], "@")
+    if !strings.HasPrefix(name, "snapshot_") {
+        t.Errorf("snapshot name = %s, want snapshot_<date>_<random>", name)
+    }
+    for i, want := range []string{"tank/app", "tank/app/db", "tank/app/logs"} {
+        if snapshots[i] != want+"@"+name {
+            t.Errorf("snapshot %d = %s, want %s@%s", i, snapshots[i], want, name)
+        }
+        if v, source, _ := f.GetProperty(snapshots[i], "zockimate:host"); v != "local" || source != "local" {
+            t.Errorf("%s: zockimate:host = %q (%s), want local", snapshots[i], v, source)
+        }
+    }
+
+    // Non récursif : le dataset seul
+    snapshots, err = z.CreateSnapshot([]string{"tank/app", "tank/other"}, false, nil)
+    if err != nil || len(snapshots) != 2 {
+        t.Fatalf("CreateSnapshot = %v, %v, want two snapshots", snapshots, err)
+    }
+
+    // Atomique : un dataset inexistant fait tout échouer
+    before, _ := z.ListSnapshots("tank/app", true)
+    if _, err := z.CreateSnapshot([]string{"tank/app", "tank/missing"}, false, nil); err == nil {
+        t.Fatal("snapshot of a missing dataset succeeded")
+    }
+    after, _ := z.ListSnapshots("tank/app", true)
+    if len(after) != len(before) {
+        t.Errorf("failed group left %d snapshot(s) behind", len(after)-len(before))
+    }
+
+    if _, err := z.CreateSnapshot(nil, false, nil); err == nil {
+        t.Error("snapshot without dataset succeeded")
+    }
+}
+
+func TestListSnapshotsOrder(t *testing.T) {
+    z, f := newTestManager("tank/app", "tank/app/db")
+
+    // Noms dans le désordre alphabétique : l'ordre est celui de création
+    for _, name := range []string{"snapshot_c", "snapshot_a", "snapshot_b"} {
+        if err := f.Snapshot([]string{"tank/app@" + name, "tank/app/db@" + name}, nil); err != nil {
+            t.Fatal(err)
+        }
+    }
+
+    got, err := z.ListSnapshots("tank/app", false)
+    if err != nil {
+        t.Fatal(err)
+    }
+    want := []string{"tank/app@snapshot_c", "tank/app@snapshot_a", "tank/app@snapshot_b"}
+    if !reflect.DeepEqual(got, want) {
+        t.Errorf("ListSnapshots = %v, want %v", got, want)
+    }
+
+    got, err = z.ListSnapshots("tank/app", true)
+    if err != nil {
+        t.Fatal(err)
+    }
+    want = append(want, "tank/app/db@snapshot_c", "tank/app/db@snapshot_a", "tank/app/db@snapshot_b")
+    if !reflect.DeepEqual(got, want) {
+        t.Errorf("ListSnapshots(recursive) = %v, want %v", got, want)
+    }
+
+    if got, err := z.ListSnapshots("tank/missing", false); err != nil || got != nil {
+        t.Errorf("ListSnapshots(missing) = %v, %v, want nothing", got, err)
+    }
+}
+
+func TestRollbackSnapshot(t *testing.T) {
+    z, f := newTestManager("tank/app", "tank/db")
+    for _, name := range []string{"snapshot_1", "snapshot_2", "snapshot_3"} {
+        if err := f.Snapshot([]string{"tank/app@" + name, "tank/db@" + name}, nil); err != nil {
+            t.Fatal(err)
+        }
+    }
+
+    // Les snapshots plus récents sont détruits, comme zfs rollback -r
+    if err := z.RollbackSnapshot([]string{"tank/app@snapshot_2", "tank/db@snapshot_2"}); err != nil {
+        t.Fatal(err)
+    }
+    for _, dataset := range []string{"tank/app", "tank/db"} {
+        got, _ := z.ListSnapshots(dataset, false)
+        want := []string{dataset + "@snapshot_1", dataset + "@snapshot_2"}
+        if !reflect.DeepEqual(got, want) {
+            t.Errorf("%s after rollback = %v, want %v", dataset, got, want)
+        }
+    }
+
+    // Groupe incomplet : aucun dataset n'est restauré
+    if err := f.Destroy("tank/db@snapshot_1"); err != nil {
+        t.Fatal(err)
+    }
+    err := z.RollbackSnapshot([]string{"tank/app@snapshot_1", "tank/db@snapshot_1"})
+    if err == nil || !strings.Contains(err.Error(), "tank/db@snapshot_1") {
+        t.Fatalf("error = %v, want the missing snapshot", err)
+    }
+    if exists, _ := f.Exists("tank/app@snapshot_2"); !exists {
+        t.Error("incomplete group rollback destroyed newer snapshots")
+    }
+}
+
+func TestDeleteSnapshot(t *testing.T) {
+    z, f := newTestManager("tank/app", "tank/db")
+    if err := f.Snapshot([]string{"tank/app@s1", "tank/db@s1"}, nil); err != nil {
+        t.Fatal(err)
+    }
+    if err := f.Destroy("tank/db@s1"); err != nil {
+        t.Fatal(err)
+    }
+
+    // Tous les snapshots sont tentés, les échecs sont regroupés
+    err := z.DeleteSnapshot([]string{"tank/db@s1", "tank/app@s1"})
+    if err == nil || !strings.Contains(err.Error(), "tank/db@s1") {
+        t.Fatalf("error = %v, want the missing snapshot", err)
+    }
+    if exists, _ := f.Exists("tank/app@s1"); exists {
+        t.Error("tank/app@s1 not deleted after a failure on another snapshot")
+    }
+}
+
+func TestProperties(t *testing.T) {
+    z, f := newTestManager("tank/app")
+    if err := f.Snapshot([]string{"tank/app@s1"}, nil); err != nil {
+        t.Fatal(err)
+    }
+    if err := f.SetProperties("tank", map[string]string{"zockimate:owner": "ops"}); err != nil {
+        t.Fatal(err)
+    }
+
+    if err := z.SetProperties([]string{"tank/app@s1"}, map[string]string{"zockimate:snapshot_id": "12"}); err != nil {
+        t.Fatal(err)
+    }
+
+    tests := []struct {
+        property, value, source string
+    }{
+        {"zockimate:snapshot_id", "12", "local"},
+        {"zockimate:owner", "ops", "inherited from tank"},
+        {"zockimate:unset", "-", "-"},
+    }
+    for _, tt := range tests {
+        value, source, err := f.GetProperty("tank/app@s1", tt.property)
+        if err != nil || value != tt.value || source != tt.source {
+            t.Errorf("%s = %q (%s), %v, want %q (%s)", tt.property, value, source, err, tt.value, tt.source)
+        }
+    }
+
+    if err := z.SetProperties([]string{"tank/app@missing"}, map[string]string{"zockimate:a": "b"}); err == nil {
+        t.Error("property set on a missing snapshot")
+    }
+    if err := z.SetProperties([]string{"tank/app@missing"}, nil); err != nil {
+        t.Errorf("empty property set failed: %v", err)
+    }
+    if err := f.Snapshot([]string{"tank/app@s2"}, map[string]string{"compression": "lz4"}); err == nil {
+        t.Error("native property accepted on a snapshot")
+    }
+}
+
+func TestOnReplica(t *testing.T) {
+    z, _ := newTestManager("tank/app", "tank/db")
+    if ok, err := z.OnReplica([]string{"tank/app@s1"}); err != nil || ok {
+        t.Errorf("OnReplica without replica = %v, %v, want false", ok, err)
+    }
+
+    logger := logrus.New()
+    logger.SetOutput(io.Discard)
+    replica := NewFakeBackend("backup/tank/app", "backup/tank/db")
+    if err := replica.Snapshot([]string{"backup/tank/app@s1"}, nil); err != nil {
+        t.Fatal(err)
+    }
+    z.SetReplica(NewFakeReplica("backup", replica, logger))
+
+    if ok, err := z.OnReplica([]string{"tank/app@s1"}); err != nil || !ok {
+        t.Errorf("OnReplica(replicated) = %v, %v, want true", ok, err)
+    }
+    if ok, err := z.OnReplica([]string{"tank/app@s1", "tank/db@s1"}); err != nil || ok {
+        t.Errorf("OnReplica(partially replicated) = %v, %v, want false", ok, err)
+    }
+}
+
+func TestDatasetFor(t *testing.T) {
+    datasets := []Dataset{
+        {Name: "boot-pool/ROOT", Mountpoint: "/"},
+        {Name: "tank", Mountpoint: "/mnt/tank"},
+        {Name: "tank/apps", Mountpoint: "/mnt/tank/apps"},
+        {Name: "tank/apps/db", Mountpoint: "/mnt/tank/apps/db"},
+        {Name: "tank/legacy", Mountpoint: "legacy"},
+        {Name: "tank/hidden", Mountpoint: "none"},
+    }
+
+    for _, c := range []struct {
+        path, want string
+    }{
+        {"/mnt/tank/apps/db", "tank/apps/db"},
+        {"/mnt/tank/apps/db/data/", "tank/apps/db"},
+        {"/mnt/tank/apps/web", "tank/apps"},
+        // Préfixe de chaîne sans être un sous-répertoire
+        {"/mnt/tank/apps/dbx", "tank/apps"},
+        {"/mnt/tank/../tank/apps/db", "tank/apps/db"},
+        {"/mnt/tank", "tank"},
+        // Le dataset racine n'est jamais retenu
+        {"/var/lib/docker/volumes/app/_data", ""},
+        {"legacy", ""},
+    } {
+        d, ok := DatasetFor(datasets, c.path)
+        if d.Name != c.want || ok != (c.want != "") {
+            t.Errorf("DatasetFor(%s) = %q, %v, want %q", c.path, d.Name, ok, c.want)
+        }
+    }
+}
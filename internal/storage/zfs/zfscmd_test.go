@@ -0,0 +1,723 @@
+// internal/storage/zfs/zfscmd_test.go
+package zfs
+
+import (
+    "encoding/json"
+    "fmt"
+    "io"
+    "os"
+    "path/filepath"
+    "sort"
+    "strings"
+    "testing"
+)
+
+// Fichier d'état de la commande zfs simulée
+const fakeZFSStateEnv = "ZOCKIMATE_FAKE_ZFS_STATE"
+
+// Lancé sous le nom zfs (lien créé par newFakeZFS), le binaire de test simule la
+// commande zfs sur un état enregistré dans un fichier JSON
+func TestMain(m *testing.M) {
+    if filepath.Base(os.Args[0]) == "zfs" {
+        os.Exit(runFakeZFS(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+    }
+    os.Exit(m.Run())
+}
+
+// fakeZFS est l'état d'une commande zfs simulée : datasets (avec un contenu
+// réduit à une chaîne), snapshots ordonnés par txg, clones, montages et propriétés
+// locales. Les messages d'erreur reprennent ceux de la commande zfs.
+type fakeZFS struct {
+    path string // Fichier d'état
+
+    TXG       uint64
+    Datasets  map[string]*fakeZFSDataset
+    Snapshots map[string]*fakeZFSSnapshot
+    Received  []fakeZFSReceive // Flux reçus, dans l'ordre
+}
+
+type fakeZFSDataset struct {
+    Data    string            // Contenu du dataset
+    Origin  string            // Snapshot d'origine d'un clone
+    Mounted bool
+    Busy    bool              // Démontage refusé (fichiers ouverts)
+    Props   map[string]string // Propriétés locales
+}
+
+type fakeZFSSnapshot struct {
+    TXG   uint64
+    GUID  uint64
+    Data  string
+    Props map[string]string
+}
+
+// fakeZFSReceive décrit un flux reçu par zfs receive
+type fakeZFSReceive struct {
+    Snapshot string // Snapshot créé
+    From     string // Snapshot de départ d'un envoi incrémental, vide pour un envoi complet
+}
+
+// fakeZFSStream est le flux produit par zfs send
+type fakeZFSStream struct {
+    Name     string // Nom du snapshot envoyé (sans dataset)
+    GUID     uint64
+    Data     string
+    FromGUID uint64 // Snapshot de départ d'un envoi incrémental
+}
+
+// newFakeZFS place une commande zfs simulée en tête du PATH, avec les datasets
+// donnés (et leurs parents) montés
+func newFakeZFS(t *testing.T, datasets ...string) *fakeZFS {
+    t.Helper()
+    exe, err := os.Executable()
+    if err != nil {
+        t.Fatal(err)
+    }
+    dir := t.TempDir()
+    if err := os.Symlink(exe, filepath.Join(dir, "zfs")); err != nil {
+        t.Fatal(err)
+    }
+    t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+    f := &fakeZFS{
+        path:      filepath.Join(dir, "state.json"),
+        Datasets:  make(map[string]*fakeZFSDataset),
+        Snapshots: make(map[string]*fakeZFSSnapshot),
+    }
+    t.Setenv(fakeZFSStateEnv, f.path)
+    for _, d := range datasets {
+        f.create(d, true)
+    }
+    f.save(t)
+    return f
+}
+
+// load relit l'état modifié par les commandes
+func (f *fakeZFS) load(t *testing.T) {
+    t.Helper()
+    if err := f.read(); err != nil {
+        t.Fatal(err)
+    }
+}
+
+// save enregistre l'état pour les commandes suivantes
+func (f *fakeZFS) save(t *testing.T) {
+    t.Helper()
+    if err := f.write(); err != nil {
+        t.Fatal(err)
+    }
+}
+
+func (f *fakeZFS) read() error {
+    data, err := os.ReadFile(f.path)
+    if err != nil {
+        return err
+    }
+    // Unmarshal complète les maps existantes : repartir d'un état vide
+    f.Datasets, f.Snapshots, f.Received = nil, nil, nil
+    return json.Unmarshal(data, f)
+}
+
+func (f *fakeZFS) write() error {
+    data, err := json.Marshal(f)
+    if err != nil {
+        return err
+    }
+    return os.WriteFile(f.path, data, 0600)
+}
+
+// snapshot crée des snapshots du contenu actuel des datasets
+func (f *fakeZFS) snapshot(t *testing.T, snapshots ...string) {
+    t.Helper()
+    f.load(t)
+    for _, s := range snapshots {
+        dataset, _, _ := strings.Cut(s, "@")
+        f.TXG++
+        f.Snapshots[s] = &fakeZFSSnapshot{TXG: f.TXG, GUID: 1000 + f.TXG, Data: f.Datasets[dataset].Data}
+    }
+    f.save(t)
+}
+
+// setData modifie le contenu d'un dataset
+func (f *fakeZFS) setData(t *testing.T, dataset, data string) {
+    t.Helper()
+    f.load(t)
+    f.Datasets[dataset].Data = data
+    f.save(t)
+}
+
+// snapshotsOf retourne les snapshots d'un dataset, du plus ancien au plus récent
+func (f *fakeZFS) snapshotsOf(dataset string) []string {
+    var names []string
+    for name := range f.Snapshots {
+        if strings.HasPrefix(name, dataset+"@") {
+            names = append(names, name)
+        }
+    }
+    sort.Slice(names, func(i, j int) bool { return f.Snapshots[names[i]].TXG < f.Snapshots[names[j]].TXG })
+    return names
+}
+
+// create crée un dataset et ses parents
+func (f *fakeZFS) create(name string, mounted bool) {
+    for i := range name {
+        if name[i] == '/' && f.Datasets[name[:i]] == nil {
+            f.Datasets[name[:i]] = &fakeZFSDataset{Mounted: mounted, Props: map[string]string{}}
+        }
+    }
+    if f.Datasets[name] == nil {
+        f.Datasets[name] = &fakeZFSDataset{Mounted: mounted, Props: map[string]string{}}
+    }
+}
+
+func (f *fakeZFS) exists(name string) bool {
+    return f.Datasets[name] != nil || f.Snapshots[name] != nil
+}
+
+// property retourne la valeur et la source d'une propriété, comme zfs get
+func (f *fakeZFS) property(name, property string) (string, string) {
+    props := map[string]string{}
+    if d := f.Datasets[name]; d != nil {
+        props = d.Props
+    } else if s := f.Snapshots[name]; s != nil {
+        props = s.Props
+        if property == "guid" {
+            return fmt.Sprint(s.GUID), "-"
+        }
+    }
+    if v, ok := props[property]; ok {
+        return v, "local"
+    }
+
+    // Héritage depuis le dataset (pour un snapshot) puis ses parents
+    dataset, _, isSnapshot := strings.Cut(name, "@")
+    switch {
+    case property == "mountpoint" && !isSnapshot:
+        for parent := parentOf(dataset); parent != ""; parent = parentOf(parent) {
+            if mp, ok := f.Datasets[parent].Props["mountpoint"]; ok {
+                return mp + strings.TrimPrefix(dataset, parent), "inherited from " + parent
+            }
+        }
+        return "/" + dataset, "default"
+    case property == "canmount" && !isSnapshot:
+        return "on", "default"
+    case strings.Contains(property, ":"):
+        // Un snapshot hérite de son dataset, un dataset de ses parents
+        parent := dataset
+        if !isSnapshot {
+            parent = parentOf(dataset)
+        }
+        for ; parent != ""; parent = parentOf(parent) {
+            if v, ok := f.Datasets[parent].Props[property]; ok {
+                return v, "inherited from " + parent
+            }
+        }
+    }
+    return "-", "-"
+}
+
+// parentOf retourne le dataset parent (vide pour la racine d'un pool)
+func parentOf(dataset string) string {
+    if i := strings.LastIndex(dataset, "/"); i >= 0 {
+        return dataset[:i]
+    }
+    return ""
+}
+
+// rename renomme un dataset, ses enfants et ses snapshots, et les origines des clones
+func (f *fakeZFS) rename(from, to string) {
+    renamed := func(name string) string {
+        if name == from || strings.HasPrefix(name, from+"/") || strings.HasPrefix(name, from+"@") {
+            return to + strings.TrimPrefix(name, from)
+        }
+        return name
+    }
+    datasets := make(map[string]*fakeZFSDataset, len(f.Datasets))
+    for name, d := range f.Datasets {
+        d.Origin = renamed(d.Origin)
+        datasets[renamed(name)] = d
+    }
+    snapshots := make(map[string]*fakeZFSSnapshot, len(f.Snapshots))
+    for name, s := range f.Snapshots {
+        snapshots[renamed(name)] = s
+    }
+    f.Datasets, f.Snapshots = datasets, snapshots
+}
+
+// runFakeZFS exécute une commande zfs simulée et retourne son code de sortie
+func runFakeZFS(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+    f := &fakeZFS{path: os.Getenv(fakeZFSStateEnv)}
+
+    // receive lit tout le flux avant de charger l'état : send s'exécute en parallèle
+    var stream []byte
+    if len(args) > 0 && args[0] == "receive" {
+        var err error
+        if stream, err = io.ReadAll(stdin); err != nil {
+            fmt.Fprintln(stderr, err)
+            return 1
+        }
+    }
+    if err := f.read(); err != nil {
+        fmt.Fprintln(stderr, err)
+        return 1
+    }
+
+    out, modified, err := f.run(args, stream)
+    io.WriteString(stdout, out) //nolint:errcheck
+    if err != nil {
+        fmt.Fprintln(stderr, err)
+        return 1
+    }
+    if modified {
+        if err := f.write(); err != nil {
+            fmt.Fprintln(stderr, err)
+            return 1
+        }
+    }
+    return 0
+}
+
+// fakeZFSArgs sépare les options (avec leurs valeurs) des arguments
+func fakeZFSArgs(args []string, withValue string) (map[string][]string, []string) {
+    opts := make(map[string][]string)
+    var rest []string
+    for i := 0; i < len(args); i++ {
+        a := args[i]
+        if !strings.HasPrefix(a, "-") || len(a) < 2 {
+            rest = append(rest, a)
+            continue
+        }
+        for j, c := range a[1:] {
+            flag := string(c)
+            if strings.Contains(withValue, flag) {
+                value := a[2+j:]
+                if value == "" && i+1 < len(args) {
+                    i++
+                    value = args[i]
+                }
+                opts[flag] = append(opts[flag], value)
+                break
+            }
+            opts[flag] = append(opts[flag], "")
+        }
+    }
+    return opts, rest
+}
+
+// propertyOptions convertit des options -o k=v en propriétés
+func propertyOptions(values []string) map[string]string {
+    props := make(map[string]string)
+    for _, v := range values {
+        k, val, _ := strings.Cut(v, "=")
+        props[k] = val
+    }
+    return props
+}
+
+func (f *fakeZFS) run(args []string, stream []byte) (string, bool, error) {
+    if len(args) == 0 {
+        return "", false, fmt.Errorf("missing command")
+    }
+    cmd, args := args[0], args[1:]
+    switch cmd {
+    case "list":
+        return f.list(args)
+    case "get":
+        return f.get(args)
+    case "send":
+        return f.send(args)
+    case "receive":
+        return "", true, f.receive(args, stream)
+    default:
+        return "", true, f.modify(cmd, args)
+    }
+}
+
+func (f *fakeZFS) list(args []string) (string, bool, error) {
+    opts, rest := fakeZFSArgs(args, "otsd")
+    types := strings.Split(strings.Join(opts["t"], ","), ",")
+    wants := func(snapshot bool) bool {
+        for _, t := range types {
+            if t == "all" || t == "" || (t == "snapshot") == snapshot {
+                return true
+            }
+        }
+        return false
+    }
+
+    var roots []string
+    if len(rest) > 0 {
+        if !f.exists(rest[0]) {
+            return "", false, fmt.Errorf("cannot open '%s': dataset does not exist", rest[0])
+        }
+        roots = rest[:1]
+    }
+    included := func(name string) bool {
+        if roots == nil {
+            return true
+        }
+        root := roots[0]
+        dataset, _, _ := strings.Cut(name, "@")
+        switch {
+        case name == root:
+            return true
+        case opts["r"] != nil:
+            return dataset == root || strings.HasPrefix(dataset, root+"/")
+        case opts["d"] != nil:
+            return dataset == root && name != root ||
+                strings.HasPrefix(name, root+"/") && !strings.Contains(strings.TrimPrefix(name, root+"/"), "/") &&
+                    !strings.Contains(name, "@")
+        }
+        return false
+    }
+
+    var datasets, snapshots []string
+    if wants(false) {
+        for name := range f.Datasets {
+            if included(name) {
+                datasets = append(datasets, name)
+            }
+        }
+        sort.Strings(datasets)
+    }
+    if wants(true) {
+        for name := range f.Snapshots {
+            if included(name) {
+                snapshots = append(snapshots, name)
+            }
+        }
+        sort.Slice(snapshots, func(i, j int) bool { return f.Snapshots[snapshots[i]].TXG < f.Snapshots[snapshots[j]].TXG })
+    }
+
+    columns := strings.Split(opts["o"][0], ",")
+    var out strings.Builder
+    for _, name := range append(datasets, snapshots...) {
+        values := make([]string, len(columns))
+        for i, c := range columns {
+            if c == "name" {
+                values[i] = name
+            } else {
+                values[i], _ = f.property(name, c)
+            }
+        }
+        out.WriteString(strings.Join(values, "\t") + "\n")
+    }
+    return out.String(), false, nil
+}
+
+func (f *fakeZFS) get(args []string) (string, bool, error) {
+    opts, rest := fakeZFSArgs(args, "ost")
+    property, names := rest[0], rest[1:]
+    if len(names) == 0 {
+        snapshots := opts["t"] != nil && opts["t"][0] == "snapshot"
+        for name := range f.Datasets {
+            if !snapshots {
+                names = append(names, name)
+            }
+        }
+        for name := range f.Snapshots {
+            if snapshots {
+                names = append(names, name)
+            }
+        }
+        sort.Strings(names)
+    }
+    columns := []string{"name", "property", "value", "source"}
+    if opts["o"] != nil {
+        columns = strings.Split(opts["o"][0], ",")
+    }
+
+    var out strings.Builder
+    for _, name := range names {
+        if !f.exists(name) {
+            return "", false, fmt.Errorf("cannot open '%s': dataset does not exist", name)
+        }
+        properties := []string{property}
+        if property == "all" {
+            properties = nil
+            if d := f.Datasets[name]; d != nil {
+                for k := range d.Props {
+                    properties = append(properties, k)
+                }
+            } else {
+                for k := range f.Snapshots[name].Props {
+                    properties = append(properties, k)
+                }
+            }
+            sort.Strings(properties)
+        }
+        for _, p := range properties {
+            value, source := f.property(name, p)
+            if opts["s"] != nil && source != opts["s"][0] {
+                continue
+            }
+            row := map[string]string{"name": name, "property": p, "value": value, "source": source}
+            values := make([]string, len(columns))
+            for i, c := range columns {
+                values[i] = row[c]
+            }
+            out.WriteString(strings.Join(values, "\t") + "\n")
+        }
+    }
+    return out.String(), false, nil
+}
+
+// modify exécute les commandes modifiant l'état
+func (f *fakeZFS) modify(cmd string, args []string) error {
+    opts, rest := fakeZFSArgs(args, "o")
+    switch cmd {
+    case "create":
+        if f.exists(rest[0]) {
+            if opts["p"] != nil {
+                return nil
+            }
+            return fmt.Errorf("cannot create '%s': dataset already exists", rest[0])
+        }
+        f.create(rest[0], true)
+
+    case "snapshot":
+        for _, s := range rest {
+            dataset, _, _ := strings.Cut(s, "@")
+            if f.Datasets[dataset] == nil {
+                return fmt.Errorf("cannot open '%s': dataset does not exist", dataset)
+            }
+            if f.exists(s) {
+                return fmt.Errorf("cannot create snapshot '%s': dataset already exists", s)
+            }
+        }
+        for _, s := range rest {
+            dataset, _, _ := strings.Cut(s, "@")
+            f.TXG++
+            f.Snapshots[s] = &fakeZFSSnapshot{TXG: f.TXG, GUID: 1000 + f.TXG,
+                Data: f.Datasets[dataset].Data, Props: propertyOptions(opts["o"])}
+        }
+
+    case "set":
+        name := rest[len(rest)-1]
+        if !f.exists(name) {
+            return fmt.Errorf("cannot open '%s': dataset does not exist", name)
+        }
+        var props map[string]string
+        if d := f.Datasets[name]; d != nil {
+            props = d.Props
+        } else {
+            if f.Snapshots[name].Props == nil {
+                f.Snapshots[name].Props = make(map[string]string)
+            }
+            props = f.Snapshots[name].Props
+        }
+        for k, v := range propertyOptions(rest[:len(rest)-1]) {
+            props[k] = v
+        }
+
+    case "destroy":
+        return f.destroy(rest[0], opts["r"] != nil)
+
+    case "rollback":
+        s := f.Snapshots[rest[0]]
+        if s == nil {
+            return fmt.Errorf("cannot open '%s': dataset does not exist", rest[0])
+        }
+        dataset, _, _ := strings.Cut(rest[0], "@")
+        if snapshots := f.snapshotsOf(dataset); snapshots[len(snapshots)-1] != rest[0] {
+            return fmt.Errorf("cannot rollback to '%s': more recent snapshots or bookmarks exist", rest[0])
+        }
+        f.Datasets[dataset].Data = s.Data
+
+    case "clone":
+        snapshot, clone := rest[0], rest[1]
+        s := f.Snapshots[snapshot]
+        if s == nil {
+            return fmt.Errorf("cannot open '%s': dataset does not exist", snapshot)
+        }
+        if f.exists(clone) {
+            return fmt.Errorf("cannot create '%s': dataset already exists", clone)
+        }
+        if i := strings.LastIndex(clone, "/"); i < 0 || f.Datasets[clone[:i]] == nil {
+            return fmt.Errorf("cannot create '%s': parent does not exist", clone)
+        }
+        props := propertyOptions(opts["o"])
+        f.Datasets[clone] = &fakeZFSDataset{Data: s.Data, Origin: snapshot,
+            Mounted: props["canmount"] != "noauto", Props: props}
+
+    case "rename":
+        from, to := rest[0], rest[1]
+        if f.Datasets[from] == nil {
+            return fmt.Errorf("cannot open '%s': dataset does not exist", from)
+        }
+        if f.exists(to) {
+            return fmt.Errorf("cannot rename to '%s': dataset already exists", to)
+        }
+        if opts["u"] == nil {
+            f.Datasets[from].Mounted = true
+        }
+        f.rename(from, to)
+
+    case "promote":
+        return f.promote(rest[0])
+
+    case "mount", "unmount":
+        d := f.Datasets[rest[0]]
+        if d == nil {
+            return fmt.Errorf("cannot open '%s': dataset does not exist", rest[0])
+        }
+        mp, _ := f.property(rest[0], "mountpoint")
+        switch {
+        case cmd == "mount" && d.Mounted:
+            return fmt.Errorf("cannot mount '%s': filesystem already mounted", rest[0])
+        case cmd == "unmount" && !d.Mounted:
+            return fmt.Errorf("cannot unmount '%s': not currently mounted", rest[0])
+        case cmd == "unmount" && d.Busy:
+            return fmt.Errorf("cannot unmount '%s': pool or dataset is busy", mp)
+        }
+        d.Mounted = cmd == "mount"
+
+    default:
+        return fmt.Errorf("unrecognized command '%s'", cmd)
+    }
+    return nil
+}
+
+// destroy détruit un snapshot, ou un dataset (récursivement avec -r)
+func (f *fakeZFS) destroy(name string, recursive bool) error {
+    if !f.exists(name) {
+        return fmt.Errorf("could not find any snapshots to destroy; check snapshot names.")
+    }
+    var snapshots, datasets []string
+    if f.Snapshots[name] != nil {
+        snapshots = []string{name}
+    } else {
+        for s := range f.Snapshots {
+            if d, _, _ := strings.Cut(s, "@"); d == name || strings.HasPrefix(d, name+"/") {
+                snapshots = append(snapshots, s)
+            }
+        }
+        for d := range f.Datasets {
+            if d == name || strings.HasPrefix(d, name+"/") {
+                datasets = append(datasets, d)
+            }
+        }
+        if !recursive && len(datasets)+len(snapshots) > 1 {
+            return fmt.Errorf("cannot destroy '%s': filesystem has children", name)
+        }
+    }
+    for _, s := range snapshots {
+        for clone, d := range f.Datasets {
+            if d.Origin == s && !contains(datasets, clone) {
+                return fmt.Errorf("cannot destroy '%s': snapshot has dependent clones", s)
+            }
+        }
+    }
+    for _, s := range snapshots {
+        delete(f.Snapshots, s)
+    }
+    for _, d := range datasets {
+        delete(f.Datasets, d)
+    }
+    return nil
+}
+
+// promote inverse la dépendance entre un clone et son origine : les snapshots
+// de l'origine jusqu'au snapshot cloné passent au clone, qui reprend l'origine
+// de son ancien parent
+func (f *fakeZFS) promote(name string) error {
+    d := f.Datasets[name]
+    if d == nil {
+        return fmt.Errorf("cannot open '%s': dataset does not exist", name)
+    }
+    if d.Origin == "" {
+        return fmt.Errorf("cannot promote '%s': not a cloned filesystem", name)
+    }
+    parent, _, _ := strings.Cut(d.Origin, "@")
+    limit := f.Snapshots[d.Origin].TXG
+    moved := make(map[string]string)
+    for _, s := range f.snapshotsOf(parent) {
+        if f.Snapshots[s].TXG <= limit {
+            _, snapName, _ := strings.Cut(s, "@")
+            if f.exists(name + "@" + snapName) {
+                return fmt.Errorf("cannot promote '%s': snapshot name conflict", name)
+            }
+            moved[s] = name + "@" + snapName
+        }
+    }
+    for from, to := range moved {
+        f.Snapshots[to] = f.Snapshots[from]
+        delete(f.Snapshots, from)
+    }
+    for _, other := range f.Datasets {
+        if to, ok := moved[other.Origin]; ok {
+            other.Origin = to
+        }
+    }
+    // Le clone promu reprend l'origine de son ancien parent
+    d.Origin, f.Datasets[parent].Origin = f.Datasets[parent].Origin, moved[d.Origin]
+    return nil
+}
+
+func (f *fakeZFS) send(args []string) (string, bool, error) {
+    opts, rest := fakeZFSArgs(args, "i")
+    s := f.Snapshots[rest[0]]
+    if s == nil {
+        return "", false, fmt.Errorf("cannot open '%s': dataset does not exist", rest[0])
+    }
+    dataset, name, _ := strings.Cut(rest[0], "@")
+    stream := fakeZFSStream{Name: name, GUID: s.GUID, Data: s.Data}
+    if opts["i"] != nil {
+        from := f.Snapshots[dataset+opts["i"][0]]
+        if from == nil {
+            return "", false, fmt.Errorf("incremental source (%s) does not exist", opts["i"][0])
+        }
+        stream.FromGUID = from.GUID
+    }
+    data, err := json.Marshal(stream)
+    return string(data), false, err
+}
+
+func (f *fakeZFS) receive(args []string, data []byte) error {
+    opts, rest := fakeZFSArgs(args, "o")
+    target := rest[0]
+    var stream fakeZFSStream
+    if err := json.Unmarshal(data, &stream); err != nil {
+        return fmt.Errorf("cannot receive: invalid stream")
+    }
+    received := fakeZFSReceive{Snapshot: target + "@" + stream.Name}
+
+    if stream.FromGUID == 0 {
+        if f.exists(target) {
+            return fmt.Errorf("cannot receive new filesystem stream: destination '%s' exists\nmust specify -F to overwrite it", target)
+        }
+        if i := strings.LastIndex(target, "/"); i < 0 || f.Datasets[target[:i]] == nil {
+            return fmt.Errorf("cannot receive new filesystem stream: parent of '%s' does not exist", target)
+        }
+        f.Datasets[target] = &fakeZFSDataset{Data: stream.Data, Mounted: opts["u"] == nil,
+            Props: propertyOptions(opts["o"])}
+    } else {
+        if f.Datasets[target] == nil {
+            return fmt.Errorf("cannot receive incremental stream: destination '%s' does not exist", target)
+        }
+        snapshots := f.snapshotsOf(target)
+        base := -1
+        for i, s := range snapshots {
+            if f.Snapshots[s].GUID == stream.FromGUID {
+                base = i
+            }
+        }
+        if base < 0 {
+            return fmt.Errorf("cannot receive incremental stream: most recent snapshot of %s does not match incremental source", target)
+        }
+        received.From = snapshots[base]
+        if newer := snapshots[base+1:]; len(newer) > 0 {
+            if opts["F"] == nil {
+                return fmt.Errorf("cannot receive incremental stream: destination %s has been modified since most recent snapshot", target)
+            }
+            for _, s := range newer {
+                delete(f.Snapshots, s)
+            }
+        }
+        f.Datasets[target].Data = stream.Data
+    }
+    f.TXG++
+    f.Snapshots[received.Snapshot] = &fakeZFSSnapshot{TXG: f.TXG, GUID: stream.GUID, Data: stream.Data}
+    f.Received = append(f.Received, received)
+    return nil
+}
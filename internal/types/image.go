@@ -15,6 +15,7 @@ type ImageReference struct {
     Tag         string   // Tag de l'image
     Original    string   // Référence originale (avant rollback)
     Platform    string   // Architecture/OS
+    Labels      map[string]string // Labels de l'image
 }
 
 // String retourne une représentation lisible de l'ImageReference
@@ -0,0 +1,68 @@
+// internal/types/operation.go
+
+package types
+
+import "time"
+
+// Types d'opérations
+const (
+    OperationUpdate   = "update"
+    OperationRollback = "rollback"
+    OperationRestore  = "restore" // Recréation d'un conteneur supprimé
+)
+
+// Origine d'une opération
+const (
+    TriggerCLI      = "cli"      // Commande zockimate
+    TriggerSchedule = "schedule" // Planificateur (zockimate schedule)
+    TriggerAPI      = "api"      // Appel direct du manager
+)
+
+// Issue d'une opération
+const (
+    OutcomeRunning    = "running"     // En cours, ou interrompue sans être terminée
+    OutcomeSuccess    = "success"
+    OutcomeFailed     = "failed"
+    OutcomeRolledBack = "rolled_back" // Mise à jour échouée, conteneur restauré
+)
+
+// Operation est une mise à jour ou un rollback d'un conteneur
+type Operation struct {
+    ID               int64      `json:"id"`
+    Host             string     `json:"host"`
+    ContainerName    string     `json:"container_name"`
+    Type             string     `json:"type"`
+    TriggeredBy      string     `json:"triggered_by"`
+    StartedAt        time.Time  `json:"started_at"`
+    FinishedAt       *time.Time `json:"finished_at,omitempty"`
+    Outcome          string     `json:"outcome"`
+    Error            string     `json:"error,omitempty"`
+    SnapshotID       int64      `json:"snapshot_id,omitempty"`        // Snapshot de sécurité
+    TargetSnapshotID int64      `json:"target_snapshot_id,omitempty"` // Snapshot restauré (rollback)
+    FromImage        string     `json:"from_image,omitempty"`         // ID de l'image avant l'opération
+    ToImage          string     `json:"to_image,omitempty"`           // ID de l'image visée
+}
+
+// Duration retourne la durée de l'opération (0 si elle n'est pas terminée)
+func (o *Operation) Duration() time.Duration {
+    if o.FinishedAt == nil {
+        return 0
+    }
+    return o.FinishedAt.Sub(o.StartedAt)
+}
+
+// Types d'entrées de la chronologie
+const (
+    TimelineSnapshot  = "snapshot"
+    TimelineOperation = "operation"
+)
+
+// TimelineEntry est un snapshot ou une opération de la chronologie d'un conteneur
+type TimelineEntry struct {
+    Kind          string            `json:"kind"` // snapshot ou operation
+    Host          string            `json:"host"`
+    ContainerName string            `json:"container_name"`
+    Time          time.Time         `json:"time"`
+    Snapshot      *SnapshotMetadata `json:"snapshot,omitempty"`
+    Operation     *Operation        `json:"operation,omitempty"`
+}
@@ -0,0 +1,15 @@
+package options
+
+import "time"
+
+// CloneOptions définit les options du clonage d'un conteneur depuis un snapshot
+type CloneOptions struct {
+    SnapshotID int64         // Entrée à cloner (0 : la plus récente)
+    Now        bool          // Prendre d'abord un snapshot de l'état actuel et le cloner
+    Name       string        // Nom du conteneur clone
+    PortOffset int           // Décalage des ports publiés sur l'hôte (0 : ports retirés)
+    Enable     bool          // Laisser le clone géré par zockimate (désactivé par défaut)
+    NoData     bool          // Ne pas cloner les datasets ZFS (montages partagés avec l'original)
+    Force      bool          // Accepter une image dont la version exacte n'est pas garantie
+    Timeout    time.Duration // Attente du démarrage du clone
+}
@@ -0,0 +1,14 @@
+package options
+
+// ExportOptions définit les options de l'export de l'historique
+type ExportOptions struct {
+    Containers []string // Conteneurs à exporter (vide : tous)
+    Images     bool     // Inclure les images référencées (docker save)
+}
+
+// ImportOptions définit les options de l'import d'un export
+type ImportOptions struct {
+    Host       string // Rattacher les entrées à cet hôte (vide : hôte d'origine)
+    SkipImages bool   // Ne pas charger les images de l'archive
+    DryRun     bool   // Afficher ce qui serait importé sans modifier la base
+}
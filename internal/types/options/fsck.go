@@ -0,0 +1,7 @@
+package options
+
+// FsckOptions définit les options de la vérification base/ZFS
+type FsckOptions struct {
+    Fix    bool // Supprimer les snapshots orphelins et marquer les entrées sans données
+    DryRun bool // Afficher les corrections sans les appliquer
+}
@@ -8,7 +8,8 @@ type HistoryOptions struct {
     Last      bool          // Seulement la dernière entrée par conteneur
     SortBy    string        // Tri (date|container)
     JSON      bool          // Sortie au format JSON
-    Search    string        // Recherche dans les messages et statuts
+    Search    string        // Recherche dans les messages (et les erreurs des opérations)
+    Timeline  bool          // Chronologie fusionnée des snapshots et des opérations
     Since     time.Time     // Depuis date
     Before    time.Time     // Avant date
     Container []string      // Filtrer par conteneurs
@@ -0,0 +1,14 @@
+package options
+
+import "time"
+
+// RestoreOptions définit les options de la restauration d'un conteneur supprimé
+type RestoreOptions struct {
+    SnapshotID   int64         // Entrée à restaurer (0 : la plus récente)
+    As           string        // Nom du conteneur recréé (vide : nom d'origine)
+    Data         bool          // Restaurer aussi les données (ZFS et archives de montages)
+    Force        bool          // Accepter une image dont la version exacte n'est pas garantie
+    DestroyNewer bool          // zfs rollback -r : détruit les snapshots plus récents au lieu de cloner
+    Timeout      time.Duration // Attente du démarrage du conteneur
+    Trigger      string        // Origine de l'opération enregistrée (cli, schedule, api par défaut)
+}
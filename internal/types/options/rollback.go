@@ -3,10 +3,12 @@ package options
 import "time"
 
 type RollbackOptions struct {
-    SnapshotID  int64
-    Image       bool
-    Data        bool
-    Config      bool
-    Force       bool
-    Timeout     time.Duration
+    SnapshotID   int64
+    Image        bool
+    Data         bool
+    Config       bool
+    Force        bool
+    DestroyNewer bool // zfs rollback -r : détruit les snapshots plus récents au lieu de cloner
+    Timeout      time.Duration
+    Trigger      string // Origine de l'opération enregistrée (cli, schedule, api par défaut)
 }
\ No newline at end of file
@@ -11,6 +11,8 @@ type UpdateOptions struct {
     Timeout   time.Duration
     ContainerReadyTimeout   time.Duration
     Notify   bool
+    IgnoreWindow bool   // Ignorer les fenêtres de maintenance et périodes de gel
+    Trigger   string    // Origine de l'opération enregistrée (cli, schedule, api par défaut)
 }
 
 // Pour UpdateOptions
@@ -21,6 +23,7 @@ func NewUpdateOptions(opts ...UpdateOption) UpdateOptions {
         Timeout:   DefaultUpdateTimeout,
         ContainerReadyTimeout: DefaultContainerReadyTimeout,
         Notify: false,
+        IgnoreWindow: false,
     }
     for _, opt := range opts {
         opt(&options)
@@ -70,4 +73,16 @@ func WithUpdateNotify(notify bool) UpdateOption {
     return func(o *UpdateOptions) {
         o.Notify = notify
     }
-}
\ No newline at end of file
+}
+
+func WithUpdateIgnoreWindow(ignore bool) UpdateOption {
+    return func(o *UpdateOptions) {
+        o.IgnoreWindow = ignore
+    }
+}
+
+func WithUpdateTrigger(trigger string) UpdateOption {
+    return func(o *UpdateOptions) {
+        o.Trigger = trigger
+    }
+}
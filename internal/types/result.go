@@ -1,10 +1,16 @@
 
 package types
 
+import "time"
+
 type CheckResult struct {
     NeedsUpdate    bool              // Si une mise à jour est nécessaire
     CurrentImage   *ImageReference   // Référence de l'image actuelle
     UpdateImage    *ImageReference   // Référence de l'image à utiliser pour la mise à jour
+    UpdateRef      string            // Référence (tag) suivie pour la mise à jour
+    FirstSeen      time.Time         // Première détection de l'image distante
+    CooldownUntil  time.Time         // Date à partir de laquelle la mise à jour peut être appliquée
+    InCooldown     bool              // Mise à jour disponible mais image trop récente (zockimate.min_age)
     Error          error             // Erreur éventuelle
 }
 
@@ -13,6 +19,11 @@ type UpdateResult struct {
     Success        bool
     NeedsUpdate    bool
     RollbackNeeded bool
+    RolledBack     bool              // Mise à jour échouée, conteneur restauré
+    Deferred       bool              // Mise à jour reportée (hors fenêtre de maintenance)
+    DeferReason    string            // Raison du report
+    Security       bool              // Mise à jour corrigeant des vulnérabilités
+    VulnDelta      *VulnDelta        // Évolution des vulnérabilités (si analysée)
     SnapshotID     int64
     OldImage       *ImageReference
     NewImage       *ImageReference
@@ -30,6 +41,32 @@ type RollbackResult struct {
     Error          error
 }
 
+// RestoreResult est le résultat de la restauration d'un conteneur supprimé
+type RestoreResult struct {
+    ContainerName   string   // Conteneur de l'entrée restaurée
+    NewName         string   // Nom du conteneur recréé
+    Success         bool
+    SnapshotID      int64
+    Image           string   // Référence de l'image utilisée
+    NetworksCreated []string // Réseaux recréés
+    DataRestored    bool
+    Error           error
+}
+
+// CloneResult est le résultat du clonage ou de la suppression d'un clone
+type CloneResult struct {
+    ContainerName    string   // Conteneur cloné
+    NewName          string   // Conteneur clone
+    Success          bool
+    SnapshotID       int64
+    Image            string   // Référence de l'image utilisée
+    Datasets         []string // Clones ZFS créés (ou détruits)
+    Ports            []string // Ports publiés remappés (hôte d'origine -> hôte du clone)
+    SharedMounts     []string // Montages hors des datasets clonés, partagés avec l'original
+    ContainerRemoved bool     // Suppression : conteneur clone supprimé
+    Error            error
+}
+
 type RenameResult struct {
     OldName         string
     NewName         string
@@ -45,4 +82,115 @@ type RemoveResult struct {
     ContainerRemoved bool
     EntriesDeleted   int64
     Error           error
-}
\ No newline at end of file
+}
+type InspectResult struct {
+    ContainerName string         `json:"container_name"`
+    Image         string         `json:"image"`
+    Running       bool           `json:"running"`
+    Enabled       bool           `json:"enabled"`                 // Label zockimate.enable
+    DatasetLabel  string         `json:"dataset_label,omitempty"` // Valeur de zockimate.zfs_dataset
+    Recursive     bool           `json:"recursive"`               // Label zockimate.zfs_recursive
+    Mounts        []InspectMount `json:"mounts"`                  // Montages et datasets ZFS associés
+    Datasets      []string       `json:"datasets"`                // Datasets inclus dans les snapshots
+    ZFSError      string         `json:"zfs_error,omitempty"`     // ZFS inaccessible sur l'hôte
+}
+
+type InspectMount struct {
+    Type        string `json:"type"`
+    Source      string `json:"source"`      // Chemin sur l'hôte
+    Destination string `json:"destination"` // Point de montage dans le conteneur
+    RW          bool   `json:"rw"`
+    Dataset     string `json:"dataset,omitempty"` // Dataset ZFS contenant la source
+    Backup      bool   `json:"backup"`            // Archivé via zockimate.backup_mounts
+}
+
+// ZFSGroup est le groupe de snapshots ZFS référencé par une entrée de la base
+type ZFSGroup struct {
+    SnapshotID    int64    `json:"snapshot_id"`
+    ContainerName string   `json:"container_name"`
+    Snapshots     []string `json:"snapshots"`
+}
+
+// FsckResult est le résultat de la vérification base/ZFS d'un hôte
+type FsckResult struct {
+    Host             string      `json:"host"`
+    Datasets         []string    `json:"datasets"`                     // Datasets examinés
+    OrphanSnapshots  []string    `json:"orphan_snapshots"`             // Snapshots ZFS sans entrée en base
+    BrokenEntries    []FsckEntry `json:"broken_entries"`               // Entrées dont des snapshots ZFS manquent
+    ReplicaEntries   []FsckEntry `json:"replica_entries"`              // Entrées incomplètes que la réplique peut compléter
+    DeletedSnapshots int         `json:"deleted_snapshots"`            // Snapshots orphelins supprimés (--fix)
+    ClearedEntries   int         `json:"cleared_entries"`              // Entrées marquées sans données (--fix)
+    Error            string      `json:"error,omitempty"`
+}
+
+// FsckEntry est une entrée de la base dont le groupe de snapshots ZFS est incomplet
+type FsckEntry struct {
+    ZFSGroup
+    Missing []string `json:"missing"` // Snapshots du groupe qui n'existent plus
+}
+
+// RebuildResult est le résultat de la reconstruction de la base depuis les propriétés ZFS d'un hôte
+type RebuildResult struct {
+    Host       string   `json:"host"`
+    Found      int      `json:"found"`             // Snapshots zockimate trouvés (groupes)
+    Imported   int      `json:"imported"`          // Entrées recréées
+    Existing   int      `json:"existing"`          // Entrées déjà présentes en base
+    Renumbered int      `json:"renumbered"`        // Entrées importées sous un nouvel identifiant
+    Invalid    []string `json:"invalid,omitempty"` // Snapshots aux propriétés incomplètes
+    Error      string   `json:"error,omitempty"`
+}
+
+// MigrationInfo décrit une migration du schéma de la base
+type MigrationInfo struct {
+    Version   int        `json:"version"`
+    Name      string     `json:"name"`
+    AppliedAt *time.Time `json:"applied_at,omitempty"` // nil si la migration est en attente
+}
+
+// SchemaStatus est la version du schéma d'une base et ses migrations
+type SchemaStatus struct {
+    Current int             `json:"current"`           // Version du schéma de la base (0 : non versionnée)
+    Latest  int             `json:"latest"`            // Dernière version connue du binaire
+    Applied []MigrationInfo `json:"applied,omitempty"` // Migrations enregistrées dans schema_version
+    Pending []MigrationInfo `json:"pending,omitempty"` // Migrations restant à appliquer
+}
+
+// MigrationResult est le résultat de la migration d'une base
+type MigrationResult struct {
+    From    int             `json:"from"`
+    To      int             `json:"to"`
+    Pending []MigrationInfo `json:"pending,omitempty"` // Migrations à appliquer (avant exécution)
+    Applied []MigrationInfo `json:"applied,omitempty"` // Migrations appliquées
+    Backup  string          `json:"backup,omitempty"`  // Copie de la base prise avant migration
+}
+
+// VacuumResult est le résultat du compactage de la base
+type VacuumResult struct {
+    Converted   int64 `json:"converted"`    // Entrées dont les configurations ont rejoint les blobs
+    OrphanBlobs int64 `json:"orphan_blobs"` // Blobs non référencés supprimés
+    Blobs       int64 `json:"blobs"`        // Blobs conservés
+    BlobSize    int64 `json:"blob_size"`    // Taille décompressée des blobs
+    BlobStored  int64 `json:"blob_stored"`  // Taille compressée des blobs
+    SizeBefore  int64 `json:"size_before"`  // Taille de la base avant compactage (octets)
+    SizeAfter   int64 `json:"size_after"`   // Taille de la base après compactage (octets)
+}
+
+// ExportResult est le résultat de l'export de l'historique
+type ExportResult struct {
+    Snapshots     int      `json:"snapshots"`
+    Operations    int      `json:"operations"`
+    Blobs         int      `json:"blobs"`                    // Configurations distinctes
+    Images        int      `json:"images"`                   // Images incluses
+    MissingImages []string `json:"missing_images,omitempty"` // Images référencées absentes des hôtes
+}
+
+// ImportResult est le résultat de l'import d'un export
+type ImportResult struct {
+    Version            int `json:"version"`             // Version du format de l'archive
+    Snapshots          int `json:"snapshots"`           // Entrées de l'archive
+    Imported           int `json:"imported"`            // Entrées ajoutées
+    Existing           int `json:"existing"`            // Entrées déjà présentes (même conteneur et date)
+    Operations         int `json:"operations"`          // Opérations de l'archive
+    OperationsImported int `json:"operations_imported"` // Opérations ajoutées
+    Images             int `json:"images"`              // Images chargées
+}
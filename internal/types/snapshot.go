@@ -11,26 +11,41 @@ import (
 // ContainerSnapshot représente une sauvegarde d'un conteneur à un instant T
 type ContainerSnapshot struct {
     ID              int64           `json:"id"`
+    Host            string          `json:"host"`
     ContainerName   string          `json:"container_name"`
     ImageRef        ImageReference  `json:"image_ref"`
     Config          []byte          `json:"config"`         // Configuration Docker sérialisée
     HostConfig      []byte          `json:"host_config"`    // Configuration Host sérialisée
     NetworkConfig   []byte          `json:"network_config"` // Configuration réseau sérialisée
-    ZFSSnapshot     string          `json:"zfs_snapshot,omitempty"`
-    Status          string          `json:"status"`
+    ZFSSnapshots    []string        `json:"zfs_snapshots,omitempty"` // Groupe de snapshots ZFS pris au même instant
+    Mounts          []MountBackup   `json:"mounts,omitempty"`  // Volumes et binds sauvegardés
+    SnapshotMode    string          `json:"snapshot_mode,omitempty"`   // Cohérence des données : live, pause, stop, hook
+    FreezeDuration  time.Duration   `json:"freeze_duration,omitempty"` // Durée de gel du conteneur
     Message         string          `json:"message"`
     CreatedAt       time.Time       `json:"created_at"`
 }
 
+// MountBackup décrit la sauvegarde d'un volume nommé ou d'un bind mount
+type MountBackup struct {
+    Type        string `json:"type"`        // volume ou bind
+    Source      string `json:"source"`      // Nom du volume ou chemin sur l'hôte
+    Destination string `json:"destination"` // Point de montage dans le conteneur
+    Archive     string `json:"archive"`     // Chemin relatif dans le magasin d'archives
+}
+
 // SnapshotMetadata contient les métadonnées d'un snapshot pour l'historique
 type SnapshotMetadata struct {
     ID            int64     `json:"id"`
+    Host          string    `json:"host"`
     ContainerName string    `json:"container_name"`
     ImageTag      string    `json:"image_tag"`
     ImageID       string    `json:"image_id"`
     RepoDigest    string    `json:"repo_digest,omitempty"`
-    Status        string    `json:"status"`
+    Platform      string    `json:"platform,omitempty"`
     Message       string    `json:"message"`
+    VulnDelta     *VulnDelta `json:"vuln_delta,omitempty"`
+    SnapshotMode   string        `json:"snapshot_mode,omitempty"`
+    FreezeDuration time.Duration `json:"freeze_duration,omitempty"`
     CreatedAt     time.Time `json:"created_at"`
 }
 
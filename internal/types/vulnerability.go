@@ -0,0 +1,72 @@
+// internal/types/vulnerability.go
+
+package types
+
+import (
+    "fmt"
+    "strings"
+)
+
+const (
+    SeverityCritical = "CRITICAL"
+    SeverityHigh     = "HIGH"
+)
+
+// Vulnerability représente une vulnérabilité remontée par un scanner
+type Vulnerability struct {
+    ID       string `json:"id"`
+    Severity string `json:"severity"`
+}
+
+// VulnDelta représente l'évolution des vulnérabilités entre l'ancienne et la nouvelle image
+type VulnDelta struct {
+    Introduced []Vulnerability `json:"introduced,omitempty"` // Présentes uniquement dans la nouvelle image
+    Fixed      []Vulnerability `json:"fixed,omitempty"`      // Corrigées par la nouvelle image
+}
+
+// count compte les vulnérabilités d'une sévérité donnée
+func count(vulns []Vulnerability, severity string) int {
+    var n int
+    for _, v := range vulns {
+        if v.Severity == severity {
+            n++
+        }
+    }
+    return n
+}
+
+// IntroducedCritical retourne les vulnérabilités critiques introduites
+func (d *VulnDelta) IntroducedCritical() []string {
+    var ids []string
+    for _, v := range d.Introduced {
+        if v.Severity == SeverityCritical {
+            ids = append(ids, v.ID)
+        }
+    }
+    return ids
+}
+
+// IsSecurityUpdate indique si la mise à jour corrige des vulnérabilités
+func (d *VulnDelta) IsSecurityUpdate() bool {
+    return d != nil && len(d.Fixed) > 0
+}
+
+// String retourne un résumé lisible du delta
+func (d *VulnDelta) String() string {
+    if d == nil {
+        return ""
+    }
+    var parts []string
+    if len(d.Introduced) > 0 {
+        parts = append(parts, fmt.Sprintf("+%d new (%d critical, %d high)",
+            len(d.Introduced), count(d.Introduced, SeverityCritical), count(d.Introduced, SeverityHigh)))
+    }
+    if len(d.Fixed) > 0 {
+        parts = append(parts, fmt.Sprintf("-%d fixed (%d critical, %d high)",
+            len(d.Fixed), count(d.Fixed, SeverityCritical), count(d.Fixed, SeverityHigh)))
+    }
+    if len(parts) == 0 {
+        return "no change"
+    }
+    return strings.Join(parts, ", ")
+}
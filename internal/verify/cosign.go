@@ -0,0 +1,96 @@
+// internal/verify/cosign.go
+package verify
+
+import (
+    "context"
+    "crypto"
+    "encoding/base64"
+    "encoding/json"
+    "fmt"
+    "strings"
+
+    "zockimate/internal/registry"
+)
+
+// Annotation portant la signature cosign sur chaque couche du manifeste ".sig"
+const cosignSignatureAnnotation = "dev.cosignproject.cosign/signature"
+
+// cosignPayload représente le payload "simple signing" signé par cosign
+type cosignPayload struct {
+    Critical struct {
+        Identity struct {
+            DockerReference string `json:"docker-reference"`
+        } `json:"identity"`
+        Image struct {
+            DockerManifestDigest string `json:"docker-manifest-digest"`
+        } `json:"image"`
+        Type string `json:"type"`
+    } `json:"critical"`
+}
+
+// verifyCosign vérifie une signature cosign par clé, stockée sous le tag "sha256-<hex>.sig"
+func (v *Verifier) verifyCosign(ctx context.Context, repo registry.Repository, digest, keyPath string) error {
+    key, err := loadPublicKey(keyPath)
+    if err != nil {
+        return err
+    }
+
+    sigTag := strings.Replace(digest, ":", "-", 1) + ".sig"
+    manifest, _, err := v.registry.GetManifest(ctx, repo, sigTag)
+    if err != nil {
+        if registry.IsNotFound(err) {
+            return fmt.Errorf("no cosign signature found for %s@%s", repo, digest)
+        }
+        return fmt.Errorf("failed to fetch cosign signature: %w", err)
+    }
+
+    var lastErr error
+    for _, layer := range manifest.Layers {
+        encoded, ok := layer.Annotations[cosignSignatureAnnotation]
+        if !ok {
+            continue
+        }
+
+        if err := v.checkCosignLayer(ctx, repo, digest, layer, encoded, key); err != nil {
+            v.logger.Debugf("Rejected cosign signature layer %s: %v", layer.Digest, err)
+            lastErr = err
+            continue
+        }
+
+        v.logger.Debugf("Valid cosign signature for %s@%s", repo, digest)
+        return nil
+    }
+
+    if lastErr != nil {
+        return fmt.Errorf("no valid cosign signature for %s@%s: %w", repo, digest, lastErr)
+    }
+    return fmt.Errorf("no cosign signature found for %s@%s", repo, digest)
+}
+
+// checkCosignLayer vérifie une couche de signature cosign
+func (v *Verifier) checkCosignLayer(ctx context.Context, repo registry.Repository, digest string,
+    layer registry.Descriptor, encoded string, key crypto.PublicKey) error {
+
+    sig, err := base64.StdEncoding.DecodeString(encoded)
+    if err != nil {
+        return fmt.Errorf("invalid signature encoding: %w", err)
+    }
+
+    payload, err := v.registry.GetBlob(ctx, repo, layer.Digest)
+    if err != nil {
+        return fmt.Errorf("failed to fetch signature payload: %w", err)
+    }
+
+    if err := verifySignature(key, payload, sig); err != nil {
+        return err
+    }
+
+    var p cosignPayload
+    if err := json.Unmarshal(payload, &p); err != nil {
+        return fmt.Errorf("invalid signature payload: %w", err)
+    }
+    if p.Critical.Image.DockerManifestDigest != digest {
+        return fmt.Errorf("signature is for digest %s, not %s", p.Critical.Image.DockerManifestDigest, digest)
+    }
+    return nil
+}
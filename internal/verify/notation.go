@@ -0,0 +1,225 @@
+// internal/verify/notation.go
+package verify
+
+import (
+    "context"
+    "crypto"
+    "crypto/ecdsa"
+    "crypto/rsa"
+    "crypto/x509"
+    "encoding/base64"
+    "encoding/json"
+    "fmt"
+    "math/big"
+    "time"
+
+    "zockimate/internal/registry"
+)
+
+const (
+    // Type d'artefact des signatures Notary v2
+    notationArtifactType = "application/vnd.cncf.notary.signature"
+    // Enveloppe JWS (seule enveloppe supportée)
+    notationJWSMediaType = "application/jose+json"
+)
+
+// jwsEnvelope représente une signature Notary v2 au format JWS JSON
+type jwsEnvelope struct {
+    Payload   string `json:"payload"`
+    Protected string `json:"protected"`
+    Header    struct {
+        X5C []string `json:"x5c"`
+    } `json:"header"`
+    Signature string `json:"signature"`
+}
+
+// jwsProtectedHeader contient les champs utiles de l'en-tête protégé
+type jwsProtectedHeader struct {
+    Algorithm     string `json:"alg"`
+    ContentType   string `json:"cty"`
+    SigningScheme string `json:"io.cncf.notary.signingScheme"`
+}
+
+// notationPayload décrit l'artefact signé
+type notationPayload struct {
+    TargetArtifact registry.Descriptor `json:"targetArtifact"`
+}
+
+// verifyNotation vérifie une signature Notary v2 (JWS, schéma notary.x509) attachée au digest
+func (v *Verifier) verifyNotation(ctx context.Context, repo registry.Repository, digest, trustStore string) error {
+    roots, _, err := loadCertificates(trustStore)
+    if err != nil {
+        return err
+    }
+
+    referrers, err := v.registry.Referrers(ctx, repo, digest, notationArtifactType)
+    if err != nil {
+        return fmt.Errorf("failed to list notation signatures: %w", err)
+    }
+    if len(referrers) == 0 {
+        return fmt.Errorf("no notation signature found for %s@%s", repo, digest)
+    }
+
+    var lastErr error
+    for _, ref := range referrers {
+        if err := v.checkNotationSignature(ctx, repo, digest, ref, roots); err != nil {
+            v.logger.Debugf("Rejected notation signature %s: %v", ref.Digest, err)
+            lastErr = err
+            continue
+        }
+
+        v.logger.Debugf("Valid notation signature for %s@%s", repo, digest)
+        return nil
+    }
+
+    return fmt.Errorf("no valid notation signature for %s@%s: %w", repo, digest, lastErr)
+}
+
+// checkNotationSignature vérifie un manifeste de signature Notary v2
+func (v *Verifier) checkNotationSignature(ctx context.Context, repo registry.Repository, digest string,
+    ref registry.Descriptor, roots *x509.CertPool) error {
+
+    manifest, _, err := v.registry.GetManifest(ctx, repo, ref.Digest)
+    if err != nil {
+        return fmt.Errorf("failed to fetch signature manifest: %w", err)
+    }
+    if len(manifest.Layers) != 1 {
+        return fmt.Errorf("unexpected number of signature layers: %d", len(manifest.Layers))
+    }
+    if manifest.Layers[0].MediaType != notationJWSMediaType {
+        return fmt.Errorf("unsupported signature envelope %s (only JWS is supported)", manifest.Layers[0].MediaType)
+    }
+
+    blob, err := v.registry.GetBlob(ctx, repo, manifest.Layers[0].Digest)
+    if err != nil {
+        return fmt.Errorf("failed to fetch signature envelope: %w", err)
+    }
+
+    var env jwsEnvelope
+    if err := json.Unmarshal(blob, &env); err != nil {
+        return fmt.Errorf("invalid JWS envelope: %w", err)
+    }
+
+    var header jwsProtectedHeader
+    rawHeader, err := base64.RawURLEncoding.DecodeString(env.Protected)
+    if err != nil {
+        return fmt.Errorf("invalid JWS protected header: %w", err)
+    }
+    if err := json.Unmarshal(rawHeader, &header); err != nil {
+        return fmt.Errorf("invalid JWS protected header: %w", err)
+    }
+    if header.SigningScheme != "" && header.SigningScheme != "notary.x509" {
+        return fmt.Errorf("unsupported signing scheme %s", header.SigningScheme)
+    }
+
+    // Vérifier la chaîne de certificats contre le magasin de confiance
+    leaf, err := verifyCertChain(env.Header.X5C, roots)
+    if err != nil {
+        return err
+    }
+
+    sig, err := base64.RawURLEncoding.DecodeString(env.Signature)
+    if err != nil {
+        return fmt.Errorf("invalid JWS signature encoding: %w", err)
+    }
+    if err := verifyJWS(header.Algorithm, leaf.PublicKey, []byte(env.Protected+"."+env.Payload), sig); err != nil {
+        return err
+    }
+
+    // Vérifier que la signature couvre bien le digest attendu
+    rawPayload, err := base64.RawURLEncoding.DecodeString(env.Payload)
+    if err != nil {
+        return fmt.Errorf("invalid JWS payload encoding: %w", err)
+    }
+    var payload notationPayload
+    if err := json.Unmarshal(rawPayload, &payload); err != nil {
+        return fmt.Errorf("invalid notation payload: %w", err)
+    }
+    if payload.TargetArtifact.Digest != digest {
+        return fmt.Errorf("signature is for digest %s, not %s", payload.TargetArtifact.Digest, digest)
+    }
+
+    return nil
+}
+
+// verifyCertChain vérifie la chaîne x5c (feuille en premier) et retourne le certificat feuille
+func verifyCertChain(x5c []string, roots *x509.CertPool) (*x509.Certificate, error) {
+    if len(x5c) == 0 {
+        return nil, fmt.Errorf("no certificate chain in signature")
+    }
+
+    var certs []*x509.Certificate
+    for _, encoded := range x5c {
+        der, err := base64.StdEncoding.DecodeString(encoded)
+        if err != nil {
+            return nil, fmt.Errorf("invalid certificate encoding: %w", err)
+        }
+        cert, err := x509.ParseCertificate(der)
+        if err != nil {
+            return nil, fmt.Errorf("invalid certificate in chain: %w", err)
+        }
+        certs = append(certs, cert)
+    }
+
+    intermediates := x509.NewCertPool()
+    for _, c := range certs[1:] {
+        intermediates.AddCert(c)
+    }
+
+    if _, err := certs[0].Verify(x509.VerifyOptions{
+        Roots:         roots,
+        Intermediates: intermediates,
+        CurrentTime:   time.Now(),
+        KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning, x509.ExtKeyUsageAny},
+    }); err != nil {
+        return nil, fmt.Errorf("certificate chain not trusted: %w", err)
+    }
+
+    return certs[0], nil
+}
+
+// verifyJWS vérifie une signature JWS selon son algorithme
+func verifyJWS(alg string, key crypto.PublicKey, signingInput, sig []byte) error {
+    var hash crypto.Hash
+    switch alg {
+    case "PS256", "ES256":
+        hash = crypto.SHA256
+    case "PS384", "ES384":
+        hash = crypto.SHA384
+    case "PS512", "ES512":
+        hash = crypto.SHA512
+    default:
+        return fmt.Errorf("unsupported JWS algorithm %q", alg)
+    }
+
+    h := hash.New()
+    h.Write(signingInput)
+    digest := h.Sum(nil)
+
+    switch k := key.(type) {
+    case *rsa.PublicKey:
+        if alg[0] != 'P' {
+            return fmt.Errorf("algorithm %s does not match RSA key", alg)
+        }
+        if err := rsa.VerifyPSS(k, hash, digest, sig, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash}); err != nil {
+            return fmt.Errorf("invalid RSA-PSS signature")
+        }
+    case *ecdsa.PublicKey:
+        if alg[0] != 'E' {
+            return fmt.Errorf("algorithm %s does not match ECDSA key", alg)
+        }
+        // Les signatures JWS ECDSA sont la concaténation brute de R et S
+        size := len(sig) / 2
+        if size == 0 || len(sig)%2 != 0 {
+            return fmt.Errorf("invalid ECDSA signature length")
+        }
+        r := new(big.Int).SetBytes(sig[:size])
+        s := new(big.Int).SetBytes(sig[size:])
+        if !ecdsa.Verify(k, digest, r, s) {
+            return fmt.Errorf("invalid ECDSA signature")
+        }
+    default:
+        return fmt.Errorf("unsupported public key type %T", key)
+    }
+    return nil
+}
@@ -0,0 +1,175 @@
+// internal/verify/verify.go
+package verify
+
+import (
+    "context"
+    "crypto"
+    "crypto/ecdsa"
+    "crypto/ed25519"
+    "crypto/rsa"
+    "crypto/sha256"
+    "crypto/x509"
+    "encoding/pem"
+    "fmt"
+    "os"
+    "path/filepath"
+
+    "github.com/sirupsen/logrus"
+
+    "zockimate/internal/registry"
+)
+
+const (
+    // Méthodes de vérification supportées (label zockimate.verify)
+    MethodNone     = "none"
+    MethodCosign   = "cosign"
+    MethodNotation = "notation"
+)
+
+// Policy définit la vérification à appliquer à une image
+type Policy struct {
+    Method string // cosign ou notation
+    Key    string // Clé publique cosign (PEM) ou magasin de certificats notation (fichier ou répertoire PEM)
+}
+
+// Verifier vérifie les signatures des images avant mise à jour
+type Verifier struct {
+    registry *registry.Client
+    logger   *logrus.Logger
+}
+
+// NewVerifier crée un vérificateur de signatures
+func NewVerifier(reg *registry.Client, logger *logrus.Logger) *Verifier {
+    return &Verifier{
+        registry: reg,
+        logger:   logger,
+    }
+}
+
+// Verify vérifie qu'un digest d'image (manifeste) est signé selon la politique
+func (v *Verifier) Verify(ctx context.Context, imageRef, digest string, policy Policy) error {
+    repo, err := registry.ParseRepository(imageRef)
+    if err != nil {
+        return err
+    }
+
+    if policy.Key == "" {
+        return fmt.Errorf("no verification key configured for %s (set zockimate.verify_key)", policy.Method)
+    }
+
+    v.logger.Debugf("Verifying %s signature of %s@%s", policy.Method, repo, digest)
+
+    switch policy.Method {
+    case MethodCosign:
+        return v.verifyCosign(ctx, repo, digest, policy.Key)
+    case MethodNotation:
+        return v.verifyNotation(ctx, repo, digest, policy.Key)
+    default:
+        return fmt.Errorf("unsupported verification method %q (use cosign or notation)", policy.Method)
+    }
+}
+
+// loadPublicKey charge une clé publique PEM (PKIX ou certificat)
+func loadPublicKey(path string) (crypto.PublicKey, error) {
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return nil, fmt.Errorf("failed to read public key: %w", err)
+    }
+
+    block, _ := pem.Decode(data)
+    if block == nil {
+        return nil, fmt.Errorf("no PEM data found in %s", path)
+    }
+
+    switch block.Type {
+    case "CERTIFICATE":
+        cert, err := x509.ParseCertificate(block.Bytes)
+        if err != nil {
+            return nil, fmt.Errorf("failed to parse certificate %s: %w", path, err)
+        }
+        return cert.PublicKey, nil
+    default:
+        key, err := x509.ParsePKIXPublicKey(block.Bytes)
+        if err != nil {
+            return nil, fmt.Errorf("failed to parse public key %s: %w", path, err)
+        }
+        return key, nil
+    }
+}
+
+// loadCertificates charge les certificats PEM d'un fichier ou d'un répertoire
+func loadCertificates(path string) (*x509.CertPool, int, error) {
+    info, err := os.Stat(path)
+    if err != nil {
+        return nil, 0, fmt.Errorf("failed to access trust store: %w", err)
+    }
+
+    files := []string{path}
+    if info.IsDir() {
+        entries, err := os.ReadDir(path)
+        if err != nil {
+            return nil, 0, fmt.Errorf("failed to read trust store: %w", err)
+        }
+        files = files[:0]
+        for _, e := range entries {
+            if !e.IsDir() {
+                files = append(files, filepath.Join(path, e.Name()))
+            }
+        }
+    }
+
+    pool := x509.NewCertPool()
+    var count int
+    for _, f := range files {
+        data, err := os.ReadFile(f)
+        if err != nil {
+            return nil, 0, fmt.Errorf("failed to read certificate %s: %w", f, err)
+        }
+        for {
+            var block *pem.Block
+            block, data = pem.Decode(data)
+            if block == nil {
+                break
+            }
+            if block.Type != "CERTIFICATE" {
+                continue
+            }
+            cert, err := x509.ParseCertificate(block.Bytes)
+            if err != nil {
+                return nil, 0, fmt.Errorf("failed to parse certificate %s: %w", f, err)
+            }
+            pool.AddCert(cert)
+            count++
+        }
+    }
+
+    if count == 0 {
+        return nil, 0, fmt.Errorf("no certificate found in trust store %s", path)
+    }
+    return pool, count, nil
+}
+
+// verifySignature vérifie une signature cosign (ECDSA/RSA en SHA-256, ou Ed25519)
+func verifySignature(key crypto.PublicKey, payload, sig []byte) error {
+    digest := sha256.Sum256(payload)
+
+    switch k := key.(type) {
+    case *ecdsa.PublicKey:
+        if !ecdsa.VerifyASN1(k, digest[:], sig) {
+            return fmt.Errorf("invalid ECDSA signature")
+        }
+    case *rsa.PublicKey:
+        if err := rsa.VerifyPKCS1v15(k, crypto.SHA256, digest[:], sig); err != nil {
+            if errPSS := rsa.VerifyPSS(k, crypto.SHA256, digest[:], sig, nil); errPSS != nil {
+                return fmt.Errorf("invalid RSA signature")
+            }
+        }
+    case ed25519.PublicKey:
+        if !ed25519.Verify(k, payload, sig) {
+            return fmt.Errorf("invalid Ed25519 signature")
+        }
+    default:
+        return fmt.Errorf("unsupported public key type %T", key)
+    }
+    return nil
+}
@@ -0,0 +1,367 @@
+// internal/verify/verify_test.go
+package verify
+
+import (
+    "context"
+    "crypto/ecdsa"
+    "crypto/elliptic"
+    "crypto/rand"
+    "crypto/sha256"
+    "crypto/x509"
+    "crypto/x509/pkix"
+    "encoding/base64"
+    "encoding/hex"
+    "encoding/json"
+    "encoding/pem"
+    "io"
+    "math/big"
+    "net/http"
+    "net/http/httptest"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/sirupsen/logrus"
+
+    "zockimate/internal/registry"
+)
+
+// fakeRegistry est un registre distribution v2 minimal en mémoire : manifestes
+// par tag ou digest, blobs par digest, API referrers optionnelle
+type fakeRegistry struct {
+    manifests map[string][]byte // "<repo>/<tag ou digest>"
+    blobs     map[string][]byte // "<repo>/<digest>"
+    referrers bool
+}
+
+func newFakeRegistry(t *testing.T) (*fakeRegistry, string) {
+    reg := &fakeRegistry{
+        manifests: make(map[string][]byte),
+        blobs:     make(map[string][]byte),
+        referrers: true,
+    }
+    srv := httptest.NewServer(http.HandlerFunc(reg.serve))
+    t.Cleanup(srv.Close)
+    return reg, strings.TrimPrefix(srv.URL, "http://")
+}
+
+func (r *fakeRegistry) serve(w http.ResponseWriter, req *http.Request) {
+    path := strings.TrimPrefix(req.URL.Path, "/v2/")
+    for _, kind := range []string{"/manifests/", "/blobs/", "/referrers/"} {
+        repo, ref, ok := strings.Cut(path, kind)
+        if !ok {
+            continue
+        }
+        switch kind {
+        case "/manifests/":
+            data, found := r.manifests[repo+"/"+ref]
+            if !found {
+                break
+            }
+            w.Header().Set("Content-Type", registry.MediaTypeOCIManifest)
+            w.Header().Set("Docker-Content-Digest", digestOf(data))
+            w.Write(data)
+            return
+        case "/blobs/":
+            if data, found := r.blobs[repo+"/"+ref]; found {
+                w.Write(data)
+                return
+            }
+        case "/referrers/":
+            if !r.referrers {
+                break
+            }
+            var index registry.Manifest
+            index.SchemaVersion = 2
+            index.MediaType = registry.MediaTypeOCIIndex
+            for key, data := range r.manifests {
+                var m registry.Manifest
+                json.Unmarshal(data, &m)
+                if strings.HasPrefix(key, repo+"/sha256:") && m.Subject != nil && m.Subject.Digest == ref {
+                    index.Manifests = append(index.Manifests, registry.Descriptor{
+                        MediaType:    registry.MediaTypeOCIManifest,
+                        ArtifactType: m.ArtifactType,
+                        Digest:       digestOf(data),
+                        Size:         int64(len(data)),
+                    })
+                }
+            }
+            w.Header().Set("Content-Type", registry.MediaTypeOCIIndex)
+            json.NewEncoder(w).Encode(index)
+            return
+        }
+    }
+    http.NotFound(w, req)
+}
+
+// putManifest enregistre un manifeste sous son digest et, si tag n'est pas vide, sous ce tag
+func (r *fakeRegistry) putManifest(repo, tag string, m registry.Manifest) string {
+    data, _ := json.Marshal(m)
+    digest := digestOf(data)
+    r.manifests[repo+"/"+digest] = data
+    if tag != "" {
+        r.manifests[repo+"/"+tag] = data
+    }
+    return digest
+}
+
+func (r *fakeRegistry) putBlob(repo string, data []byte) registry.Descriptor {
+    digest := digestOf(data)
+    r.blobs[repo+"/"+digest] = data
+    return registry.Descriptor{Digest: digest, Size: int64(len(data))}
+}
+
+func digestOf(data []byte) string {
+    sum := sha256.Sum256(data)
+    return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+func newTestVerifier() *Verifier {
+    logger := logrus.New()
+    logger.SetOutput(io.Discard)
+    return NewVerifier(registry.NewClient(nil, nil, logger), logger)
+}
+
+// writePEM écrit un bloc PEM dans un fichier temporaire
+func writePEM(t *testing.T, name, blockType string, der []byte) string {
+    path := filepath.Join(t.TempDir(), name)
+    if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0644); err != nil {
+        t.Fatal(err)
+    }
+    return path
+}
+
+func newKey(t *testing.T) *ecdsa.PrivateKey {
+    key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+    if err != nil {
+        t.Fatal(err)
+    }
+    return key
+}
+
+func writePublicKey(t *testing.T, key *ecdsa.PrivateKey) string {
+    der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+    if err != nil {
+        t.Fatal(err)
+    }
+    return writePEM(t, "cosign.pub", "PUBLIC KEY", der)
+}
+
+// pushCosignSignature publie une signature cosign de digest signée par key
+func pushCosignSignature(t *testing.T, reg *fakeRegistry, repo, digest, signedDigest string, key *ecdsa.PrivateKey) {
+    var p cosignPayload
+    p.Critical.Identity.DockerReference = repo
+    p.Critical.Image.DockerManifestDigest = signedDigest
+    p.Critical.Type = "cosign container image signature"
+    payload, _ := json.Marshal(p)
+
+    sum := sha256.Sum256(payload)
+    sig, err := ecdsa.SignASN1(rand.Reader, key, sum[:])
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    layer := reg.putBlob(repo, payload)
+    layer.MediaType = "application/vnd.dev.cosign.simplesigning.v1+json"
+    layer.Annotations = map[string]string{cosignSignatureAnnotation: base64.StdEncoding.EncodeToString(sig)}
+    reg.putManifest(repo, strings.Replace(digest, ":", "-", 1)+".sig", registry.Manifest{
+        SchemaVersion: 2,
+        MediaType:     registry.MediaTypeOCIManifest,
+        Layers:        []registry.Descriptor{layer},
+    })
+}
+
+func TestVerifyCosign(t *testing.T) {
+    reg, addr := newFakeRegistry(t)
+    repo := "team/app"
+    ref := addr + "/" + repo + ":1.0"
+    digest := digestOf([]byte("image manifest"))
+    other := digestOf([]byte("other manifest"))
+
+    key := newKey(t)
+    keyPath := writePublicKey(t, key)
+    pushCosignSignature(t, reg, repo, digest, digest, key)
+    pushCosignSignature(t, reg, repo, other, digest, key) // Signature rejouée sur un autre digest
+
+    otherKeyPath := writePublicKey(t, newKey(t))
+    v := newTestVerifier()
+    ctx := context.Background()
+
+    tests := []struct {
+        name    string
+        digest  string
+        key     string
+        wantErr string
+    }{
+        {"valid signature", digest, keyPath, ""},
+        {"untrusted key", digest, otherKeyPath, "invalid ECDSA signature"},
+        {"signature of another digest", other, keyPath, "signature is for digest"},
+        {"unsigned digest", digestOf([]byte("unsigned")), keyPath, "no cosign signature found"},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            err := v.Verify(ctx, ref, tt.digest, Policy{Method: MethodCosign, Key: tt.key})
+            checkError(t, err, tt.wantErr)
+        })
+    }
+}
+
+func TestVerifyPolicy(t *testing.T) {
+    v := newTestVerifier()
+    ctx := context.Background()
+    digest := digestOf([]byte("image manifest"))
+
+    err := v.Verify(ctx, "localhost:1/app:1.0", digest, Policy{Method: MethodCosign})
+    checkError(t, err, "no verification key configured")
+
+    err = v.Verify(ctx, "localhost:1/app:1.0", digest, Policy{Method: "gpg", Key: "key.pem"})
+    checkError(t, err, "unsupported verification method")
+}
+
+// notaryPKI est une autorité racine et un certificat de signature qu'elle a émis
+type notaryPKI struct {
+    rootPath string
+    leafDER  []byte
+    leafKey  *ecdsa.PrivateKey
+}
+
+func newNotaryPKI(t *testing.T) notaryPKI {
+    rootKey := newKey(t)
+    root := &x509.Certificate{
+        SerialNumber:          big.NewInt(1),
+        Subject:               pkix.Name{CommonName: "test root"},
+        NotBefore:             time.Now().Add(-time.Hour),
+        NotAfter:              time.Now().Add(time.Hour),
+        IsCA:                  true,
+        BasicConstraintsValid: true,
+        KeyUsage:              x509.KeyUsageCertSign,
+    }
+    rootDER, err := x509.CreateCertificate(rand.Reader, root, root, &rootKey.PublicKey, rootKey)
+    if err != nil {
+        t.Fatal(err)
+    }
+    root, _ = x509.ParseCertificate(rootDER)
+
+    leafKey := newKey(t)
+    leaf := &x509.Certificate{
+        SerialNumber: big.NewInt(2),
+        Subject:      pkix.Name{CommonName: "test signer"},
+        NotBefore:    time.Now().Add(-time.Hour),
+        NotAfter:     time.Now().Add(time.Hour),
+        KeyUsage:     x509.KeyUsageDigitalSignature,
+        ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+    }
+    leafDER, err := x509.CreateCertificate(rand.Reader, leaf, root, &leafKey.PublicKey, rootKey)
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    return notaryPKI{
+        rootPath: writePEM(t, "root.crt", "CERTIFICATE", rootDER),
+        leafDER:  leafDER,
+        leafKey:  leafKey,
+    }
+}
+
+// pushNotationSignature publie une signature Notary v2 JWS (ES256) de signedDigest,
+// attachée à digest
+func pushNotationSignature(t *testing.T, reg *fakeRegistry, repo, digest, signedDigest string, pki notaryPKI) {
+    protected, _ := json.Marshal(jwsProtectedHeader{
+        Algorithm:     "ES256",
+        ContentType:   "application/vnd.cncf.notary.payload.v1+json",
+        SigningScheme: "notary.x509",
+    })
+    payload, _ := json.Marshal(notationPayload{TargetArtifact: registry.Descriptor{
+        MediaType: registry.MediaTypeOCIManifest,
+        Digest:    signedDigest,
+    }})
+
+    env := jwsEnvelope{
+        Protected: base64.RawURLEncoding.EncodeToString(protected),
+        Payload:   base64.RawURLEncoding.EncodeToString(payload),
+    }
+    sum := sha256.Sum256([]byte(env.Protected + "." + env.Payload))
+    r, s, err := ecdsa.Sign(rand.Reader, pki.leafKey, sum[:])
+    if err != nil {
+        t.Fatal(err)
+    }
+    sig := make([]byte, 64)
+    r.FillBytes(sig[:32])
+    s.FillBytes(sig[32:])
+    env.Signature = base64.RawURLEncoding.EncodeToString(sig)
+    env.Header.X5C = []string{base64.StdEncoding.EncodeToString(pki.leafDER)}
+    envelope, _ := json.Marshal(env)
+
+    layer := reg.putBlob(repo, envelope)
+    layer.MediaType = notationJWSMediaType
+    sigDigest := reg.putManifest(repo, "", registry.Manifest{
+        SchemaVersion: 2,
+        MediaType:     registry.MediaTypeOCIManifest,
+        ArtifactType:  notationArtifactType,
+        Layers:        []registry.Descriptor{layer},
+        Subject:       &registry.Descriptor{MediaType: registry.MediaTypeOCIManifest, Digest: digest},
+    })
+
+    // Index du tag de repli "sha256-<hex>" pour les registres sans API referrers
+    reg.putManifest(repo, strings.Replace(digest, ":", "-", 1), registry.Manifest{
+        SchemaVersion: 2,
+        MediaType:     registry.MediaTypeOCIIndex,
+        Manifests: []registry.Descriptor{{
+            MediaType:    registry.MediaTypeOCIManifest,
+            ArtifactType: notationArtifactType,
+            Digest:       sigDigest,
+        }},
+    })
+}
+
+func TestVerifyNotation(t *testing.T) {
+    reg, addr := newFakeRegistry(t)
+    repo := "team/app"
+    ref := addr + "/" + repo + ":1.0"
+    digest := digestOf([]byte("image manifest"))
+    other := digestOf([]byte("other manifest"))
+
+    pki := newNotaryPKI(t)
+    pushNotationSignature(t, reg, repo, digest, digest, pki)
+    pushNotationSignature(t, reg, repo, other, digest, pki)
+
+    untrusted := newNotaryPKI(t)
+    v := newTestVerifier()
+    ctx := context.Background()
+
+    tests := []struct {
+        name      string
+        digest    string
+        store     string
+        referrers bool
+        wantErr   string
+    }{
+        {"valid signature", digest, pki.rootPath, true, ""},
+        {"valid signature through tag fallback", digest, pki.rootPath, false, ""},
+        {"untrusted certificate", digest, untrusted.rootPath, true, "certificate chain not trusted"},
+        {"signature of another digest", other, pki.rootPath, true, "signature is for digest"},
+        {"unsigned digest", digestOf([]byte("unsigned")), pki.rootPath, true, "no notation signature found"},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            reg.referrers = tt.referrers
+            err := v.Verify(ctx, ref, tt.digest, Policy{Method: MethodNotation, Key: tt.store})
+            checkError(t, err, tt.wantErr)
+        })
+    }
+}
+
+func checkError(t *testing.T, err error, want string) {
+    t.Helper()
+    if want == "" {
+        if err != nil {
+            t.Fatalf("unexpected error: %v", err)
+        }
+        return
+    }
+    if err == nil || !strings.Contains(err.Error(), want) {
+        t.Fatalf("error = %v, want %q", err, want)
+    }
+}
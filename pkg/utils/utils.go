@@ -3,6 +3,7 @@ package utils
 
 import (
     "fmt"
+    "strconv"
     "strings"
     "time"
 
@@ -40,6 +41,26 @@ func GetTimeout(labels map[string]string, defaultTimeout time.Duration, logger *
     return defaultTimeout
 }
 
+// ParseDuration parse une durée Go en acceptant aussi les jours (ex: "2d", "1d12h")
+func ParseDuration(s string) (time.Duration, error) {
+    if i := strings.Index(s, "d"); i > 0 {
+        days, err := strconv.Atoi(s[:i])
+        if err != nil {
+            return 0, fmt.Errorf("invalid duration: %q", s)
+        }
+        d := time.Duration(days) * 24 * time.Hour
+        if rest := s[i+1:]; rest != "" {
+            r, err := time.ParseDuration(rest)
+            if err != nil {
+                return 0, fmt.Errorf("invalid duration: %q", s)
+            }
+            d += r
+        }
+        return d, nil
+    }
+    return time.ParseDuration(s)
+}
+
 // Docker Label helpers
 // ------------------
 
@@ -49,11 +70,78 @@ func IsContainerEnabled(labels map[string]string) bool {
     return ok && enabled == "true"
 }
 
-// GetZFSDataset récupère le dataset ZFS configuré pour un conteneur
+// GetZFSDataset récupère le(s) dataset(s) ZFS configuré(s) pour un conteneur, ou "auto"
 func GetZFSDataset(labels map[string]string) string {
     return labels["zockimate.zfs_dataset"]
 }
 
+// IsZFSRecursive indique si les datasets enfants sont inclus dans les snapshots (zockimate.zfs_recursive)
+func IsZFSRecursive(labels map[string]string) bool {
+    return labels["zockimate.zfs_recursive"] == "true"
+}
+
+// GetBackupMounts récupère les montages à sauvegarder (zockimate.backup_mounts: volumes|all)
+func GetBackupMounts(labels map[string]string) string {
+    return strings.ToLower(labels["zockimate.backup_mounts"])
+}
+
+// GetSnapshotMode récupère le mode de cohérence des snapshots (zockimate.snapshot_mode: live|pause|stop|hook)
+func GetSnapshotMode(labels map[string]string) string {
+    return strings.ToLower(labels["zockimate.snapshot_mode"])
+}
+
+// GetFreezeTimeout récupère la durée maximale de gel du conteneur (zockimate.freeze_timeout)
+func GetFreezeTimeout(labels map[string]string, defaultTimeout time.Duration) (time.Duration, error) {
+    value, ok := labels["zockimate.freeze_timeout"]
+    if !ok || value == "" {
+        return defaultTimeout, nil
+    }
+    d, err := ParseDuration(value)
+    if err != nil || d <= 0 {
+        return 0, fmt.Errorf("invalid zockimate.freeze_timeout label: %q", value)
+    }
+    return d, nil
+}
+
+// GetQuiesceHooks récupère les commandes exécutées avant et après le snapshot en mode hook
+// (zockimate.quiesce_cmd, zockimate.unquiesce_cmd)
+func GetQuiesceHooks(labels map[string]string) (quiesce, unquiesce string) {
+    return labels["zockimate.quiesce_cmd"], labels["zockimate.unquiesce_cmd"]
+}
+
+// GetMaintenanceWindow récupère la fenêtre de maintenance configurée pour un conteneur
+func GetMaintenanceWindow(labels map[string]string) string {
+    return labels["zockimate.window"]
+}
+
+// GetMinAge récupère l'âge minimal d'une nouvelle image avant application (zockimate.min_age)
+func GetMinAge(labels map[string]string) (time.Duration, error) {
+    value, ok := labels["zockimate.min_age"]
+    if !ok || value == "" {
+        return 0, nil
+    }
+    d, err := ParseDuration(value)
+    if err != nil || d < 0 {
+        return 0, fmt.Errorf("invalid zockimate.min_age label: %q", value)
+    }
+    return d, nil
+}
+
+// GetVerifyMethod récupère la méthode de vérification de signature (zockimate.verify)
+func GetVerifyMethod(labels map[string]string) string {
+    return strings.ToLower(labels["zockimate.verify"])
+}
+
+// GetVerifyKey récupère la clé ou le magasin de confiance de vérification (zockimate.verify_key)
+func GetVerifyKey(labels map[string]string) string {
+    return labels["zockimate.verify_key"]
+}
+
+// GetVulnPolicy récupère la politique de vulnérabilités (zockimate.vuln_policy: block|flag)
+func GetVulnPolicy(labels map[string]string) string {
+    return strings.ToLower(labels["zockimate.vuln_policy"])
+}
+
 // ParseTime essaie de parser une chaîne de date avec différents formats
 func ParseTime(timeStr string) (time.Time, error) {
     for _, layout := range []string{
@@ -72,6 +160,23 @@ func ParseTime(timeStr string) (time.Time, error) {
 // Log helpers
 // ----------
 
+// SplitContainerID sépare un identifiant "hôte/conteneur" ; host est vide si non qualifié
+func SplitContainerID(id string) (host, name string) {
+    id = CleanContainerName(id)
+    if h, n, ok := strings.Cut(id, "/"); ok {
+        return h, n
+    }
+    return "", id
+}
+
+// ContainerID retourne l'identifiant affiché d'un conteneur, qualifié sauf sur l'hôte par défaut
+func ContainerID(host, name, defaultHost string) string {
+    if host == "" || host == defaultHost {
+        return name
+    }
+    return host + "/" + name
+}
+
 // CleanContainerName retire le "/" initial du nom d'un conteneur
 func CleanContainerName(name string) string {
     return strings.TrimPrefix(name, "/")